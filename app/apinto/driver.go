@@ -2,6 +2,7 @@ package main
 
 import (
 	anthropic "github.com/eolinker/apinto/drivers/ai-provider/authropic"
+	"github.com/eolinker/apinto/drivers/ai-provider/azure_openai"
 	"github.com/eolinker/apinto/drivers/ai-provider/baichuan"
 	"github.com/eolinker/apinto/drivers/ai-provider/bedrock"
 	"github.com/eolinker/apinto/drivers/ai-provider/chatglm"
@@ -17,7 +18,9 @@ import (
 	"github.com/eolinker/apinto/drivers/ai-provider/moonshot"
 	"github.com/eolinker/apinto/drivers/ai-provider/novita"
 	"github.com/eolinker/apinto/drivers/ai-provider/nvidia"
+	"github.com/eolinker/apinto/drivers/ai-provider/ollama"
 	"github.com/eolinker/apinto/drivers/ai-provider/openAI"
+	"github.com/eolinker/apinto/drivers/ai-provider/openai_compatible"
 	"github.com/eolinker/apinto/drivers/ai-provider/openrouter"
 	"github.com/eolinker/apinto/drivers/ai-provider/perfxcloud"
 	"github.com/eolinker/apinto/drivers/ai-provider/spark"
@@ -47,8 +50,10 @@ import (
 	"github.com/eolinker/apinto/drivers/resources/datasource/influxdbv2"
 	"github.com/eolinker/apinto/drivers/resources/redis"
 	dubbo2_router "github.com/eolinker/apinto/drivers/router/dubbo2-router"
+	dubbo3_router "github.com/eolinker/apinto/drivers/router/dubbo3-router"
 	grpc_router "github.com/eolinker/apinto/drivers/router/grpc-router"
 	http_router "github.com/eolinker/apinto/drivers/router/http-router"
+	thrift_router "github.com/eolinker/apinto/drivers/router/thrift-router"
 	"github.com/eolinker/apinto/drivers/service"
 	cache_strategy "github.com/eolinker/apinto/drivers/strategy/cache-strategy"
 	fuse_strategy "github.com/eolinker/apinto/drivers/strategy/fuse-strategy"
@@ -57,6 +62,7 @@ import (
 	visit_strategy "github.com/eolinker/apinto/drivers/strategy/visit-strategy"
 	"github.com/eolinker/apinto/drivers/template"
 	protocbuf "github.com/eolinker/apinto/drivers/transcode/protobuf"
+	"github.com/eolinker/apinto/drivers/transcode/thrift"
 
 	"github.com/eolinker/apinto/drivers/app"
 	"github.com/eolinker/apinto/drivers/output/prometheus"
@@ -68,6 +74,8 @@ func driverRegister(extenderRegister eosc.IExtenderDriverRegister) {
 	http_router.Register(extenderRegister)
 	grpc_router.Register(extenderRegister)
 	dubbo2_router.Register(extenderRegister)
+	dubbo3_router.Register(extenderRegister)
+	thrift_router.Register(extenderRegister)
 
 	// 上游服务
 	service.Register(extenderRegister)
@@ -107,6 +115,7 @@ func driverRegister(extenderRegister eosc.IExtenderDriverRegister) {
 
 	// 编码器
 	protocbuf.Register(extenderRegister)
+	thrift.Register(extenderRegister)
 
 	// 证书
 	certs.Register(extenderRegister)
@@ -143,4 +152,7 @@ func driverRegister(extenderRegister eosc.IExtenderDriverRegister) {
 	vertex_ai.Register(extenderRegister)
 	fakegpt.Register(extenderRegister)
 	zhinao.Register(extenderRegister)
+	openai_compatible.Register(extenderRegister)
+	ollama.Register(extenderRegister)
+	azure_openai.Register(extenderRegister)
 }
@@ -4,6 +4,7 @@ import (
 	access_relational "github.com/eolinker/apinto/drivers/plugins/access-relational"
 	"github.com/eolinker/apinto/drivers/plugins/acl"
 	ai_formatter "github.com/eolinker/apinto/drivers/plugins/ai-formatter"
+	ai_guard "github.com/eolinker/apinto/drivers/plugins/ai-guard"
 	ai_prompt "github.com/eolinker/apinto/drivers/plugins/ai-prompt"
 	"github.com/eolinker/apinto/drivers/plugins/app"
 	auto_redirect "github.com/eolinker/apinto/drivers/plugins/auto-redirect"
@@ -11,6 +12,7 @@ import (
 	data_transform "github.com/eolinker/apinto/drivers/plugins/data-transform"
 	dubbo2_proxy_rewrite "github.com/eolinker/apinto/drivers/plugins/dubbo2-proxy-rewrite"
 	extra_params "github.com/eolinker/apinto/drivers/plugins/extra-params"
+	grpc_message "github.com/eolinker/apinto/drivers/plugins/grpc-message"
 	grpc_proxy_rewrite "github.com/eolinker/apinto/drivers/plugins/grpc-proxy-rewrite"
 	"github.com/eolinker/apinto/drivers/plugins/gzip"
 	js_inject "github.com/eolinker/apinto/drivers/plugins/js-inject"
@@ -29,11 +31,16 @@ import (
 	body_check "github.com/eolinker/apinto/drivers/plugins/body-check"
 	circuit_breaker "github.com/eolinker/apinto/drivers/plugins/circuit-breaker"
 	"github.com/eolinker/apinto/drivers/plugins/counter"
+	dubbo2_to_grpc "github.com/eolinker/apinto/drivers/plugins/dubbo2-to-grpc"
 	dubbo2_to_http "github.com/eolinker/apinto/drivers/plugins/dubbo2-to-http"
+	dubbo3_to_http "github.com/eolinker/apinto/drivers/plugins/dubbo3-to-http"
 	extra_params_v2 "github.com/eolinker/apinto/drivers/plugins/extra-params_v2"
 	grpc_to_http "github.com/eolinker/apinto/drivers/plugins/gRPC-to-http"
+	"github.com/eolinker/apinto/drivers/plugins/graphql"
 	http_to_dubbo2 "github.com/eolinker/apinto/drivers/plugins/http-to-dubbo2"
+	http_to_dubbo3 "github.com/eolinker/apinto/drivers/plugins/http-to-dubbo3"
 	http_to_grpc "github.com/eolinker/apinto/drivers/plugins/http-to-gRPC"
+	http_to_thrift "github.com/eolinker/apinto/drivers/plugins/http-to-thrift"
 	"github.com/eolinker/apinto/drivers/plugins/http_mocking"
 	ip_restriction "github.com/eolinker/apinto/drivers/plugins/ip-restriction"
 	"github.com/eolinker/apinto/drivers/plugins/monitor"
@@ -48,6 +55,7 @@ import (
 	"github.com/eolinker/apinto/drivers/plugins/strategy/grey"
 	"github.com/eolinker/apinto/drivers/plugins/strategy/limiting"
 	"github.com/eolinker/apinto/drivers/plugins/strategy/visit"
+	websocket_message "github.com/eolinker/apinto/drivers/plugins/websocket-message"
 
 	"github.com/eolinker/eosc"
 )
@@ -66,11 +74,24 @@ func pluginRegister(extenderRegister eosc.IExtenderDriverRegister) {
 	dubbo2_proxy_rewrite.Register(extenderRegister)
 	http_to_dubbo2.Register(extenderRegister)
 	dubbo2_to_http.Register(extenderRegister)
+	dubbo2_to_grpc.Register(extenderRegister)
+	http_to_dubbo3.Register(extenderRegister)
+	dubbo3_to_http.Register(extenderRegister)
 
 	// gRPC协议相关插件
 	http_to_grpc.Register(extenderRegister)
 	grpc_to_http.Register(extenderRegister)
 	grpc_proxy_rewrite.Register(extenderRegister)
+	grpc_message.Register(extenderRegister)
+
+	// Thrift协议相关插件
+	http_to_thrift.Register(extenderRegister)
+
+	// GraphQL相关插件
+	graphql.Register(extenderRegister)
+
+	// websocket相关插件
+	websocket_message.Register(extenderRegister)
 
 	// 请求处理相关插件
 	body_check.Register(extenderRegister)
@@ -117,5 +138,6 @@ func pluginRegister(extenderRegister eosc.IExtenderDriverRegister) {
 
 	// ai相关插件
 	ai_prompt.Register(extenderRegister)
+	ai_guard.Register(extenderRegister)
 	ai_formatter.Register(extenderRegister)
 }
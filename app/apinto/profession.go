@@ -42,6 +42,20 @@ func ApintoProfession() []*eosc.ProfessionConfig {
 					Desc:   "dubbo2路由",
 					Params: nil,
 				},
+				{
+					Id:     "eolinker.com:apinto:dubbo3_router",
+					Name:   "dubbo3",
+					Label:  "dubbo3",
+					Desc:   "dubbo3 triple路由",
+					Params: nil,
+				},
+				{
+					Id:     "eolinker.com:apinto:thrift_router",
+					Name:   "thrift",
+					Label:  "thrift",
+					Desc:   "thrift路由",
+					Params: nil,
+				},
 			},
 			Mod: eosc.ProfessionConfig_Worker,
 		},
@@ -284,6 +298,12 @@ func ApintoProfession() []*eosc.ProfessionConfig {
 					Label: "protobuf编码器",
 					Desc:  "protobuf编码器",
 				},
+				{
+					Id:    "eolinker.com:apinto:thrift_transcode",
+					Name:  "thrift",
+					Label: "thrift编码器",
+					Desc:  "thrift IDL编码器",
+				},
 			},
 			Mod: eosc.ProfessionConfig_Worker,
 		},
@@ -461,6 +481,24 @@ func ApintoProfession() []*eosc.ProfessionConfig {
 					Label: "zhinao",
 					Desc:  "zhinao",
 				},
+				{
+					Id:    "eolinker.com:apinto:openai-compatible", // 插件ID
+					Name:  "openai-compatible",                     // 驱动名称
+					Label: "OpenAI兼容服务",
+					Desc:  "兼容OpenAI接口的自部署模型服务，如vLLM、LM Studio",
+				},
+				{
+					Id:    "eolinker.com:apinto:ollama", // 插件ID
+					Name:  "ollama",                     // 驱动名称
+					Label: "Ollama",
+					Desc:  "Ollama",
+				},
+				{
+					Id:    "eolinker.com:apinto:azure-openai", // 插件ID
+					Name:  "azure-openai",                     // 驱动名称
+					Label: "Azure OpenAI",
+					Desc:  "Azure OpenAI",
+				},
 			},
 			Mod: eosc.ProfessionConfig_Worker,
 		},
@@ -10,6 +10,8 @@ type IConverterDriver interface {
 type IConverter interface {
 	RequestConvert(ctx eocontext.EoContext, extender map[string]interface{}) error
 	ResponseConvert(ctx eocontext.EoContext) error
+	// StreamConvert 客户端请求流式返回时替代ResponseConvert，将上游原生的流式响应逐块转换为统一的SSE格式
+	StreamConvert(ctx eocontext.EoContext) error
 }
 
 type IChildConverter interface {
@@ -34,16 +34,14 @@ func init() {
 		panic(err)
 	}
 	for key, value := range models {
-		if value.ModelProperties != nil {
-			if v, ok := modelModes[value.ModelProperties.Mode]; ok {
-				modelConvert[key] = v
-			}
+		if v, ok := modelModes[value.ConvertMode()]; ok {
+			modelConvert[key] = v
 		}
 	}
 }
 
 type Converter struct {
-	apikey         string
+	keys           *ai_provider.KeyPool
 	version        string
 	balanceHandler eocontext.BalanceHandler
 	converter      convert.IConverter
@@ -57,7 +55,7 @@ func (c *Converter) RequestConvert(ctx eocontext.EoContext, extender map[string]
 	if err != nil {
 		return err
 	}
-	httpContext.Proxy().Header().SetHeader("x-api-key", c.apikey)
+	httpContext.Proxy().Header().SetHeader("x-api-key", c.keys.Next(ctx))
 	httpContext.Proxy().Header().SetHeader("anthropic-version", c.version)
 
 	return c.converter.RequestConvert(httpContext, extender)
@@ -67,9 +65,13 @@ func (c *Converter) ResponseConvert(ctx eocontext.EoContext) error {
 	return c.converter.ResponseConvert(ctx)
 }
 
+func (c *Converter) StreamConvert(ctx eocontext.EoContext) error {
+	return c.converter.StreamConvert(ctx)
+}
+
 type executor struct {
 	drivers.WorkerBase
-	apikey  string
+	keys    *ai_provider.KeyPool
 	version string
 	eocontext.BalanceHandler
 }
@@ -80,7 +82,7 @@ func (e *executor) GetConverter(model string) (convert.IConverter, bool) {
 		return nil, false
 	}
 
-	return &Converter{balanceHandler: e.BalanceHandler, converter: converter, apikey: e.apikey, version: e.version}, true
+	return &Converter{balanceHandler: e.BalanceHandler, converter: converter, keys: e.keys, version: e.version}, true
 }
 
 func (e *executor) GetModel(model string) (convert.FGenerateConfig, bool) {
@@ -140,7 +142,7 @@ func (e *executor) reset(conf *Config, workers map[eosc.RequireId]eosc.IWorker)
 		e.BalanceHandler = nil
 	}
 	e.version = conf.Version
-	e.apikey = conf.APIKey
+	e.keys = ai_provider.NewKeyPool(conf.APIKey, conf.APIKeys)
 	convert.Set(e.Id(), e)
 
 	return nil
@@ -156,6 +158,10 @@ func (e *executor) CheckSkill(skill string) bool {
 	return convert.CheckSkill(skill)
 }
 
+func (e *executor) Provider() string {
+	return name
+}
+
 type ModelConfig struct {
 	MaxTokens      int     `json:"max_tokens"`
 	ResponseFormat string  `json:"response_format"`
@@ -1,17 +1,47 @@
 package anthropic
 
+import "encoding/json"
+
 type ClientRequest struct {
 	Messages []*Message `json:"messages"`
 }
 
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string     `json:"role"`
+	Content []*Content `json:"content"`
 }
 
+// Content 内容块，type为text、image、tool_use或tool_result
 type Content struct {
+	Type   string       `json:"type"`
+	Text   string       `json:"text,omitempty"`
+	Source *ImageSource `json:"source,omitempty"`
+	// ID、Name及Input为tool_use的调用ID、工具名及参数
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+	// ToolUseID及Result为tool_result对应的调用ID及调用结果
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Result    string `json:"content,omitempty"`
+}
+
+// ImageSource 图片来源，type为base64或url
+type ImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+type Tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+type ToolChoice struct {
 	Type string `json:"type"`
-	Text string `json:"text"`
+	Name string `json:"name,omitempty"`
 }
 
 type Response struct {
@@ -20,4 +50,30 @@ type Response struct {
 	Role       string    `json:"role"`
 	Contents   []Content `json:"content"`
 	StopReason string    `json:"stop_reason"`
+	Usage      Usage     `json:"usage"`
+}
+
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// StreamEvent 流式响应事件，不同事件类型使用不同字段
+type StreamEvent struct {
+	Type    string   `json:"type"`
+	Message Response `json:"message"`
+	// Index 内容块的序号，ContentBlock为content_block_start开始的内容块
+	Index        int     `json:"index"`
+	ContentBlock Content `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage Usage `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
 }
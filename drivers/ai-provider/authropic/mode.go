@@ -52,14 +52,20 @@ func (c *Chat) RequestConvert(ctx eocontext.EoContext, extender map[string]inter
 	if err != nil {
 		return err
 	}
-	messages := make([]Message, 0, len(baseCfg.Config.Messages)+1)
-	for _, m := range baseCfg.Config.Messages {
-		messages = append(messages, Message{
-			Role:    m.Role,
-			Content: m.Content,
-		})
+	system, messages := convertMessages(baseCfg.Config.Messages)
+	if system != "" {
+		baseCfg.SetAppend("system", system)
 	}
 	baseCfg.SetAppend("messages", messages)
+	if len(baseCfg.Config.Tools) > 0 {
+		baseCfg.SetAppend("tools", convertTools(baseCfg.Config.Tools))
+		if choice := convertToolChoice(baseCfg.Config.ToolChoice); choice != nil {
+			baseCfg.SetAppend("tool_choice", choice)
+		}
+	}
+	if baseCfg.Config.Stream {
+		baseCfg.SetAppend("stream", true)
+	}
 	for k, v := range extender {
 		baseCfg.SetAppend(k, v)
 	}
@@ -88,12 +94,9 @@ func (c *Chat) ResponseConvert(ctx eocontext.EoContext) error {
 	}
 	responseBody := &ai_provider.ClientResponse{}
 	if len(data.Config.Contents) > 0 {
-		msg := data.Config.Contents[0]
-		responseBody.Message = ai_provider.Message{
-			Role:    data.Config.Role,
-			Content: msg.Text,
-		}
-		responseBody.FinishReason = data.Config.StopReason
+		responseBody.Message = responseMessage(data.Config.Role, data.Config.Contents)
+		responseBody.FinishReason = finishReason(data.Config.StopReason)
+		responseBody.Usage = ai_provider.NewUsage(data.Config.Usage.InputTokens, data.Config.Usage.OutputTokens, 0)
 	} else {
 		responseBody.Code = -1
 		responseBody.Error = "no response"
@@ -105,3 +108,49 @@ func (c *Chat) ResponseConvert(ctx eocontext.EoContext) error {
 	httpContext.Response().SetBody(body)
 	return nil
 }
+
+func (c *Chat) StreamConvert(ctx eocontext.EoContext) error {
+	return ai_provider.StreamConvert(ctx, ai_provider.NewSSEDecoder, streamConvert, c.ResponseConvert)
+}
+
+// streamConvert 转换Anthropic的流式事件，文本及工具调用参数的增量在content_block_delta中，结束原因及输出用量在message_delta中
+func streamConvert(event *ai_provider.StreamEvent) (*ai_provider.ClientResponse, error) {
+	switch event.Event {
+	case "message_start", "content_block_start", "content_block_delta", "message_delta", "error":
+	default:
+		return nil, nil
+	}
+	data := new(StreamEvent)
+	err := json.Unmarshal(event.Data, data)
+	if err != nil {
+		return nil, err
+	}
+	switch event.Event {
+	case "message_start":
+		usage := data.Message.Usage
+		return &ai_provider.ClientResponse{
+			Message: ai_provider.Message{Role: data.Message.Role},
+			Usage:   ai_provider.NewUsage(usage.InputTokens, usage.OutputTokens, 0),
+		}, nil
+	case "content_block_start":
+		// 工具调用开始时返回调用ID及工具名，参数在后续的input_json_delta中返回
+		if data.ContentBlock.Type != "tool_use" {
+			return nil, nil
+		}
+		call := ai_provider.NewToolCall(data.Index, data.ContentBlock.ID, data.ContentBlock.Name, "")
+		return &ai_provider.ClientResponse{Message: ai_provider.Message{Role: "assistant", ToolCalls: []*ai_provider.ToolCall{call}}}, nil
+	case "content_block_delta":
+		if data.Delta.Type == "input_json_delta" {
+			call := ai_provider.NewToolCall(data.Index, "", "", data.Delta.PartialJSON)
+			return &ai_provider.ClientResponse{Message: ai_provider.Message{Role: "assistant", ToolCalls: []*ai_provider.ToolCall{call}}}, nil
+		}
+		return &ai_provider.ClientResponse{Message: ai_provider.Message{Role: "assistant", Content: data.Delta.Text}}, nil
+	case "message_delta":
+		return &ai_provider.ClientResponse{
+			FinishReason: finishReason(data.Delta.StopReason),
+			Usage:        ai_provider.NewUsage(data.Usage.InputTokens, data.Usage.OutputTokens, 0),
+		}, nil
+	default:
+		return &ai_provider.ClientResponse{Code: -1, Error: data.Error.Message}, nil
+	}
+}
@@ -0,0 +1,125 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"strings"
+
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+)
+
+// convertMessages 转换为Anthropic的消息格式：system消息合并为system参数，工具调用结果以user消息的tool_result返回，连续相同角色的消息合并为一条
+func convertMessages(messages []*ai_provider.Message) (string, []*Message) {
+	systems := make([]string, 0, 1)
+	result := make([]*Message, 0, len(messages))
+	for _, m := range messages {
+		if m == nil {
+			continue
+		}
+		role := m.Role
+		var contents []*Content
+		switch role {
+		case "system":
+			systems = append(systems, m.Content)
+			continue
+		case "tool":
+			role = "user"
+			contents = []*Content{{Type: "tool_result", ToolUseID: m.ToolCallID, Result: m.Content}}
+		default:
+			contents = convertParts(m.ContentParts())
+			for _, call := range m.ToolCalls {
+				contents = append(contents, &Content{
+					Type:  "tool_use",
+					ID:    call.ID,
+					Name:  call.Function.Name,
+					Input: call.ArgumentsJSON(),
+				})
+			}
+		}
+		if len(contents) == 0 {
+			continue
+		}
+		if n := len(result); n > 0 && result[n-1].Role == role {
+			result[n-1].Content = append(result[n-1].Content, contents...)
+			continue
+		}
+		result = append(result, &Message{Role: role, Content: contents})
+	}
+	return strings.Join(systems, "\n"), result
+}
+
+func convertParts(parts []*ai_provider.ContentPart) []*Content {
+	contents := make([]*Content, 0, len(parts))
+	for _, p := range parts {
+		switch p.Type {
+		case ai_provider.PartText:
+			if p.Text != "" {
+				contents = append(contents, &Content{Type: "text", Text: p.Text})
+			}
+		case ai_provider.PartImageURL:
+			if p.ImageURL == nil {
+				continue
+			}
+			source := &ImageSource{Type: "url", URL: p.ImageURL.URL}
+			if mediaType, data, ok := p.ImageURL.Base64(); ok {
+				source = &ImageSource{Type: "base64", MediaType: mediaType, Data: data}
+			}
+			contents = append(contents, &Content{Type: "image", Source: source})
+		}
+	}
+	return contents
+}
+
+func convertTools(tools []*ai_provider.Tool) []*Tool {
+	result := make([]*Tool, 0, len(tools))
+	for _, t := range tools {
+		if t.Function == nil {
+			continue
+		}
+		schema := t.Function.Parameters
+		if len(schema) == 0 {
+			schema = json.RawMessage(`{"type":"object"}`)
+		}
+		result = append(result, &Tool{Name: t.Function.Name, Description: t.Function.Description, InputSchema: schema})
+	}
+	return result
+}
+
+// convertToolChoice required对应Anthropic的any，指定函数对应tool
+func convertToolChoice(choice *ai_provider.ToolChoice) *ToolChoice {
+	if choice == nil {
+		return nil
+	}
+	switch choice.Type {
+	case ai_provider.ToolChoiceAuto, ai_provider.ToolChoiceNone:
+		return &ToolChoice{Type: choice.Type}
+	case ai_provider.ToolChoiceRequired:
+		return &ToolChoice{Type: "any"}
+	case ai_provider.ToolChoiceFunction:
+		return &ToolChoice{Type: "tool", Name: choice.Function}
+	}
+	return nil
+}
+
+// responseMessage 合并响应中的文本块，tool_use转换为工具调用
+func responseMessage(role string, contents []Content) ai_provider.Message {
+	message := ai_provider.Message{Role: role}
+	texts := make([]string, 0, len(contents))
+	for _, c := range contents {
+		switch c.Type {
+		case "text":
+			texts = append(texts, c.Text)
+		case "tool_use":
+			message.ToolCalls = append(message.ToolCalls, ai_provider.NewToolCall(-1, c.ID, c.Name, string(c.Input)))
+		}
+	}
+	message.Content = strings.Join(texts, "")
+	return message
+}
+
+// finishReason tool_use转换为统一的tool_calls
+func finishReason(reason string) string {
+	if reason == "tool_use" {
+		return ai_provider.FinishToolCalls
+	}
+	return reason
+}
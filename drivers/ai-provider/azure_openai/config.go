@@ -0,0 +1,59 @@
+package azure_openai
+
+import (
+	"fmt"
+	"net/url"
+
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+	"github.com/eolinker/eosc"
+)
+
+const (
+	authAPIKey  = "api_key"
+	authEntraID = "entra_id"
+)
+
+type Config struct {
+	Base         string               `json:"base" label:"资源地址" required:"true" description:"Azure OpenAI资源的地址，如https://{resource}.openai.azure.com"`
+	APIVersion   string               `json:"api_version" label:"API版本" default:"2024-10-21"`
+	AuthType     string               `json:"auth_type" label:"鉴权方式" enum:"api_key,entra_id" default:"api_key"`
+	APIKey       string               `json:"api_key" label:"API Key" switch:"auth_type==='api_key'"`
+	APIKeys      []ai_provider.APIKey `json:"api_keys" label:"API Key池" switch:"auth_type==='api_key'" description:"多个API Key按权重轮询，被限流的Key在冷却期内暂停使用"`
+	TenantID     string               `json:"tenant_id" label:"租户ID" switch:"auth_type==='entra_id'"`
+	ClientID     string               `json:"client_id" label:"客户端ID" switch:"auth_type==='entra_id'"`
+	ClientSecret string               `json:"client_secret" label:"客户端密钥" switch:"auth_type==='entra_id'"`
+	Deployments  []*Deployment        `json:"deployments" label:"部署列表" required:"true"`
+}
+
+// Deployment Azure OpenAI的部署，请求时以部署名称作为模型名称
+type Deployment struct {
+	Name  string `json:"name" label:"部署名称" required:"true"`
+	Model string `json:"model" label:"模型" required:"true" description:"部署的OpenAI模型，如gpt-4o，用于获取模型类型、上下文长度及计费信息"`
+}
+
+func checkConfig(v interface{}) (*Config, error) {
+	conf, ok := v.(*Config)
+	if !ok {
+		return nil, eosc.ErrorConfigType
+	}
+	u, err := url.Parse(conf.Base)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("base url is invalid")
+	}
+	switch conf.AuthType {
+	case "", authAPIKey:
+		if conf.APIKey == "" && len(conf.APIKeys) == 0 {
+			return nil, fmt.Errorf("api_key is required")
+		}
+	case authEntraID:
+		if conf.TenantID == "" || conf.ClientID == "" || conf.ClientSecret == "" {
+			return nil, fmt.Errorf("tenant_id, client_id and client_secret are required")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported auth type: %s", conf.AuthType)
+	}
+	if len(conf.Deployments) == 0 {
+		return nil, fmt.Errorf("deployments is required")
+	}
+	return conf, nil
+}
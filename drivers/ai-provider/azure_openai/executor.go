@@ -0,0 +1,228 @@
+package azure_openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/eolinker/apinto/convert"
+	"github.com/eolinker/apinto/drivers"
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+	// 部署的模型信息取自OpenAI驱动加载的模型定义
+	_ "github.com/eolinker/apinto/drivers/ai-provider/openAI"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/eocontext"
+	http_context "github.com/eolinker/eosc/eocontext/http-context"
+	"github.com/eolinker/eosc/log"
+)
+
+const openAIProvider = "openai"
+
+var (
+	_ convert.IConverterDriver     = (*executor)(nil)
+	_ ai_provider.IEmbeddingDriver = (*executor)(nil)
+	_ ai_provider.IModelSource     = (*executor)(nil)
+)
+
+// newConverter 按部署的接口路径生成模型对应模式的转换器
+func newConverter(path string, mode string) (convert.IConverter, bool) {
+	switch mode {
+	case ai_provider.ModeChat.String():
+		return ai_provider.NewOpenAIChat(path + "/chat/completions"), true
+	case ai_provider.ModeComplete.String():
+		return ai_provider.NewOpenAICompletion(path + "/completions"), true
+	case ai_provider.ModeEmbedding.String():
+		return ai_provider.NewOpenAIEmbedding(path + "/embeddings"), true
+	case ai_provider.ModeSpeech.String():
+		return ai_provider.NewOpenAISpeech(path + "/audio/speech"), true
+	case ai_provider.ModeTranscription.String():
+		return ai_provider.NewOpenAITranscription(path + "/audio/transcriptions"), true
+	}
+	return nil, false
+}
+
+type deployment struct {
+	model     *ai_provider.Model
+	path      string
+	converter convert.IConverter
+}
+
+// authenticator 按鉴权方式设置请求头部，返回头部名称及值
+type authenticator func(ctx context.Context, eoCtx eocontext.EoContext) (string, string, error)
+
+type Converter struct {
+	apiVersion     string
+	auth           authenticator
+	balanceHandler eocontext.BalanceHandler
+	converter      convert.IConverter
+}
+
+func (c *Converter) RequestConvert(ctx eocontext.EoContext, extender map[string]interface{}) error {
+	ctx.SetBalance(c.balanceHandler)
+	httpContext, err := http_context.Assert(ctx)
+	if err != nil {
+		return err
+	}
+	key, value, err := c.auth(ctx.Context(), ctx)
+	if err != nil {
+		return err
+	}
+	httpContext.Proxy().Header().SetHeader(key, value)
+	err = c.converter.RequestConvert(httpContext, extender)
+	if err != nil {
+		return err
+	}
+	httpContext.Proxy().URI().SetQuery("api-version", c.apiVersion)
+	return nil
+}
+
+func (c *Converter) ResponseConvert(ctx eocontext.EoContext) error {
+	return c.converter.ResponseConvert(ctx)
+}
+
+func (c *Converter) StreamConvert(ctx eocontext.EoContext) error {
+	return c.converter.StreamConvert(ctx)
+}
+
+type executor struct {
+	drivers.WorkerBase
+	base        string
+	apiVersion  string
+	auth        authenticator
+	deployments map[string]*deployment
+	eocontext.BalanceHandler
+}
+
+func (e *executor) GetConverter(model string) (convert.IConverter, bool) {
+	d, ok := e.deployments[model]
+	if !ok || d.converter == nil {
+		return nil, false
+	}
+	return &Converter{balanceHandler: e.BalanceHandler, converter: d.converter, auth: e.auth, apiVersion: e.apiVersion}, true
+}
+
+// GetModel 模型参数按配置原样转发，部署名称已在路径中，model仅用于记录
+func (e *executor) GetModel(model string) (convert.FGenerateConfig, bool) {
+	if _, ok := e.deployments[model]; !ok {
+		return nil, false
+	}
+	return func(cfg string) (map[string]interface{}, error) {
+		result := make(map[string]interface{})
+		if cfg != "" {
+			if err := json.Unmarshal([]byte(cfg), &result); err != nil {
+				log.Errorf("unmarshal config error: %v, cfg: %s", err, cfg)
+				result = make(map[string]interface{})
+			}
+		}
+		result["model"] = model
+		return result, nil
+	}, true
+}
+
+// Model 返回部署对应的OpenAI模型信息
+func (e *executor) Model(model string) (*ai_provider.Model, bool) {
+	d, ok := e.deployments[model]
+	if !ok {
+		return nil, false
+	}
+	return d.model, true
+}
+
+func (e *executor) Start() error {
+	return nil
+}
+
+func (e *executor) Reset(conf interface{}, workers map[eosc.RequireId]eosc.IWorker) error {
+	cfg, err := checkConfig(conf)
+	if err != nil {
+		return err
+	}
+	return e.reset(cfg, workers)
+}
+
+func (e *executor) reset(conf *Config, workers map[eosc.RequireId]eosc.IWorker) error {
+	deployments := make(map[string]*deployment, len(conf.Deployments))
+	for _, d := range conf.Deployments {
+		if d == nil || d.Name == "" {
+			return fmt.Errorf("deployment name is required")
+		}
+		if _, has := deployments[d.Name]; has {
+			return fmt.Errorf("duplicate deployment: %s", d.Name)
+		}
+		m, has := ai_provider.GetModel(openAIProvider, d.Model)
+		if !has {
+			return fmt.Errorf("deployment %s: unknown model %s", d.Name, d.Model)
+		}
+		model := *m
+		model.Provider = name
+		path := "/openai/deployments/" + url.PathEscape(d.Name)
+		converter, _ := newConverter(path, model.ConvertMode())
+		deployments[d.Name] = &deployment{model: &model, path: path, converter: converter}
+	}
+	balanceHandler, err := ai_provider.NewBalanceHandler(e.Id(), conf.Base, 0)
+	if err != nil {
+		return err
+	}
+	apiVersion := conf.APIVersion
+	if apiVersion == "" {
+		apiVersion = "2024-10-21"
+	}
+	e.BalanceHandler = balanceHandler
+	e.base = strings.TrimSuffix(conf.Base, "/")
+	e.apiVersion = apiVersion
+	e.auth = newAuthenticator(conf)
+	e.deployments = deployments
+	convert.Set(e.Id(), e)
+	return nil
+}
+
+// newAuthenticator API Key以api-key头部传递，Entra ID以Bearer令牌传递
+func newAuthenticator(conf *Config) authenticator {
+	if conf.AuthType == authEntraID {
+		source := newTokenSource(entraAuthority, conf.TenantID, conf.ClientID, conf.ClientSecret)
+		return func(ctx context.Context, eoCtx eocontext.EoContext) (string, string, error) {
+			token, err := source.Token(ctx)
+			if err != nil {
+				return "", "", err
+			}
+			return "Authorization", "Bearer " + token, nil
+		}
+	}
+	keys := ai_provider.NewKeyPool(conf.APIKey, conf.APIKeys)
+	return func(ctx context.Context, eoCtx eocontext.EoContext) (string, string, error) {
+		if eoCtx == nil {
+			return "api-key", keys.Pick(), nil
+		}
+		return "api-key", keys.Next(eoCtx), nil
+	}
+}
+
+func (e *executor) Stop() error {
+	e.BalanceHandler = nil
+	convert.Del(e.Id())
+	return nil
+}
+
+func (e *executor) CheckSkill(skill string) bool {
+	return convert.CheckSkill(skill)
+}
+
+// Embeddings 调用部署的向量模型
+func (e *executor) Embeddings(ctx context.Context, model string, inputs []string) ([][]float64, error) {
+	d, ok := e.deployments[model]
+	if !ok || d.model.ModelType != ai_provider.ModelTypeTextEmbedding {
+		return nil, fmt.Errorf("embedding deployment %s not found", model)
+	}
+	key, value, err := e.auth(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	u := e.base + d.path + "/embeddings?api-version=" + url.QueryEscape(e.apiVersion)
+	return ai_provider.RequestOpenAIEmbeddings(ctx, u, map[string]string{key: value}, model, inputs)
+}
+
+func (e *executor) Provider() string {
+	return name
+}
@@ -0,0 +1,92 @@
+package azure_openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+)
+
+func TestExecutor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/embed-prod/embeddings" || r.URL.Query().Get("api-version") != "2024-10-21" || r.Header.Get("api-key") != "secret" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"message":"not found"}}`))
+			return
+		}
+		w.Write([]byte(`{"data":[{"index":0,"embedding":[0.1,0.2]}]}`))
+	}))
+	defer server.Close()
+
+	w, err := Create("azure-openai@ai-provider", "azure", &Config{
+		Base:   server.URL,
+		APIKey: "secret",
+		Deployments: []*Deployment{
+			{Name: "chat-prod", Model: "gpt-4o"},
+			{Name: "embed-prod", Model: "text-embedding-3-small"},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := w.(*executor)
+	m, ok := e.Model("chat-prod")
+	if !ok || m.Provider != name || m.Pricing == nil || m.ModelProperties.ContextSize == 0 {
+		t.Errorf("unexpected model: %+v", m)
+	}
+	if _, ok = e.GetConverter("chat-prod"); !ok {
+		t.Error("expect chat converter")
+	}
+	if e.deployments["chat-prod"].converter.(*ai_provider.OpenAIChat).Endpoint() != "/openai/deployments/chat-prod/chat/completions" {
+		t.Error("unexpected chat endpoint")
+	}
+	vectors, err := e.Embeddings(context.Background(), "embed-prod", []string{"hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) != 1 || len(vectors[0]) != 2 {
+		t.Errorf("unexpected vectors: %v", vectors)
+	}
+	_, err = Create("azure-openai@ai-provider", "azure", &Config{
+		Base:        server.URL,
+		APIKey:      "secret",
+		Deployments: []*Deployment{{Name: "unknown", Model: "unknown-model"}},
+	}, nil)
+	if err == nil {
+		t.Error("expect error for unknown model")
+	}
+}
+
+func TestTokenSource(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		r.ParseForm()
+		if r.URL.Path != "/tenant/oauth2/v2.0/token" || r.Form.Get("client_secret") != "secret" || r.Form.Get("scope") != entraScope {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error_description":"invalid client"}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	source := newTokenSource(server.URL, "tenant", "client", "secret")
+	for i := 0; i < 2; i++ {
+		token, err := source.Token(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "token" {
+			t.Errorf("unexpected token: %s", token)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expect token reused, requests: %d", requests)
+	}
+	if _, err := newTokenSource(server.URL, "tenant", "client", "wrong").Token(context.Background()); err == nil || err.Error() != "invalid client" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package azure_openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	entraAuthority = "https://login.microsoftonline.com"
+	entraScope     = "https://cognitiveservices.azure.com/.default"
+	// tokenRefreshAhead 令牌过期前提前刷新的时长
+	tokenRefreshAhead = 5 * time.Minute
+)
+
+var tokenClient = &http.Client{Timeout: 10 * time.Second}
+
+// tokenSource 以客户端凭据方式获取Entra ID的访问令牌，令牌在过期前复用
+type tokenSource struct {
+	endpoint     string
+	clientID     string
+	clientSecret string
+
+	lock   sync.Mutex
+	token  string
+	expire time.Time
+}
+
+func newTokenSource(authority, tenantID, clientID, clientSecret string) *tokenSource {
+	return &tokenSource{
+		endpoint:     fmt.Sprintf("%s/%s/oauth2/v2.0/token", strings.TrimSuffix(authority, "/"), url.PathEscape(tenantID)),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+	}
+}
+
+func (s *tokenSource) Token(ctx context.Context) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.token != "" && time.Now().Add(tokenRefreshAhead).Before(s.expire) {
+		return s.token, nil
+	}
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", s.clientID)
+	form.Set("client_secret", s.clientSecret)
+	form.Set("scope", entraScope)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := tokenClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	result := struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		ErrorDescription string `json:"error_description"`
+	}{}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return "", fmt.Errorf("invalid token response, status: %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK || result.AccessToken == "" {
+		if result.ErrorDescription != "" {
+			return "", errors.New(result.ErrorDescription)
+		}
+		return "", fmt.Errorf("get token failed, status: %d", resp.StatusCode)
+	}
+	s.token = result.AccessToken
+	s.expire = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+	return s.token, nil
+}
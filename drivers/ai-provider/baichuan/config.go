@@ -2,12 +2,14 @@ package baichuan
 
 import (
 	"fmt"
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
 	"github.com/eolinker/eosc"
 )
 
 type Config struct {
-	APIKey       string `json:"api_key"`
-	Organization string `json:"organization"`
+	APIKey       string               `json:"api_key"`
+	APIKeys      []ai_provider.APIKey `json:"api_keys" label:"API Key池" description:"多个API Key按权重轮询，被限流的Key在冷却期内暂停使用"`
+	Organization string               `json:"organization"`
 }
 
 func checkConfig(v interface{}) (*Config, error) {
@@ -15,7 +17,7 @@ func checkConfig(v interface{}) (*Config, error) {
 	if !ok {
 		return nil, eosc.ErrorConfigType
 	}
-	if conf.APIKey == "" {
+	if conf.APIKey == "" && len(conf.APIKeys) == 0 {
 		return nil, fmt.Errorf("api_key is required")
 	}
 	return conf, nil
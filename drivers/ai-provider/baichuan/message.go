@@ -1,13 +1,13 @@
 package baichuan
 
+import ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+
 type ClientRequest struct {
 	Messages []*Message `json:"messages"`
 }
 
-type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
+// Message 与统一格式的消息一致，响应中包含工具调用
+type Message = ai_provider.Message
 
 type Response struct {
 	Id                string           `json:"id"`
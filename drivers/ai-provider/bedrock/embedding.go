@@ -0,0 +1,115 @@
+package bedrock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+	"github.com/eolinker/eosc/eocontext"
+	http_context "github.com/eolinker/eosc/eocontext/http-context"
+)
+
+var errTitanMultiInput = errors.New("amazon titan embedding supports only one input per request")
+
+// Embedding 通过InvokeModel接口调用向量模型，请求体按模型系列区分：Cohere模型支持批量输入，Titan模型每次仅支持一条输入
+type Embedding struct {
+	endPoint string
+	cohere   bool
+}
+
+func NewEmbedding(model string) IModelMode {
+	return &Embedding{
+		endPoint: fmt.Sprintf("/model/%s/invoke", model),
+		cohere:   strings.HasPrefix(model, "cohere."),
+	}
+}
+
+func (e *Embedding) Endpoint() string {
+	return e.endPoint
+}
+
+func (e *Embedding) RequestConvert(ctx eocontext.EoContext, extender map[string]interface{}) error {
+	httpContext, err := http_context.Assert(ctx)
+	if err != nil {
+		return err
+	}
+	body, err := httpContext.Proxy().Body().RawBody()
+	if err != nil {
+		return err
+	}
+	request := new(ai_provider.EmbeddingRequest)
+	err = json.Unmarshal(body, request)
+	if err != nil {
+		return err
+	}
+	var cfg map[string]interface{}
+	if e.cohere {
+		cfg = map[string]interface{}{
+			"texts":      []string(request.Input),
+			"input_type": "search_document",
+		}
+	} else {
+		if len(request.Input) != 1 {
+			return errTitanMultiInput
+		}
+		cfg = map[string]interface{}{
+			"inputText": request.Input[0],
+		}
+		if request.Dimensions > 0 {
+			cfg["dimensions"] = request.Dimensions
+		}
+	}
+	body, err = json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	httpContext.Proxy().URI().SetPath(e.endPoint)
+	httpContext.Proxy().Body().SetRaw("application/json", body)
+	return nil
+}
+
+type embeddingResponse struct {
+	// Titan模型
+	Embedding           []float64 `json:"embedding"`
+	InputTextTokenCount int       `json:"inputTextTokenCount"`
+	// Cohere模型
+	Embeddings [][]float64 `json:"embeddings"`
+}
+
+func (e *Embedding) ResponseConvert(ctx eocontext.EoContext) error {
+	httpContext, err := http_context.Assert(ctx)
+	if err != nil {
+		return err
+	}
+	if httpContext.Response().StatusCode() != 200 {
+		return nil
+	}
+	data := new(embeddingResponse)
+	err = json.Unmarshal(httpContext.Response().GetBody(), data)
+	if err != nil {
+		return err
+	}
+	vectors := data.Embeddings
+	if data.Embedding != nil {
+		vectors = [][]float64{data.Embedding}
+	}
+	response := &ai_provider.EmbeddingResponse{
+		Data:  make([]*ai_provider.Embedding, 0, len(vectors)),
+		Usage: ai_provider.NewUsage(data.InputTextTokenCount, 0, 0),
+	}
+	for i, v := range vectors {
+		response.Data = append(response.Data, &ai_provider.Embedding{Index: i, Embedding: v})
+	}
+	body, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	httpContext.Response().SetBody(body)
+	return nil
+}
+
+func (e *Embedding) StreamConvert(ctx eocontext.EoContext) error {
+	return e.ResponseConvert(ctx)
+}
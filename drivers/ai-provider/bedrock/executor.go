@@ -41,10 +41,8 @@ func init() {
 		panic(err)
 	}
 	for key, value := range models {
-		if value.ModelProperties != nil {
-			if v, ok := modelModes[value.ModelProperties.Mode]; ok {
-				modelConvert[key] = v(value.Model)
-			}
+		if v, ok := modelModes[value.ConvertMode()]; ok {
+			modelConvert[key] = v(value.Model)
 		}
 	}
 }
@@ -69,7 +67,7 @@ func (c *Converter) RequestConvert(ctx eocontext.EoContext, extender map[string]
 		return err
 	}
 	body, _ := httpContext.Proxy().Body().RawBody()
-	headers, err := signRequest(c.signer, c.region, c.model, http.Header{}, string(body))
+	headers, err := signRequest(c.signer, c.region, httpContext.Proxy().URI().Path(), http.Header{}, string(body))
 	if err != nil {
 		return err
 	}
@@ -86,6 +84,10 @@ func (c *Converter) ResponseConvert(ctx eocontext.EoContext) error {
 	return c.converter.ResponseConvert(ctx)
 }
 
+func (c *Converter) StreamConvert(ctx eocontext.EoContext) error {
+	return c.converter.StreamConvert(ctx)
+}
+
 type executor struct {
 	drivers.WorkerBase
 	cfg *basicConfig
@@ -172,14 +174,18 @@ func (e *executor) CheckSkill(skill string) bool {
 	return convert.CheckSkill(skill)
 }
 
+func (e *executor) Provider() string {
+	return name
+}
+
 type ModelConfig struct {
 	MaxTokens   int     `json:"max_tokens"`
 	Temperature float64 `json:"temperature"`
 	TopP        float64 `json:"top_p"`
 }
 
-func signRequest(signer *v4.Signer, region string, model string, headers http.Header, body string) (http.Header, error) {
-	request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com/model/%s/converse", region, model), nil)
+func signRequest(signer *v4.Signer, region string, path string, headers http.Header, body string) (http.Header, error) {
+	request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com%s", region, path), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1,5 +1,7 @@
 package bedrock
 
+import "encoding/json"
+
 type ClientRequest struct {
 	Messages        []*Message       `json:"message,omitempty"`
 	System          *Content         `json:"system,omitempty"`
@@ -11,8 +13,46 @@ type Message struct {
 	Content []*Content `json:"content"`
 }
 
+// Content 内容块，text、image、toolUse及toolResult中仅有一个生效
 type Content struct {
-	Text string `json:"text"`
+	Text       string      `json:"text,omitempty"`
+	Image      *Image      `json:"image,omitempty"`
+	ToolUse    *ToolUse    `json:"toolUse,omitempty"`
+	ToolResult *ToolResult `json:"toolResult,omitempty"`
+}
+
+// Image Converse仅支持base64格式的图片，format为png、jpeg、gif或webp
+type Image struct {
+	Format string `json:"format"`
+	Source struct {
+		Bytes string `json:"bytes"`
+	} `json:"source"`
+}
+
+type ToolUse struct {
+	ToolUseId string          `json:"toolUseId"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input,omitempty"`
+}
+
+type ToolResult struct {
+	ToolUseId string     `json:"toolUseId"`
+	Content   []*Content `json:"content"`
+}
+
+type ToolConfig struct {
+	Tools      []*Tool                `json:"tools"`
+	ToolChoice map[string]interface{} `json:"toolChoice,omitempty"`
+}
+
+type Tool struct {
+	ToolSpec struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+		InputSchema struct {
+			Json json.RawMessage `json:"json"`
+		} `json:"inputSchema"`
+	} `json:"toolSpec"`
 }
 
 type InferenceConfig struct {
@@ -24,8 +64,34 @@ type InferenceConfig struct {
 type Response struct {
 	Output     Output `json:"output"`
 	StopReason string `json:"stopReason"`
+	Usage      Usage  `json:"usage"`
+}
+
+type Usage struct {
+	InputTokens  int `json:"inputTokens"`
+	OutputTokens int `json:"outputTokens"`
+	TotalTokens  int `json:"totalTokens"`
 }
 
 type Output struct {
 	Message *Message `json:"message"`
 }
+
+// StreamEvent ConverseStream的事件负载，不同事件使用不同字段
+type StreamEvent struct {
+	Role string `json:"role"`
+	// ContentBlockIndex 内容块序号，Start为contentBlockStart开始的工具调用
+	ContentBlockIndex int `json:"contentBlockIndex"`
+	Start             struct {
+		ToolUse *ToolUse `json:"toolUse"`
+	} `json:"start"`
+	Delta struct {
+		Text    string `json:"text"`
+		ToolUse *struct {
+			Input string `json:"input"`
+		} `json:"toolUse"`
+	} `json:"delta"`
+	StopReason string `json:"stopReason"`
+	Message    string `json:"message"`
+	Usage      Usage  `json:"usage"`
+}
@@ -17,6 +17,9 @@ type FNewModelMode func(string) IModelMode
 var (
 	modelModes = map[string]FNewModelMode{
 		ai_provider.ModeChat.String(): NewChat,
+		// Converse接口统一了对话及补全模式的模型
+		ai_provider.ModeComplete.String():  NewChat,
+		ai_provider.ModeEmbedding.String(): NewEmbedding,
 	}
 )
 
@@ -26,12 +29,14 @@ type IModelMode interface {
 }
 
 type Chat struct {
-	endPoint string
+	endPoint       string
+	streamEndPoint string
 }
 
 func NewChat(model string) IModelMode {
 	return &Chat{
-		endPoint: fmt.Sprintf("/model/%s/converse", model),
+		endPoint:       fmt.Sprintf("/model/%s/converse", model),
+		streamEndPoint: fmt.Sprintf("/model/%s/converse-stream", model),
 	}
 }
 
@@ -55,21 +60,16 @@ func (c *Chat) RequestConvert(ctx eocontext.EoContext, extender map[string]inter
 	if err != nil {
 		return err
 	}
-	messages := make([]Message, 0, len(baseCfg.Config.Messages))
-	systemMessage := make([]Content, 0)
-
-	for _, m := range baseCfg.Config.Messages {
-		if m.Role == "system" {
-			systemMessage = append(systemMessage, Content{Text: m.Content})
-		} else {
-			messages = append(messages, Message{
-				Role:    m.Role,
-				Content: []*Content{{Text: m.Content}},
-			})
-		}
+	if baseCfg.Config.Stream {
+		// 流式接口以AWS事件流格式返回
+		httpContext.Proxy().URI().SetPath(c.streamEndPoint)
 	}
+	systemMessage, messages := convertMessages(baseCfg.Config.Messages)
 	baseCfg.SetAppend("messages", messages)
 	baseCfg.SetAppend("system", systemMessage)
+	if len(baseCfg.Config.Tools) > 0 {
+		baseCfg.SetAppend("toolConfig", convertToolConfig(baseCfg.Config.Tools, baseCfg.Config.ToolChoice))
+	}
 
 	for k, v := range extender {
 		baseCfg.SetAppend(k, v)
@@ -99,12 +99,9 @@ func (c *Chat) ResponseConvert(ctx eocontext.EoContext) error {
 	}
 	responseBody := &ai_provider.ClientResponse{}
 	if data.Config.Output.Message != nil && len(data.Config.Output.Message.Content) > 0 {
-		msg := data.Config.Output.Message
-		responseBody.Message = ai_provider.Message{
-			Role:    msg.Role,
-			Content: msg.Content[0].Text,
-		}
-		responseBody.FinishReason = data.Config.StopReason
+		responseBody.Message = responseMessage(data.Config.Output.Message)
+		responseBody.FinishReason = finishReason(data.Config.StopReason)
+		responseBody.Usage = ai_provider.NewUsage(data.Config.Usage.InputTokens, data.Config.Usage.OutputTokens, data.Config.Usage.TotalTokens)
 	} else {
 		responseBody.Code = -1
 		responseBody.Error = "no response"
@@ -116,3 +113,44 @@ func (c *Chat) ResponseConvert(ctx eocontext.EoContext) error {
 	httpContext.Response().SetBody(body)
 	return nil
 }
+
+func (c *Chat) StreamConvert(ctx eocontext.EoContext) error {
+	return ai_provider.StreamConvert(ctx, ai_provider.NewAWSEventStreamDecoder, streamConvert, c.ResponseConvert)
+}
+
+// streamConvert 转换ConverseStream的事件，异常事件以事件名区分，负载中包含message
+func streamConvert(event *ai_provider.StreamEvent) (*ai_provider.ClientResponse, error) {
+	data := new(StreamEvent)
+	err := json.Unmarshal(event.Data, data)
+	if err != nil {
+		return nil, err
+	}
+	switch event.Event {
+	case "messageStart":
+		return &ai_provider.ClientResponse{Message: ai_provider.Message{Role: data.Role}}, nil
+	case "contentBlockStart":
+		// 工具调用开始时返回调用ID及工具名，参数在后续的contentBlockDelta中返回
+		if data.Start.ToolUse == nil {
+			return nil, nil
+		}
+		call := ai_provider.NewToolCall(data.ContentBlockIndex, data.Start.ToolUse.ToolUseId, data.Start.ToolUse.Name, "")
+		return &ai_provider.ClientResponse{Message: ai_provider.Message{Role: "assistant", ToolCalls: []*ai_provider.ToolCall{call}}}, nil
+	case "contentBlockDelta":
+		if data.Delta.ToolUse != nil {
+			call := ai_provider.NewToolCall(data.ContentBlockIndex, "", "", data.Delta.ToolUse.Input)
+			return &ai_provider.ClientResponse{Message: ai_provider.Message{Role: "assistant", ToolCalls: []*ai_provider.ToolCall{call}}}, nil
+		}
+		return &ai_provider.ClientResponse{Message: ai_provider.Message{Role: "assistant", Content: data.Delta.Text}}, nil
+	case "messageStop":
+		return &ai_provider.ClientResponse{FinishReason: finishReason(data.StopReason)}, nil
+	case "metadata":
+		usage := ai_provider.NewUsage(data.Usage.InputTokens, data.Usage.OutputTokens, data.Usage.TotalTokens)
+		if usage == nil {
+			return nil, nil
+		}
+		return &ai_provider.ClientResponse{Usage: usage}, nil
+	case "contentBlockStop":
+		return nil, nil
+	}
+	return &ai_provider.ClientResponse{Code: -1, Error: data.Message}, nil
+}
@@ -2,9 +2,7 @@ package bedrock
 
 import (
 	"fmt"
-	"io"
 	"net/http"
-	"os"
 	"strings"
 	"testing"
 	"time"
@@ -16,11 +14,7 @@ import (
 const region = "us-east-1"
 const model = "anthropic.claude-3-haiku-20240307-v1:0"
 
-func TestSign(t *testing.T) {
-	accessKeyID := os.Getenv("AWS_ACCESS_KEY_ID")
-	secretAccessKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
-	signer := v4.NewSigner(credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""))
-	body := `{
+const body = `{
     "messages": [
         {
             "role": "user",
@@ -37,70 +31,60 @@ func TestSign(t *testing.T) {
         }
     ]
 }`
-	bodyReader := strings.NewReader(body)
-	request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com/model/%s/converse", region, model), nil)
-	if err != nil {
-		t.Fatal(err)
-	}
-	request.Header.Set("Content-Type", "application/json")
-	_, err = signer.Sign(request, bodyReader, "bedrock", region, time.Now())
+
+func testSigner() *v4.Signer {
+	return v4.NewSigner(credentials.NewStaticCredentials("AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", ""))
+}
+
+// expectAuthorization 以签名结果中的时间对指定路径重新签名，得到期望的Authorization
+func expectAuthorization(t *testing.T, signer *v4.Signer, path string, headers http.Header) string {
+	t.Helper()
+	signTime, err := time.Parse("20060102T150405Z", headers.Get("X-Amz-Date"))
 	if err != nil {
 		t.Fatal(err)
 	}
-	client := http.Client{}
-	resp, err := client.Do(request)
+	request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com%s", region, path), nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer resp.Body.Close()
-	data, err := io.ReadAll(resp.Body)
+	request.Header.Set("content-type", "application/json")
+	_, err = signer.Sign(request, strings.NewReader(body), "bedrock", region, signTime)
 	if err != nil {
 		t.Fatal(err)
 	}
-	t.Log(string(data))
+	return request.Header.Get("Authorization")
 }
 
 func TestSignRequest(t *testing.T) {
-	accessKeyID := os.Getenv("AWS_ACCESS_KEY_ID")
-	secretAccessKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
-
-	signer := v4.NewSigner(credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""))
-	body := `{
-    "messages": [
-        {
-            "role": "user",
-            "content": [
-                {
-                    "text": "如何预防痛风？"
-                }
-            ]
-        }
-    ],
-    "system": [
-        {
-            "text": "你是一个医生，需要针对用户的问题提供专业性的意见"
-        }
-    ]
-}`
-	request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com/model/%s/converse", region, model), strings.NewReader(body))
-	if err != nil {
-		t.Fatal(err)
+	signer := testSigner()
+	chat := NewChat(model).(*Chat)
+	paths := map[string]string{
+		"converse":        chat.endPoint,
+		"converse-stream": chat.streamEndPoint,
 	}
-	request.Header.Set("content-type", "application/json")
-	headers, err := signRequest(signer, region, model, request.Header, body)
-	if err != nil {
-		t.Fatal(err)
-	}
-	request.Header = headers
-	client := http.Client{}
-	resp, err := client.Do(request)
-	if err != nil {
-		t.Fatal(err)
+	authorizations := make(map[string]string, len(paths))
+	for name, path := range paths {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("content-type", "application/json")
+			headers, err := signRequest(signer, region, path, header, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if header.Get("Authorization") != "" {
+				t.Fatal("signRequest should not modify the input headers")
+			}
+			authorization := headers.Get("Authorization")
+			if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") {
+				t.Fatalf("unexpected authorization: %s", authorization)
+			}
+			if expect := expectAuthorization(t, signer, path, headers); authorization != expect {
+				t.Fatalf("signature does not match path %s:\nexpect %s\ngot    %s", path, expect, authorization)
+			}
+			authorizations[name] = authorization
+		})
 	}
-	defer resp.Body.Close()
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		t.Fatal(err)
+	if authorizations["converse"] == authorizations["converse-stream"] {
+		t.Fatal("converse and converse-stream share the same signature")
 	}
-	t.Log(string(data))
 }
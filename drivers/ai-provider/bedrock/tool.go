@@ -0,0 +1,117 @@
+package bedrock
+
+import (
+	"encoding/json"
+	"strings"
+
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+)
+
+// convertMessages 转换为Converse的消息格式：system消息单独返回，工具调用结果以user消息的toolResult返回，连续相同角色的消息合并为一条
+func convertMessages(messages []*ai_provider.Message) ([]*Content, []*Message) {
+	systems := make([]*Content, 0, 1)
+	result := make([]*Message, 0, len(messages))
+	for _, m := range messages {
+		if m == nil {
+			continue
+		}
+		role := m.Role
+		var contents []*Content
+		switch role {
+		case "system":
+			systems = append(systems, &Content{Text: m.Content})
+			continue
+		case "tool":
+			role = "user"
+			contents = []*Content{{ToolResult: &ToolResult{ToolUseId: m.ToolCallID, Content: []*Content{{Text: m.Content}}}}}
+		default:
+			contents = convertParts(m.ContentParts())
+			for _, call := range m.ToolCalls {
+				contents = append(contents, &Content{ToolUse: &ToolUse{ToolUseId: call.ID, Name: call.Function.Name, Input: call.ArgumentsJSON()}})
+			}
+		}
+		if len(contents) == 0 {
+			continue
+		}
+		if n := len(result); n > 0 && result[n-1].Role == role {
+			result[n-1].Content = append(result[n-1].Content, contents...)
+			continue
+		}
+		result = append(result, &Message{Role: role, Content: contents})
+	}
+	return systems, result
+}
+
+// convertParts Converse不支持图片地址，仅转换base64格式的图片
+func convertParts(parts []*ai_provider.ContentPart) []*Content {
+	contents := make([]*Content, 0, len(parts))
+	for _, p := range parts {
+		switch p.Type {
+		case ai_provider.PartText:
+			if p.Text != "" {
+				contents = append(contents, &Content{Text: p.Text})
+			}
+		case ai_provider.PartImageURL:
+			mediaType, data, ok := p.ImageURL.Base64()
+			if !ok {
+				continue
+			}
+			image := &Image{Format: strings.TrimPrefix(mediaType, "image/")}
+			image.Source.Bytes = data
+			contents = append(contents, &Content{Image: image})
+		}
+	}
+	return contents
+}
+
+// convertToolConfig required对应Converse的any，指定函数对应tool，Converse不支持none，此时不指定调用方式
+func convertToolConfig(tools []*ai_provider.Tool, choice *ai_provider.ToolChoice) *ToolConfig {
+	config := &ToolConfig{Tools: make([]*Tool, 0, len(tools))}
+	for _, t := range tools {
+		if t.Function == nil {
+			continue
+		}
+		tool := &Tool{}
+		tool.ToolSpec.Name = t.Function.Name
+		tool.ToolSpec.Description = t.Function.Description
+		tool.ToolSpec.InputSchema.Json = t.Function.Parameters
+		if len(tool.ToolSpec.InputSchema.Json) == 0 {
+			tool.ToolSpec.InputSchema.Json = json.RawMessage(`{"type":"object"}`)
+		}
+		config.Tools = append(config.Tools, tool)
+	}
+	if choice != nil {
+		switch choice.Type {
+		case ai_provider.ToolChoiceAuto:
+			config.ToolChoice = map[string]interface{}{"auto": struct{}{}}
+		case ai_provider.ToolChoiceRequired:
+			config.ToolChoice = map[string]interface{}{"any": struct{}{}}
+		case ai_provider.ToolChoiceFunction:
+			config.ToolChoice = map[string]interface{}{"tool": map[string]string{"name": choice.Function}}
+		}
+	}
+	return config
+}
+
+// responseMessage 合并响应中的文本块，toolUse转换为工具调用
+func responseMessage(msg *Message) ai_provider.Message {
+	message := ai_provider.Message{Role: msg.Role}
+	texts := make([]string, 0, len(msg.Content))
+	for _, c := range msg.Content {
+		if c.ToolUse != nil {
+			message.ToolCalls = append(message.ToolCalls, ai_provider.NewToolCall(-1, c.ToolUse.ToolUseId, c.ToolUse.Name, string(c.ToolUse.Input)))
+			continue
+		}
+		texts = append(texts, c.Text)
+	}
+	message.Content = strings.Join(texts, "")
+	return message
+}
+
+// finishReason tool_use转换为统一的tool_calls
+func finishReason(reason string) string {
+	if reason == "tool_use" {
+		return ai_provider.FinishToolCalls
+	}
+	return reason
+}
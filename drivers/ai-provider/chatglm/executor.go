@@ -36,10 +36,8 @@ func init() {
 		panic(err)
 	}
 	for key, value := range models {
-		if value.ModelProperties != nil {
-			if v, ok := modelModes[value.ModelProperties.Mode]; ok {
-				modelConvert[key] = v
-			}
+		if v, ok := modelModes[value.ConvertMode()]; ok {
+			modelConvert[key] = v
 		}
 	}
 }
@@ -67,6 +65,10 @@ func (c *Converter) ResponseConvert(ctx eocontext.EoContext) error {
 	return c.converter.ResponseConvert(ctx)
 }
 
+func (c *Converter) StreamConvert(ctx eocontext.EoContext) error {
+	return c.converter.StreamConvert(ctx)
+}
+
 type executor struct {
 	drivers.WorkerBase
 	apikey string
@@ -155,6 +157,10 @@ func (e *executor) CheckSkill(skill string) bool {
 	return convert.CheckSkill(skill)
 }
 
+func (e *executor) Provider() string {
+	return name
+}
+
 type ModelConfig struct {
 	FrequencyPenalty float64 `json:"frequency_penalty"`
 	MaxTokens        int     `json:"max_tokens"`
@@ -0,0 +1,172 @@
+package cohere
+
+import (
+	"encoding/json"
+
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+	"github.com/eolinker/eosc/eocontext"
+	http_context "github.com/eolinker/eosc/eocontext/http-context"
+)
+
+type billedUnits struct {
+	InputTokens int `json:"input_tokens"`
+}
+
+type meta struct {
+	BilledUnits billedUnits `json:"billed_units"`
+}
+
+// Embedding 向量模型，按检索文档的用途生成向量
+type Embedding struct {
+	endPoint string
+}
+
+func NewEmbedding() *Embedding {
+	return &Embedding{
+		endPoint: "/v2/embed",
+	}
+}
+
+func (e *Embedding) Endpoint() string {
+	return e.endPoint
+}
+
+func (e *Embedding) RequestConvert(ctx eocontext.EoContext, extender map[string]interface{}) error {
+	httpContext, err := http_context.Assert(ctx)
+	if err != nil {
+		return err
+	}
+	body, err := httpContext.Proxy().Body().RawBody()
+	if err != nil {
+		return err
+	}
+	request := new(ai_provider.EmbeddingRequest)
+	err = json.Unmarshal(body, request)
+	if err != nil {
+		return err
+	}
+	body, err = json.Marshal(map[string]interface{}{
+		"model":           extender["model"],
+		"texts":           []string(request.Input),
+		"input_type":      "search_document",
+		"embedding_types": []string{"float"},
+	})
+	if err != nil {
+		return err
+	}
+	httpContext.Proxy().URI().SetPath(e.endPoint)
+	httpContext.Proxy().Body().SetRaw("application/json", body)
+	return nil
+}
+
+type embeddingResponse struct {
+	Embeddings struct {
+		Float [][]float64 `json:"float"`
+	} `json:"embeddings"`
+	Meta meta `json:"meta"`
+}
+
+func (e *Embedding) ResponseConvert(ctx eocontext.EoContext) error {
+	httpContext, err := http_context.Assert(ctx)
+	if err != nil {
+		return err
+	}
+	if httpContext.Response().StatusCode() != 200 {
+		return nil
+	}
+	data := new(embeddingResponse)
+	err = json.Unmarshal(httpContext.Response().GetBody(), data)
+	if err != nil {
+		return err
+	}
+	response := &ai_provider.EmbeddingResponse{
+		Data:  make([]*ai_provider.Embedding, 0, len(data.Embeddings.Float)),
+		Usage: ai_provider.NewUsage(data.Meta.BilledUnits.InputTokens, 0, 0),
+	}
+	for i, v := range data.Embeddings.Float {
+		response.Data = append(response.Data, &ai_provider.Embedding{Index: i, Embedding: v})
+	}
+	body, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	httpContext.Response().SetBody(body)
+	return nil
+}
+
+func (e *Embedding) StreamConvert(ctx eocontext.EoContext) error {
+	return e.ResponseConvert(ctx)
+}
+
+// Rerank 重排序模型
+type Rerank struct {
+	endPoint string
+}
+
+func NewRerank() *Rerank {
+	return &Rerank{
+		endPoint: "/v2/rerank",
+	}
+}
+
+func (r *Rerank) Endpoint() string {
+	return r.endPoint
+}
+
+func (r *Rerank) RequestConvert(ctx eocontext.EoContext, extender map[string]interface{}) error {
+	httpContext, err := http_context.Assert(ctx)
+	if err != nil {
+		return err
+	}
+	body, err := httpContext.Proxy().Body().RawBody()
+	if err != nil {
+		return err
+	}
+	request := new(ai_provider.RerankRequest)
+	err = json.Unmarshal(body, request)
+	if err != nil {
+		return err
+	}
+	cfg := map[string]interface{}{
+		"model":     extender["model"],
+		"query":     request.Query,
+		"documents": request.Documents,
+	}
+	if request.TopN > 0 {
+		cfg["top_n"] = request.TopN
+	}
+	body, err = json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	httpContext.Proxy().URI().SetPath(r.endPoint)
+	httpContext.Proxy().Body().SetRaw("application/json", body)
+	return nil
+}
+
+func (r *Rerank) ResponseConvert(ctx eocontext.EoContext) error {
+	httpContext, err := http_context.Assert(ctx)
+	if err != nil {
+		return err
+	}
+	if httpContext.Response().StatusCode() != 200 {
+		return nil
+	}
+	data := new(struct {
+		Results []*ai_provider.RerankResult `json:"results"`
+	})
+	err = json.Unmarshal(httpContext.Response().GetBody(), data)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(&ai_provider.RerankResponse{Results: data.Results})
+	if err != nil {
+		return err
+	}
+	httpContext.Response().SetBody(body)
+	return nil
+}
+
+func (r *Rerank) StreamConvert(ctx eocontext.EoContext) error {
+	return r.ResponseConvert(ctx)
+}
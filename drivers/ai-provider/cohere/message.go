@@ -1,13 +1,13 @@
 package cohere
 
+import ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+
 type ClientRequest struct {
 	Messages []*RequestMessage `json:"messages"`
 }
 
-type RequestMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
+// RequestMessage v2接口的消息格式及工具调用与OpenAI一致
+type RequestMessage = ai_provider.Message
 
 type Response struct {
 	Id           string          `json:"id"`
@@ -22,8 +22,9 @@ type ResponseContent struct {
 }
 
 type ResponseMessage struct {
-	Role    string            `json:"role"`
-	Content []ResponseContent `json:"content"`
+	Role      string                  `json:"role"`
+	Content   []ResponseContent       `json:"content"`
+	ToolCalls []*ai_provider.ToolCall `json:"tool_calls"`
 }
 
 type Tokens struct {
@@ -35,3 +36,19 @@ type Usage struct {
 	BilledUnits Tokens `json:"billed_units"`
 	Tokens      Tokens `json:"tokens"`
 }
+
+// StreamEvent 流式响应事件
+type StreamEvent struct {
+	Type string `json:"type"`
+	// Index 工具调用事件中工具调用的序号
+	Index int `json:"index"`
+	Delta struct {
+		Message struct {
+			Role      string                `json:"role"`
+			Content   ResponseContent       `json:"content"`
+			ToolCalls *ai_provider.ToolCall `json:"tool_calls"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+		Usage        Usage  `json:"usage"`
+	} `json:"delta"`
+}
@@ -7,11 +7,14 @@ import (
 	"github.com/eolinker/eosc"
 	"github.com/eolinker/eosc/eocontext"
 	http_context "github.com/eolinker/eosc/eocontext/http-context"
+	"strings"
 )
 
 var (
 	modelModes = map[string]IModelMode{
-		ai_provider.ModeChat.String(): NewChat(),
+		ai_provider.ModeChat.String():      NewChat(),
+		ai_provider.ModeEmbedding.String(): NewEmbedding(),
+		ai_provider.ModeRerank.String():    NewRerank(),
 	}
 )
 
@@ -50,14 +53,16 @@ func (c *Chat) RequestConvert(ctx eocontext.EoContext, extender map[string]inter
 	if err != nil {
 		return err
 	}
-	messages := make([]RequestMessage, 0, len(baseCfg.Config.Messages)+1)
-	for _, m := range baseCfg.Config.Messages {
-		messages = append(messages, RequestMessage{
-			Role:    m.Role,
-			Content: m.Content,
-		})
+	baseCfg.SetAppend("messages", baseCfg.Config.Messages)
+	if len(baseCfg.Config.Tools) > 0 {
+		baseCfg.SetAppend("tools", baseCfg.Config.Tools)
+		if choice := toolChoice(baseCfg.Config.ToolChoice); choice != "" {
+			baseCfg.SetAppend("tool_choice", choice)
+		}
+	}
+	if baseCfg.Config.Stream {
+		baseCfg.SetAppend("stream", true)
 	}
-	baseCfg.SetAppend("messages", messages)
 	for k, v := range extender {
 		baseCfg.SetAppend(k, v)
 	}
@@ -86,10 +91,12 @@ func (c *Chat) ResponseConvert(ctx eocontext.EoContext) error {
 	responseBody := &ai_provider.ClientResponse{}
 	if data.Config.Id != "" {
 		responseBody.Message = ai_provider.Message{
-			Role:    data.Config.Message.Role,
-			Content: data.Config.Message.Content[0].Text,
+			Role:      data.Config.Message.Role,
+			Content:   data.Config.Message.text(),
+			ToolCalls: data.Config.Message.ToolCalls,
 		}
-		responseBody.FinishReason = data.Config.FinishReason
+		responseBody.FinishReason = finishReason(data.Config.FinishReason)
+		responseBody.Usage = ai_provider.NewUsage(data.Config.Usage.Tokens.InputTokens, data.Config.Usage.Tokens.OutputTokens, 0)
 	} else {
 		responseBody.Code = -1
 		responseBody.Error = "no response"
@@ -101,3 +108,68 @@ func (c *Chat) ResponseConvert(ctx eocontext.EoContext) error {
 	httpContext.Response().SetBody(body)
 	return nil
 }
+
+func (c *Chat) StreamConvert(ctx eocontext.EoContext) error {
+	return ai_provider.StreamConvert(ctx, ai_provider.NewSSEDecoder, streamConvert, c.ResponseConvert)
+}
+
+// streamConvert 转换Cohere的流式事件，事件类型以data中的type为准
+func streamConvert(event *ai_provider.StreamEvent) (*ai_provider.ClientResponse, error) {
+	data := new(StreamEvent)
+	err := json.Unmarshal(event.Data, data)
+	if err != nil {
+		return nil, err
+	}
+	switch data.Type {
+	case "message-start":
+		return &ai_provider.ClientResponse{Message: ai_provider.Message{Role: data.Delta.Message.Role}}, nil
+	case "content-delta":
+		return &ai_provider.ClientResponse{Message: ai_provider.Message{Role: "assistant", Content: data.Delta.Message.Content.Text}}, nil
+	case "tool-call-start", "tool-call-delta":
+		// tool-call-start返回调用ID及工具名，tool-call-delta返回参数增量
+		call := data.Delta.Message.ToolCalls
+		if call == nil {
+			return nil, nil
+		}
+		index := data.Index
+		call.Index = &index
+		return &ai_provider.ClientResponse{Message: ai_provider.Message{Role: "assistant", ToolCalls: []*ai_provider.ToolCall{call}}}, nil
+	case "message-end":
+		tokens := data.Delta.Usage.Tokens
+		return &ai_provider.ClientResponse{
+			FinishReason: finishReason(data.Delta.FinishReason),
+			Usage:        ai_provider.NewUsage(tokens.InputTokens, tokens.OutputTokens, 0),
+		}, nil
+	}
+	return nil, nil
+}
+
+// toolChoice Cohere仅支持REQUIRED及NONE，未指定时由模型决定，不支持指定函数，此时要求必须调用工具
+func toolChoice(choice *ai_provider.ToolChoice) string {
+	if choice == nil {
+		return ""
+	}
+	switch choice.Type {
+	case ai_provider.ToolChoiceRequired, ai_provider.ToolChoiceFunction:
+		return "REQUIRED"
+	case ai_provider.ToolChoiceNone:
+		return "NONE"
+	}
+	return ""
+}
+
+// finishReason TOOL_CALL转换为统一的tool_calls
+func finishReason(reason string) string {
+	if reason == "TOOL_CALL" {
+		return ai_provider.FinishToolCalls
+	}
+	return reason
+}
+
+func (m *ResponseMessage) text() string {
+	texts := make([]string, 0, len(m.Content))
+	for _, c := range m.Content {
+		texts = append(texts, c.Text)
+	}
+	return strings.Join(texts, "")
+}
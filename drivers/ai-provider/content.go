@@ -0,0 +1,127 @@
+package ai_provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+const (
+	PartText       = "text"
+	PartImageURL   = "image_url"
+	PartInputAudio = "input_audio"
+)
+
+// ContentPart 消息的内容块，格式与OpenAI一致
+type ContentPart struct {
+	Type       string      `json:"type"`
+	Text       string      `json:"text,omitempty"`
+	ImageURL   *ImageURL   `json:"image_url,omitempty"`
+	InputAudio *InputAudio `json:"input_audio,omitempty"`
+}
+
+// ImageURL 图片地址，可为http地址或data:<media type>;base64,<data>格式的base64数据
+type ImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Base64 解析base64格式的图片，返回媒体类型及base64数据，不是base64格式时返回false
+func (i *ImageURL) Base64() (string, string, bool) {
+	if i == nil || !strings.HasPrefix(i.URL, "data:") {
+		return "", "", false
+	}
+	meta, data, has := strings.Cut(strings.TrimPrefix(i.URL, "data:"), ",")
+	if !has {
+		return "", "", false
+	}
+	mediaType, encoding, _ := strings.Cut(meta, ";")
+	if encoding != "base64" {
+		return "", "", false
+	}
+	return mediaType, data, true
+}
+
+// InputAudio base64格式的音频
+type InputAudio struct {
+	Data   string `json:"data"`
+	Format string `json:"format"`
+}
+
+type messageJSON struct {
+	Role       string      `json:"role"`
+	Content    interface{} `json:"content"`
+	Name       string      `json:"name,omitempty"`
+	ToolCalls  []*ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string      `json:"tool_call_id,omitempty"`
+}
+
+// MarshalJSON 包含内容块时content以数组返回，否则以字符串返回
+func (m Message) MarshalJSON() ([]byte, error) {
+	v := messageJSON{
+		Role:       m.Role,
+		Content:    m.Content,
+		Name:       m.Name,
+		ToolCalls:  m.ToolCalls,
+		ToolCallID: m.ToolCallID,
+	}
+	if len(m.Parts) > 0 {
+		v.Content = m.Parts
+	}
+	return json.Marshal(v)
+}
+
+func (m *Message) UnmarshalJSON(data []byte) error {
+	v := &struct {
+		messageJSON
+		Content json.RawMessage `json:"content"`
+	}{}
+	err := json.Unmarshal(data, v)
+	if err != nil {
+		return err
+	}
+	*m = Message{
+		Role:       v.Role,
+		Name:       v.Name,
+		ToolCalls:  v.ToolCalls,
+		ToolCallID: v.ToolCallID,
+	}
+	content := bytes.TrimSpace(v.Content)
+	switch {
+	case len(content) == 0 || bytes.Equal(content, []byte("null")):
+	case content[0] == '[':
+		err = json.Unmarshal(content, &m.Parts)
+		if err != nil {
+			return err
+		}
+		m.Content = PartsText(m.Parts)
+	default:
+		err = json.Unmarshal(content, &m.Content)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PartsText 拼接内容块中的文本
+func PartsText(parts []*ContentPart) string {
+	texts := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p != nil && p.Type == PartText {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// ContentParts 返回消息的内容块，仅有文本时返回单个文本块
+func (m *Message) ContentParts() []*ContentPart {
+	if len(m.Parts) > 0 {
+		return m.Parts
+	}
+	if m.Content == "" {
+		return nil
+	}
+	return []*ContentPart{{Type: PartText, Text: m.Content}}
+}
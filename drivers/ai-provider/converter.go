@@ -2,6 +2,12 @@ package ai_provider
 
 type ClientRequest struct {
 	Messages []*Message `json:"messages"`
+	// Stream 开启后响应以统一的SSE格式逐块返回
+	Stream bool `json:"stream"`
+	// Tools 模型可调用的工具，格式与OpenAI一致
+	Tools []*Tool `json:"tools,omitempty"`
+	// ToolChoice 工具调用方式：auto、none、required或指定函数
+	ToolChoice *ToolChoice `json:"tool_choice,omitempty"`
 }
 
 type ClientResponse struct {
@@ -9,11 +15,61 @@ type ClientResponse struct {
 	FinishReason string  `json:"finish_reason,omitempty"`
 	Code         int     `json:"code"`
 	Error        string  `json:"error"`
+	// Usage 本次请求的token用量，流式返回时在包含用量的响应块中返回
+	Usage *Usage `json:"usage,omitempty"`
 }
 
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// NewUsage 返回统一格式的token用量，全部为0时返回nil，未返回总量时按输入与输出之和计算
+func NewUsage(promptTokens, completionTokens, totalTokens int) *Usage {
+	if promptTokens == 0 && completionTokens == 0 && totalTokens == 0 {
+		return nil
+	}
+	if totalTokens == 0 {
+		totalTokens = promptTokens + completionTokens
+	}
+	return &Usage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      totalTokens,
+	}
+}
+
+// Merge 合并流式响应中分多次返回的用量，非0的值覆盖原值
+func (u *Usage) Merge(other *Usage) {
+	if other == nil {
+		return
+	}
+	if other.PromptTokens > 0 {
+		u.PromptTokens = other.PromptTokens
+	}
+	if other.CompletionTokens > 0 {
+		u.CompletionTokens = other.CompletionTokens
+	}
+	if other.TotalTokens > 0 {
+		u.TotalTokens = other.TotalTokens
+	}
+	if u.TotalTokens < u.PromptTokens+u.CompletionTokens {
+		u.TotalTokens = u.PromptTokens + u.CompletionTokens
+	}
+}
+
+// Message 统一格式的对话消息，content可为字符串或内容块数组，为数组时Content为其中文本的拼接
 type Message struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
+	// Parts 多模态内容块，包含图片、音频等非文本内容时使用
+	Parts []*ContentPart `json:"-"`
+	Name  string         `json:"name,omitempty"`
+	// ToolCalls 模型返回的工具调用，流式返回时按Index分块返回
+	ToolCalls []*ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID role为tool时对应的工具调用ID
+	ToolCallID string `json:"tool_call_id,omitempty"`
 }
 
 const (
@@ -0,0 +1,51 @@
+package ai_provider
+
+import (
+	"fmt"
+)
+
+// CustomModel 通过配置声明的模型，供模型列表不随驱动内置的供应商（如自部署的模型服务）使用
+type CustomModel struct {
+	Model       string    `json:"model" label:"模型名称" required:"true"`
+	Type        ModelType `json:"type" label:"模型类型" enum:"llm,text-embedding,rerank,moderation,tts,speech2text" default:"llm"`
+	Mode        string    `json:"mode" label:"对话模式" enum:"chat,completion" default:"chat" switch:"type==='llm'"`
+	ContextSize int       `json:"context_size" label:"上下文长度"`
+	Pricing     *Pricing  `json:"pricing" label:"计费信息"`
+}
+
+// IModelSource 模型列表来自配置的供应商驱动，按驱动实例返回模型信息
+type IModelSource interface {
+	Model(model string) (*Model, bool)
+}
+
+// NewCustomModels 转换配置的模型，模型名称为空或重复时返回错误
+func NewCustomModels(provider string, models []*CustomModel) (map[string]*Model, error) {
+	result := make(map[string]*Model, len(models))
+	for _, m := range models {
+		if m == nil || m.Model == "" {
+			return nil, fmt.Errorf("model name is required")
+		}
+		if _, has := result[m.Model]; has {
+			return nil, fmt.Errorf("duplicate model: %s", m.Model)
+		}
+		modelType := m.Type
+		if modelType == "" {
+			modelType = ModelTypeLLM
+		}
+		model := &Model{
+			Model:     m.Model,
+			ModelType: modelType,
+			Pricing:   m.Pricing,
+			Provider:  provider,
+		}
+		if modelType == ModelTypeLLM {
+			mode := m.Mode
+			if mode == "" {
+				mode = ModeChat.String()
+			}
+			model.ModelProperties = &ModelMode{Mode: mode, ContextSize: m.ContextSize}
+		}
+		result[m.Model] = model
+	}
+	return result, nil
+}
@@ -0,0 +1,103 @@
+package ai_provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// embeddingTimeout 在请求之外调用向量模型的超时时间
+const embeddingTimeout = 10 * time.Second
+
+var embeddingClient = &http.Client{Timeout: embeddingTimeout}
+
+// IEmbeddingDriver 可直接调用向量模型的供应商，供语义缓存等功能在转发请求之外生成文本向量
+type IEmbeddingDriver interface {
+	Embeddings(ctx context.Context, model string, inputs []string) ([][]float64, error)
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// OpenAIEmbeddings 调用OpenAI兼容的/v1/embeddings接口，按输入顺序返回向量
+func OpenAIEmbeddings(ctx context.Context, base, apikey, model string, inputs []string) ([][]float64, error) {
+	return RequestOpenAIEmbeddings(ctx, strings.TrimSuffix(base, "/")+"/v1/embeddings", map[string]string{"Authorization": "Bearer " + apikey}, model, inputs)
+}
+
+// RequestOpenAIEmbeddings 调用OpenAI兼容格式的向量接口，url为完整的接口地址，headers为鉴权等请求头部
+func RequestOpenAIEmbeddings(ctx context.Context, url string, headers map[string]string, model string, inputs []string) ([][]float64, error) {
+	result := new(openAIEmbeddingResponse)
+	status, err := PostJSON(ctx, url, headers, map[string]interface{}{
+		"model": model,
+		"input": inputs,
+	}, result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != nil {
+		return nil, errors.New(result.Error.Message)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("embeddings failed, status: %d", status)
+	}
+	vectors := make([][]float64, len(inputs))
+	for _, d := range result.Data {
+		if d.Index >= 0 && d.Index < len(vectors) {
+			vectors[d.Index] = d.Embedding
+		}
+	}
+	return vectors, CheckVectors(vectors)
+}
+
+// CheckVectors 检查是否返回了每个输入的向量
+func CheckVectors(vectors [][]float64) error {
+	for _, v := range vectors {
+		if len(v) == 0 {
+			return errors.New("embeddings response missing vectors")
+		}
+	}
+	return nil
+}
+
+// PostJSON 在转发请求之外调用供应商的接口，将JSON响应解析到v中并返回响应状态码
+func PostJSON(ctx context.Context, url string, headers map[string]string, body interface{}, v interface{}) (int, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, value := range headers {
+		req.Header.Set(k, value)
+	}
+	resp, err := embeddingClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	data, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("invalid response, status: %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
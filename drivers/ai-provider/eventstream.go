@@ -0,0 +1,120 @@
+package ai_provider
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+const (
+	eventStreamPreludeLen = 12
+	eventStreamCRCLen     = 4
+	// eventStreamMaxMessageLen AWS事件流单条消息的最大长度
+	eventStreamMaxMessageLen = 16 * 1024 * 1024
+)
+
+var errInvalidEventStream = errors.New("invalid aws event stream message")
+
+// NewAWSEventStreamDecoder 按application/vnd.amazon.eventstream格式拆分事件。
+// 消息类型为exception时Event为:exception-type，为error时Event为:error-code且Data为{"message": :error-message}
+func NewAWSEventStreamDecoder(reader io.Reader) IStreamDecoder {
+	return &awsEventStreamDecoder{reader: reader}
+}
+
+type awsEventStreamDecoder struct {
+	reader io.Reader
+}
+
+// Next 消息格式：总长度(4) 头部长度(4) 前导CRC(4) 头部 负载 消息CRC(4)
+func (d *awsEventStreamDecoder) Next() (*StreamEvent, error) {
+	prelude := make([]byte, eventStreamPreludeLen)
+	_, err := io.ReadFull(d.reader, prelude)
+	if err != nil {
+		return nil, err
+	}
+	totalLen := binary.BigEndian.Uint32(prelude[0:4])
+	headersLen := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return nil, errInvalidEventStream
+	}
+	if totalLen > eventStreamMaxMessageLen || totalLen < eventStreamPreludeLen+eventStreamCRCLen ||
+		headersLen > totalLen-eventStreamPreludeLen-eventStreamCRCLen {
+		return nil, errInvalidEventStream
+	}
+	message := make([]byte, totalLen-eventStreamPreludeLen)
+	_, err = io.ReadFull(d.reader, message)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	crcOffset := len(message) - eventStreamCRCLen
+	checksum := crc32.Update(crc32.ChecksumIEEE(prelude), crc32.IEEETable, message[:crcOffset])
+	if checksum != binary.BigEndian.Uint32(message[crcOffset:]) {
+		return nil, errInvalidEventStream
+	}
+	headers, err := parseEventStreamHeaders(message[:headersLen])
+	if err != nil {
+		return nil, err
+	}
+	event := &StreamEvent{Data: message[headersLen:crcOffset]}
+	switch headers[":message-type"] {
+	case "exception":
+		event.Event = headers[":exception-type"]
+	case "error":
+		event.Event = headers[":error-code"]
+		event.Data, _ = json.Marshal(map[string]string{"message": headers[":error-message"]})
+	default:
+		event.Event = headers[":event-type"]
+	}
+	return event, nil
+}
+
+// parseEventStreamHeaders 解析消息头部，仅保留字符串类型的值
+func parseEventStreamHeaders(data []byte) (map[string]string, error) {
+	headers := make(map[string]string)
+	for len(data) > 0 {
+		nameLen := int(data[0])
+		if len(data) < 1+nameLen+1 {
+			return nil, errInvalidEventStream
+		}
+		name := string(data[1 : 1+nameLen])
+		valueType := data[1+nameLen]
+		data = data[2+nameLen:]
+		var valueLen int
+		switch valueType {
+		case 0, 1:
+			// bool值，无负载
+		case 2:
+			valueLen = 1
+		case 3:
+			valueLen = 2
+		case 4:
+			valueLen = 4
+		case 5, 8:
+			valueLen = 8
+		case 9:
+			valueLen = 16
+		case 6, 7:
+			if len(data) < 2 {
+				return nil, errInvalidEventStream
+			}
+			valueLen = int(binary.BigEndian.Uint16(data[:2]))
+			data = data[2:]
+		default:
+			return nil, fmt.Errorf("invalid aws event stream header type: %d", valueType)
+		}
+		if len(data) < valueLen {
+			return nil, errInvalidEventStream
+		}
+		if valueType == 7 {
+			headers[name] = string(data[:valueLen])
+		}
+		data = data[valueLen:]
+	}
+	return headers, nil
+}
@@ -3,11 +3,13 @@ package fakegpt
 import (
 	"fmt"
 
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
 	"github.com/eolinker/eosc"
 )
 
 type Config struct {
-	APIKey string `json:"apikey"`
+	APIKey  string               `json:"apikey"`
+	APIKeys []ai_provider.APIKey `json:"api_keys" label:"API Key池" description:"多个API Key按权重轮询，被限流的Key在冷却期内暂停使用"`
 }
 
 func checkConfig(v interface{}) (*Config, error) {
@@ -15,7 +17,7 @@ func checkConfig(v interface{}) (*Config, error) {
 	if !ok {
 		return nil, eosc.ErrorConfigType
 	}
-	if conf.APIKey == "" {
+	if conf.APIKey == "" && len(conf.APIKeys) == 0 {
 		return nil, fmt.Errorf("apikey is required")
 	}
 	return conf, nil
@@ -71,6 +71,7 @@ func (c *Chat) ResponseConvert(ctx eocontext.EoContext) error {
 			Content: msg.Message.Content,
 		}
 		responseBody.FinishReason = msg.FinishReason
+		responseBody.Usage = ai_provider.NewUsage(data.Config.Usage.PromptTokens, data.Config.Usage.CompletionTokens, data.Config.Usage.TotalTokens)
 	} else {
 		responseBody.Code = -1
 		responseBody.Error = "no response"
@@ -82,3 +83,8 @@ func (c *Chat) ResponseConvert(ctx eocontext.EoContext) error {
 	httpContext.Response().SetBody(body)
 	return nil
 }
+
+// StreamConvert 模拟接口不支持流式，完整响应以单个事件返回
+func (c *Chat) StreamConvert(ctx eocontext.EoContext) error {
+	return ai_provider.StreamConvert(ctx, ai_provider.NewSSEDecoder, ai_provider.OpenAIStreamConvert, c.ResponseConvert)
+}
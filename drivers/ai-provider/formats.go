@@ -0,0 +1,128 @@
+package ai_provider
+
+import (
+	"encoding/json"
+)
+
+const (
+	ModeEmbedding     Mode = "embedding"
+	ModeRerank        Mode = "rerank"
+	ModeModeration    Mode = "moderation"
+	ModeSpeech        Mode = "tts"
+	ModeTranscription Mode = "speech2text"
+)
+
+// ConvertMode 返回模型使用的转换模式：大语言模型按model_properties.mode区分对话及补全，其余模型按模型类型区分
+func (m *Model) ConvertMode() string {
+	switch m.ModelType {
+	case ModelTypeLLM:
+		if m.ModelProperties == nil {
+			return ""
+		}
+		return m.ModelProperties.Mode
+	case ModelTypeTextEmbedding:
+		return ModeEmbedding.String()
+	case ModelTypeRerank:
+		return ModeRerank.String()
+	case ModelTypeModeration:
+		return ModeModeration.String()
+	case ModelTypeTTS:
+		return ModeSpeech.String()
+	case ModelTypeSpeech2Text:
+		return ModeTranscription.String()
+	}
+	return ""
+}
+
+// Inputs 输入文本，请求中可为单个字符串或字符串数组
+type Inputs []string
+
+func (i *Inputs) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		*i = Inputs{s}
+		return nil
+	}
+	var arr []string
+	err := json.Unmarshal(data, &arr)
+	if err != nil {
+		return err
+	}
+	*i = arr
+	return nil
+}
+
+// EmbeddingRequest 统一的向量请求
+type EmbeddingRequest struct {
+	Input Inputs `json:"input"`
+	// Dimensions 输出向量的维度，仅部分模型支持
+	Dimensions int `json:"dimensions,omitempty"`
+}
+
+type Embedding struct {
+	Index     int       `json:"index"`
+	Embedding []float64 `json:"embedding"`
+}
+
+// EmbeddingResponse 统一的向量响应，Data按输入顺序返回
+type EmbeddingResponse struct {
+	Data  []*Embedding `json:"data"`
+	Code  int          `json:"code"`
+	Error string       `json:"error"`
+	Usage *Usage       `json:"usage,omitempty"`
+}
+
+// RerankRequest 统一的重排序请求
+type RerankRequest struct {
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n,omitempty"`
+}
+
+type RerankResult struct {
+	// Index 文档在请求中的下标
+	Index          int     `json:"index"`
+	RelevanceScore float64 `json:"relevance_score"`
+	Document       string  `json:"document,omitempty"`
+}
+
+// RerankResponse 统一的重排序响应，Results按相关度从高到低排列
+type RerankResponse struct {
+	Results []*RerankResult `json:"results"`
+	Code    int             `json:"code"`
+	Error   string          `json:"error"`
+	Usage   *Usage          `json:"usage,omitempty"`
+}
+
+// ModerationRequest 统一的内容审核请求
+type ModerationRequest struct {
+	Input Inputs `json:"input"`
+}
+
+type ModerationResult struct {
+	Flagged        bool               `json:"flagged"`
+	Categories     map[string]bool    `json:"categories"`
+	CategoryScores map[string]float64 `json:"category_scores"`
+}
+
+// ModerationResponse 统一的内容审核响应，Results按输入顺序返回
+type ModerationResponse struct {
+	Results []*ModerationResult `json:"results"`
+	Code    int                 `json:"code"`
+	Error   string              `json:"error"`
+}
+
+// SpeechRequest 统一的语音合成请求，成功时直接返回音频内容
+type SpeechRequest struct {
+	Input          string  `json:"input"`
+	Voice          string  `json:"voice"`
+	ResponseFormat string  `json:"response_format"`
+	Speed          float64 `json:"speed"`
+}
+
+// TranscriptionResponse 统一的语音转文字响应，请求以multipart/form-data格式上传file字段
+type TranscriptionResponse struct {
+	Text  string `json:"text"`
+	Code  int    `json:"code"`
+	Error string `json:"error"`
+}
@@ -0,0 +1,42 @@
+package ai_provider
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestInputs(t *testing.T) {
+	for body, want := range map[string]Inputs{
+		`{"input":"hello"}`:           {"hello"},
+		`{"input":["hello","world"]}`: {"hello", "world"},
+	} {
+		request := new(EmbeddingRequest)
+		if err := json.Unmarshal([]byte(body), request); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(request.Input, want) {
+			t.Errorf("%s: got %v, want %v", body, request.Input, want)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"input":1}`), new(EmbeddingRequest)); err == nil {
+		t.Error("expect error for invalid input")
+	}
+}
+
+func TestOpenAICompletionStreamConvert(t *testing.T) {
+	chunk, err := OpenAICompletionStreamConvert(&StreamEvent{Data: []byte(`{"choices":[{"text":"Hi","finish_reason":null}]}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chunk.Message.Content != "Hi" || chunk.Message.Role != "assistant" {
+		t.Errorf("unexpected chunk: %+v", chunk)
+	}
+	chunk, _ = OpenAICompletionStreamConvert(&StreamEvent{Data: []byte(`{"choices":[],"usage":{"prompt_tokens":3,"completion_tokens":1}}`)})
+	if chunk == nil || chunk.Usage == nil || chunk.Usage.TotalTokens != 4 {
+		t.Errorf("unexpected usage chunk: %+v", chunk)
+	}
+	if chunk, _ = OpenAICompletionStreamConvert(&StreamEvent{Data: []byte("[DONE]")}); chunk != nil {
+		t.Errorf("expect [DONE] ignored, got %+v", chunk)
+	}
+}
@@ -0,0 +1,205 @@
+package ai_provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/eolinker/eosc"
+)
+
+// Gemini格式的消息及工具转换，供Google AI Studio及Vertex AI复用
+
+type GeminiContent struct {
+	Role  string        `json:"role"`
+	Parts []*GeminiPart `json:"parts"`
+}
+
+// GeminiPart 内容块，text、inlineData、fileData、functionCall及functionResponse中仅有一个生效
+type GeminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	InlineData       *GeminiBlob             `json:"inlineData,omitempty"`
+	FileData         *GeminiFile             `json:"fileData,omitempty"`
+	FunctionCall     *GeminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *GeminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+type GeminiBlob struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+type GeminiFile struct {
+	MimeType string `json:"mimeType,omitempty"`
+	FileUri  string `json:"fileUri"`
+}
+
+type GeminiFunctionCall struct {
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+type GeminiFunctionResponse struct {
+	Name     string          `json:"name"`
+	Response json.RawMessage `json:"response"`
+}
+
+type GeminiTool struct {
+	FunctionDeclarations []*Function `json:"functionDeclarations"`
+}
+
+type GeminiToolConfig struct {
+	FunctionCallingConfig struct {
+		Mode                 string   `json:"mode"`
+		AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+	} `json:"functionCallingConfig"`
+}
+
+// GeminiContents 转换为Gemini的contents：assistant对应model，工具调用结果以functionResponse返回，
+// 有多条消息时system消息以model角色发送
+func GeminiContents(messages []*Message) []*GeminiContent {
+	// Gemini的调用结果按函数名对应，根据调用ID查找函数名
+	names := make(map[string]string)
+	contents := make([]*GeminiContent, 0, len(messages))
+	for _, m := range messages {
+		if m == nil {
+			continue
+		}
+		content := &GeminiContent{Role: "user"}
+		switch m.Role {
+		case "system":
+			if len(messages) > 1 {
+				content.Role = "model"
+			}
+			content.Parts = geminiParts(m.ContentParts())
+		case "assistant":
+			content.Role = "model"
+			content.Parts = geminiParts(m.ContentParts())
+			for _, call := range m.ToolCalls {
+				names[call.ID] = call.Function.Name
+				content.Parts = append(content.Parts, &GeminiPart{FunctionCall: &GeminiFunctionCall{Name: call.Function.Name, Args: call.ArgumentsJSON()}})
+			}
+		case "tool":
+			name, has := names[m.ToolCallID]
+			if !has {
+				name = m.Name
+			}
+			content.Parts = []*GeminiPart{{FunctionResponse: &GeminiFunctionResponse{Name: name, Response: geminiFunctionResponse(m.Content)}}}
+		default:
+			content.Parts = geminiParts(m.ContentParts())
+		}
+		if len(content.Parts) == 0 {
+			continue
+		}
+		contents = append(contents, content)
+	}
+	return contents
+}
+
+// geminiFunctionResponse Gemini的调用结果需为JSON对象，非对象时以{"content":结果}返回
+func geminiFunctionResponse(result string) json.RawMessage {
+	trimmed := strings.TrimSpace(result)
+	if strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed)) {
+		return json.RawMessage(trimmed)
+	}
+	data, _ := json.Marshal(map[string]string{"content": result})
+	return data
+}
+
+func geminiParts(parts []*ContentPart) []*GeminiPart {
+	result := make([]*GeminiPart, 0, len(parts))
+	for _, p := range parts {
+		switch p.Type {
+		case PartText:
+			if p.Text != "" {
+				result = append(result, &GeminiPart{Text: p.Text})
+			}
+		case PartImageURL:
+			if p.ImageURL == nil {
+				continue
+			}
+			if mediaType, data, ok := p.ImageURL.Base64(); ok {
+				result = append(result, &GeminiPart{InlineData: &GeminiBlob{MimeType: mediaType, Data: data}})
+			} else {
+				result = append(result, &GeminiPart{FileData: &GeminiFile{FileUri: p.ImageURL.URL}})
+			}
+		case PartInputAudio:
+			if p.InputAudio != nil {
+				result = append(result, &GeminiPart{InlineData: &GeminiBlob{MimeType: "audio/" + p.InputAudio.Format, Data: p.InputAudio.Data}})
+			}
+		}
+	}
+	return result
+}
+
+// GeminiTools 转换工具定义及调用方式，required对应ANY，指定函数时对应ANY并限定可调用的函数
+func GeminiTools(tools []*Tool, choice *ToolChoice) ([]*GeminiTool, *GeminiToolConfig) {
+	declarations := make([]*Function, 0, len(tools))
+	for _, t := range tools {
+		if t.Function != nil {
+			declarations = append(declarations, t.Function)
+		}
+	}
+	if len(declarations) == 0 {
+		return nil, nil
+	}
+	geminiTools := []*GeminiTool{{FunctionDeclarations: declarations}}
+	if choice == nil {
+		return geminiTools, nil
+	}
+	config := &GeminiToolConfig{}
+	switch choice.Type {
+	case ToolChoiceAuto:
+		config.FunctionCallingConfig.Mode = "AUTO"
+	case ToolChoiceNone:
+		config.FunctionCallingConfig.Mode = "NONE"
+	case ToolChoiceRequired:
+		config.FunctionCallingConfig.Mode = "ANY"
+	case ToolChoiceFunction:
+		config.FunctionCallingConfig.Mode = "ANY"
+		config.FunctionCallingConfig.AllowedFunctionNames = []string{choice.Function}
+	default:
+		return geminiTools, nil
+	}
+	return geminiTools, config
+}
+
+// SetGeminiRequest 将统一格式的消息及工具写入Gemini格式的请求
+func SetGeminiRequest(base *eosc.Base[ClientRequest]) {
+	base.SetAppend("contents", GeminiContents(base.Config.Messages))
+	tools, config := GeminiTools(base.Config.Tools, base.Config.ToolChoice)
+	if tools != nil {
+		base.SetAppend("tools", tools)
+	}
+	if config != nil {
+		base.SetAppend("toolConfig", config)
+	}
+}
+
+// GeminiMessage 转换Gemini的响应内容，合并文本块，functionCall转换为工具调用，stream为true时工具调用按序号返回
+func GeminiMessage(parts []*GeminiPart, stream bool) Message {
+	message := Message{Role: "assistant"}
+	texts := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p.FunctionCall != nil {
+			i := len(message.ToolCalls)
+			index := -1
+			if stream {
+				index = i
+			}
+			message.ToolCalls = append(message.ToolCalls, NewToolCall(index, fmt.Sprintf("call_%d", i), p.FunctionCall.Name, string(p.FunctionCall.Args)))
+			continue
+		}
+		texts = append(texts, p.Text)
+	}
+	message.Content = strings.Join(texts, "")
+	return message
+}
+
+// GeminiFinishReason 返回工具调用时结束原因为tool_calls
+func GeminiFinishReason(message *Message, reason string) string {
+	if reason == FinishStop && len(message.ToolCalls) > 0 {
+		return FinishToolCalls
+	}
+	return reason
+}
@@ -34,16 +34,14 @@ func init() {
 		panic(err)
 	}
 	for key, value := range models {
-		if value.ModelProperties != nil {
-			if f, ok := modelModes[value.ModelProperties.Mode]; ok {
-				modelConvert[key] = f(value.Model)
-			}
+		if f, ok := modelModes[value.ConvertMode()]; ok {
+			modelConvert[key] = f(value.Model)
 		}
 	}
 }
 
 type Converter struct {
-	apikey         string
+	keys           *ai_provider.KeyPool
 	balanceHandler eocontext.BalanceHandler
 	converter      convert.IConverter
 }
@@ -56,7 +54,7 @@ func (c *Converter) RequestConvert(ctx eocontext.EoContext, extender map[string]
 	if err != nil {
 		return err
 	}
-	httpContext.Proxy().URI().SetQuery("key", c.apikey)
+	httpContext.Proxy().URI().SetQuery("key", c.keys.Next(ctx))
 
 	return c.converter.RequestConvert(httpContext, extender)
 }
@@ -65,10 +63,14 @@ func (c *Converter) ResponseConvert(ctx eocontext.EoContext) error {
 	return c.converter.ResponseConvert(ctx)
 }
 
+func (c *Converter) StreamConvert(ctx eocontext.EoContext) error {
+	return c.converter.StreamConvert(ctx)
+}
+
 type executor struct {
 	drivers.WorkerBase
 	eocontext.BalanceHandler
-	apikey string
+	keys *ai_provider.KeyPool
 }
 
 func (e *executor) GetConverter(model string) (convert.IConverter, bool) {
@@ -77,7 +79,7 @@ func (e *executor) GetConverter(model string) (convert.IConverter, bool) {
 		return nil, false
 	}
 
-	return &Converter{balanceHandler: e.BalanceHandler, converter: converter, apikey: e.apikey}, true
+	return &Converter{balanceHandler: e.BalanceHandler, converter: converter, keys: e.keys}, true
 }
 
 func (e *executor) GetModel(model string) (convert.FGenerateConfig, bool) {
@@ -127,7 +129,7 @@ func (e *executor) reset(conf *Config, workers map[eosc.RequireId]eosc.IWorker)
 	} else {
 		e.BalanceHandler = nil
 	}
-	e.apikey = conf.APIKey
+	e.keys = ai_provider.NewKeyPool(conf.APIKey, conf.APIKeys)
 	convert.Set(e.Id(), e)
 	return nil
 }
@@ -141,6 +143,10 @@ func (e *executor) CheckSkill(skill string) bool {
 	return convert.CheckSkill(skill)
 }
 
+func (e *executor) Provider() string {
+	return name
+}
+
 type ModelConfig struct {
 	ResponseMimeType string  `json:"response_format"`
 	MaxOutputTokens  int     `json:"max_tokens_to_sample"`
@@ -1,16 +1,26 @@
 package google
 
+import ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+
 type ClientRequest struct {
 	Contents []*Content `json:"contents"`
 }
 
-type Content struct {
-	Parts []map[string]interface{} `json:"parts"`
-	Role  string                   `json:"role"`
-}
+type Content = ai_provider.GeminiContent
 
 type Response struct {
-	Candidates []Candidate `json:"candidates"`
+	Candidates    []Candidate   `json:"candidates"`
+	UsageMetadata UsageMetadata `json:"usageMetadata"`
+}
+
+type UsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	TotalTokenCount      int `json:"totalTokenCount"`
+}
+
+func (u *UsageMetadata) usage() *ai_provider.Usage {
+	return ai_provider.NewUsage(u.PromptTokenCount, u.CandidatesTokenCount, u.TotalTokenCount)
 }
 
 type Candidate struct {
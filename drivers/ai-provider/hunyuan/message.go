@@ -29,6 +29,8 @@ type ResponseInfo struct {
 type Choice struct {
 	FinishReason string  `json:"FinishReason"`
 	Message      Message `json:"Message"`
+	// Delta 流式响应的增量内容
+	Delta Message `json:"Delta"`
 }
 
 type Usage struct {
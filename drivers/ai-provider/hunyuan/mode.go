@@ -79,6 +79,9 @@ func (c *Chat) RequestConvert(ctx eocontext.EoContext, extender map[string]inter
 	}
 
 	baseCfg.SetAppend("Messages", messages)
+	if baseCfg.Config.Stream {
+		baseCfg.SetAppend("Stream", true)
+	}
 	for k, v := range extender {
 		baseCfg.SetAppend(k, v)
 	}
@@ -114,6 +117,7 @@ func (c *Chat) ResponseConvert(ctx eocontext.EoContext) error {
 			Content: msg.Message.Content,
 		}
 		responseBody.FinishReason = msg.FinishReason
+		responseBody.Usage = ai_provider.NewUsage(data.Config.Response.Usage.PromptTokens, data.Config.Response.Usage.CompletionTokens, data.Config.Response.Usage.TotalTokens)
 		//}
 	} else {
 		responseBody.Code = -1
@@ -127,3 +131,31 @@ func (c *Chat) ResponseConvert(ctx eocontext.EoContext) error {
 	httpContext.Response().SetBody(body)
 	return nil
 }
+
+func (c *Chat) StreamConvert(ctx eocontext.EoContext) error {
+	return ai_provider.StreamConvert(ctx, ai_provider.NewSSEDecoder, streamConvert, c.ResponseConvert)
+}
+
+// streamConvert 流式响应的事件未包裹在Response中，增量内容在Choices[0].Delta中
+func streamConvert(event *ai_provider.StreamEvent) (*ai_provider.ClientResponse, error) {
+	data := new(ResponseInfo)
+	err := json.Unmarshal(event.Data, data)
+	if err != nil {
+		return nil, err
+	}
+	if data.Error.Message != "" {
+		return &ai_provider.ClientResponse{Code: -1, Error: data.Error.Message}, nil
+	}
+	if len(data.Choices) < 1 {
+		return nil, nil
+	}
+	msg := data.Choices[0]
+	return &ai_provider.ClientResponse{
+		Message: ai_provider.Message{
+			Role:    msg.Delta.Role,
+			Content: msg.Delta.Content,
+		},
+		FinishReason: msg.FinishReason,
+		Usage:        ai_provider.NewUsage(data.Usage.PromptTokens, data.Usage.CompletionTokens, data.Usage.TotalTokens),
+	}, nil
+}
@@ -0,0 +1,136 @@
+package ai_provider
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eolinker/eosc/eocontext"
+)
+
+const (
+	apiKeyCtxKey = "ai_api_key"
+	// defaultKeyCooldown 被限流且上游未返回Retry-After时Key暂停使用的时长
+	defaultKeyCooldown = time.Minute
+)
+
+// APIKey Key池中的一个API Key
+type APIKey struct {
+	Key    string `json:"key" label:"API Key"`
+	Weight int    `json:"weight" label:"权重" default:"1" description:"按权重分配请求，默认为1"`
+}
+
+type poolKey struct {
+	pool    *KeyPool
+	key     string
+	weight  int
+	current int
+	// cooldown 暂停使用的截止时间，UnixNano
+	cooldown int64
+}
+
+// KeyPool 同一供应商的多个API Key，按权重平滑轮询，被限流的Key在冷却期内不参与轮询
+type KeyPool struct {
+	lock sync.Mutex
+	keys []*poolKey
+}
+
+// NewKeyPool 合并单个API Key及Key池配置，忽略空Key及重复Key
+func NewKeyPool(apikey string, keys []APIKey) *KeyPool {
+	pool := &KeyPool{}
+	exists := make(map[string]struct{}, len(keys)+1)
+	add := func(key string, weight int) {
+		if key == "" {
+			return
+		}
+		if _, has := exists[key]; has {
+			return
+		}
+		exists[key] = struct{}{}
+		if weight < 1 {
+			weight = 1
+		}
+		pool.keys = append(pool.keys, &poolKey{pool: pool, key: key, weight: weight})
+	}
+	add(apikey, 1)
+	for _, k := range keys {
+		add(k.Key, k.Weight)
+	}
+	return pool
+}
+
+// Len Key池中Key的数量
+func (p *KeyPool) Len() int {
+	return len(p.keys)
+}
+
+// Next 选择本次请求使用的Key，并记录到上下文供限流时冷却
+func (p *KeyPool) Next(ctx eocontext.EoContext) string {
+	selected := p.pick()
+	if selected == nil {
+		return ""
+	}
+	ctx.WithValue(apiKeyCtxKey, selected)
+	return selected.key
+}
+
+// Pick 在转发请求之外（如调用向量模型）选择使用的Key
+func (p *KeyPool) Pick() string {
+	selected := p.pick()
+	if selected == nil {
+		return ""
+	}
+	return selected.key
+}
+
+// pick 全部Key都在冷却期时选择最早恢复的Key
+func (p *KeyPool) pick() *poolKey {
+	if len(p.keys) == 0 {
+		return nil
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := time.Now().UnixNano()
+	var selected *poolKey
+	total := 0
+	for _, k := range p.keys {
+		if k.cooldown > now {
+			continue
+		}
+		k.current += k.weight
+		total += k.weight
+		if selected == nil || k.current > selected.current {
+			selected = k
+		}
+	}
+	if selected != nil {
+		selected.current -= total
+		return selected
+	}
+	selected = p.keys[0]
+	for _, k := range p.keys[1:] {
+		if k.cooldown < selected.cooldown {
+			selected = k
+		}
+	}
+	return selected
+}
+
+// ReleaseKey 上游响应后释放本次请求使用的Key，上游返回429时暂停使用该Key，retryAfter为上游返回的Retry-After秒数，为空时使用默认时长
+func ReleaseKey(ctx eocontext.EoContext, statusCode int, retryAfter string) {
+	k, ok := ctx.Value(apiKeyCtxKey).(*poolKey)
+	if !ok || k == nil {
+		return
+	}
+	ctx.WithValue(apiKeyCtxKey, (*poolKey)(nil))
+	if statusCode != 429 {
+		return
+	}
+	d := defaultKeyCooldown
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds > 0 {
+		d = time.Duration(seconds) * time.Second
+	}
+	k.pool.lock.Lock()
+	k.cooldown = time.Now().Add(d).UnixNano()
+	k.pool.lock.Unlock()
+}
@@ -0,0 +1,40 @@
+package ai_provider
+
+import (
+	"testing"
+
+	http_context "github.com/eolinker/apinto/node/http-context"
+	"github.com/valyala/fasthttp"
+)
+
+func TestKeyPool(t *testing.T) {
+	pool := NewKeyPool("a", []APIKey{{Key: "b", Weight: 2}, {Key: "a", Weight: 5}, {Key: ""}})
+	if pool.Len() != 2 {
+		t.Fatalf("expect 2 keys, got %d", pool.Len())
+	}
+	ctx := http_context.NewContext(&fasthttp.RequestCtx{}, 0)
+	count := map[string]int{}
+	for i := 0; i < 30; i++ {
+		count[pool.Next(ctx)]++
+	}
+	if count["a"] != 10 || count["b"] != 20 {
+		t.Errorf("unexpected weighted result: %v", count)
+	}
+
+	// 被限流的Key在冷却期内不再被选择
+	var limited string
+	for limited != "b" {
+		limited = pool.Next(ctx)
+	}
+	ReleaseKey(ctx, 429, "")
+	for i := 0; i < 5; i++ {
+		if key := pool.Next(ctx); key != "a" {
+			t.Fatalf("expect a while b cooling down, got %s", key)
+		}
+	}
+	ReleaseKey(ctx, 429, "10")
+	// 全部冷却时选择最早恢复的Key
+	if key := pool.Next(ctx); key != "a" {
+		t.Errorf("expect a when all keys cooling down, got %s", key)
+	}
+}
@@ -35,16 +35,14 @@ func init() {
 		panic(err)
 	}
 	for key, value := range models {
-		if value.ModelProperties != nil {
-			if v, ok := modelModes[value.ModelProperties.Mode]; ok {
-				modelConvert[key] = v
-			}
+		if v, ok := modelModes[value.ConvertMode()]; ok {
+			modelConvert[key] = v
 		}
 	}
 }
 
 type Converter struct {
-	apikey         string
+	keys           *ai_provider.KeyPool
 	balanceHandler eocontext.BalanceHandler
 	converter      convert.IConverter
 }
@@ -57,7 +55,7 @@ func (c *Converter) RequestConvert(ctx eocontext.EoContext, extender map[string]
 	if err != nil {
 		return err
 	}
-	httpContext.Proxy().Header().SetHeader("Authorization", "Bearer "+c.apikey)
+	httpContext.Proxy().Header().SetHeader("Authorization", "Bearer "+c.keys.Next(ctx))
 
 	return c.converter.RequestConvert(httpContext, extender)
 }
@@ -66,9 +64,13 @@ func (c *Converter) ResponseConvert(ctx eocontext.EoContext) error {
 	return c.converter.ResponseConvert(ctx)
 }
 
+func (c *Converter) StreamConvert(ctx eocontext.EoContext) error {
+	return c.converter.StreamConvert(ctx)
+}
+
 type executor struct {
 	drivers.WorkerBase
-	apikey string
+	keys *ai_provider.KeyPool
 	eocontext.BalanceHandler
 }
 
@@ -78,7 +80,7 @@ func (e *executor) GetConverter(model string) (convert.IConverter, bool) {
 		return nil, false
 	}
 
-	return &Converter{balanceHandler: e.BalanceHandler, converter: converter, apikey: e.apikey}, true
+	return &Converter{balanceHandler: e.BalanceHandler, converter: converter, keys: e.keys}, true
 }
 
 func (e *executor) GetModel(model string) (convert.FGenerateConfig, bool) {
@@ -127,7 +129,7 @@ func (e *executor) Reset(conf interface{}, workers map[eosc.RequireId]eosc.IWork
 
 func (e *executor) reset(conf *Config, workers map[eosc.RequireId]eosc.IWorker) error {
 	e.BalanceHandler = nil
-	e.apikey = conf.APIKey
+	e.keys = ai_provider.NewKeyPool(conf.APIKey, conf.APIKeys)
 	convert.Set(e.Id(), e)
 
 	return nil
@@ -143,6 +145,10 @@ func (e *executor) CheckSkill(skill string) bool {
 	return convert.CheckSkill(skill)
 }
 
+func (e *executor) Provider() string {
+	return name
+}
+
 type ModelConfig struct {
 	MaxTokens   int     `json:"max_tokens"`
 	Temperature float64 `json:"temperature"`
@@ -0,0 +1,30 @@
+package ollama
+
+import (
+	"fmt"
+	"net/url"
+
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+	"github.com/eolinker/eosc"
+)
+
+type Config struct {
+	Base   string                     `json:"base" label:"API地址" required:"true" default:"http://127.0.0.1:11434" description:"Ollama服务地址，如http://127.0.0.1:11434"`
+	APIKey string                     `json:"api_key" label:"API Key" description:"经反向代理开启鉴权时以Bearer方式传递，可为空"`
+	Models []*ai_provider.CustomModel `json:"models" label:"模型列表" required:"true" description:"支持对话、补全及向量模型"`
+}
+
+func checkConfig(v interface{}) (*Config, error) {
+	conf, ok := v.(*Config)
+	if !ok {
+		return nil, eosc.ErrorConfigType
+	}
+	u, err := url.Parse(conf.Base)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("base url is invalid")
+	}
+	if len(conf.Models) == 0 {
+		return nil, fmt.Errorf("models is required")
+	}
+	return conf, nil
+}
@@ -0,0 +1,186 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/eolinker/apinto/convert"
+	"github.com/eolinker/apinto/drivers"
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/eocontext"
+	http_context "github.com/eolinker/eosc/eocontext/http-context"
+	"github.com/eolinker/eosc/log"
+)
+
+var (
+	_ convert.IConverterDriver     = (*executor)(nil)
+	_ ai_provider.IEmbeddingDriver = (*executor)(nil)
+	_ ai_provider.IModelSource     = (*executor)(nil)
+
+	// requestParams 作为请求参数的模型配置，其余配置作为options中的模型参数
+	requestParams = map[string]struct{}{
+		"format":     {},
+		"keep_alive": {},
+		"think":      {},
+	}
+)
+
+type Converter struct {
+	apikey         string
+	balanceHandler eocontext.BalanceHandler
+	converter      convert.IConverter
+}
+
+func (c *Converter) RequestConvert(ctx eocontext.EoContext, extender map[string]interface{}) error {
+	ctx.SetBalance(c.balanceHandler)
+	httpContext, err := http_context.Assert(ctx)
+	if err != nil {
+		return err
+	}
+	if c.apikey != "" {
+		httpContext.Proxy().Header().SetHeader("Authorization", "Bearer "+c.apikey)
+	}
+	return c.converter.RequestConvert(httpContext, extender)
+}
+
+func (c *Converter) ResponseConvert(ctx eocontext.EoContext) error {
+	return c.converter.ResponseConvert(ctx)
+}
+
+func (c *Converter) StreamConvert(ctx eocontext.EoContext) error {
+	return c.converter.StreamConvert(ctx)
+}
+
+type executor struct {
+	drivers.WorkerBase
+	base   string
+	apikey string
+	models map[string]*ai_provider.Model
+	eocontext.BalanceHandler
+}
+
+func (e *executor) GetConverter(model string) (convert.IConverter, bool) {
+	m, ok := e.models[model]
+	if !ok {
+		return nil, false
+	}
+	converter, ok := modelModes[m.ConvertMode()]
+	if !ok {
+		return nil, false
+	}
+	return &Converter{balanceHandler: e.BalanceHandler, converter: converter, apikey: e.apikey}, true
+}
+
+// GetModel 模型配置中temperature、num_ctx等模型参数放入options，format、keep_alive等作为请求参数
+func (e *executor) GetModel(model string) (convert.FGenerateConfig, bool) {
+	if _, ok := e.models[model]; !ok {
+		return nil, false
+	}
+	return func(cfg string) (map[string]interface{}, error) {
+		result := map[string]interface{}{
+			"model": model,
+		}
+		if cfg == "" {
+			return result, nil
+		}
+		tmp := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(cfg), &tmp); err != nil {
+			log.Errorf("unmarshal config error: %v, cfg: %s", err, cfg)
+			return result, nil
+		}
+		options := make(map[string]interface{})
+		for k, v := range tmp {
+			if _, ok := requestParams[k]; ok {
+				result[k] = v
+				continue
+			}
+			options[k] = v
+		}
+		if len(options) > 0 {
+			result["options"] = options
+		}
+		return result, nil
+	}, true
+}
+
+// Model 返回配置的模型信息
+func (e *executor) Model(model string) (*ai_provider.Model, bool) {
+	m, ok := e.models[model]
+	return m, ok
+}
+
+func (e *executor) Start() error {
+	return nil
+}
+
+func (e *executor) Reset(conf interface{}, workers map[eosc.RequireId]eosc.IWorker) error {
+	cfg, err := checkConfig(conf)
+	if err != nil {
+		return err
+	}
+	return e.reset(cfg, workers)
+}
+
+func (e *executor) reset(conf *Config, workers map[eosc.RequireId]eosc.IWorker) error {
+	models, err := ai_provider.NewCustomModels(name, conf.Models)
+	if err != nil {
+		return err
+	}
+	balanceHandler, err := ai_provider.NewBalanceHandler(e.Id(), conf.Base, 0)
+	if err != nil {
+		return err
+	}
+	e.BalanceHandler = balanceHandler
+	e.base = strings.TrimSuffix(conf.Base, "/")
+	e.apikey = conf.APIKey
+	e.models = models
+	convert.Set(e.Id(), e)
+	return nil
+}
+
+func (e *executor) Stop() error {
+	e.BalanceHandler = nil
+	convert.Del(e.Id())
+	return nil
+}
+
+func (e *executor) CheckSkill(skill string) bool {
+	return convert.CheckSkill(skill)
+}
+
+// Embeddings 调用配置的向量模型
+func (e *executor) Embeddings(ctx context.Context, model string, inputs []string) ([][]float64, error) {
+	if m, ok := e.models[model]; !ok || m.ModelType != ai_provider.ModelTypeTextEmbedding {
+		return nil, fmt.Errorf("embedding model %s not found", model)
+	}
+	headers := make(map[string]string)
+	if e.apikey != "" {
+		headers["Authorization"] = "Bearer " + e.apikey
+	}
+	result := new(EmbeddingResponse)
+	status, err := ai_provider.PostJSON(ctx, e.base+"/api/embed", headers, map[string]interface{}{
+		"model": model,
+		"input": inputs,
+	}, result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != "" {
+		return nil, errors.New(result.Error)
+	}
+	if status != 200 {
+		return nil, fmt.Errorf("embeddings failed, status: %d", status)
+	}
+	if len(result.Embeddings) != len(inputs) {
+		return nil, errors.New("embeddings response missing vectors")
+	}
+	return result.Embeddings, ai_provider.CheckVectors(result.Embeddings)
+}
+
+func (e *executor) Provider() string {
+	return name
+}
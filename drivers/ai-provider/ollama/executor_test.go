@@ -0,0 +1,157 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+	node_http_context "github.com/eolinker/apinto/node/http-context"
+	"github.com/eolinker/eosc/eocontext"
+	"github.com/valyala/fasthttp"
+)
+
+func TestEmbeddings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&request)
+		if r.URL.Path != "/api/embed" || request.Model != "nomic-embed-text" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"model not found"}`))
+			return
+		}
+		w.Write([]byte(`{"embeddings":[[0.1,0.2],[0.3,0.4]],"prompt_eval_count":4}`))
+	}))
+	defer server.Close()
+
+	w, err := Create("ollama@ai-provider", "local", &Config{
+		Base:   server.URL,
+		Models: []*ai_provider.CustomModel{{Model: "nomic-embed-text", Type: ai_provider.ModelTypeTextEmbedding}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vectors, err := w.(*executor).Embeddings(context.Background(), "nomic-embed-text", []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) != 2 || vectors[1][1] != 0.4 {
+		t.Errorf("unexpected vectors: %v", vectors)
+	}
+}
+
+func TestGetModel(t *testing.T) {
+	e := &executor{models: map[string]*ai_provider.Model{"llama3.1": {Model: "llama3.1"}}}
+	generate, ok := e.GetModel("llama3.1")
+	if !ok {
+		t.Fatal("expect model found")
+	}
+	result, _ := generate(`{"temperature":0.2,"keep_alive":"5m"}`)
+	options, _ := result["options"].(map[string]interface{})
+	if result["keep_alive"] != "5m" || options["temperature"] != 0.2 {
+		t.Errorf("unexpected config: %v", result)
+	}
+}
+
+func TestChatStreamConvert(t *testing.T) {
+	chunk, err := chatStreamConvert(&ai_provider.StreamEvent{Data: []byte(`{"message":{"role":"assistant","content":"Hel"},"done":false}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chunk.Message.Content != "Hel" || chunk.FinishReason != "" || chunk.Usage != nil {
+		t.Errorf("unexpected chunk: %+v", chunk)
+	}
+	chunk, _ = chatStreamConvert(&ai_provider.StreamEvent{Data: []byte(`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"weather","arguments":{"city":"beijing"}}}]},"done":true,"done_reason":"stop","prompt_eval_count":10,"eval_count":5}`)})
+	if len(chunk.Message.ToolCalls) != 1 || chunk.Message.ToolCalls[0].Function.Arguments != `{"city":"beijing"}` {
+		t.Errorf("unexpected tool calls: %+v", chunk.Message.ToolCalls)
+	}
+	if chunk.FinishReason != ai_provider.FinishToolCalls || chunk.Usage == nil || chunk.Usage.TotalTokens != 15 {
+		t.Errorf("unexpected finish chunk: %+v", chunk)
+	}
+}
+
+type testNode struct {
+	addr string
+}
+
+func (n *testNode) GetAttrs() eocontext.Attrs                 { return nil }
+func (n *testNode) GetAttrByName(name string) (string, bool)  { return "", false }
+func (n *testNode) ID() string                                { return n.addr }
+func (n *testNode) IP() string                                { return "127.0.0.1" }
+func (n *testNode) Port() int                                 { return 0 }
+func (n *testNode) Addr() string                              { return n.addr }
+func (n *testNode) Status() eocontext.NodeStatus              { return eocontext.Running }
+func (n *testNode) Up()                                       {}
+func (n *testNode) Down()                                     {}
+func (n *testNode) Leave()                                    {}
+func (n *testNode) PassHost() (eocontext.PassHostMod, string) { return eocontext.NodeHost, "" }
+
+// TestChatStream 上游以chunked返回application/x-ndjson时逐行转换为统一SSE格式
+func TestChatStream(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := make(map[string]interface{})
+		json.NewDecoder(r.Body).Decode(&request)
+		if r.URL.Path != "/api/chat" || request["stream"] != true {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for _, line := range []string{
+			`{"message":{"role":"assistant","content":"Hel"},"done":false}`,
+			`{"message":{"role":"assistant","content":"lo"},"done":true,"done_reason":"stop","prompt_eval_count":3,"eval_count":2}`,
+		} {
+			w.Write([]byte(line + "\n"))
+			w.(http.Flusher).Flush()
+			time.Sleep(20 * time.Millisecond)
+		}
+	}))
+	defer upstream.Close()
+	node := &testNode{addr: strings.TrimPrefix(upstream.URL, "http://")}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chat := NewChat()
+	server := &fasthttp.Server{Handler: func(fast *fasthttp.RequestCtx) {
+		ctx := node_http_context.NewContext(fast, 0)
+		ctx.SetProxyOption(nil)
+		if err := chat.RequestConvert(ctx, map[string]interface{}{"model": "llama3.1"}); err != nil {
+			t.Error(err)
+		}
+		ctx.SetUpstreamHostHandler(node)
+		ctx.SendTo("http", node, time.Second)
+		if err := chat.StreamConvert(ctx); err != nil {
+			t.Error(err)
+		}
+		ctx.FastFinish()
+	}}
+	go server.Serve(ln)
+	defer server.Shutdown()
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+	req.SetRequestURI("http://" + ln.Addr().String() + "/chat")
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.SetBodyString(`{"messages":[{"role":"user","content":"hi"}],"stream":true}`)
+	err = fasthttp.DoTimeout(req, resp, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := string(resp.Body())
+	for _, want := range []string{`"content":"Hel"`, `"content":"lo"`, `"total_tokens":5`, "data: [DONE]"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %s in %s", want, body)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package ollama
+
+import (
+	"encoding/json"
+
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+)
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	// Images base64格式的图片，不包含data:前缀
+	Images    []string    `json:"images,omitempty"`
+	ToolCalls []*ToolCall `json:"tool_calls,omitempty"`
+	// ToolName role为tool时对应的工具名
+	ToolName string `json:"tool_name,omitempty"`
+}
+
+// ToolCall Ollama的工具调用没有调用ID，参数为JSON对象
+type ToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+// Response 对话及补全接口的响应，流式返回时每行为一个Response，最后一行done为true并返回用量
+type Response struct {
+	Message         Message `json:"message"`
+	Response        string  `json:"response"`
+	Done            bool    `json:"done"`
+	DoneReason      string  `json:"done_reason"`
+	PromptEvalCount int     `json:"prompt_eval_count"`
+	EvalCount       int     `json:"eval_count"`
+	Error           string  `json:"error"`
+}
+
+func (r *Response) usage() *ai_provider.Usage {
+	return ai_provider.NewUsage(r.PromptEvalCount, r.EvalCount, 0)
+}
+
+type EmbeddingResponse struct {
+	Embeddings      [][]float64 `json:"embeddings"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+	Error           string      `json:"error"`
+}
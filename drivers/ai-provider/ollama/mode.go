@@ -0,0 +1,311 @@
+package ollama
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/eolinker/apinto/convert"
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/eocontext"
+	http_context "github.com/eolinker/eosc/eocontext/http-context"
+)
+
+var (
+	modelModes = map[string]convert.IConverter{
+		ai_provider.ModeChat.String():      NewChat(),
+		ai_provider.ModeComplete.String():  NewGenerate(),
+		ai_provider.ModeEmbedding.String(): NewEmbedding(),
+	}
+)
+
+// readRequest 读取客户端的统一格式请求并设置转发地址
+func readRequest(ctx eocontext.EoContext, path string, v interface{}) (http_context.IHttpContext, error) {
+	httpContext, err := http_context.Assert(ctx)
+	if err != nil {
+		return nil, err
+	}
+	body, err := httpContext.Proxy().Body().RawBody()
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(body, v)
+	if err != nil {
+		return nil, err
+	}
+	httpContext.Proxy().URI().SetPath(path)
+	return httpContext, nil
+}
+
+func setBody(ctx http_context.IHttpContext, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	ctx.Proxy().Body().SetRaw("application/json", data)
+	return nil
+}
+
+// readResponse 读取上游的成功响应，非200时保留上游响应并返回false
+func readResponse(ctx eocontext.EoContext, v interface{}) (http_context.IHttpContext, bool, error) {
+	httpContext, err := http_context.Assert(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if httpContext.Response().StatusCode() != 200 {
+		return httpContext, false, nil
+	}
+	err = json.Unmarshal(httpContext.Response().GetBody(), v)
+	if err != nil {
+		return nil, false, err
+	}
+	return httpContext, true, nil
+}
+
+func setResponse(ctx http_context.IHttpContext, response interface{}) error {
+	body, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	ctx.Response().SetBody(body)
+	ctx.Response().SetHeader("Content-Type", "application/json")
+	return nil
+}
+
+// Chat 对话模型，使用原生的/api/chat接口
+type Chat struct {
+	endPoint string
+}
+
+func NewChat() *Chat {
+	return &Chat{endPoint: "/api/chat"}
+}
+
+func (c *Chat) Endpoint() string {
+	return c.endPoint
+}
+
+func (c *Chat) RequestConvert(ctx eocontext.EoContext, extender map[string]interface{}) error {
+	baseCfg := eosc.NewBase[ai_provider.ClientRequest]()
+	httpContext, err := readRequest(ctx, c.endPoint, baseCfg)
+	if err != nil {
+		return err
+	}
+	baseCfg.SetAppend("messages", convertMessages(baseCfg.Config.Messages))
+	// Ollama默认以流式返回，需显式指定
+	baseCfg.SetAppend("stream", baseCfg.Config.Stream)
+	if len(baseCfg.Config.Tools) > 0 && (baseCfg.Config.ToolChoice == nil || baseCfg.Config.ToolChoice.Type != ai_provider.ToolChoiceNone) {
+		baseCfg.SetAppend("tools", baseCfg.Config.Tools)
+	}
+	for k, v := range extender {
+		baseCfg.SetAppend(k, v)
+	}
+	return setBody(httpContext, baseCfg)
+}
+
+func (c *Chat) ResponseConvert(ctx eocontext.EoContext) error {
+	data := new(Response)
+	httpContext, ok, err := readResponse(ctx, data)
+	if !ok {
+		return err
+	}
+	response := &ai_provider.ClientResponse{
+		Message: responseMessage(&data.Message, false),
+		Usage:   data.usage(),
+	}
+	response.FinishReason = finishReason(&response.Message, data.DoneReason)
+	return setResponse(httpContext, response)
+}
+
+func (c *Chat) StreamConvert(ctx eocontext.EoContext) error {
+	return ai_provider.StreamConvert(ctx, ai_provider.NewJSONLinesDecoder, chatStreamConvert, c.ResponseConvert)
+}
+
+// chatStreamConvert 每行为本次增量，最后一行返回结束原因及用量
+func chatStreamConvert(event *ai_provider.StreamEvent) (*ai_provider.ClientResponse, error) {
+	data := new(Response)
+	err := json.Unmarshal(event.Data, data)
+	if err != nil {
+		return nil, err
+	}
+	if data.Error != "" {
+		return &ai_provider.ClientResponse{Code: -1, Error: data.Error}, nil
+	}
+	response := &ai_provider.ClientResponse{Message: responseMessage(&data.Message, true)}
+	if data.Done {
+		response.FinishReason = finishReason(&response.Message, data.DoneReason)
+		response.Usage = data.usage()
+	}
+	return response, nil
+}
+
+// Generate 补全模型，使用原生的/api/generate接口，对话消息按顺序拼接为提示词
+type Generate struct {
+	endPoint string
+}
+
+func NewGenerate() *Generate {
+	return &Generate{endPoint: "/api/generate"}
+}
+
+func (c *Generate) Endpoint() string {
+	return c.endPoint
+}
+
+func (c *Generate) RequestConvert(ctx eocontext.EoContext, extender map[string]interface{}) error {
+	baseCfg := eosc.NewBase[ai_provider.ClientRequest]()
+	httpContext, err := readRequest(ctx, c.endPoint, baseCfg)
+	if err != nil {
+		return err
+	}
+	baseCfg.SetAppend("prompt", ai_provider.CompletionPrompt(baseCfg.Config.Messages))
+	baseCfg.SetAppend("stream", baseCfg.Config.Stream)
+	for k, v := range extender {
+		baseCfg.SetAppend(k, v)
+	}
+	return setBody(httpContext, baseCfg)
+}
+
+func (c *Generate) ResponseConvert(ctx eocontext.EoContext) error {
+	data := new(Response)
+	httpContext, ok, err := readResponse(ctx, data)
+	if !ok {
+		return err
+	}
+	return setResponse(httpContext, &ai_provider.ClientResponse{
+		Message:      ai_provider.Message{Role: "assistant", Content: data.Response},
+		FinishReason: data.DoneReason,
+		Usage:        data.usage(),
+	})
+}
+
+func (c *Generate) StreamConvert(ctx eocontext.EoContext) error {
+	return ai_provider.StreamConvert(ctx, ai_provider.NewJSONLinesDecoder, generateStreamConvert, c.ResponseConvert)
+}
+
+func generateStreamConvert(event *ai_provider.StreamEvent) (*ai_provider.ClientResponse, error) {
+	data := new(Response)
+	err := json.Unmarshal(event.Data, data)
+	if err != nil {
+		return nil, err
+	}
+	if data.Error != "" {
+		return &ai_provider.ClientResponse{Code: -1, Error: data.Error}, nil
+	}
+	response := &ai_provider.ClientResponse{Message: ai_provider.Message{Role: "assistant", Content: data.Response}}
+	if data.Done {
+		response.FinishReason = data.DoneReason
+		response.Usage = data.usage()
+	}
+	return response, nil
+}
+
+// Embedding 向量模型，使用原生的/api/embed接口
+type Embedding struct {
+	endPoint string
+}
+
+func NewEmbedding() *Embedding {
+	return &Embedding{endPoint: "/api/embed"}
+}
+
+func (c *Embedding) Endpoint() string {
+	return c.endPoint
+}
+
+func (c *Embedding) RequestConvert(ctx eocontext.EoContext, extender map[string]interface{}) error {
+	request := new(ai_provider.EmbeddingRequest)
+	httpContext, err := readRequest(ctx, c.endPoint, request)
+	if err != nil {
+		return err
+	}
+	return setBody(httpContext, map[string]interface{}{
+		"model": extender["model"],
+		"input": []string(request.Input),
+	})
+}
+
+func (c *Embedding) ResponseConvert(ctx eocontext.EoContext) error {
+	data := new(EmbeddingResponse)
+	httpContext, ok, err := readResponse(ctx, data)
+	if !ok {
+		return err
+	}
+	return setResponse(httpContext, embeddingResponse(data))
+}
+
+// StreamConvert 向量接口不支持流式返回，按完整响应转换
+func (c *Embedding) StreamConvert(ctx eocontext.EoContext) error {
+	return c.ResponseConvert(ctx)
+}
+
+func embeddingResponse(data *EmbeddingResponse) *ai_provider.EmbeddingResponse {
+	response := &ai_provider.EmbeddingResponse{
+		Data:  make([]*ai_provider.Embedding, 0, len(data.Embeddings)),
+		Usage: ai_provider.NewUsage(data.PromptEvalCount, 0, 0),
+	}
+	for i, v := range data.Embeddings {
+		response.Data = append(response.Data, &ai_provider.Embedding{Index: i, Embedding: v})
+	}
+	return response
+}
+
+// convertMessages 图片转换为images，工具调用结果按调用ID查找对应的工具名
+func convertMessages(messages []*ai_provider.Message) []*Message {
+	names := make(map[string]string)
+	result := make([]*Message, 0, len(messages))
+	for _, m := range messages {
+		if m == nil {
+			continue
+		}
+		message := &Message{Role: m.Role, Content: m.Content}
+		for _, p := range m.Parts {
+			if p.Type != ai_provider.PartImageURL {
+				continue
+			}
+			// Ollama不支持图片地址，仅转换base64格式的图片
+			if _, data, ok := p.ImageURL.Base64(); ok {
+				message.Images = append(message.Images, data)
+			}
+		}
+		for _, call := range m.ToolCalls {
+			names[call.ID] = call.Function.Name
+			toolCall := &ToolCall{}
+			toolCall.Function.Name = call.Function.Name
+			toolCall.Function.Arguments = call.ArgumentsJSON()
+			message.ToolCalls = append(message.ToolCalls, toolCall)
+		}
+		if m.Role == "tool" {
+			message.ToolName = names[m.ToolCallID]
+			if message.ToolName == "" {
+				message.ToolName = m.Name
+			}
+		}
+		result = append(result, message)
+	}
+	return result
+}
+
+// responseMessage Ollama的工具调用没有调用ID，按序号生成
+func responseMessage(msg *Message, stream bool) ai_provider.Message {
+	message := ai_provider.Message{Role: msg.Role, Content: msg.Content}
+	if message.Role == "" {
+		message.Role = "assistant"
+	}
+	for i, call := range msg.ToolCalls {
+		index := -1
+		if stream {
+			index = i
+		}
+		message.ToolCalls = append(message.ToolCalls, ai_provider.NewToolCall(index, fmt.Sprintf("call_%d", i), call.Function.Name, string(call.Function.Arguments)))
+	}
+	return message
+}
+
+// finishReason 返回工具调用时结束原因为tool_calls
+func finishReason(message *ai_provider.Message, reason string) string {
+	if len(message.ToolCalls) > 0 {
+		return ai_provider.FinishToolCalls
+	}
+	return reason
+}
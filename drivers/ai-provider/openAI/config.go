@@ -4,13 +4,15 @@ import (
 	"fmt"
 	"net/url"
 
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
 	"github.com/eolinker/eosc"
 )
 
 type Config struct {
-	APIKey       string `json:"openai_api_key"`
-	Organization string `json:"openai_organization"`
-	Base         string `json:"openai_api_base"`
+	APIKey       string               `json:"openai_api_key"`
+	APIKeys      []ai_provider.APIKey `json:"api_keys" label:"API Key池" description:"多个API Key按权重轮询，被限流的Key在冷却期内暂停使用"`
+	Organization string               `json:"openai_organization"`
+	Base         string               `json:"openai_api_base"`
 }
 
 func checkConfig(v interface{}) (*Config, error) {
@@ -18,7 +20,7 @@ func checkConfig(v interface{}) (*Config, error) {
 	if !ok {
 		return nil, eosc.ErrorConfigType
 	}
-	if conf.APIKey == "" {
+	if conf.APIKey == "" && len(conf.APIKeys) == 0 {
 		return nil, fmt.Errorf("api_key is required")
 	}
 	if conf.Base != "" {
@@ -1,6 +1,7 @@
 package openAI
 
 import (
+	"context"
 	"embed"
 	"encoding/json"
 	"fmt"
@@ -25,7 +26,8 @@ var (
 	providerDir  embed.FS
 	modelConvert = make(map[string]convert.IConverter)
 
-	_ convert.IConverterDriver = (*executor)(nil)
+	_ convert.IConverterDriver     = (*executor)(nil)
+	_ ai_provider.IEmbeddingDriver = (*executor)(nil)
 )
 
 func init() {
@@ -34,16 +36,14 @@ func init() {
 		panic(err)
 	}
 	for key, value := range models {
-		if value.ModelProperties != nil {
-			if v, ok := modelModes[value.ModelProperties.Mode]; ok {
-				modelConvert[key] = v
-			}
+		if v, ok := modelModes[value.ConvertMode()]; ok {
+			modelConvert[key] = v
 		}
 	}
 }
 
 type Converter struct {
-	apikey         string
+	keys           *ai_provider.KeyPool
 	balanceHandler eocontext.BalanceHandler
 	converter      convert.IConverter
 }
@@ -56,7 +56,7 @@ func (c *Converter) RequestConvert(ctx eocontext.EoContext, extender map[string]
 	if err != nil {
 		return err
 	}
-	httpContext.Proxy().Header().SetHeader("Authorization", "Bearer "+c.apikey)
+	httpContext.Proxy().Header().SetHeader("Authorization", "Bearer "+c.keys.Next(ctx))
 
 	return c.converter.RequestConvert(httpContext, extender)
 }
@@ -65,9 +65,14 @@ func (c *Converter) ResponseConvert(ctx eocontext.EoContext) error {
 	return c.converter.ResponseConvert(ctx)
 }
 
+func (c *Converter) StreamConvert(ctx eocontext.EoContext) error {
+	return c.converter.StreamConvert(ctx)
+}
+
 type executor struct {
 	drivers.WorkerBase
-	apikey string
+	keys *ai_provider.KeyPool
+	base string
 	eocontext.BalanceHandler
 }
 
@@ -77,7 +82,7 @@ func (e *executor) GetConverter(model string) (convert.IConverter, bool) {
 		return nil, false
 	}
 
-	return &Converter{balanceHandler: e.BalanceHandler, converter: converter, apikey: e.apikey}, true
+	return &Converter{balanceHandler: e.BalanceHandler, converter: converter, keys: e.keys}, true
 }
 
 func (e *executor) GetModel(model string) (convert.FGenerateConfig, bool) {
@@ -138,7 +143,8 @@ func (e *executor) reset(conf *Config, workers map[eosc.RequireId]eosc.IWorker)
 	} else {
 		e.BalanceHandler = nil
 	}
-	e.apikey = conf.APIKey
+	e.keys = ai_provider.NewKeyPool(conf.APIKey, conf.APIKeys)
+	e.base = conf.Base
 	convert.Set(e.Id(), e)
 
 	return nil
@@ -154,6 +160,19 @@ func (e *executor) CheckSkill(skill string) bool {
 	return convert.CheckSkill(skill)
 }
 
+// Embeddings 调用OpenAI的向量模型，未配置openai_api_base时使用官方地址
+func (e *executor) Embeddings(ctx context.Context, model string, inputs []string) ([][]float64, error) {
+	base := e.base
+	if base == "" {
+		base = defaultBase
+	}
+	return ai_provider.OpenAIEmbeddings(ctx, base, e.keys.Pick(), model, inputs)
+}
+
+func (e *executor) Provider() string {
+	return name
+}
+
 type ModelConfig struct {
 	FrequencyPenalty float64 `json:"frequency_penalty"`
 	MaxTokens        int     `json:"max_tokens"`
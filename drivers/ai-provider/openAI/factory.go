@@ -7,6 +7,9 @@ import (
 
 var name = "openai"
 
+// defaultBase 未配置openai_api_base时直接调用的官方地址
+const defaultBase = "https://api.openai.com"
+
 // Register 注册驱动
 func Register(register eosc.IExtenderDriverRegister) {
 	register.RegisterExtenderDriver(name, NewFactory())
@@ -13,7 +13,12 @@ import (
 
 var (
 	modelModes = map[string]IModelMode{
-		ai_provider.ModeChat.String(): NewChat(),
+		ai_provider.ModeChat.String():          NewChat(),
+		ai_provider.ModeComplete.String():      ai_provider.NewOpenAICompletion("/v1/completions"),
+		ai_provider.ModeEmbedding.String():     ai_provider.NewOpenAIEmbedding("/v1/embeddings"),
+		ai_provider.ModeModeration.String():    ai_provider.NewOpenAIModeration("/v1/moderations"),
+		ai_provider.ModeSpeech.String():        ai_provider.NewOpenAISpeech("/v1/audio/speech"),
+		ai_provider.ModeTranscription.String(): ai_provider.NewOpenAITranscription("/v1/audio/transcriptions"),
 	}
 )
 
@@ -52,14 +57,12 @@ func (c *Chat) RequestConvert(ctx eocontext.EoContext, extender map[string]inter
 	if err != nil {
 		return err
 	}
-	messages := make([]Message, 0, len(baseCfg.Config.Messages)+1)
-	for _, m := range baseCfg.Config.Messages {
-		messages = append(messages, Message{
-			Role:    m.Role,
-			Content: m.Content,
-		})
+	ai_provider.SetOpenAIMessages(baseCfg)
+	if baseCfg.Config.Stream {
+		baseCfg.SetAppend("stream", true)
+		// 流式返回时默认不返回用量，需显式开启
+		baseCfg.SetAppend("stream_options", map[string]interface{}{"include_usage": true})
 	}
-	baseCfg.SetAppend("messages", messages)
 	for k, v := range extender {
 		baseCfg.SetAppend(k, v)
 	}
@@ -89,11 +92,9 @@ func (c *Chat) ResponseConvert(ctx eocontext.EoContext) error {
 	responseBody := &ai_provider.ClientResponse{}
 	if len(data.Config.Choices) > 0 {
 		msg := data.Config.Choices[0]
-		responseBody.Message = ai_provider.Message{
-			Role:    msg.Message.Role,
-			Content: msg.Message.Content,
-		}
+		responseBody.Message = msg.Message
 		responseBody.FinishReason = msg.FinishReason
+		responseBody.Usage = ai_provider.NewUsage(data.Config.Usage.PromptTokens, data.Config.Usage.CompletionTokens, data.Config.Usage.TotalTokens)
 	} else {
 		responseBody.Code = -1
 		responseBody.Error = "no response"
@@ -105,3 +106,7 @@ func (c *Chat) ResponseConvert(ctx eocontext.EoContext) error {
 	httpContext.Response().SetBody(body)
 	return nil
 }
+
+func (c *Chat) StreamConvert(ctx eocontext.EoContext) error {
+	return ai_provider.StreamConvert(ctx, ai_provider.NewSSEDecoder, ai_provider.OpenAIStreamConvert, c.ResponseConvert)
+}
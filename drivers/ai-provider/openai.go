@@ -0,0 +1,422 @@
+package ai_provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/eocontext"
+	http_context "github.com/eolinker/eosc/eocontext/http-context"
+)
+
+// OpenAI兼容格式的补全、向量、审核、语音合成及语音转文字接口，供兼容OpenAI接口的供应商复用
+
+// setJSONBody 设置转发地址及JSON请求体
+func setJSONBody(ctx http_context.IHttpContext, path string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	ctx.Proxy().URI().SetPath(path)
+	ctx.Proxy().Body().SetRaw("application/json", data)
+	return nil
+}
+
+// setResponse 将统一格式的响应写回
+func setResponse(ctx http_context.IHttpContext, response interface{}) error {
+	body, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	ctx.Response().SetBody(body)
+	ctx.Response().SetHeader("Content-Type", "application/json")
+	return nil
+}
+
+// readResponse 读取上游的成功响应，非200时保留上游响应并返回false
+func readResponse(ctx eocontext.EoContext, v interface{}) (http_context.IHttpContext, bool, error) {
+	httpContext, err := http_context.Assert(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if httpContext.Response().StatusCode() != 200 {
+		return httpContext, false, nil
+	}
+	err = json.Unmarshal(httpContext.Response().GetBody(), v)
+	if err != nil {
+		return nil, false, err
+	}
+	return httpContext, true, nil
+}
+
+// readRequest 读取客户端的统一格式请求
+func readRequest(ctx eocontext.EoContext, v interface{}) (http_context.IHttpContext, error) {
+	httpContext, err := http_context.Assert(ctx)
+	if err != nil {
+		return nil, err
+	}
+	body, err := httpContext.Proxy().Body().RawBody()
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(body, v)
+	if err != nil {
+		return nil, err
+	}
+	return httpContext, nil
+}
+
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+func (u *openAIUsage) usage() *Usage {
+	if u == nil {
+		return nil
+	}
+	return NewUsage(u.PromptTokens, u.CompletionTokens, u.TotalTokens)
+}
+
+// OpenAIChat 对话模型，消息及工具调用与统一格式一致
+type OpenAIChat struct {
+	endPoint string
+}
+
+func NewOpenAIChat(endPoint string) *OpenAIChat {
+	return &OpenAIChat{endPoint: endPoint}
+}
+
+func (c *OpenAIChat) Endpoint() string {
+	return c.endPoint
+}
+
+func (c *OpenAIChat) RequestConvert(ctx eocontext.EoContext, extender map[string]interface{}) error {
+	baseCfg := eosc.NewBase[ClientRequest]()
+	httpContext, err := readRequest(ctx, baseCfg)
+	if err != nil {
+		return err
+	}
+	SetOpenAIMessages(baseCfg)
+	if baseCfg.Config.Stream {
+		baseCfg.SetAppend("stream", true)
+		baseCfg.SetAppend("stream_options", map[string]interface{}{"include_usage": true})
+	}
+	for k, v := range extender {
+		baseCfg.SetAppend(k, v)
+	}
+	return setJSONBody(httpContext, c.endPoint, baseCfg)
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message      Message `json:"message"`
+		FinishReason string  `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *openAIUsage `json:"usage"`
+}
+
+func (c *OpenAIChat) ResponseConvert(ctx eocontext.EoContext) error {
+	data := new(openAIChatResponse)
+	httpContext, ok, err := readResponse(ctx, data)
+	if !ok {
+		return err
+	}
+	response := &ClientResponse{Usage: data.Usage.usage()}
+	if len(data.Choices) > 0 {
+		response.Message = data.Choices[0].Message
+		response.FinishReason = data.Choices[0].FinishReason
+	} else {
+		response.Code = -1
+		response.Error = "no response"
+	}
+	return setResponse(httpContext, response)
+}
+
+func (c *OpenAIChat) StreamConvert(ctx eocontext.EoContext) error {
+	return StreamConvert(ctx, NewSSEDecoder, OpenAIStreamConvert, c.ResponseConvert)
+}
+
+// OpenAICompletion 补全模式的模型，对话消息按顺序拼接为提示词
+type OpenAICompletion struct {
+	endPoint string
+}
+
+func NewOpenAICompletion(endPoint string) *OpenAICompletion {
+	return &OpenAICompletion{endPoint: endPoint}
+}
+
+func (c *OpenAICompletion) Endpoint() string {
+	return c.endPoint
+}
+
+// CompletionPrompt 将对话消息拼接为补全模式的提示词
+func CompletionPrompt(messages []*Message) string {
+	prompts := make([]string, 0, len(messages))
+	for _, m := range messages {
+		if m != nil && m.Content != "" {
+			prompts = append(prompts, m.Content)
+		}
+	}
+	return strings.Join(prompts, "\n\n")
+}
+
+func (c *OpenAICompletion) RequestConvert(ctx eocontext.EoContext, extender map[string]interface{}) error {
+	baseCfg := eosc.NewBase[ClientRequest]()
+	httpContext, err := readRequest(ctx, baseCfg)
+	if err != nil {
+		return err
+	}
+	baseCfg.SetAppend("prompt", CompletionPrompt(baseCfg.Config.Messages))
+	if baseCfg.Config.Stream {
+		baseCfg.SetAppend("stream", true)
+		baseCfg.SetAppend("stream_options", map[string]interface{}{"include_usage": true})
+	}
+	for k, v := range extender {
+		// 补全接口不支持response_format
+		if k == "response_format" {
+			continue
+		}
+		baseCfg.SetAppend(k, v)
+	}
+	return setJSONBody(httpContext, c.endPoint, baseCfg)
+}
+
+type openAICompletionResponse struct {
+	Choices []struct {
+		Text         string `json:"text"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *openAIUsage `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (c *OpenAICompletion) ResponseConvert(ctx eocontext.EoContext) error {
+	data := new(openAICompletionResponse)
+	httpContext, ok, err := readResponse(ctx, data)
+	if !ok {
+		return err
+	}
+	response := &ClientResponse{Usage: data.Usage.usage()}
+	if len(data.Choices) > 0 {
+		response.Message = Message{Role: "assistant", Content: data.Choices[0].Text}
+		response.FinishReason = data.Choices[0].FinishReason
+	} else {
+		response.Code = -1
+		response.Error = "no response"
+	}
+	return setResponse(httpContext, response)
+}
+
+func (c *OpenAICompletion) StreamConvert(ctx eocontext.EoContext) error {
+	return StreamConvert(ctx, NewSSEDecoder, OpenAICompletionStreamConvert, c.ResponseConvert)
+}
+
+// OpenAICompletionStreamConvert 转换补全接口的流式响应事件
+func OpenAICompletionStreamConvert(event *StreamEvent) (*ClientResponse, error) {
+	if bytes.Equal(event.Data, []byte("[DONE]")) {
+		return nil, nil
+	}
+	chunk := new(openAICompletionResponse)
+	err := json.Unmarshal(event.Data, chunk)
+	if err != nil {
+		return nil, err
+	}
+	if chunk.Error != nil {
+		return &ClientResponse{Code: -1, Error: chunk.Error.Message}, nil
+	}
+	usage := chunk.Usage.usage()
+	if len(chunk.Choices) < 1 {
+		if usage == nil {
+			return nil, nil
+		}
+		return &ClientResponse{Usage: usage}, nil
+	}
+	return &ClientResponse{
+		Message:      Message{Role: "assistant", Content: chunk.Choices[0].Text},
+		FinishReason: chunk.Choices[0].FinishReason,
+		Usage:        usage,
+	}, nil
+}
+
+// OpenAIEmbedding 向量模型
+type OpenAIEmbedding struct {
+	endPoint string
+}
+
+func NewOpenAIEmbedding(endPoint string) *OpenAIEmbedding {
+	return &OpenAIEmbedding{endPoint: endPoint}
+}
+
+func (c *OpenAIEmbedding) Endpoint() string {
+	return c.endPoint
+}
+
+func (c *OpenAIEmbedding) RequestConvert(ctx eocontext.EoContext, extender map[string]interface{}) error {
+	request := new(EmbeddingRequest)
+	httpContext, err := readRequest(ctx, request)
+	if err != nil {
+		return err
+	}
+	body := map[string]interface{}{
+		"model":           extender["model"],
+		"input":           []string(request.Input),
+		"encoding_format": "float",
+	}
+	if request.Dimensions > 0 {
+		body["dimensions"] = request.Dimensions
+	}
+	return setJSONBody(httpContext, c.endPoint, body)
+}
+
+func (c *OpenAIEmbedding) ResponseConvert(ctx eocontext.EoContext) error {
+	data := new(struct {
+		Data  []*Embedding `json:"data"`
+		Usage *openAIUsage `json:"usage"`
+	})
+	httpContext, ok, err := readResponse(ctx, data)
+	if !ok {
+		return err
+	}
+	return setResponse(httpContext, &EmbeddingResponse{Data: data.Data, Usage: data.Usage.usage()})
+}
+
+// StreamConvert 向量接口不支持流式返回，按完整响应转换
+func (c *OpenAIEmbedding) StreamConvert(ctx eocontext.EoContext) error {
+	return c.ResponseConvert(ctx)
+}
+
+// OpenAIModeration 内容审核模型
+type OpenAIModeration struct {
+	endPoint string
+}
+
+func NewOpenAIModeration(endPoint string) *OpenAIModeration {
+	return &OpenAIModeration{endPoint: endPoint}
+}
+
+func (c *OpenAIModeration) Endpoint() string {
+	return c.endPoint
+}
+
+func (c *OpenAIModeration) RequestConvert(ctx eocontext.EoContext, extender map[string]interface{}) error {
+	request := new(ModerationRequest)
+	httpContext, err := readRequest(ctx, request)
+	if err != nil {
+		return err
+	}
+	return setJSONBody(httpContext, c.endPoint, map[string]interface{}{
+		"model": extender["model"],
+		"input": []string(request.Input),
+	})
+}
+
+func (c *OpenAIModeration) ResponseConvert(ctx eocontext.EoContext) error {
+	data := new(struct {
+		Results []*ModerationResult `json:"results"`
+	})
+	httpContext, ok, err := readResponse(ctx, data)
+	if !ok {
+		return err
+	}
+	return setResponse(httpContext, &ModerationResponse{Results: data.Results})
+}
+
+// StreamConvert 审核接口不支持流式返回，按完整响应转换
+func (c *OpenAIModeration) StreamConvert(ctx eocontext.EoContext) error {
+	return c.ResponseConvert(ctx)
+}
+
+// OpenAISpeech 语音合成模型，成功时直接返回上游的音频内容
+type OpenAISpeech struct {
+	endPoint string
+}
+
+func NewOpenAISpeech(endPoint string) *OpenAISpeech {
+	return &OpenAISpeech{endPoint: endPoint}
+}
+
+func (c *OpenAISpeech) Endpoint() string {
+	return c.endPoint
+}
+
+func (c *OpenAISpeech) RequestConvert(ctx eocontext.EoContext, extender map[string]interface{}) error {
+	request := new(SpeechRequest)
+	httpContext, err := readRequest(ctx, request)
+	if err != nil {
+		return err
+	}
+	if request.Voice == "" {
+		request.Voice = "alloy"
+	}
+	body := map[string]interface{}{
+		"model": extender["model"],
+		"input": request.Input,
+		"voice": request.Voice,
+	}
+	if request.ResponseFormat != "" {
+		body["response_format"] = request.ResponseFormat
+	}
+	if request.Speed > 0 {
+		body["speed"] = request.Speed
+	}
+	return setJSONBody(httpContext, c.endPoint, body)
+}
+
+func (c *OpenAISpeech) ResponseConvert(ctx eocontext.EoContext) error {
+	return nil
+}
+
+func (c *OpenAISpeech) StreamConvert(ctx eocontext.EoContext) error {
+	return nil
+}
+
+// OpenAITranscription 语音转文字模型，客户端以multipart/form-data上传file字段，由网关写入模型
+type OpenAITranscription struct {
+	endPoint string
+}
+
+func NewOpenAITranscription(endPoint string) *OpenAITranscription {
+	return &OpenAITranscription{endPoint: endPoint}
+}
+
+func (c *OpenAITranscription) Endpoint() string {
+	return c.endPoint
+}
+
+func (c *OpenAITranscription) RequestConvert(ctx eocontext.EoContext, extender map[string]interface{}) error {
+	httpContext, err := http_context.Assert(ctx)
+	if err != nil {
+		return err
+	}
+	if model, ok := extender["model"].(string); ok {
+		err = httpContext.Proxy().Body().SetToForm("model", model)
+		if err != nil {
+			return err
+		}
+	}
+	err = httpContext.Proxy().Body().SetToForm("response_format", "json")
+	if err != nil {
+		return err
+	}
+	httpContext.Proxy().URI().SetPath(c.endPoint)
+	return nil
+}
+
+func (c *OpenAITranscription) ResponseConvert(ctx eocontext.EoContext) error {
+	data := new(TranscriptionResponse)
+	httpContext, ok, err := readResponse(ctx, data)
+	if !ok {
+		return err
+	}
+	return setResponse(httpContext, &TranscriptionResponse{Text: data.Text})
+}
+
+// StreamConvert 语音转文字接口不支持流式返回，按完整响应转换
+func (c *OpenAITranscription) StreamConvert(ctx eocontext.EoContext) error {
+	return c.ResponseConvert(ctx)
+}
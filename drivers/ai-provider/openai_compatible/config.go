@@ -0,0 +1,32 @@
+package openai_compatible
+
+import (
+	"fmt"
+	"net/url"
+
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+	"github.com/eolinker/eosc"
+)
+
+type Config struct {
+	Base       string                     `json:"base" label:"API地址" required:"true" description:"OpenAI兼容接口的地址，包含版本路径，如http://127.0.0.1:8000/v1"`
+	AuthHeader string                     `json:"auth_header" label:"鉴权头部" default:"Authorization" description:"为Authorization时以Bearer方式传递API Key"`
+	APIKey     string                     `json:"api_key" label:"API Key" description:"服务未开启鉴权时可为空"`
+	APIKeys    []ai_provider.APIKey       `json:"api_keys" label:"API Key池" description:"多个API Key按权重轮询，被限流的Key在冷却期内暂停使用"`
+	Models     []*ai_provider.CustomModel `json:"models" label:"模型列表" required:"true"`
+}
+
+func checkConfig(v interface{}) (*Config, error) {
+	conf, ok := v.(*Config)
+	if !ok {
+		return nil, eosc.ErrorConfigType
+	}
+	u, err := url.Parse(conf.Base)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("base url is invalid")
+	}
+	if len(conf.Models) == 0 {
+		return nil, fmt.Errorf("models is required")
+	}
+	return conf, nil
+}
@@ -0,0 +1,181 @@
+package openai_compatible
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/eolinker/apinto/convert"
+	"github.com/eolinker/apinto/drivers"
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/eocontext"
+	http_context "github.com/eolinker/eosc/eocontext/http-context"
+	"github.com/eolinker/eosc/log"
+)
+
+var (
+	_ convert.IConverterDriver     = (*executor)(nil)
+	_ ai_provider.IEmbeddingDriver = (*executor)(nil)
+	_ ai_provider.IModelSource     = (*executor)(nil)
+)
+
+// newModes 按接口地址中的路径生成各模式的转换器
+func newModes(prefix string) map[string]convert.IConverter {
+	return map[string]convert.IConverter{
+		ai_provider.ModeChat.String():          ai_provider.NewOpenAIChat(prefix + "/chat/completions"),
+		ai_provider.ModeComplete.String():      ai_provider.NewOpenAICompletion(prefix + "/completions"),
+		ai_provider.ModeEmbedding.String():     ai_provider.NewOpenAIEmbedding(prefix + "/embeddings"),
+		ai_provider.ModeModeration.String():    ai_provider.NewOpenAIModeration(prefix + "/moderations"),
+		ai_provider.ModeSpeech.String():        ai_provider.NewOpenAISpeech(prefix + "/audio/speech"),
+		ai_provider.ModeTranscription.String(): ai_provider.NewOpenAITranscription(prefix + "/audio/transcriptions"),
+	}
+}
+
+type Converter struct {
+	keys           *ai_provider.KeyPool
+	authHeader     string
+	balanceHandler eocontext.BalanceHandler
+	converter      convert.IConverter
+}
+
+func (c *Converter) RequestConvert(ctx eocontext.EoContext, extender map[string]interface{}) error {
+	ctx.SetBalance(c.balanceHandler)
+	httpContext, err := http_context.Assert(ctx)
+	if err != nil {
+		return err
+	}
+	if c.keys.Len() > 0 {
+		httpContext.Proxy().Header().SetHeader(c.authHeader, authValue(c.authHeader, c.keys.Next(ctx)))
+	}
+	return c.converter.RequestConvert(httpContext, extender)
+}
+
+func (c *Converter) ResponseConvert(ctx eocontext.EoContext) error {
+	return c.converter.ResponseConvert(ctx)
+}
+
+func (c *Converter) StreamConvert(ctx eocontext.EoContext) error {
+	return c.converter.StreamConvert(ctx)
+}
+
+// authValue 鉴权头部为Authorization时以Bearer方式传递
+func authValue(header, key string) string {
+	if strings.EqualFold(header, "Authorization") {
+		return "Bearer " + key
+	}
+	return key
+}
+
+type executor struct {
+	drivers.WorkerBase
+	base       string
+	authHeader string
+	keys       *ai_provider.KeyPool
+	models     map[string]*ai_provider.Model
+	modes      map[string]convert.IConverter
+	eocontext.BalanceHandler
+}
+
+func (e *executor) GetConverter(model string) (convert.IConverter, bool) {
+	m, ok := e.models[model]
+	if !ok {
+		return nil, false
+	}
+	converter, ok := e.modes[m.ConvertMode()]
+	if !ok {
+		return nil, false
+	}
+	return &Converter{balanceHandler: e.BalanceHandler, converter: converter, keys: e.keys, authHeader: e.authHeader}, true
+}
+
+// GetModel 模型参数按配置原样转发
+func (e *executor) GetModel(model string) (convert.FGenerateConfig, bool) {
+	if _, ok := e.models[model]; !ok {
+		return nil, false
+	}
+	return func(cfg string) (map[string]interface{}, error) {
+		result := make(map[string]interface{})
+		if cfg != "" {
+			if err := json.Unmarshal([]byte(cfg), &result); err != nil {
+				log.Errorf("unmarshal config error: %v, cfg: %s", err, cfg)
+				result = make(map[string]interface{})
+			}
+		}
+		result["model"] = model
+		return result, nil
+	}, true
+}
+
+// Model 返回配置的模型信息
+func (e *executor) Model(model string) (*ai_provider.Model, bool) {
+	m, ok := e.models[model]
+	return m, ok
+}
+
+func (e *executor) Start() error {
+	return nil
+}
+
+func (e *executor) Reset(conf interface{}, workers map[eosc.RequireId]eosc.IWorker) error {
+	cfg, err := checkConfig(conf)
+	if err != nil {
+		return err
+	}
+	return e.reset(cfg, workers)
+}
+
+func (e *executor) reset(conf *Config, workers map[eosc.RequireId]eosc.IWorker) error {
+	u, err := url.Parse(conf.Base)
+	if err != nil {
+		return err
+	}
+	models, err := ai_provider.NewCustomModels(name, conf.Models)
+	if err != nil {
+		return err
+	}
+	balanceHandler, err := ai_provider.NewBalanceHandler(e.Id(), conf.Base, 0)
+	if err != nil {
+		return err
+	}
+	authHeader := conf.AuthHeader
+	if authHeader == "" {
+		authHeader = "Authorization"
+	}
+	e.BalanceHandler = balanceHandler
+	e.base = strings.TrimSuffix(conf.Base, "/")
+	e.authHeader = authHeader
+	e.keys = ai_provider.NewKeyPool(conf.APIKey, conf.APIKeys)
+	e.models = models
+	e.modes = newModes(strings.TrimSuffix(u.Path, "/"))
+	convert.Set(e.Id(), e)
+	return nil
+}
+
+func (e *executor) Stop() error {
+	e.BalanceHandler = nil
+	convert.Del(e.Id())
+	return nil
+}
+
+func (e *executor) CheckSkill(skill string) bool {
+	return convert.CheckSkill(skill)
+}
+
+// Embeddings 调用配置的向量模型
+func (e *executor) Embeddings(ctx context.Context, model string, inputs []string) ([][]float64, error) {
+	if m, ok := e.models[model]; !ok || m.ModelType != ai_provider.ModelTypeTextEmbedding {
+		return nil, fmt.Errorf("embedding model %s not found", model)
+	}
+	headers := make(map[string]string)
+	if e.keys.Len() > 0 {
+		headers[e.authHeader] = authValue(e.authHeader, e.keys.Pick())
+	}
+	return ai_provider.RequestOpenAIEmbeddings(ctx, e.base+"/embeddings", headers, model, inputs)
+}
+
+func (e *executor) Provider() string {
+	return name
+}
@@ -0,0 +1,58 @@
+package openai_compatible
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+)
+
+func TestExecutor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" || r.Header.Get("X-Api-Key") != "secret" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"message":"not found"}}`))
+			return
+		}
+		w.Write([]byte(`{"data":[{"index":0,"embedding":[0.1,0.2]}]}`))
+	}))
+	defer server.Close()
+
+	w, err := Create("openai-compatible@ai-provider", "local", &Config{
+		Base:       server.URL + "/v1",
+		AuthHeader: "X-Api-Key",
+		APIKey:     "secret",
+		Models: []*ai_provider.CustomModel{
+			{Model: "qwen2.5", ContextSize: 32768, Pricing: &ai_provider.Pricing{Input: "1", Output: "2"}},
+			{Model: "bge-m3", Type: ai_provider.ModelTypeTextEmbedding},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := w.(*executor)
+	if _, ok := e.GetConverter("qwen2.5"); !ok {
+		t.Error("expect chat converter")
+	}
+	if _, ok := e.GetConverter("unknown"); ok {
+		t.Error("expect unknown model not found")
+	}
+	if m, ok := e.Model("qwen2.5"); !ok || m.Pricing == nil || m.ModelProperties.Mode != ai_provider.ModeChat.String() {
+		t.Errorf("unexpected model: %+v", m)
+	}
+	if e.modes[ai_provider.ModeChat.String()].(*ai_provider.OpenAIChat).Endpoint() != "/v1/chat/completions" {
+		t.Error("unexpected chat endpoint")
+	}
+	vectors, err := e.Embeddings(context.Background(), "bge-m3", []string{"hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) != 1 || len(vectors[0]) != 2 {
+		t.Errorf("unexpected vectors: %v", vectors)
+	}
+	if _, err = e.Embeddings(context.Background(), "qwen2.5", []string{"hello"}); err == nil {
+		t.Error("expect error for non-embedding model")
+	}
+}
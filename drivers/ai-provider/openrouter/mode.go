@@ -50,14 +50,10 @@ func (c *Chat) RequestConvert(ctx eocontext.EoContext, extender map[string]inter
 	if err != nil {
 		return err
 	}
-	messages := make([]Message, 0, len(baseCfg.Config.Messages)+1)
-	for _, m := range baseCfg.Config.Messages {
-		messages = append(messages, Message{
-			Role:    m.Role,
-			Content: m.Content,
-		})
+	ai_provider.SetOpenAIMessages(baseCfg)
+	if baseCfg.Config.Stream {
+		baseCfg.SetAppend("stream", true)
 	}
-	baseCfg.SetAppend("messages", messages)
 	for k, v := range extender {
 		baseCfg.SetAppend(k, v)
 	}
@@ -86,11 +82,9 @@ func (c *Chat) ResponseConvert(ctx eocontext.EoContext) error {
 	responseBody := &ai_provider.ClientResponse{}
 	if len(data.Config.Choices) > 0 {
 		msg := data.Config.Choices[0]
-		responseBody.Message = ai_provider.Message{
-			Role:    msg.Message.Role,
-			Content: msg.Message.Content,
-		}
+		responseBody.Message = msg.Message
 		responseBody.FinishReason = msg.FinishReason
+		responseBody.Usage = ai_provider.NewUsage(data.Config.Usage.PromptTokens, data.Config.Usage.CompletionTokens, data.Config.Usage.TotalTokens)
 	} else {
 		responseBody.Code = -1
 		responseBody.Error = "no response"
@@ -102,3 +96,7 @@ func (c *Chat) ResponseConvert(ctx eocontext.EoContext) error {
 	httpContext.Response().SetBody(body)
 	return nil
 }
+
+func (c *Chat) StreamConvert(ctx eocontext.EoContext) error {
+	return ai_provider.StreamConvert(ctx, ai_provider.NewSSEDecoder, ai_provider.OpenAIStreamConvert, c.ResponseConvert)
+}
@@ -17,11 +17,12 @@ const (
 	ModelTypeSpeech2Text   ModelType = "speech2text"
 	ModelTypeModeration    ModelType = "moderation"
 	ModelTypeTTS           ModelType = "tts"
+	ModelTypeRerank        ModelType = "rerank"
 )
 
 const (
 	ModeChat     Mode = "chat"
-	ModeComplete Mode = "complete"
+	ModeComplete Mode = "completion"
 )
 
 type Mode string
@@ -39,6 +40,44 @@ type Model struct {
 	Model           string     `json:"model" yaml:"model"`
 	ModelType       ModelType  `json:"model_type" yaml:"model_type"`
 	ModelProperties *ModelMode `json:"model_properties" yaml:"model_properties"`
+	Pricing         *Pricing   `json:"pricing" yaml:"pricing"`
+	// Provider 模型所属的供应商，加载模型时设置
+	Provider string `json:"-" yaml:"-"`
+}
+
+// Pricing 模型的计费信息，费用为token数乘以单价再乘以单位
+type Pricing struct {
+	Input    string `json:"input" yaml:"input" label:"输入单价"`
+	Output   string `json:"output" yaml:"output" label:"输出单价"`
+	Unit     string `json:"unit" yaml:"unit" label:"单位" description:"如0.000001表示单价为每百万token的价格"`
+	Currency string `json:"currency" yaml:"currency" label:"币种"`
+}
+
+// Cost 计算本次用量的费用
+func (p *Pricing) Cost(usage *Usage) float64 {
+	input, _ := strconv.ParseFloat(p.Input, 64)
+	output, _ := strconv.ParseFloat(p.Output, 64)
+	unit, err := strconv.ParseFloat(p.Unit, 64)
+	if err != nil {
+		unit = 1
+	}
+	return (float64(usage.PromptTokens)*input + float64(usage.CompletionTokens)*output) * unit
+}
+
+// IProvider AI供应商驱动的实例，返回对应的供应商名称，用于查询模型信息
+type IProvider interface {
+	Provider() string
+}
+
+var (
+	// providerModels 各供应商已加载的模型，仅在驱动初始化时写入
+	providerModels = make(map[string]map[string]*Model)
+)
+
+// GetModel 返回供应商的模型信息
+func GetModel(provider string, model string) (*Model, bool) {
+	m, has := providerModels[provider][model]
+	return m, has
 }
 
 type ModelMode struct {
@@ -72,10 +111,12 @@ func LoadModels(providerContent []byte, dirFs embed.FS) (map[string]*Model, erro
 			if err != nil {
 				return nil, err
 			}
+			m.Provider = provider.Provider
 			models[m.Model] = &m
 		}
 
 	}
+	providerModels[provider.Provider] = models
 	return models, nil
 }
 
@@ -0,0 +1,30 @@
+package ai_provider
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPricingCost(t *testing.T) {
+	usage := &Usage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500}
+	tests := []struct {
+		name    string
+		pricing Pricing
+		want    float64
+	}{
+		// 未配置单位时按每个token计价
+		{name: "no unit", pricing: Pricing{Input: "0.002", Output: "0.004", Currency: "USD"}, want: 4},
+		{name: "per million tokens", pricing: Pricing{Input: "2.5", Output: "10", Unit: "0.000001", Currency: "USD"}, want: 0.0075},
+		{name: "per thousand tokens", pricing: Pricing{Input: "0.8", Output: "2", Unit: "0.001", Currency: "RMB"}, want: 1.8},
+		// 币种只作为标签输出，不参与计算
+		{name: "same price other currency", pricing: Pricing{Input: "2.5", Output: "10", Unit: "0.000001", Currency: "RMB"}, want: 0.0075},
+		{name: "invalid unit", pricing: Pricing{Input: "1", Output: "1", Unit: "abc"}, want: 1500},
+		{name: "free output", pricing: Pricing{Input: "3", Unit: "0.000001"}, want: 0.003},
+		{name: "empty", pricing: Pricing{}, want: 0},
+	}
+	for _, tt := range tests {
+		if got := tt.pricing.Cost(usage); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%s: cost %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package ai_provider
+
+import (
+	"github.com/eolinker/eosc/eocontext"
+)
+
+const (
+	tokenQuotaKey    = "ai_token_quota"
+	tokenEstimateKey = "ai_token_estimate"
+)
+
+// ITokenQuota AI请求的token配额，由限流策略写入上下文
+type ITokenQuota interface {
+	// Acquire 按预估的token数检查并预占配额，超出限制时写入拒绝响应并返回错误
+	Acquire(ctx eocontext.EoContext, estimate int64) error
+}
+
+// SetTokenQuota 限流策略写入token配额。ai_formatter已先于限流策略执行并写入预估用量时立即检查
+func SetTokenQuota(ctx eocontext.EoContext, quota ITokenQuota) error {
+	if estimate, ok := ctx.Value(tokenEstimateKey).(int64); ok {
+		return quota.Acquire(ctx, estimate)
+	}
+	ctx.WithValue(tokenQuotaKey, quota)
+	return nil
+}
+
+// AcquireTokens ai_formatter确定供应商及模型后按预估用量检查token配额，限流策略尚未执行时记录预估用量
+func AcquireTokens(ctx eocontext.EoContext, estimate int64) error {
+	if quota, ok := ctx.Value(tokenQuotaKey).(ITokenQuota); ok {
+		return quota.Acquire(ctx, estimate)
+	}
+	ctx.WithValue(tokenEstimateKey, estimate)
+	return nil
+}
+
+// EstimateTokens 按提示词预估请求的token数，英文约每4个字符1个token，中文等非ASCII字符按每字1个token计算
+func EstimateTokens(request *ClientRequest) int64 {
+	if request == nil {
+		return 0
+	}
+	tokens := int64(0)
+	for _, message := range request.Messages {
+		if message == nil {
+			continue
+		}
+		ascii := int64(0)
+		for _, r := range message.Content {
+			if r < 0x80 {
+				ascii++
+			} else {
+				tokens++
+			}
+		}
+		// 每条消息的角色等格式开销
+		tokens += (ascii+3)/4 + 4
+	}
+	return tokens
+}
@@ -0,0 +1,17 @@
+package ai_provider
+
+import "testing"
+
+func TestEstimateTokens(t *testing.T) {
+	request := &ClientRequest{Messages: []*Message{
+		{Role: "system", Content: "You are a helpful assistant."},
+		{Role: "user", Content: "你好"},
+	}}
+	// 28个ASCII字符约7个token，2个中文字符各1个token，另加每条消息4个token的格式开销
+	if got := EstimateTokens(request); got != 7+2+8 {
+		t.Errorf("got %d, want %d", got, 7+2+8)
+	}
+	if got := EstimateTokens(nil); got != 0 {
+		t.Errorf("got %d, want 0", got)
+	}
+}
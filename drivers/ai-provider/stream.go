@@ -0,0 +1,237 @@
+package ai_provider
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+
+	node_http_context "github.com/eolinker/apinto/node/http-context"
+	"github.com/eolinker/eosc/eocontext"
+	http_context "github.com/eolinker/eosc/eocontext/http-context"
+)
+
+var (
+	streamDataPrefix = []byte("data: ")
+	streamEventEnd   = []byte("\n\n")
+	streamDone       = []byte("data: [DONE]\n\n")
+	streamComment    = []byte(":\n\n")
+)
+
+// StreamEvent 上游流式响应中的一个事件
+type StreamEvent struct {
+	// Event 事件类型，SSE的event字段或AWS事件流的:event-type头部
+	Event string
+	Data  []byte
+	// Comment SSE注释行（如网关发送的心跳），以注释行转发给客户端
+	Comment bool
+}
+
+// IStreamDecoder 按上游原生的流式格式拆分事件，流结束时返回io.EOF
+type IStreamDecoder interface {
+	Next() (*StreamEvent, error)
+}
+
+type FNewStreamDecoder func(reader io.Reader) IStreamDecoder
+
+// FStreamConvert 将上游的一个事件转换为统一格式的响应块，Message.Content为本次增量内容，返回nil表示忽略该事件
+type FStreamConvert func(event *StreamEvent) (*ClientResponse, error)
+
+// StreamConvert 上游以流式返回时将响应逐事件转换为统一的SSE格式：每个响应块以`data: <ClientResponse>`返回，流结束时返回`data: [DONE]`。
+// 上游未以流式返回时（如不支持流式的模型或出错时返回的JSON），使用responseConvert转换完整响应后以单个事件返回
+func StreamConvert(ctx eocontext.EoContext, newDecoder FNewStreamDecoder, convert FStreamConvert, responseConvert func(ctx eocontext.EoContext) error) error {
+	httpContext, err := http_context.Assert(ctx)
+	if err != nil {
+		return err
+	}
+	if httpContext.Response().StatusCode() != 200 {
+		return nil
+	}
+	if wrapper, ok := ctx.(node_http_context.IResponseStreamWrapper); ok && wrapper.WrapResponseStream(func(reader io.Reader) io.Reader {
+		return NewStreamReader(newDecoder(reader), convert)
+	}) {
+		httpContext.Response().SetHeader("Content-Type", "text/event-stream")
+		return nil
+	}
+	err = responseConvert(ctx)
+	if err != nil {
+		return err
+	}
+	body := httpContext.Response().GetBody()
+	buf := bytes.NewBuffer(make([]byte, 0, len(body)+len(streamDone)+8))
+	buf.Write(streamDataPrefix)
+	buf.Write(body)
+	buf.Write(streamEventEnd)
+	buf.Write(streamDone)
+	httpContext.Response().SetBody(buf.Bytes())
+	httpContext.Response().SetHeader("Content-Type", "text/event-stream")
+	return nil
+}
+
+// NewStreamReader 返回按统一SSE格式输出的数据流
+func NewStreamReader(decoder IStreamDecoder, convert FStreamConvert) io.Reader {
+	return &streamReader{decoder: decoder, convert: convert}
+}
+
+type streamReader struct {
+	decoder IStreamDecoder
+	convert FStreamConvert
+	buf     bytes.Buffer
+	done    bool
+}
+
+func (r *streamReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		event, err := r.decoder.Next()
+		if err != nil {
+			if err != io.EOF {
+				// 上游中断时告知客户端错误原因
+				r.write(&ClientResponse{Code: -1, Error: err.Error()})
+			}
+			r.buf.Write(streamDone)
+			r.done = true
+			continue
+		}
+		if event.Comment {
+			r.buf.Write(streamComment)
+			continue
+		}
+		chunk, err := r.convert(event)
+		if err != nil {
+			chunk = &ClientResponse{Code: -1, Error: err.Error()}
+		}
+		if chunk != nil {
+			r.write(chunk)
+		}
+	}
+	return r.buf.Read(p)
+}
+
+func (r *streamReader) write(chunk *ClientResponse) {
+	data, _ := json.Marshal(chunk)
+	r.buf.Write(streamDataPrefix)
+	r.buf.Write(data)
+	r.buf.Write(streamEventEnd)
+}
+
+// NewSSEDecoder 按text/event-stream格式拆分事件，多行data以换行拼接，事件之间的注释行作为注释事件返回
+func NewSSEDecoder(reader io.Reader) IStreamDecoder {
+	return &sseDecoder{reader: bufio.NewReader(reader)}
+}
+
+type sseDecoder struct {
+	reader *bufio.Reader
+}
+
+func (d *sseDecoder) Next() (*StreamEvent, error) {
+	event := &StreamEvent{}
+	var data [][]byte
+	for {
+		line, err := d.reader.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			if err == io.EOF && len(data) > 0 {
+				break
+			}
+			return nil, err
+		}
+		line = bytes.TrimRight(line, "\r\n")
+		if len(line) == 0 {
+			if len(data) > 0 {
+				break
+			}
+			event.Event = ""
+			continue
+		}
+		if line[0] == ':' {
+			if len(data) == 0 && event.Event == "" {
+				return &StreamEvent{Comment: true}, nil
+			}
+			continue
+		}
+		field, value, _ := bytes.Cut(line, []byte(":"))
+		value = bytes.TrimPrefix(value, []byte(" "))
+		switch string(field) {
+		case "event":
+			event.Event = string(value)
+		case "data":
+			data = append(data, value)
+		}
+		if err == io.EOF {
+			if len(data) > 0 {
+				break
+			}
+			return nil, io.EOF
+		}
+	}
+	event.Data = bytes.Join(data, []byte("\n"))
+	return event, nil
+}
+
+// NewJSONLinesDecoder 按行拆分事件，每行为一个JSON对象，忽略空行
+func NewJSONLinesDecoder(reader io.Reader) IStreamDecoder {
+	return &jsonLinesDecoder{reader: bufio.NewReader(reader)}
+}
+
+type jsonLinesDecoder struct {
+	reader *bufio.Reader
+}
+
+func (d *jsonLinesDecoder) Next() (*StreamEvent, error) {
+	for {
+		line, err := d.reader.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			return &StreamEvent{Data: line}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// openAIChunk OpenAI兼容格式的流式响应块
+type openAIChunk struct {
+	Choices []struct {
+		Delta        Message `json:"delta"`
+		FinishReason string  `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *Usage `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// OpenAIStreamConvert 转换OpenAI兼容格式的流式响应事件，忽略结束标记[DONE]
+func OpenAIStreamConvert(event *StreamEvent) (*ClientResponse, error) {
+	if bytes.Equal(event.Data, []byte("[DONE]")) {
+		return nil, nil
+	}
+	chunk := new(openAIChunk)
+	err := json.Unmarshal(event.Data, chunk)
+	if err != nil {
+		return nil, err
+	}
+	if chunk.Error != nil {
+		return &ClientResponse{Code: -1, Error: chunk.Error.Message}, nil
+	}
+	var usage *Usage
+	if chunk.Usage != nil {
+		usage = NewUsage(chunk.Usage.PromptTokens, chunk.Usage.CompletionTokens, chunk.Usage.TotalTokens)
+	}
+	if len(chunk.Choices) < 1 {
+		if usage == nil {
+			return nil, nil
+		}
+		// 仅包含用量的响应块
+		return &ClientResponse{Usage: usage}, nil
+	}
+	choice := chunk.Choices[0]
+	return &ClientResponse{
+		Message:      choice.Delta,
+		FinishReason: choice.FinishReason,
+		Usage:        usage,
+	}, nil
+}
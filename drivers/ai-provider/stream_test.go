@@ -0,0 +1,85 @@
+package ai_provider
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStreamReaderSSE(t *testing.T) {
+	upstream := ": keep-alive\n\n" +
+		"data: {\"choices\":[{\"delta\":{\"role\":\"assistant\",\"content\":\"Hel\"}}]}\r\n\r\n" +
+		"data: {\"choices\":[{\"delta\":{\"content\":\"lo\"},\"finish_reason\":\"stop\"}]}\n\n" +
+		"data: {\"choices\":[],\"usage\":{\"prompt_tokens\":1,\"completion_tokens\":2}}\n\n" +
+		"data: [DONE]\n\n"
+	data, err := io.ReadAll(NewStreamReader(NewSSEDecoder(strings.NewReader(upstream)), OpenAIStreamConvert))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 注释行（心跳）以注释转发
+	want := ":\n\n" +
+		`data: {"message":{"role":"assistant","content":"Hel"},"code":0,"error":""}` + "\n\n" +
+		`data: {"message":{"role":"","content":"lo"},"finish_reason":"stop","code":0,"error":""}` + "\n\n" +
+		`data: {"message":{"role":"","content":""},"code":0,"error":"","usage":{"prompt_tokens":1,"completion_tokens":2,"total_tokens":3}}` + "\n\n" +
+		"data: [DONE]\n\n"
+	if string(data) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", data, want)
+	}
+
+	// 上游中断时返回错误后结束
+	data, _ = io.ReadAll(NewStreamReader(NewSSEDecoder(strings.NewReader("data: {bad\n\n")), OpenAIStreamConvert))
+	if !bytes.Contains(data, []byte(`"code":-1`)) || !bytes.HasSuffix(data, streamDone) {
+		t.Errorf("unexpected error stream: %s", data)
+	}
+}
+
+func eventStreamMessage(headers map[string]string, payload string) []byte {
+	var h bytes.Buffer
+	for k, v := range headers {
+		h.WriteByte(byte(len(k)))
+		h.WriteString(k)
+		h.WriteByte(7)
+		binary.Write(&h, binary.BigEndian, uint16(len(v)))
+		h.WriteString(v)
+	}
+	var msg bytes.Buffer
+	binary.Write(&msg, binary.BigEndian, uint32(12+h.Len()+len(payload)+4))
+	binary.Write(&msg, binary.BigEndian, uint32(h.Len()))
+	binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+	msg.Write(h.Bytes())
+	msg.WriteString(payload)
+	binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+	return msg.Bytes()
+}
+
+func TestAWSEventStreamDecoder(t *testing.T) {
+	var upstream bytes.Buffer
+	upstream.Write(eventStreamMessage(map[string]string{":message-type": "event", ":event-type": "contentBlockDelta"}, `{"delta":{"text":"hi"}}`))
+	upstream.Write(eventStreamMessage(map[string]string{":message-type": "exception", ":exception-type": "throttlingException"}, `{"message":"slow down"}`))
+	decoder := NewAWSEventStreamDecoder(&upstream)
+	for _, want := range []StreamEvent{
+		{Event: "contentBlockDelta", Data: []byte(`{"delta":{"text":"hi"}}`)},
+		{Event: "throttlingException", Data: []byte(`{"message":"slow down"}`)},
+	} {
+		event, err := decoder.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.Event != want.Event || !json.Valid(event.Data) || !bytes.Equal(event.Data, want.Data) {
+			t.Errorf("got %s %s, want %s %s", event.Event, event.Data, want.Event, want.Data)
+		}
+	}
+	if _, err := decoder.Next(); err != io.EOF {
+		t.Errorf("expect EOF, got %v", err)
+	}
+
+	broken := eventStreamMessage(map[string]string{":event-type": "messageStop"}, `{}`)
+	broken[len(broken)-1] ^= 0xff
+	if _, err := NewAWSEventStreamDecoder(bytes.NewReader(broken)).Next(); err == nil {
+		t.Error("expect checksum error")
+	}
+}
@@ -0,0 +1,120 @@
+package ai_provider
+
+import (
+	"encoding/json"
+
+	"github.com/eolinker/eosc"
+)
+
+const (
+	ToolChoiceAuto     = "auto"
+	ToolChoiceNone     = "none"
+	ToolChoiceRequired = "required"
+	ToolChoiceFunction = "function"
+
+	FinishToolCalls = "tool_calls"
+)
+
+// Tool 模型可调用的工具，目前仅支持函数
+type Tool struct {
+	Type     string    `json:"type"`
+	Function *Function `json:"function"`
+}
+
+type Function struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Parameters 函数参数的JSON Schema
+	Parameters json.RawMessage `json:"parameters,omitempty"`
+}
+
+// ToolCall 模型返回的工具调用，Arguments为JSON字符串
+type ToolCall struct {
+	// Index 流式返回时工具调用的序号，同一序号的Arguments需按顺序拼接
+	Index    *int         `json:"index,omitempty"`
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+type FunctionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
+}
+
+// ArgumentsJSON 返回JSON格式的调用参数，参数为空时返回空对象
+func (c *ToolCall) ArgumentsJSON() json.RawMessage {
+	if c.Function.Arguments == "" || !json.Valid([]byte(c.Function.Arguments)) {
+		return json.RawMessage("{}")
+	}
+	return json.RawMessage(c.Function.Arguments)
+}
+
+// NewToolCall 返回模型调用的工具，index小于0时表示非流式返回
+func NewToolCall(index int, id, name, arguments string) *ToolCall {
+	call := &ToolCall{
+		ID:       id,
+		Type:     ToolChoiceFunction,
+		Function: FunctionCall{Name: name, Arguments: arguments},
+	}
+	if index >= 0 {
+		call.Index = &index
+	}
+	if id == "" && name == "" {
+		call.Type = ""
+	}
+	return call
+}
+
+// ToolChoice 工具调用方式，请求中可为auto、none、required字符串，或{"type":"function","function":{"name":"xxx"}}指定函数
+type ToolChoice struct {
+	Type string
+	// Function Type为function时指定的函数名
+	Function string
+}
+
+func (t *ToolChoice) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		t.Type = s
+		return nil
+	}
+	v := struct {
+		Type     string `json:"type"`
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}{}
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+	t.Type = v.Type
+	t.Function = v.Function.Name
+	return nil
+}
+
+func (t ToolChoice) MarshalJSON() ([]byte, error) {
+	if t.Type != ToolChoiceFunction {
+		return json.Marshal(t.Type)
+	}
+	return json.Marshal(map[string]interface{}{
+		"type":     ToolChoiceFunction,
+		"function": map[string]string{"name": t.Function},
+	})
+}
+
+// SetOpenAIMessages 将统一格式的消息、工具及工具调用方式写入OpenAI兼容格式的请求
+func SetOpenAIMessages(base *eosc.Base[ClientRequest]) {
+	messages := base.Config.Messages
+	if messages == nil {
+		messages = []*Message{}
+	}
+	base.SetAppend("messages", messages)
+	if len(base.Config.Tools) > 0 {
+		base.SetAppend("tools", base.Config.Tools)
+		if base.Config.ToolChoice != nil && base.Config.ToolChoice.Type != "" {
+			base.SetAppend("tool_choice", base.Config.ToolChoice)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package ai_provider
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMessageContentParts(t *testing.T) {
+	body := `{"role":"user","content":[{"type":"text","text":"describe"},{"type":"image_url","image_url":{"url":"data:image/png;base64,AAAA"}}]}`
+	m := new(Message)
+	if err := json.Unmarshal([]byte(body), m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Content != "describe" || len(m.Parts) != 2 {
+		t.Fatalf("unexpected message: %+v", m)
+	}
+	mediaType, data, ok := m.Parts[1].ImageURL.Base64()
+	if !ok || mediaType != "image/png" || data != "AAAA" {
+		t.Errorf("unexpected image: %s %s %v", mediaType, data, ok)
+	}
+	out, _ := json.Marshal(m)
+	if string(out) != body {
+		t.Errorf("got %s, want %s", out, body)
+	}
+	text, _ := json.Marshal(Message{Role: "assistant", Content: "Hi"})
+	if string(text) != `{"role":"assistant","content":"Hi"}` {
+		t.Errorf("unexpected text message: %s", text)
+	}
+}
+
+func TestMessageToolCalls(t *testing.T) {
+	body := `{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"weather","arguments":"{\"city\":\"beijing\"}"}}]}`
+	m := new(Message)
+	if err := json.Unmarshal([]byte(body), m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.ToolCalls) != 1 || m.ToolCalls[0].Function.Name != "weather" || m.ToolCalls[0].Index != nil {
+		t.Fatalf("unexpected tool calls: %+v", m.ToolCalls)
+	}
+	if string(m.ToolCalls[0].ArgumentsJSON()) != `{"city":"beijing"}` {
+		t.Errorf("unexpected arguments: %s", m.ToolCalls[0].ArgumentsJSON())
+	}
+	chunk, err := OpenAIStreamConvert(&StreamEvent{Data: []byte(`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"ci"}}]}}]}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := chunk.Message.ToolCalls
+	if len(calls) != 1 || calls[0].Index == nil || *calls[0].Index != 0 || calls[0].Function.Arguments != `{"ci` {
+		t.Errorf("unexpected stream tool calls: %+v", calls)
+	}
+}
+
+func TestToolChoice(t *testing.T) {
+	for body, want := range map[string]ToolChoice{
+		`"auto"`: {Type: ToolChoiceAuto},
+		`{"type":"function","function":{"name":"weather"}}`: {Type: ToolChoiceFunction, Function: "weather"},
+	} {
+		choice := new(ToolChoice)
+		if err := json.Unmarshal([]byte(body), choice); err != nil {
+			t.Fatal(err)
+		}
+		if *choice != want {
+			t.Errorf("%s: got %+v, want %+v", body, choice, want)
+		}
+		out, _ := json.Marshal(choice)
+		decoded := new(ToolChoice)
+		if err := json.Unmarshal(out, decoded); err != nil || *decoded != want {
+			t.Errorf("unexpected marshal result: %s", out)
+		}
+	}
+}
+
+func TestGeminiContents(t *testing.T) {
+	index := 0
+	messages := []*Message{
+		{Role: "user", Content: "weather?"},
+		{Role: "assistant", ToolCalls: []*ToolCall{{Index: &index, ID: "call_0", Type: "function", Function: FunctionCall{Name: "weather", Arguments: `{"city":"beijing"}`}}}},
+		{Role: "tool", ToolCallID: "call_0", Content: "sunny"},
+	}
+	contents := GeminiContents(messages)
+	if len(contents) != 3 || contents[1].Role != "model" || contents[1].Parts[0].FunctionCall == nil {
+		t.Fatalf("unexpected contents: %+v", contents)
+	}
+	response := contents[2].Parts[0].FunctionResponse
+	if response == nil || response.Name != "weather" || string(response.Response) != `{"content":"sunny"}` {
+		t.Errorf("unexpected function response: %+v", response)
+	}
+	message := GeminiMessage([]*GeminiPart{{FunctionCall: &GeminiFunctionCall{Name: "weather", Args: json.RawMessage(`{}`)}}}, false)
+	if len(message.ToolCalls) != 1 || GeminiFinishReason(&message, FinishStop) != FinishToolCalls {
+		t.Errorf("unexpected message: %+v", message)
+	}
+}
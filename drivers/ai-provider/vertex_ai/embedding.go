@@ -0,0 +1,104 @@
+package vertex_ai
+
+import (
+	"encoding/json"
+
+	"github.com/eolinker/apinto/convert"
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+	"github.com/eolinker/eosc/eocontext"
+	http_context "github.com/eolinker/eosc/eocontext/http-context"
+)
+
+// Embedding 通过predict接口调用文本向量模型
+type Embedding struct {
+	model    string
+	endPoint string
+}
+
+func NewEmbedding(model string) convert.IChildConverter {
+	return &Embedding{
+		endPoint: "/v1/projects/%s/locations/%s/publishers/google/models/%s:predict",
+		model:    model,
+	}
+}
+
+func (e *Embedding) Endpoint() string {
+	return e.endPoint
+}
+
+func (e *Embedding) RequestConvert(ctx eocontext.EoContext, extender map[string]interface{}) error {
+	httpContext, err := http_context.Assert(ctx)
+	if err != nil {
+		return err
+	}
+	body, err := httpContext.Proxy().Body().RawBody()
+	if err != nil {
+		return err
+	}
+	request := new(ai_provider.EmbeddingRequest)
+	err = json.Unmarshal(body, request)
+	if err != nil {
+		return err
+	}
+	instances := make([]map[string]string, 0, len(request.Input))
+	for _, input := range request.Input {
+		instances = append(instances, map[string]string{"content": input})
+	}
+	cfg := map[string]interface{}{
+		"instances": instances,
+	}
+	if request.Dimensions > 0 {
+		cfg["parameters"] = map[string]interface{}{"outputDimensionality": request.Dimensions}
+	}
+	body, err = json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	httpContext.Proxy().Body().SetRaw("application/json", body)
+	return nil
+}
+
+type embeddingResponse struct {
+	Predictions []struct {
+		Embeddings struct {
+			Values     []float64 `json:"values"`
+			Statistics struct {
+				TokenCount int `json:"token_count"`
+			} `json:"statistics"`
+		} `json:"embeddings"`
+	} `json:"predictions"`
+}
+
+func (e *Embedding) ResponseConvert(ctx eocontext.EoContext) error {
+	httpContext, err := http_context.Assert(ctx)
+	if err != nil {
+		return err
+	}
+	if httpContext.Response().StatusCode() != 200 {
+		return nil
+	}
+	data := new(embeddingResponse)
+	err = json.Unmarshal(httpContext.Response().GetBody(), data)
+	if err != nil {
+		return err
+	}
+	response := &ai_provider.EmbeddingResponse{
+		Data: make([]*ai_provider.Embedding, 0, len(data.Predictions)),
+	}
+	tokens := 0
+	for i, p := range data.Predictions {
+		response.Data = append(response.Data, &ai_provider.Embedding{Index: i, Embedding: p.Embeddings.Values})
+		tokens += p.Embeddings.Statistics.TokenCount
+	}
+	response.Usage = ai_provider.NewUsage(tokens, 0, 0)
+	body, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	httpContext.Response().SetBody(body)
+	return nil
+}
+
+func (e *Embedding) StreamConvert(ctx eocontext.EoContext) error {
+	return e.ResponseConvert(ctx)
+}
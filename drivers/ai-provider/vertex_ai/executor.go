@@ -45,10 +45,8 @@ func init() {
 		panic(err)
 	}
 	for key, value := range models {
-		if value.ModelProperties != nil {
-			if f, ok := modelModes[value.ModelProperties.Mode]; ok {
-				modelConvert[key] = f(value.Model)
-			}
+		if f, ok := modelModes[value.ConvertMode()]; ok {
+			modelConvert[key] = f(value.Model)
 		}
 	}
 }
@@ -87,6 +85,10 @@ func (c *Converter) ResponseConvert(ctx eocontext.EoContext) error {
 	return c.converter.ResponseConvert(ctx)
 }
 
+func (c *Converter) StreamConvert(ctx eocontext.EoContext) error {
+	return c.converter.StreamConvert(ctx)
+}
+
 type executor struct {
 	drivers.WorkerBase
 	eocontext.BalanceHandler
@@ -186,6 +188,10 @@ func (e *executor) CheckSkill(skill string) bool {
 	return convert.CheckSkill(skill)
 }
 
+func (e *executor) Provider() string {
+	return name
+}
+
 type ModelConfig struct {
 	MaxOutputTokens  int     `json:"max_tokens"`
 	Temperature      float64 `json:"temperature"`
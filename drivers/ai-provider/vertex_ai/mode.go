@@ -2,6 +2,7 @@ package vertex_ai
 
 import (
 	"encoding/json"
+	"strings"
 
 	"github.com/eolinker/eosc"
 
@@ -15,7 +16,8 @@ type FNewModelMode func(string) convert.IChildConverter
 
 var (
 	modelModes = map[string]FNewModelMode{
-		ai_provider.ModeChat.String(): NewChat,
+		ai_provider.ModeChat.String():      NewChat,
+		ai_provider.ModeEmbedding.String(): NewEmbedding,
 	}
 )
 
@@ -50,24 +52,12 @@ func (c *Chat) RequestConvert(ctx eocontext.EoContext, extender map[string]inter
 	if err != nil {
 		return err
 	}
-	messages := make([]Content, 0, len(baseCfg.Config.Messages)+1)
-	for _, m := range baseCfg.Config.Messages {
-		role := "user"
-		if m.Role == "system" && len(baseCfg.Config.Messages) > 1 {
-			role = "model"
-		}
-		parts := make([]map[string]interface{}, 0, 1)
-		if m.Content != "" {
-			parts = append(parts, map[string]interface{}{
-				"text": m.Content,
-			})
-		}
-		messages = append(messages, Content{
-			Role:  role,
-			Parts: parts,
-		})
+	if baseCfg.Config.Stream {
+		// 流式接口以SSE格式返回
+		httpContext.Proxy().URI().SetPath(strings.Replace(httpContext.Proxy().URI().Path(), ":generateContent", ":streamGenerateContent", 1))
+		httpContext.Proxy().URI().SetQuery("alt", "sse")
 	}
-	baseCfg.SetAppend("contents", messages)
+	ai_provider.SetGeminiRequest(baseCfg)
 	for k, v := range extender {
 		baseCfg.SetAppend(k, v)
 	}
@@ -97,22 +87,9 @@ func (c *Chat) ResponseConvert(ctx eocontext.EoContext) error {
 	responseBody := &ai_provider.ClientResponse{}
 	if len(data.Config.Candidates) > 0 {
 		msg := data.Config.Candidates[0]
-		role := "user"
-		if msg.Content.Role == "model" {
-			role = "assistant"
-		}
-		text := ""
-		if len(msg.Content.Parts) > 0 {
-			if v, ok := msg.Content.Parts[0]["text"]; ok {
-				text = v.(string)
-			}
-		}
-
-		responseBody.Message = ai_provider.Message{
-			Role:    role,
-			Content: text,
-		}
-		responseBody.FinishReason = msg.FinishReason
+		responseBody.Message = ai_provider.GeminiMessage(msg.Content.Parts, false)
+		responseBody.FinishReason = ai_provider.GeminiFinishReason(&responseBody.Message, msg.FinishReason)
+		responseBody.Usage = data.Config.UsageMetadata.usage()
 	} else {
 		responseBody.Code = -1
 		responseBody.Error = "no response"
@@ -124,3 +101,31 @@ func (c *Chat) ResponseConvert(ctx eocontext.EoContext) error {
 	httpContext.Response().SetBody(body)
 	return nil
 }
+
+func (c *Chat) StreamConvert(ctx eocontext.EoContext) error {
+	return ai_provider.StreamConvert(ctx, ai_provider.NewSSEDecoder, streamConvert, c.ResponseConvert)
+}
+
+// streamConvert 流式响应的每个事件均为完整的Response，内容为本次增量
+func streamConvert(event *ai_provider.StreamEvent) (*ai_provider.ClientResponse, error) {
+	data := new(Response)
+	err := json.Unmarshal(event.Data, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(data.Candidates) < 1 {
+		usage := data.UsageMetadata.usage()
+		if usage == nil {
+			return nil, nil
+		}
+		return &ai_provider.ClientResponse{Usage: usage}, nil
+	}
+	msg := data.Candidates[0]
+	message := ai_provider.GeminiMessage(msg.Content.Parts, true)
+	return &ai_provider.ClientResponse{
+		Message:      message,
+		FinishReason: ai_provider.GeminiFinishReason(&message, msg.FinishReason),
+		// 每个事件返回截至当前的累计用量
+		Usage: data.UsageMetadata.usage(),
+	}, nil
+}
@@ -17,4 +17,11 @@ type Response struct {
 	FinishReason string `json:"finish_reason"`
 	ErrorCode    int    `json:"error_code"`
 	ErrorMsg     string `json:"error_msg"`
+	Usage        Usage  `json:"usage"`
+}
+
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
@@ -88,6 +88,9 @@ func (c *Chat) RequestConvert(ctx eocontext.EoContext, extender map[string]inter
 		})
 	}
 	baseCfg.SetAppend("messages", messages)
+	if baseCfg.Config.Stream {
+		baseCfg.SetAppend("stream", true)
+	}
 	for k, v := range extender {
 		baseCfg.SetAppend(k, v)
 	}
@@ -123,6 +126,7 @@ func (c *Chat) ResponseConvert(ctx eocontext.EoContext) error {
 			Content: msg.Result,
 		}
 		responseBody.FinishReason = msg.FinishReason
+		responseBody.Usage = ai_provider.NewUsage(msg.Usage.PromptTokens, msg.Usage.CompletionTokens, msg.Usage.TotalTokens)
 		//}
 	} else {
 		responseBody.Code = data.Config.ErrorCode
@@ -136,3 +140,27 @@ func (c *Chat) ResponseConvert(ctx eocontext.EoContext) error {
 	httpContext.Response().SetBody(body)
 	return nil
 }
+
+func (c *Chat) StreamConvert(ctx eocontext.EoContext) error {
+	return ai_provider.StreamConvert(ctx, ai_provider.NewSSEDecoder, streamConvert, c.ResponseConvert)
+}
+
+// streamConvert 流式响应的每个事件均为Response，result为本次增量
+func streamConvert(event *ai_provider.StreamEvent) (*ai_provider.ClientResponse, error) {
+	data := new(Response)
+	err := json.Unmarshal(event.Data, data)
+	if err != nil {
+		return nil, err
+	}
+	if data.ErrorCode != 0 {
+		return &ai_provider.ClientResponse{Code: data.ErrorCode, Error: data.ErrorMsg}, nil
+	}
+	return &ai_provider.ClientResponse{
+		Message: ai_provider.Message{
+			Role:    "assistant",
+			Content: data.Result,
+		},
+		FinishReason: data.FinishReason,
+		Usage:        ai_provider.NewUsage(data.Usage.PromptTokens, data.Usage.CompletionTokens, data.Usage.TotalTokens),
+	}, nil
+}
@@ -8,9 +8,9 @@ type Config struct {
 type MetricConfig struct {
 	Metric      string   `json:"metric" yaml:"metric" required:"true" label:"指标名"`
 	Description string   `json:"description" yaml:"description" required:"true" label:"指标描述"`
-	Collector   string   `json:"collector" yaml:"collector" required:"true" label:"收集类型" enum:"request_total,request_timing,request_retry,request_req,request_resp,proxy_total,proxy_timing,proxy_req,proxy_resp"`
+	Collector   string   `json:"collector" yaml:"collector" required:"true" label:"收集类型" enum:"request_total,request_timing,request_retry,request_req,request_resp,proxy_total,proxy_timing,proxy_req,proxy_resp,ai_prompt_tokens,ai_completion_tokens,ai_total_tokens,ai_cost"`
 	Objectives  string   `json:"objectives" yaml:"objectives" label:"quantiles分位数值配置" description:"格式为0.5:0.01用,号隔开数值.当收集类型为request_timing,request_retry,request_req,request_resp,proxy_timing,proxy_req,proxy_resp时选填"`
-	Labels      []string `json:"labels" yaml:"labels" required:"true" label:"标签列表" description:"$表示引用变量,不带$表示使用常量,as表示使用别名. $node表示标签名为node，值使用node变量;$node as node_id表示标签名为node_id,值使用node变量;node as node_id表示常量，标签名为node_id,值为字符串node.变量可选:node,cluster,method,upstream,status,api,app,host,handler,addr,path"`
+	Labels      []string `json:"labels" yaml:"labels" required:"true" label:"标签列表" description:"$表示引用变量,不带$表示使用常量,as表示使用别名. $node表示标签名为node，值使用node变量;$node as node_id表示标签名为node_id,值使用node变量;node as node_id表示常量，标签名为node_id,值为字符串node.变量可选:node,cluster,method,upstream,status,api,app,host,handler,addr,path,ai_provider,ai_model"`
 }
 
 type MetricType string
@@ -39,18 +39,27 @@ var (
 		"proxy_timing":   typeProxyMetric,
 		"proxy_req":      typeProxyMetric,
 		"proxy_resp":     typeProxyMetric,
+		// AI请求的token用量及费用，来自ai_formatter插件写入的标签
+		"ai_prompt_tokens":     typeRequestMetric,
+		"ai_completion_tokens": typeRequestMetric,
+		"ai_total_tokens":      typeRequestMetric,
+		"ai_cost":              typeRequestMetric,
 	}
 
 	//collectorTypeSet collector对应的指标类型
 	collectorTypeSet = map[string]string{
-		"request_total":  typeCounter,
-		"request_timing": typeSummary,
-		"request_retry":  typeSummary,
-		"request_req":    typeSummary,
-		"request_resp":   typeSummary,
-		"proxy_total":    typeCounter,
-		"proxy_timing":   typeSummary,
-		"proxy_req":      typeSummary,
-		"proxy_resp":     typeSummary,
+		"request_total":        typeCounter,
+		"request_timing":       typeSummary,
+		"request_retry":        typeSummary,
+		"request_req":          typeSummary,
+		"request_resp":         typeSummary,
+		"proxy_total":          typeCounter,
+		"proxy_timing":         typeSummary,
+		"proxy_req":            typeSummary,
+		"proxy_resp":           typeSummary,
+		"ai_prompt_tokens":     typeCounter,
+		"ai_completion_tokens": typeCounter,
+		"ai_total_tokens":      typeCounter,
+		"ai_cost":              typeCounter,
 	}
 )
@@ -0,0 +1,34 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestAICollector(t *testing.T) {
+	for _, collector := range []string{"ai_prompt_tokens", "ai_completion_tokens", "ai_total_tokens", "ai_cost"} {
+		if collectorSet[collector] != typeRequestMetric {
+			t.Fatalf("%s: expect request metric", collector)
+		}
+		m, err := newIMetric(&metricInfoCfg{
+			collector: collector,
+			labels:    []labelConfig{{Name: "ai_model"}},
+		}, collector, collector, "")
+		if err != nil {
+			t.Fatalf("%s: %v", collector, err)
+		}
+		c, ok := m.(*counterVec)
+		if !ok {
+			t.Fatalf("%s: expect counter, got %T", collector, m)
+		}
+		labels := map[string]string{"ai_model": "gpt"}
+		m.Observe(1.5, labels)
+		m.Observe(2, labels)
+		// counter的value必须大于0，负数不计入
+		m.Observe(-1, labels)
+		if v := testutil.ToFloat64(c.counter.With(labels)); v != 3.5 {
+			t.Fatalf("%s: expect 3.5, got %v", collector, v)
+		}
+	}
+}
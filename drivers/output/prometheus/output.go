@@ -72,7 +72,10 @@ func (p *PromOutput) writeMetric(metric iMetric, metricInfo *metricInfoCfg, entr
 			}
 
 		}
-		value, _ := entry.GetFloat(metricInfo.collector)
+		value, has := entry.GetFloat(metricInfo.collector)
+		if !has {
+			continue
+		}
 		metric.Observe(value, labels)
 
 	}
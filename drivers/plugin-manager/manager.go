@@ -81,6 +81,24 @@ func (p *PluginManager) GetConfigType(name string) (reflect.Type, bool) {
 	return nil, false
 }
 
+func (p *PluginManager) BodyRequired(conf map[string]*plugin.Config) []string {
+	names := make([]string, 0)
+	for _, plg := range p.plugins {
+		if plg.Status == StatusDisable || !plugin.IsBodyRequired(plg.ID) {
+			continue
+		}
+		if v, ok := conf[plg.Name]; ok {
+			if v.Disable {
+				continue
+			}
+		} else if plg.Status != StatusGlobal {
+			continue
+		}
+		names = append(names, plg.Name)
+	}
+	return names
+}
+
 func (p *PluginManager) Reset(conf interface{}) error {
 
 	plugins, err := p.check(conf)
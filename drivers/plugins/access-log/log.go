@@ -5,6 +5,7 @@ import (
 
 	"github.com/eolinker/apinto/drivers"
 	http_entry "github.com/eolinker/apinto/entries/http-entry"
+	http_context "github.com/eolinker/apinto/node/http-context"
 	"github.com/eolinker/apinto/output"
 	"github.com/eolinker/eosc"
 	"github.com/eolinker/eosc/eocontext"
@@ -29,19 +30,26 @@ func (l *accessLog) DoHttpFilter(ctx http_service.IHttpContext, next eocontext.I
 	if err != nil {
 		log.Error(err)
 	}
+	outputs := l.proxy.List()
+	// 响应以流式转发时，在流结束后记录日志，此时响应长度及响应时间为整个流的统计
+	if sr, ok := ctx.(http_context.IStreamResponse); ok && sr.OnStreamEnd(func() { doOutput(ctx, outputs) }) {
+		return nil
+	}
+	doOutput(ctx, outputs)
+	return nil
+}
+
+func doOutput(ctx http_service.IHttpContext, outputs []output.IEntryOutput) {
 	entry := http_entry.NewEntry(ctx)
 
-	outputs := l.proxy.List()
 	for _, v := range outputs {
 
-		err = v.Output(entry)
+		err := v.Output(entry)
 		if err != nil {
 			log.Error("access log http-entry error:", err)
 			continue
 		}
 	}
-
-	return nil
 }
 
 func (l *accessLog) Destroy() {
@@ -0,0 +1,282 @@
+package ai_formatter
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eolinker/apinto/convert"
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+	http_context "github.com/eolinker/eosc/eocontext/http-context"
+	"github.com/eolinker/eosc/log"
+)
+
+const (
+	cacheScopeGlobal = "global"
+
+	cacheHit     = "hit"
+	cacheSimilar = "similar"
+	cacheMiss    = "miss"
+)
+
+type cacheEntry struct {
+	key    string
+	scope  string
+	vector []float64
+	body   []byte
+	expire time.Time
+}
+
+// responseCache 按规范化后的消息及模型配置缓存完整响应，配置向量模型时按余弦相似度匹配相近的提示词。
+// 缓存保存在进程内，超过最大条数时淘汰最久未使用的条目
+type responseCache struct {
+	ttl        time.Duration
+	maxEntries int
+	threshold  float64
+	embedding  string
+	model      string
+	// shared 为true时所有调用方共享缓存，否则按应用隔离
+	shared bool
+
+	lock    sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+func newResponseCache(conf *CacheConfig) (*responseCache, error) {
+	if conf == nil || conf.TTL <= 0 {
+		return nil, nil
+	}
+	maxEntries := conf.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+	c := &responseCache{
+		ttl:        time.Duration(conf.TTL) * time.Second,
+		maxEntries: maxEntries,
+		threshold:  conf.Threshold,
+		embedding:  string(conf.EmbeddingProvider),
+		model:      conf.EmbeddingModel,
+		shared:     conf.Scope == cacheScopeGlobal,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+	if c.embedding != "" {
+		if c.model == "" {
+			return nil, errors.New("embedding model is required")
+		}
+		if c.threshold <= 0 || c.threshold > 1 {
+			c.threshold = 0.95
+		}
+	}
+	return c, nil
+}
+
+// partition 缓存的隔离范围，未共享时为调用方的应用ID
+func (c *responseCache) partition(ctx http_context.IHttpContext) string {
+	if c.shared {
+		return ""
+	}
+	return ctx.GetLabel("application_id")
+}
+
+// cacheRequest 一次请求的缓存键，scope为调用方范围、目标模型及配置，key在scope基础上加入规范化后的消息
+type cacheRequest struct {
+	scope  string
+	key    string
+	prompt string
+	vector []float64
+}
+
+func newCacheRequest(t *target, request *ai_provider.ClientRequest, partition string) *cacheRequest {
+	config, _ := json.Marshal(t.extender)
+	tools, _ := json.Marshal(struct {
+		Tools      []*ai_provider.Tool     `json:"tools"`
+		ToolChoice *ai_provider.ToolChoice `json:"tool_choice"`
+	}{request.Tools, request.ToolChoice})
+	scope := hash(partition, t.provider, t.model, string(config), string(tools))
+	prompt := normalizeMessages(request.Messages)
+	return &cacheRequest{scope: scope, key: hash(scope, prompt), prompt: prompt}
+}
+
+// normalizeMessages 合并连续空白并去除首尾空白，使仅有格式差异的提示词命中同一缓存，图片、音频及工具调用按原样加入
+func normalizeMessages(messages []*ai_provider.Message) string {
+	builder := strings.Builder{}
+	for _, m := range messages {
+		if m == nil {
+			continue
+		}
+		builder.WriteString(strings.ToLower(m.Role))
+		builder.WriteString(": ")
+		builder.WriteString(strings.Join(strings.Fields(m.Content), " "))
+		builder.WriteString("\n")
+		for _, p := range m.Parts {
+			if p.Type != ai_provider.PartText {
+				data, _ := json.Marshal(p)
+				builder.Write(data)
+				builder.WriteString("\n")
+			}
+		}
+		if len(m.ToolCalls) > 0 || m.ToolCallID != "" {
+			data, _ := json.Marshal(struct {
+				ToolCalls  []*ai_provider.ToolCall `json:"tool_calls"`
+				ToolCallID string                  `json:"tool_call_id"`
+			}{m.ToolCalls, m.ToolCallID})
+			builder.Write(data)
+			builder.WriteString("\n")
+		}
+	}
+	return builder.String()
+}
+
+func hash(values ...string) string {
+	h := sha256.New()
+	for _, v := range values {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// lookup 先按缓存键精确匹配，未命中且配置了向量模型时按相似度匹配，返回缓存的响应及命中方式
+func (c *responseCache) lookup(ctx http_context.IHttpContext, req *cacheRequest) ([]byte, string) {
+	if body, has := c.get(req.key); has {
+		return body, cacheHit
+	}
+	if c.embedding == "" {
+		return nil, cacheMiss
+	}
+	vector, err := c.embed(ctx.Context(), req.prompt)
+	if err != nil {
+		log.Warnf("ai cache embedding error: %v", err)
+		return nil, cacheMiss
+	}
+	req.vector = vector
+	if body, has := c.similar(req.scope, vector); has {
+		return body, cacheSimilar
+	}
+	return nil, cacheMiss
+}
+
+func (c *responseCache) embed(ctx context.Context, prompt string) ([]float64, error) {
+	v, has := convert.Get(c.embedding)
+	if !has {
+		return nil, errors.New("embedding provider not found")
+	}
+	driver, ok := v.(ai_provider.IEmbeddingDriver)
+	if !ok {
+		return nil, errors.New("provider not support embeddings")
+	}
+	vectors, err := driver.Embeddings(ctx, c.model, []string{prompt})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+func (c *responseCache) get(key string) ([]byte, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	elem, has := c.entries[key]
+	if !has {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expire) {
+		c.remove(elem)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return entry.body, true
+}
+
+// similar 在同一模型配置的缓存中查找相似度最高且不低于阈值的响应
+func (c *responseCache) similar(scope string, vector []float64) ([]byte, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := time.Now()
+	var (
+		best  *list.Element
+		score float64
+	)
+	for elem := c.lru.Front(); elem != nil; {
+		next := elem.Next()
+		entry := elem.Value.(*cacheEntry)
+		if now.After(entry.expire) {
+			c.remove(elem)
+		} else if entry.scope == scope && entry.vector != nil {
+			if s := cosine(vector, entry.vector); s >= c.threshold && s > score {
+				best, score = elem, s
+			}
+		}
+		elem = next
+	}
+	if best == nil {
+		return nil, false
+	}
+	c.lru.MoveToFront(best)
+	return best.Value.(*cacheEntry).body, true
+}
+
+func (c *responseCache) set(req *cacheRequest, body []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if elem, has := c.entries[req.key]; has {
+		c.remove(elem)
+	}
+	entry := &cacheEntry{
+		key:    req.key,
+		scope:  req.scope,
+		vector: req.vector,
+		body:   append([]byte(nil), body...),
+		expire: time.Now().Add(c.ttl),
+	}
+	c.entries[req.key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *responseCache) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*cacheEntry).key)
+}
+
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// writeCached 返回缓存的响应，客户端请求流式返回时以单个事件返回
+func writeCached(ctx http_context.IHttpContext, body []byte, stream bool) {
+	if stream {
+		buf := make([]byte, 0, len(body)+32)
+		buf = append(buf, "data: "...)
+		buf = append(buf, body...)
+		buf = append(buf, "\n\ndata: [DONE]\n\n"...)
+		ctx.Response().SetHeader("Content-Type", "text/event-stream")
+		ctx.Response().SetBody(buf)
+	} else {
+		ctx.Response().SetHeader("Content-Type", "application/json")
+		ctx.Response().SetBody(body)
+	}
+	ctx.Response().SetStatus(200, "200")
+}
@@ -0,0 +1,111 @@
+package ai_formatter
+
+import (
+	"testing"
+
+	"github.com/eolinker/apinto/convert"
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+	node_http_context "github.com/eolinker/apinto/node/http-context"
+	http_context "github.com/eolinker/eosc/eocontext/http-context"
+	"github.com/valyala/fasthttp"
+)
+
+func TestResponseCache(t *testing.T) {
+	cache, _ := newResponseCache(&CacheConfig{TTL: 60, MaxEntries: 2, EmbeddingProvider: "embedding", EmbeddingModel: "text-embedding-3-small"})
+	tg := &target{provider: "openai", model: "gpt-4o", extender: map[string]interface{}{"temperature": 0.2}}
+	request := func(content string) *cacheRequest {
+		return newCacheRequest(tg, &ai_provider.ClientRequest{Messages: []*ai_provider.Message{{Role: "user", Content: content}}}, "")
+	}
+
+	first := request("what is  apinto?\n")
+	first.vector = []float64{1, 0, 0}
+	cache.set(first, []byte("a"))
+	// 仅空白不同的提示词精确命中
+	if body, ok := cache.get(request(" what is apinto? ").key); !ok || string(body) != "a" {
+		t.Errorf("expect exact hit, got %s %v", body, ok)
+	}
+	if body, ok := cache.similar(first.scope, []float64{0.99, 0.05, 0}); !ok || string(body) != "a" {
+		t.Errorf("expect similar hit, got %s %v", body, ok)
+	}
+	if _, ok := cache.similar(first.scope, []float64{0, 1, 0}); ok {
+		t.Error("expect similar miss")
+	}
+	// 模型配置不同时不命中
+	other := &target{provider: "openai", model: "gpt-4o", extender: map[string]interface{}{"temperature": 1}}
+	if _, ok := cache.similar(newCacheRequest(other, &ai_provider.ClientRequest{}, "").scope, []float64{1, 0, 0}); ok {
+		t.Error("expect miss with another model config")
+	}
+
+	cache.set(request("b"), []byte("b"))
+	cache.set(request("c"), []byte("c"))
+	if _, ok := cache.get(first.key); ok {
+		t.Error("expect least recently used entry evicted")
+	}
+}
+
+func newTestContext(application string) http_context.IHttpContext {
+	ctx := node_http_context.NewContext(new(fasthttp.RequestCtx), 0)
+	ctx.SetLabel("application_id", application)
+	return ctx
+}
+
+func TestResponseCacheLookup(t *testing.T) {
+	convert.Set("embedding@ai-provider", &testProvider{vectors: map[string][]float64{
+		"user: what is apinto?\n": {1, 0},
+		"user: what's apinto?\n":  {0.99, 0.05},
+		"user: how to install?\n": {0, 1},
+	}})
+	defer convert.Del("embedding@ai-provider")
+	cache, err := newResponseCache(&CacheConfig{TTL: 60, EmbeddingProvider: "embedding@ai-provider", EmbeddingModel: "embedding"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tg := &target{provider: "openai", model: "gpt-4o"}
+	request := func(ctx http_context.IHttpContext, content string) *cacheRequest {
+		return newCacheRequest(tg, &ai_provider.ClientRequest{Messages: []*ai_provider.Message{{Role: "user", Content: content}}}, cache.partition(ctx))
+	}
+
+	app := newTestContext("app-a")
+	first := request(app, "what is apinto?")
+	if body, status := cache.lookup(app, first); body != nil || status != cacheMiss {
+		t.Fatalf("expect miss, got %s %s", body, status)
+	}
+	cache.set(first, []byte(`{"message":{"role":"assistant","content":"gateway"}}`))
+
+	tests := []struct {
+		ctx     http_context.IHttpContext
+		content string
+		status  string
+	}{
+		{ctx: app, content: "what is  apinto?", status: cacheHit},
+		{ctx: app, content: "what's apinto?", status: cacheSimilar},
+		{ctx: app, content: "how to install?", status: cacheMiss},
+		// 其他应用不命中
+		{ctx: newTestContext("app-b"), content: "what is apinto?", status: cacheMiss},
+		{ctx: newTestContext("app-b"), content: "what's apinto?", status: cacheMiss},
+	}
+	for _, tt := range tests {
+		if _, status := cache.lookup(tt.ctx, request(tt.ctx, tt.content)); status != tt.status {
+			t.Errorf("%s: got %s, want %s", tt.content, status, tt.status)
+		}
+	}
+
+	body, _ := cache.lookup(app, request(app, "what is apinto?"))
+	ctx := newTestContext("app-a")
+	writeCached(ctx, body, true)
+	if got := string(ctx.Response().GetBody()); got != "data: "+string(body)+"\n\ndata: [DONE]\n\n" || ctx.Response().GetHeader("Content-Type") != "text/event-stream" {
+		t.Errorf("unexpected stream response: %s", got)
+	}
+	writeCached(ctx, body, false)
+	if got := string(ctx.Response().GetBody()); got != string(body) || ctx.Response().StatusCode() != 200 {
+		t.Errorf("unexpected response: %s", got)
+	}
+
+	// 共享范围时不同应用命中同一缓存
+	shared, _ := newResponseCache(&CacheConfig{TTL: 60, Scope: cacheScopeGlobal})
+	shared.set(newCacheRequest(tg, &ai_provider.ClientRequest{Messages: []*ai_provider.Message{{Role: "user", Content: "hi"}}}, shared.partition(app)), []byte("a"))
+	other := newTestContext("app-b")
+	if _, status := shared.lookup(other, newCacheRequest(tg, &ai_provider.ClientRequest{Messages: []*ai_provider.Message{{Role: "user", Content: "hi"}}}, shared.partition(other))); status != cacheHit {
+		t.Errorf("expect shared hit, got %s", status)
+	}
+}
@@ -5,6 +5,25 @@ import (
 )
 
 type Config struct {
+	Provider  eosc.RequireId `json:"provider" skill:"github.com/eolinker/apinto/convert.convert.IConverterDriver"`
+	Model     string         `json:"model"`
+	Config    string         `json:"config"`
+	Fallbacks []*Target      `json:"fallbacks" label:"备用模型" description:"上游返回429、5xx或请求失败时按顺序切换到备用模型"`
+	Cache     *CacheConfig   `json:"cache" label:"响应缓存"`
+}
+
+// CacheConfig 响应缓存，相同提示词及模型配置的请求直接返回缓存的响应，配置向量模型后相似的提示词也可命中
+type CacheConfig struct {
+	TTL               int            `json:"ttl" label:"缓存时间" description:"单位：秒，为0时不开启缓存"`
+	MaxEntries        int            `json:"max_entries" label:"最大缓存条数" default:"1000"`
+	EmbeddingProvider eosc.RequireId `json:"embedding_provider" skill:"github.com/eolinker/apinto/convert.convert.IConverterDriver" required:"false" label:"向量模型供应商" description:"配置后开启相似度缓存"`
+	EmbeddingModel    string         `json:"embedding_model" label:"向量模型" switch:"embedding_provider!==''"`
+	Threshold         float64        `json:"threshold" label:"相似度阈值" default:"0.95" description:"0-1，余弦相似度不低于阈值时返回缓存的响应" switch:"embedding_provider!==''"`
+	Scope             string         `json:"scope" label:"缓存范围" enum:"application,global" default:"application" description:"application：按调用方应用隔离缓存；global：所有调用方共享缓存"`
+}
+
+// Target 备用的供应商及模型
+type Target struct {
 	Provider eosc.RequireId `json:"provider" skill:"github.com/eolinker/apinto/convert.convert.IConverterDriver"`
 	Model    string         `json:"model"`
 	Config   string         `json:"config"`
@@ -1,18 +1,28 @@
 package ai_formatter
 
 import (
+	"encoding/json"
 	"errors"
 
 	"github.com/eolinker/apinto/convert"
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
 
 	"github.com/eolinker/apinto/drivers"
+	node_http_context "github.com/eolinker/apinto/node/http-context"
 	"github.com/eolinker/eosc"
 	"github.com/eolinker/eosc/eocontext"
 	http_context "github.com/eolinker/eosc/eocontext/http-context"
+	"github.com/eolinker/eosc/log"
 )
 
 type executor struct {
 	drivers.WorkerBase
+	targets []*target
+	cache   *responseCache
+}
+
+// target 一个供应商及模型，按配置顺序依次尝试
+type target struct {
 	provider string
 	model    string
 	extender map[string]interface{}
@@ -23,25 +33,137 @@ func (e *executor) DoFilter(ctx eocontext.EoContext, next eocontext.IChain) (err
 }
 
 func (e *executor) DoHttpFilter(ctx http_context.IHttpContext, next eocontext.IChain) error {
-	v, has := convert.Get(e.provider)
-	if !has {
-		return errors.New("provider not implement IConverterDriver")
+	request := clientRequest(ctx)
+	stream := request != nil && request.Stream
+	var snapshot *requestSnapshot
+	if len(e.targets) > 1 {
+		snapshot = newRequestSnapshot(ctx)
 	}
-	converter, has := v.GetConverter(e.model)
-	if !has {
-		return errors.New("invalid model")
+	var (
+		converter convert.IConverter
+		recorder  *usageRecorder
+		cacheReq  *cacheRequest
+		served    int
+		err       error
+	)
+	for i, t := range e.targets {
+		v, has := convert.Get(t.provider)
+		if !has {
+			return errors.New("provider not implement IConverterDriver")
+		}
+		converter, has = v.GetConverter(t.model)
+		if !has {
+			return errors.New("invalid model")
+		}
+		if i > 0 {
+			snapshot.restore(ctx)
+		}
+		recorder = newUsageRecorder(ctx, e.label(ctx, v, t))
+		if i == 0 {
+			// 仅缓存对话请求
+			if e.cache != nil && request != nil && len(request.Messages) > 0 {
+				cacheReq = newCacheRequest(t, request, e.cache.partition(ctx))
+				body, status := e.cache.lookup(ctx, cacheReq)
+				ctx.SetLabel("ai_cache", status)
+				if body != nil {
+					writeCached(ctx, body, stream)
+					return nil
+				}
+			}
+			err = ai_provider.AcquireTokens(ctx, ai_provider.EstimateTokens(request))
+			if err != nil {
+				return err
+			}
+		}
+		err = converter.RequestConvert(ctx, t.extender)
+		if err != nil {
+			return err
+		}
+		err = e.send(ctx, next, i)
+		served = i
+		ai_provider.ReleaseKey(ctx, ctx.Response().StatusCode(), ctx.Response().GetHeader("Retry-After"))
+		if i == len(e.targets)-1 || !retryable(ctx, err) {
+			break
+		}
+		log.Warnf("ai target %s/%s failed, status: %d, error: %v, try next target", t.provider, t.model, ctx.Response().StatusCode(), err)
 	}
-	err := converter.RequestConvert(ctx, e.extender)
 	if err != nil {
 		return err
 	}
-	if next != nil {
-		err = next.DoChain(ctx)
+	if stream {
+		err = converter.StreamConvert(ctx)
 		if err != nil {
 			return err
 		}
+		if sw, ok := ctx.(node_http_context.IResponseStreamWrapper); !ok || !sw.WrapResponseStream(recorder.wrap) {
+			recorder.scan(ctx.Response().GetBody())
+		}
+		return nil
+	}
+	err = converter.ResponseConvert(ctx)
+	if err != nil {
+		return err
+	}
+	if ctx.Response().StatusCode() == 200 {
+		body := ctx.Response().GetBody()
+		recorder.recordBody(body)
+		// 仅缓存主模型的响应，备用模型的响应不代表该模型配置的结果
+		if cacheReq != nil && served == 0 {
+			e.cache.set(cacheReq, body)
+		}
+	}
+	return nil
+}
+
+// send 首个模型沿插件链转发，切换备用模型时插件链已执行，直接重新转发
+func (e *executor) send(ctx http_context.IHttpContext, next eocontext.IChain, index int) error {
+	if index == 0 {
+		if next != nil {
+			return next.DoChain(ctx)
+		}
+		return nil
+	}
+	return ctx.GetComplete().Complete(ctx)
+}
+
+// label 写入供应商、模型及实际请求的目标标签，返回模型的计费信息
+func (e *executor) label(ctx http_context.IHttpContext, driver convert.IConverterDriver, t *target) *ai_provider.Pricing {
+	provider := t.provider
+	p, ok := driver.(ai_provider.IProvider)
+	if ok {
+		provider = p.Provider()
+	}
+	ctx.SetLabel("ai_provider", provider)
+	ctx.SetLabel("ai_model", t.model)
+	ctx.SetLabel("ai_target", t.provider+"/"+t.model)
+	if source, ok := driver.(ai_provider.IModelSource); ok {
+		if model, has := source.Model(t.model); has {
+			return model.Pricing
+		}
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+	model, has := ai_provider.GetModel(provider, t.model)
+	if !has {
+		return nil
+	}
+	return model.Pricing
+}
+
+// clientRequest 读取客户端的统一格式请求，需在请求转换前读取，格式错误时返回nil
+func clientRequest(ctx http_context.IHttpContext) *ai_provider.ClientRequest {
+	body, err := ctx.Proxy().Body().RawBody()
+	if err != nil {
+		return nil
+	}
+	request := new(ai_provider.ClientRequest)
+	err = json.Unmarshal(body, request)
+	if err != nil {
+		return nil
 	}
-	return converter.ResponseConvert(ctx)
+	return request
 }
 
 func (e *executor) Destroy() {
@@ -52,31 +174,53 @@ func (e *executor) Start() error {
 }
 
 func (e *executor) Reset(conf interface{}, workers map[eosc.RequireId]eosc.IWorker) error {
-	return nil
+	cfg, err := checkConfig(conf)
+	if err != nil {
+		return err
+	}
+	return e.reset(cfg, workers)
 }
 
 func (e *executor) reset(cfg *Config, workers map[eosc.RequireId]eosc.IWorker) error {
-	v, has := convert.Get(string(cfg.Provider))
+	targets := make([]*target, 0, len(cfg.Fallbacks)+1)
+	for _, t := range append([]*Target{{Provider: cfg.Provider, Model: cfg.Model, Config: cfg.Config}}, cfg.Fallbacks...) {
+		if t == nil {
+			continue
+		}
+		tg, err := newTarget(t)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, tg)
+	}
+	cache, err := newResponseCache(cfg.Cache)
+	if err != nil {
+		return err
+	}
+	e.targets = targets
+	e.cache = cache
+	return nil
+}
+
+func newTarget(t *Target) (*target, error) {
+	v, has := convert.Get(string(t.Provider))
 	if !has {
-		return errors.New("provider not implement IConverterDriver")
+		return nil, errors.New("provider not implement IConverterDriver")
 	}
-	_, has = v.GetConverter(cfg.Model)
+	_, has = v.GetConverter(t.Model)
 	if !has {
-		return errors.New("invalid model")
+		return nil, errors.New("invalid model")
 	}
-	f, has := v.GetModel(cfg.Model)
+	f, has := v.GetModel(t.Model)
 	if !has {
-		return errors.New("invalid model")
+		return nil, errors.New("invalid model")
 	}
 
-	extender, err := f(cfg.Config)
+	extender, err := f(t.Config)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	e.provider = string(cfg.Provider)
-	e.model = cfg.Model
-	e.extender = extender
-	return nil
+	return &target{provider: string(t.Provider), model: t.Model, extender: extender}, nil
 }
 
 func (e *executor) Stop() error {
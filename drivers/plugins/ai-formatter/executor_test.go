@@ -0,0 +1,54 @@
+package ai_formatter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eolinker/apinto/convert"
+	"github.com/eolinker/eosc"
+)
+
+// testProvider 按输入文本返回预设向量的供应商
+type testProvider struct {
+	vectors map[string][]float64
+}
+
+func (p *testProvider) GetModel(model string) (convert.FGenerateConfig, bool) {
+	return func(cfg string) (map[string]interface{}, error) {
+		return map[string]interface{}{"model": model}, nil
+	}, true
+}
+
+func (p *testProvider) GetConverter(model string) (convert.IConverter, bool) {
+	return nil, true
+}
+
+func (p *testProvider) Embeddings(ctx context.Context, model string, inputs []string) ([][]float64, error) {
+	result := make([][]float64, 0, len(inputs))
+	for _, input := range inputs {
+		result = append(result, p.vectors[input])
+	}
+	return result, nil
+}
+
+func TestExecutorReset(t *testing.T) {
+	convert.Set("test@ai-provider", &testProvider{})
+	defer convert.Del("test@ai-provider")
+	w, err := Create("formatter@plugin", "formatter", &Config{Provider: "test@ai-provider", Model: "gpt-4o"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := w.(*executor)
+	err = e.Reset(&Config{
+		Provider:  "test@ai-provider",
+		Model:     "gpt-4o",
+		Fallbacks: []*Target{{Provider: "test@ai-provider", Model: "gpt-4o-mini"}},
+		Cache:     &CacheConfig{TTL: 60},
+	}, map[eosc.RequireId]eosc.IWorker{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.targets) != 2 || e.targets[1].model != "gpt-4o-mini" || e.cache == nil {
+		t.Errorf("config not applied: %+v, cache %v", e.targets, e.cache)
+	}
+}
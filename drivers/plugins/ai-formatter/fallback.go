@@ -0,0 +1,58 @@
+package ai_formatter
+
+import (
+	"net/http"
+
+	"github.com/eolinker/eosc/eocontext"
+	http_context "github.com/eolinker/eosc/eocontext/http-context"
+)
+
+// requestSnapshot 请求转换前的转发请求，切换备用模型时恢复，避免上一个供应商的路径及鉴权头部残留
+type requestSnapshot struct {
+	method   string
+	path     string
+	rawQuery string
+	headers  http.Header
+	body     []byte
+	balance  eocontext.BalanceHandler
+}
+
+func newRequestSnapshot(ctx http_context.IHttpContext) *requestSnapshot {
+	body, _ := ctx.Proxy().Body().RawBody()
+	return &requestSnapshot{
+		method:   ctx.Proxy().Method(),
+		path:     ctx.Proxy().URI().Path(),
+		rawQuery: ctx.Proxy().URI().RawQuery(),
+		headers:  ctx.Proxy().Header().Headers().Clone(),
+		body:     append([]byte(nil), body...),
+		balance:  ctx.GetBalance(),
+	}
+}
+
+func (s *requestSnapshot) restore(ctx http_context.IHttpContext) {
+	ctx.Proxy().SetMethod(s.method)
+	ctx.Proxy().URI().SetPath(s.path)
+	ctx.Proxy().URI().SetRawQuery(s.rawQuery)
+	header := ctx.Proxy().Header()
+	for key := range header.Headers() {
+		if _, has := s.headers[key]; !has {
+			header.DelHeader(key)
+		}
+	}
+	for key, values := range s.headers {
+		if len(values) > 0 {
+			header.SetHeader(key, values[0])
+		}
+	}
+	ctx.Proxy().Body().SetRaw(s.headers.Get("Content-Type"), s.body)
+	ctx.SetBalance(s.balance)
+}
+
+// retryable 上游被限流、服务端错误或请求失败（如超时）时切换备用模型
+func retryable(ctx http_context.IHttpContext, err error) bool {
+	if err != nil {
+		return true
+	}
+	status := ctx.Response().StatusCode()
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
@@ -0,0 +1,84 @@
+package ai_formatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+	http_context "github.com/eolinker/eosc/eocontext/http-context"
+)
+
+var (
+	streamDataPrefix = []byte("data: ")
+	usageField       = []byte(`"usage"`)
+)
+
+// usageRecorder 将转换后响应中的token用量及按模型单价计算的费用写入标签，供访问日志及监控指标读取
+type usageRecorder struct {
+	ctx     http_context.IHttpContext
+	pricing *ai_provider.Pricing
+	usage   ai_provider.Usage
+	pending []byte
+}
+
+func newUsageRecorder(ctx http_context.IHttpContext, pricing *ai_provider.Pricing) *usageRecorder {
+	return &usageRecorder{ctx: ctx, pricing: pricing}
+}
+
+func (r *usageRecorder) record(usage *ai_provider.Usage) {
+	if usage == nil {
+		return
+	}
+	r.usage.Merge(usage)
+	r.ctx.SetLabel("ai_prompt_tokens", strconv.Itoa(r.usage.PromptTokens))
+	r.ctx.SetLabel("ai_completion_tokens", strconv.Itoa(r.usage.CompletionTokens))
+	r.ctx.SetLabel("ai_total_tokens", strconv.Itoa(r.usage.TotalTokens))
+	if r.pricing != nil {
+		r.ctx.SetLabel("ai_cost", strconv.FormatFloat(r.pricing.Cost(&r.usage), 'f', -1, 64))
+		r.ctx.SetLabel("ai_currency", r.pricing.Currency)
+	}
+}
+
+// recordBody 读取完整响应中的用量
+func (r *usageRecorder) recordBody(body []byte) {
+	response := new(ai_provider.ClientResponse)
+	if json.Unmarshal(body, response) == nil {
+		r.record(response.Usage)
+	}
+}
+
+// scan 按行读取统一SSE格式的响应块，记录其中的用量
+func (r *usageRecorder) scan(data []byte) {
+	r.pending = append(r.pending, data...)
+	offset := 0
+	for {
+		i := bytes.IndexByte(r.pending[offset:], '\n')
+		if i < 0 {
+			break
+		}
+		line := r.pending[offset : offset+i]
+		offset += i + 1
+		if bytes.HasPrefix(line, streamDataPrefix) && bytes.Contains(line, usageField) {
+			r.recordBody(line[len(streamDataPrefix):])
+		}
+	}
+	r.pending = append(r.pending[:0], r.pending[offset:]...)
+}
+
+func (r *usageRecorder) wrap(reader io.Reader) io.Reader {
+	return &usageReader{reader: reader, recorder: r}
+}
+
+// usageReader 转发流式响应的同时记录用量
+type usageReader struct {
+	reader   io.Reader
+	recorder *usageRecorder
+}
+
+func (u *usageReader) Read(p []byte) (int, error) {
+	n, err := u.reader.Read(p)
+	u.recorder.scan(p[:n])
+	return n, err
+}
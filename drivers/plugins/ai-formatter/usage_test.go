@@ -0,0 +1,66 @@
+package ai_formatter
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+	http_context "github.com/eolinker/apinto/node/http-context"
+	"github.com/valyala/fasthttp"
+)
+
+func TestUsageRecorderStream(t *testing.T) {
+	ctx := http_context.NewContext(new(fasthttp.RequestCtx), 8080)
+	r := newUsageRecorder(ctx, &ai_provider.Pricing{Input: "2", Output: "8", Unit: "0.000001", Currency: "USD"})
+	// 输入用量与输出用量分别在两个响应块中返回，最后一行没有换行符时不解析
+	stream := "data: {\"message\":{\"role\":\"assistant\",\"content\":\"hi\"}}\n\n" +
+		"data: {\"message\":{\"content\":\"\"},\"usage\":{\"prompt_tokens\":100}}\n\n" +
+		": keep-alive\n" +
+		"data: {\"message\":{\"content\":\"\"},\"finish_reason\":\"stop\",\"usage\":{\"completion_tokens\":50}}\n\n" +
+		"data: [DONE]\n\n" +
+		"data: {\"usage\":{\"prompt_tokens\":999}}"
+	// 逐字节读取，用量所在的行被拆分到多次读取中
+	data, err := io.ReadAll(r.wrap(iotest.OneByteReader(strings.NewReader(stream))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != stream {
+		t.Fatal("stream content changed")
+	}
+	want := map[string]string{
+		"ai_prompt_tokens":     "100",
+		"ai_completion_tokens": "50",
+		"ai_total_tokens":      "150",
+		"ai_cost":              "0.0006",
+		"ai_currency":          "USD",
+	}
+	for label, value := range want {
+		if got := ctx.GetLabel(label); got != value {
+			t.Errorf("label %s: %q, want %q", label, got, value)
+		}
+	}
+}
+
+func TestUsageRecorderBody(t *testing.T) {
+	ctx := http_context.NewContext(new(fasthttp.RequestCtx), 8080)
+	r := newUsageRecorder(ctx, nil)
+	r.recordBody([]byte(`{"message":{"role":"assistant","content":"hi"},"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":20}}`))
+	if ctx.GetLabel("ai_total_tokens") != "20" || ctx.GetLabel("ai_prompt_tokens") != "10" || ctx.GetLabel("ai_completion_tokens") != "5" {
+		t.Errorf("unexpected labels %v", ctx.Labels())
+	}
+	// 未配置单价时不记录费用
+	if ctx.GetLabel("ai_cost") != "" || ctx.GetLabel("ai_currency") != "" {
+		t.Errorf("cost recorded without pricing: %v", ctx.Labels())
+	}
+
+	ctx = http_context.NewContext(new(fasthttp.RequestCtx), 8080)
+	r = newUsageRecorder(ctx, nil)
+	r.recordBody([]byte(`{"message":{"content":"hi"}}`))
+	r.recordBody([]byte(`not json`))
+	r.record(nil)
+	if len(ctx.Labels()) != 0 {
+		t.Errorf("labels recorded without usage: %v", ctx.Labels())
+	}
+}
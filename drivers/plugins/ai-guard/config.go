@@ -0,0 +1,37 @@
+package ai_guard
+
+import (
+	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/apinto/drivers/strategy/data-mask-strategy/mask"
+	"github.com/eolinker/eosc"
+)
+
+const (
+	actionMask  = "mask"
+	actionBlock = "block"
+)
+
+type Config struct {
+	Rules         []*Rule `json:"rules" label:"检查规则" description:"按顺序检查对话消息的文本内容及工具调用参数"`
+	CheckResponse bool    `json:"check_response" label:"检查模型回复" description:"按相同规则检查模型返回的消息；流式返回时每段内容保留末尾64个字符与后续增量拼接后检查，超过该长度的匹配内容不保证命中"`
+	StatusCode    int     `json:"status_code" label:"拦截状态码" default:"400" minimum:"100" maximum:"599"`
+}
+
+type Rule struct {
+	Name   string          `json:"name" label:"规则名称" description:"命中时写入标签，为空时使用匹配类型:匹配值"`
+	Match  *mask.BasicItem `json:"match" label:"匹配规则" description:"支持inner（name、phone、id-card、bank-card等）、keyword、regex，inner的name仅匹配JSON内容中的name、cname字段"`
+	Action string          `json:"action" label:"处理方式" enum:"mask,block" default:"mask" description:"mask：脱敏后继续转发；block：拒绝请求"`
+	Mask   *mask.Mask      `json:"mask" label:"脱敏方式" switch:"action==='mask'"`
+}
+
+func Create(id, name string, conf *Config, workers map[eosc.RequireId]eosc.IWorker) (eosc.IWorker, error) {
+	h, err := newHandler(conf)
+	if err != nil {
+		return nil, err
+	}
+	e := &executor{
+		WorkerBase: drivers.Worker(id, name),
+	}
+	e.handler.Store(h)
+	return e, nil
+}
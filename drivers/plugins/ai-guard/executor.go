@@ -0,0 +1,122 @@
+package ai_guard
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/eolinker/apinto/drivers"
+	node_http_context "github.com/eolinker/apinto/node/http-context"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/eocontext"
+	http_context "github.com/eolinker/eosc/eocontext/http-context"
+)
+
+var _ eocontext.IFilter = (*executor)(nil)
+var _ http_context.HttpFilter = (*executor)(nil)
+
+type executor struct {
+	drivers.WorkerBase
+	handler atomic.Pointer[handler]
+}
+
+func (e *executor) DoFilter(ctx eocontext.EoContext, next eocontext.IChain) error {
+	return http_context.DoHttpFilter(e, ctx, next)
+}
+
+// DoHttpFilter 需配置在ai_formatter之前，转发前检查客户端的统一格式请求，转换后检查模型的回复
+func (e *executor) DoHttpFilter(ctx http_context.IHttpContext, next eocontext.IChain) error {
+	h := e.handler.Load()
+	body, err := ctx.Proxy().Body().RawBody()
+	if err != nil {
+		return err
+	}
+	matched := make(hits, 0)
+	target, blocked, err := h.checkRequest(body, &matched)
+	if err != nil {
+		return err
+	}
+	if blocked != nil {
+		ctx.SetLabel("ai_guard_blocked", blocked.name)
+		ctx.Response().SetStatus(h.statusCode, strconv.Itoa(h.statusCode))
+		ctx.Response().SetHeader("Content-Type", "application/json")
+		ctx.Response().SetBody(blockedBody(blocked))
+		return blockedError(blocked)
+	}
+	if len(matched) > 0 {
+		ctx.SetLabel("ai_guard_request", matched.String())
+		ctx.Proxy().Body().SetRaw("application/json", target)
+	}
+	if next != nil {
+		err = next.DoChain(ctx)
+	}
+	if err != nil || !h.scanResponse || ctx.Response().StatusCode() != 200 {
+		return err
+	}
+	return h.guardResponse(ctx)
+}
+
+// guardResponse 检查转换后的模型回复，流式返回时包装数据流逐个响应块检查
+func (h *handler) guardResponse(ctx http_context.IHttpContext) error {
+	g := &responseGuard{ctx: ctx, handler: h}
+	if sw, ok := ctx.(node_http_context.IResponseStreamWrapper); ok && sw.WrapResponseStream(g.wrap) {
+		return nil
+	}
+	body := ctx.Response().GetBody()
+	if bytes.HasPrefix(body, streamDataPrefix) {
+		// 未以数据流转发的流式响应（如缓存命中）按完整内容逐块检查
+		data, err := io.ReadAll(g.wrap(bytes.NewReader(body)))
+		if err != nil {
+			return err
+		}
+		ctx.Response().SetBody(data)
+		return nil
+	}
+	target, blocked, err := h.checkResponse(body, &g.matched)
+	if err != nil {
+		return err
+	}
+	if blocked != nil {
+		g.block(blocked)
+		ctx.Response().SetStatus(h.statusCode, strconv.Itoa(h.statusCode))
+		ctx.Response().SetHeader("Content-Type", "application/json")
+		ctx.Response().SetBody(blockedBody(blocked))
+		return nil
+	}
+	if len(g.matched) > 0 {
+		g.label()
+		ctx.Response().SetBody(target)
+	}
+	return nil
+}
+
+func (e *executor) Start() error {
+	return nil
+}
+
+func (e *executor) Reset(conf interface{}, workers map[eosc.RequireId]eosc.IWorker) error {
+	cfg, ok := conf.(*Config)
+	if !ok {
+		return errors.New("invalid config")
+	}
+	h, err := newHandler(cfg)
+	if err != nil {
+		return err
+	}
+	e.handler.Store(h)
+	return nil
+}
+
+func (e *executor) Stop() error {
+	return nil
+}
+
+func (e *executor) Destroy() {
+	return
+}
+
+func (e *executor) CheckSkill(skill string) bool {
+	return http_context.FilterSkillName == skill
+}
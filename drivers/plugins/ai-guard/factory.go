@@ -0,0 +1,43 @@
+package ai_guard
+
+import (
+	"sync"
+
+	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/apinto/drivers/strategy/data-mask-strategy/mask/inner"
+	"github.com/eolinker/apinto/drivers/strategy/data-mask-strategy/mask/keyword"
+	"github.com/eolinker/apinto/drivers/strategy/data-mask-strategy/mask/regex"
+	"github.com/eolinker/apinto/plugin"
+	"github.com/eolinker/eosc"
+)
+
+const (
+	Name = "ai_guard"
+)
+
+var once sync.Once
+
+func Register(register eosc.IExtenderDriverRegister) {
+	plugin.DeclareBodyRequired(Name)
+	register.RegisterExtenderDriver(Name, NewFactory())
+}
+
+type Factory struct {
+	eosc.IExtenderDriverFactory
+}
+
+func NewFactory() *Factory {
+	return &Factory{
+		IExtenderDriverFactory: drivers.NewFactory[Config](Create),
+	}
+}
+
+func (f *Factory) Create(profession string, name string, label string, desc string, params map[string]interface{}) (eosc.IExtenderDriver, error) {
+	once.Do(func() {
+		// 内容检查复用数据脱敏策略的匹配规则
+		inner.Register()
+		keyword.Register()
+		regex.Register()
+	})
+	return f.IExtenderDriverFactory.Create(profession, name, label, desc, params)
+}
@@ -0,0 +1,263 @@
+package ai_guard
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+	"github.com/eolinker/apinto/drivers/strategy/data-mask-strategy/mask"
+)
+
+// blockMarker 拦截规则的替换内容，仅用于判断是否命中
+const blockMarker = "\x00blocked\x00"
+
+type rule struct {
+	name  string
+	block bool
+	// driver 按规则处理内容，identity 以原值替换，用于排除匹配器对内容格式的改写（如JSON重新序列化）
+	driver   mask.IMaskDriver
+	identity mask.IMaskDriver
+}
+
+func newRule(conf *Rule) (*rule, error) {
+	if conf == nil || conf.Match == nil {
+		return nil, errors.New("match is required")
+	}
+	match := conf.Match
+	switch match.Type {
+	case mask.MatchInner:
+	case mask.MatchKeyword:
+		if match.Value == "" {
+			return nil, errors.New("keyword is required")
+		}
+	case mask.MatchRegex:
+		// 正则匹配器创建时不返回错误，提前校验
+		if _, err := regexp.Compile(match.Value); err != nil {
+			return nil, fmt.Errorf("invalid regex %s: %w", match.Value, err)
+		}
+	default:
+		return nil, fmt.Errorf("match type not support: %s", match.Type)
+	}
+	fac, has := mask.GetMaskFactory(match.Type)
+	if !has {
+		return nil, fmt.Errorf("match type not found: %s", match.Type)
+	}
+	r := &rule{name: conf.Name, block: conf.Action == actionBlock}
+	if r.name == "" {
+		r.name = match.Type + ":" + match.Value
+	}
+	var maskFunc mask.MaskFunc
+	if r.block {
+		maskFunc = func(origin string) string {
+			return blockMarker
+		}
+	} else {
+		if conf.Mask == nil {
+			return nil, fmt.Errorf("rule %s: mask is required", r.name)
+		}
+		var err error
+		maskFunc, err = mask.GenMaskFunc(conf.Mask)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	r.driver, err = fac.Create(&mask.Rule{Match: match, Mask: conf.Mask}, maskFunc)
+	if err != nil {
+		return nil, err
+	}
+	r.identity, err = fac.Create(&mask.Rule{Match: match, Mask: conf.Mask}, func(origin string) string {
+		return origin
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// match 返回规则处理后的内容及是否命中
+func (r *rule) match(text string) (string, bool) {
+	if text == "" {
+		return text, false
+	}
+	out, err := r.driver.Exec([]byte(text))
+	if err != nil || string(out) == text {
+		return text, false
+	}
+	origin, err := r.identity.Exec([]byte(text))
+	if err != nil || bytes.Equal(out, origin) {
+		return text, false
+	}
+	return string(out), true
+}
+
+type handler struct {
+	rules        []*rule
+	scanResponse bool
+	statusCode   int
+}
+
+func newHandler(conf *Config) (*handler, error) {
+	h := &handler{
+		scanResponse: conf.CheckResponse,
+		statusCode:   conf.StatusCode,
+		rules:        make([]*rule, 0, len(conf.Rules)),
+	}
+	if h.statusCode < 100 || h.statusCode > 599 {
+		h.statusCode = 400
+	}
+	for _, c := range conf.Rules {
+		r, err := newRule(c)
+		if err != nil {
+			return nil, err
+		}
+		h.rules = append(h.rules, r)
+	}
+	return h, nil
+}
+
+// hits 命中的规则名称，按命中顺序去重
+type hits []string
+
+func (h *hits) add(name string) {
+	for _, n := range *h {
+		if n == name {
+			return
+		}
+	}
+	*h = append(*h, name)
+}
+
+func (h hits) String() string {
+	return strings.Join(h, ",")
+}
+
+// checkText 依次执行规则，返回脱敏后的内容，命中拦截规则时返回该规则
+func (h *handler) checkText(text string, matched *hits) (string, *rule) {
+	for _, r := range h.rules {
+		out, ok := r.match(text)
+		if !ok {
+			continue
+		}
+		if r.block {
+			return text, r
+		}
+		matched.add(r.name)
+		text = out
+	}
+	return text, nil
+}
+
+// checkMessages 检查消息的文本内容及工具调用参数，返回是否有内容被脱敏
+func (h *handler) checkMessages(messages []*ai_provider.Message, matched *hits) (bool, *rule) {
+	changed := false
+	check := func(text *string) *rule {
+		out, blocked := h.checkText(*text, matched)
+		if blocked != nil {
+			return blocked
+		}
+		if out != *text {
+			*text = out
+			changed = true
+		}
+		return nil
+	}
+	for _, m := range messages {
+		if m == nil {
+			continue
+		}
+		if len(m.Parts) > 0 {
+			for _, p := range m.Parts {
+				if p == nil || p.Type != ai_provider.PartText {
+					continue
+				}
+				if blocked := check(&p.Text); blocked != nil {
+					return false, blocked
+				}
+			}
+			m.Content = ai_provider.PartsText(m.Parts)
+		} else if blocked := check(&m.Content); blocked != nil {
+			return false, blocked
+		}
+		for _, call := range m.ToolCalls {
+			if call == nil {
+				continue
+			}
+			if blocked := check(&call.Function.Arguments); blocked != nil {
+				return false, blocked
+			}
+		}
+	}
+	return changed, nil
+}
+
+// checkRequest 检查统一格式请求中的messages，返回脱敏后的请求体，非对话请求时原样返回
+func (h *handler) checkRequest(body []byte, matched *hits) ([]byte, *rule, error) {
+	raw := make(map[string]json.RawMessage)
+	if json.Unmarshal(body, &raw) != nil {
+		return body, nil, nil
+	}
+	data, has := raw["messages"]
+	if !has {
+		return body, nil, nil
+	}
+	var messages []*ai_provider.Message
+	if json.Unmarshal(data, &messages) != nil {
+		return body, nil, nil
+	}
+	changed, blocked := h.checkMessages(messages, matched)
+	if blocked != nil || !changed {
+		return body, blocked, nil
+	}
+	data, err := json.Marshal(messages)
+	if err != nil {
+		return nil, nil, err
+	}
+	raw["messages"] = data
+	body, err = json.Marshal(raw)
+	return body, nil, err
+}
+
+// checkResponse 检查统一格式响应（或流式响应块）中的message，返回脱敏后的响应
+func (h *handler) checkResponse(body []byte, matched *hits) ([]byte, *rule, error) {
+	raw := make(map[string]json.RawMessage)
+	if json.Unmarshal(body, &raw) != nil {
+		return body, nil, nil
+	}
+	data, has := raw["message"]
+	if !has {
+		return body, nil, nil
+	}
+	message := new(ai_provider.Message)
+	if json.Unmarshal(data, message) != nil {
+		return body, nil, nil
+	}
+	changed, blocked := h.checkMessages([]*ai_provider.Message{message}, matched)
+	if blocked != nil || !changed {
+		return body, blocked, nil
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return nil, nil, err
+	}
+	raw["message"] = data
+	body, err = json.Marshal(raw)
+	return body, nil, err
+}
+
+// blockedBody 拦截时返回的响应内容
+func blockedBody(r *rule) []byte {
+	data, _ := json.Marshal(map[string]interface{}{
+		"code":  -1,
+		"error": blockedError(r).Error(),
+	})
+	return data
+}
+
+func blockedError(r *rule) error {
+	return fmt.Errorf("content blocked by ai guard rule: %s", r.name)
+}
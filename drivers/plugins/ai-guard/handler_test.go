@@ -0,0 +1,168 @@
+package ai_guard
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+	"github.com/eolinker/apinto/drivers/strategy/data-mask-strategy/mask"
+	"github.com/eolinker/apinto/drivers/strategy/data-mask-strategy/mask/inner"
+	"github.com/eolinker/apinto/drivers/strategy/data-mask-strategy/mask/keyword"
+	"github.com/eolinker/apinto/drivers/strategy/data-mask-strategy/mask/regex"
+)
+
+func testHandler(t *testing.T) *handler {
+	inner.Register()
+	keyword.Register()
+	regex.Register()
+	h, err := newHandler(&Config{
+		CheckResponse: true,
+		Rules: []*Rule{
+			{Name: "phone", Match: &mask.BasicItem{Type: mask.MatchInner, Value: mask.MatchInnerValuePhone}, Action: actionMask, Mask: &mask.Mask{Type: mask.MaskPartialMask, Begin: 3, Length: 4}},
+			{Match: &mask.BasicItem{Type: mask.MatchKeyword, Value: "password"}, Action: actionBlock},
+			{Name: "name", Match: &mask.BasicItem{Type: mask.MatchInner, Value: mask.MatchInnerValueName}, Action: actionMask, Mask: &mask.Mask{Type: mask.MaskPartialMask, Begin: 1, Length: -1}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestCheckRequest(t *testing.T) {
+	h := testHandler(t)
+	matched := make(hits, 0)
+	body, blocked, err := h.checkRequest([]byte(`{"messages":[{"role":"user","content":"call 13812345678"},{"role":"assistant","content":null,"tool_calls":[{"id":"call_0","type":"function","function":{"name":"lookup","arguments":"{\"name\": \"Alice\"}"}}]}],"stream":true}`), &matched)
+	if err != nil || blocked != nil {
+		t.Fatal(err, blocked)
+	}
+	if !bytes.Contains(body, []byte("call 138****5678")) || !bytes.Contains(body, []byte(`"stream":true`)) {
+		t.Errorf("unexpected body: %s", body)
+	}
+	if !bytes.Contains(body, []byte(`A****`)) || matched.String() != "phone,name" {
+		t.Errorf("unexpected name mask: %s, %s", body, matched)
+	}
+	// 未命中时不改写请求体
+	origin := []byte(`{"messages":[{"role":"user","content":"{\"name\":\"\"}  hi"}]}`)
+	body, _, _ = h.checkRequest(origin, &matched)
+	if !bytes.Equal(body, origin) {
+		t.Errorf("unexpected rewrite: %s", body)
+	}
+	_, blocked, _ = h.checkRequest([]byte(`{"messages":[{"role":"user","content":[{"type":"text","text":"my password is 123"}]}]}`), &matched)
+	if blocked == nil || blocked.name != "keyword:password" {
+		t.Errorf("expect blocked by keyword rule, got %v", blocked)
+	}
+	if _, err := newHandler(&Config{Rules: []*Rule{{Match: &mask.BasicItem{Type: mask.MatchRegex, Value: "("}, Action: actionBlock}}}); err == nil {
+		t.Error("expect invalid regex error")
+	}
+}
+
+type labels map[string]string
+
+func (l labels) SetLabel(name, value string) {
+	l[name] = value
+}
+
+func chunk(content string, finishReason string) string {
+	data, _ := json.Marshal(map[string]interface{}{
+		"message":       map[string]string{"role": "assistant", "content": content},
+		"finish_reason": finishReason,
+		"code":          0,
+		"error":         "",
+	})
+	return "data: " + string(data) + "\n\n"
+}
+
+// streamContent 拼接统一SSE格式响应中的回复内容
+func streamContent(t *testing.T, out []byte) string {
+	var content strings.Builder
+	for _, line := range strings.Split(string(out), "\n") {
+		if !strings.HasPrefix(line, "data: {") {
+			continue
+		}
+		response := new(ai_provider.ClientResponse)
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), response); err != nil {
+			t.Fatalf("invalid chunk %s: %v", line, err)
+		}
+		content.WriteString(response.Message.Content)
+	}
+	return content.String()
+}
+
+func TestGuardReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		stream  string
+		content string
+		labels  labels
+	}{
+		{
+			name:    "phone split across chunks",
+			stream:  chunk("call 138123", "") + chunk("45678 now", "") + chunk("", "stop") + "data: [DONE]\n\n",
+			content: "call 138****5678 now",
+			labels:  labels{"ai_guard_response": "phone"},
+		},
+		{
+			name:    "flush without finish reason",
+			stream:  chunk(strings.Repeat("a", 70)+" ", "") + chunk("1381234", "") + chunk("5678", "") + "data: [DONE]\n\n",
+			content: strings.Repeat("a", 70) + " 138****5678",
+			labels:  labels{"ai_guard_response": "phone"},
+		},
+		{
+			name:    "keyword split across chunks",
+			stream:  chunk("the pass", "") + chunk("word is", "") + chunk("ignored", "stop") + "data: [DONE]\n\n",
+			content: "",
+			labels:  labels{"ai_guard_blocked": "keyword:password"},
+		},
+	}
+	for _, tt := range tests {
+		ls := make(labels)
+		g := &responseGuard{ctx: ls, handler: testHandler(t)}
+		r := g.wrap(strings.NewReader(tt.stream)).(*guardReader)
+		r.chunk = make([]byte, 7)
+		out, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if content := streamContent(t, out); content != tt.content {
+			t.Errorf("%s: content %q, want %q", tt.name, content, tt.content)
+		}
+		if !bytes.HasSuffix(out, streamDone) || bytes.Count(out, streamDone) != 1 {
+			t.Errorf("%s: unexpected stream end: %q", tt.name, out)
+		}
+		for k, v := range tt.labels {
+			if ls[k] != v {
+				t.Errorf("%s: label %s %q, want %q", tt.name, k, ls[k], v)
+			}
+		}
+	}
+}
+
+func TestGuardReaderToolCalls(t *testing.T) {
+	ls := make(labels)
+	g := &responseGuard{ctx: ls, handler: testHandler(t)}
+	stream := `data: {"message":{"role":"assistant","content":"","tool_calls":[{"index":0,"id":"call_0","type":"function","function":{"name":"sms","arguments":"{\"to\":\"1381"}}]},"code":0,"error":""}` + "\n\n" +
+		`data: {"message":{"role":"","content":"","tool_calls":[{"index":0,"function":{"arguments":"2345678\"}"}}]},"code":0,"error":""}` + "\n\n" +
+		`data: {"message":{"role":"","content":""},"finish_reason":"tool_calls","code":0,"error":""}` + "\n\n" +
+		"data: [DONE]\n\n"
+	out, err := io.ReadAll(g.wrap(strings.NewReader(stream)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var arguments strings.Builder
+	for _, line := range strings.Split(string(out), "\n") {
+		response := new(ai_provider.ClientResponse)
+		if json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), response) != nil {
+			continue
+		}
+		for _, call := range response.Message.ToolCalls {
+			arguments.WriteString(call.Function.Arguments)
+		}
+	}
+	if arguments.String() != `{"to":"138****5678"}` || ls["ai_guard_response"] != "phone" {
+		t.Errorf("unexpected arguments %s, labels %v", arguments.String(), ls)
+	}
+}
@@ -0,0 +1,259 @@
+package ai_guard
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
+)
+
+// streamWindow 流式回复按增量返回，每段内容末尾保留的字符数，与后续增量拼接后再检查，
+// 使长度不超过该值的匹配内容（手机号、身份证号、关键字等）被拆分到多个响应块时仍能命中
+const streamWindow = 64
+
+var (
+	streamDataPrefix = []byte("data: ")
+	streamDone       = []byte("data: [DONE]\n\n")
+	streamDoneData   = []byte("[DONE]")
+)
+
+type labeler interface {
+	SetLabel(name, value string)
+}
+
+// responseGuard 检查一次请求的模型回复，命中的规则写入标签
+type responseGuard struct {
+	ctx     labeler
+	handler *handler
+	matched hits
+}
+
+func (g *responseGuard) label() {
+	g.ctx.SetLabel("ai_guard_response", g.matched.String())
+}
+
+func (g *responseGuard) block(r *rule) {
+	g.ctx.SetLabel("ai_guard_blocked", r.name)
+}
+
+func (g *responseGuard) wrap(reader io.Reader) io.Reader {
+	return &guardReader{reader: reader, guard: g, chunk: make([]byte, 4096), tools: make(map[int]string)}
+}
+
+// guardReader 按行读取统一SSE格式的响应块，回复内容及工具调用参数保留末尾窗口与后续增量拼接后检查，
+// 结束（finish_reason、[DONE]或数据流结束）时返回保留的内容；命中拦截规则时返回错误块并结束数据流
+type guardReader struct {
+	reader  io.Reader
+	guard   *responseGuard
+	chunk   []byte
+	pending []byte
+	out     bytes.Buffer
+	err     error
+
+	// content 保留未返回的回复内容，tools 按工具调用序号保留未返回的参数
+	content string
+	tools   map[int]string
+}
+
+func (r *guardReader) Read(p []byte) (int, error) {
+	for r.out.Len() == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		n, err := r.reader.Read(r.chunk)
+		r.pending = append(r.pending, r.chunk[:n]...)
+		r.scan()
+		if err != nil && r.err == nil {
+			// 数据流结束时返回保留的内容，剩余不完整的行原样返回
+			r.flush()
+			if r.err == nil {
+				r.out.Write(r.pending)
+				r.err = err
+			}
+			r.pending = nil
+		}
+	}
+	return r.out.Read(p)
+}
+
+func (r *guardReader) scan() {
+	offset := 0
+	for r.err == nil {
+		i := bytes.IndexByte(r.pending[offset:], '\n')
+		if i < 0 {
+			break
+		}
+		line := r.pending[offset : offset+i+1]
+		offset += i + 1
+		r.line(line)
+	}
+	if r.err != nil {
+		// 已拦截，丢弃剩余内容
+		r.pending = nil
+		return
+	}
+	r.pending = append(r.pending[:0], r.pending[offset:]...)
+}
+
+func (r *guardReader) line(line []byte) {
+	data := bytes.TrimRight(line, "\r\n")
+	if !bytes.HasPrefix(data, streamDataPrefix) {
+		r.out.Write(line)
+		return
+	}
+	data = data[len(streamDataPrefix):]
+	if bytes.Equal(data, streamDoneData) {
+		r.flush()
+		if r.err == nil {
+			r.out.Write(line)
+		}
+		return
+	}
+	response := make(map[string]json.RawMessage)
+	if json.Unmarshal(data, &response) != nil {
+		r.out.Write(line)
+		return
+	}
+	raw, has := response["message"]
+	message := new(ai_provider.Message)
+	if !has || json.Unmarshal(raw, message) != nil {
+		r.out.Write(line)
+		return
+	}
+	var finishReason string
+	json.Unmarshal(response["finish_reason"], &finishReason)
+	if !r.check(message, finishReason != "") {
+		return
+	}
+	raw, err := json.Marshal(message)
+	if err != nil {
+		r.out.Write(line)
+		return
+	}
+	response["message"] = raw
+	data, err = json.Marshal(response)
+	if err != nil {
+		r.out.Write(line)
+		return
+	}
+	// 事件结束的空行随后原样返回
+	r.out.Write(streamDataPrefix)
+	r.out.Write(data)
+	r.out.WriteString("\n")
+}
+
+// check 将保留的内容与本次增量拼接后检查，message改写为本次可返回的内容，finish为true时返回全部保留的内容；命中拦截规则时返回false
+func (r *guardReader) check(message *ai_provider.Message, finish bool) bool {
+	size := len(r.guard.matched)
+	defer func() {
+		if len(r.guard.matched) > size {
+			r.guard.label()
+		}
+	}()
+	var blocked *rule
+	message.Content, blocked = r.window(&r.content, message.Content, finish)
+	if blocked != nil {
+		r.block(blocked)
+		return false
+	}
+	indexes := make(map[int]struct{}, len(message.ToolCalls))
+	for _, call := range message.ToolCalls {
+		if call == nil {
+			continue
+		}
+		if call.Index == nil {
+			// 非增量的工具调用直接检查完整参数
+			var held string
+			call.Function.Arguments, blocked = r.window(&held, call.Function.Arguments, true)
+		} else {
+			index := *call.Index
+			indexes[index] = struct{}{}
+			held := r.tools[index]
+			call.Function.Arguments, blocked = r.window(&held, call.Function.Arguments, finish)
+			r.tools[index] = held
+		}
+		if blocked != nil {
+			r.block(blocked)
+			return false
+		}
+	}
+	if !finish {
+		return true
+	}
+	// 结束时返回本次未包含的工具调用保留的参数
+	for _, index := range r.toolIndexes() {
+		if _, has := indexes[index]; !has {
+			message.ToolCalls = append(message.ToolCalls, ai_provider.NewToolCall(index, "", "", r.tools[index]))
+		}
+	}
+	r.tools = make(map[int]string)
+	return true
+}
+
+// window 检查保留内容与增量拼接后的内容，返回可返回的部分，末尾streamWindow个字符继续保留
+func (r *guardReader) window(held *string, delta string, flush bool) (string, *rule) {
+	text := *held + delta
+	if text == "" {
+		return "", nil
+	}
+	out, blocked := r.guard.handler.checkText(text, &r.guard.matched)
+	if blocked != nil {
+		return "", blocked
+	}
+	if flush {
+		*held = ""
+		return out, nil
+	}
+	runes := []rune(out)
+	if len(runes) <= streamWindow {
+		*held = out
+		return "", nil
+	}
+	*held = string(runes[len(runes)-streamWindow:])
+	return string(runes[:len(runes)-streamWindow]), nil
+}
+
+func (r *guardReader) toolIndexes() []int {
+	indexes := make([]int, 0, len(r.tools))
+	for index, held := range r.tools {
+		if held != "" {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// flush 未收到结束原因时，在数据流结束前以单独的响应块返回保留的内容
+func (r *guardReader) flush() {
+	if r.content == "" && len(r.toolIndexes()) == 0 {
+		return
+	}
+	message := &ai_provider.Message{Role: "assistant"}
+	if !r.check(message, true) {
+		return
+	}
+	data, err := json.Marshal(&ai_provider.ClientResponse{Message: *message})
+	if err != nil {
+		return
+	}
+	r.write(data)
+}
+
+// write 返回单独的响应块
+func (r *guardReader) write(data []byte) {
+	r.out.Write(streamDataPrefix)
+	r.out.Write(data)
+	r.out.WriteString("\n\n")
+}
+
+func (r *guardReader) block(blocked *rule) {
+	r.guard.block(blocked)
+	r.write(blockedBody(blocked))
+	r.out.Write(streamDone)
+	r.content = ""
+	r.tools = make(map[int]string)
+	r.err = io.EOF
+}
@@ -2,6 +2,7 @@ package body_record_truncation
 
 import (
 	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/apinto/plugin"
 	"github.com/eolinker/eosc"
 )
 
@@ -10,6 +11,7 @@ const (
 )
 
 func Register(register eosc.IExtenderDriverRegister) {
+	plugin.DeclareBodyRequired(Name)
 	register.RegisterExtenderDriver(Name, NewFactory())
 }
 
@@ -0,0 +1,310 @@
+package dubbo2_to_grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/protocol"
+	"dubbo.apache.org/dubbo-go/v3/protocol/dubbo/impl"
+	"github.com/eolinker/apinto/entries/ctx_key"
+	"github.com/eolinker/apinto/entries/router"
+	grpc_descriptor "github.com/eolinker/apinto/grpc-descriptor"
+	grpc_context "github.com/eolinker/apinto/node/grpc-context"
+	"github.com/eolinker/apinto/utils"
+	"github.com/eolinker/eosc/eocontext"
+	dubbo2_context "github.com/eolinker/eosc/eocontext/dubbo2-context"
+	"github.com/eolinker/eosc/log"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/jhump/protoreflect/dynamic/grpcdynamic"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	errorTimeoutComplete = errors.New("complete timeout")
+	errNodeIsNull        = errors.New("node is null")
+
+	unmarshaler = &jsonpb.Unmarshaler{AllowUnknownFields: true}
+	marshaler   = &jsonpb.Marshaler{OrigName: true}
+
+	// skipAttachments Dubbo协议内部使用的attachment，不转发为gRPC metadata
+	skipAttachments = map[string]struct{}{
+		constant.PathKey:      {},
+		constant.InterfaceKey: {},
+		constant.VersionKey:   {},
+		constant.GroupKey:     {},
+		constant.TimeoutKey:   {},
+		constant.LocalAddr:    {},
+		constant.RemoteAddr:   {},
+		"input":               {},
+		"dubbo":               {},
+	}
+)
+
+type complete struct {
+	descriptor grpc_descriptor.IDescriptor
+	service    string
+	method     string
+	authority  string
+	headers    map[string]string
+	params     []param
+}
+
+func newComplete(descriptor grpc_descriptor.IDescriptor, conf *Config) *complete {
+	params := make([]param, 0, len(conf.Params))
+	for _, p := range conf.Params {
+		params = append(params, param{
+			className: p.ClassName,
+			fieldName: p.FieldName,
+		})
+	}
+	return &complete{
+		descriptor: descriptor,
+		service:    conf.Service,
+		method:     conf.Method,
+		authority:  conf.Authority,
+		headers:    conf.Headers,
+		params:     params,
+	}
+}
+
+func (c *complete) Complete(org eocontext.EoContext) error {
+	ctx, err := dubbo2_context.Assert(org)
+	if err != nil {
+		return err
+	}
+
+	retryValue := ctx.Value(ctx_key.CtxKeyRetry)
+	retry, ok := retryValue.(int)
+	if !ok {
+		retry = router.DefaultRetry
+	}
+
+	timeoutValue := ctx.Value(ctx_key.CtxKeyTimeout)
+	timeout, ok := timeoutValue.(time.Duration)
+	if !ok || timeout == 0 {
+		timeout = router.DefaultTimeout
+	}
+
+	//设置响应开始时间
+	proxyTime := time.Now()
+	defer func() {
+		ctx.Response().SetResponseTime(time.Since(proxyTime))
+	}()
+
+	methodDesc, err := c.findMethod(ctx.Proxy().Service())
+	if err != nil {
+		ctx.Response().SetBody(Dubbo2ErrorResult(err))
+		return err
+	}
+
+	reqBody, err := c.requestBody(ctx.Proxy().GetParam())
+	if err != nil {
+		ctx.Response().SetBody(Dubbo2ErrorResult(err))
+		return err
+	}
+	input := dynamic.NewMessage(methodDesc.GetInputType())
+	err = input.UnmarshalJSONPB(unmarshaler, reqBody)
+	if err != nil {
+		err = fmt.Errorf("参数解析错误，%w", err)
+		ctx.Response().SetBody(Dubbo2ErrorResult(err))
+		return err
+	}
+
+	md := attachmentsToMD(ctx.Proxy().Attachments(), c.headers)
+	balance := ctx.GetBalance()
+	isTLS := balance.Scheme() == "https"
+
+	var lastErr error
+	for index := 0; index <= retry; index++ {
+
+		if time.Since(proxyTime) > timeout {
+			ctx.Response().SetBody(Dubbo2ErrorResult(errorTimeoutComplete))
+			return errorTimeoutComplete
+		}
+
+		node, _, err := balance.Select(ctx)
+		if err != nil {
+			log.Error("select error: ", err)
+			ctx.Response().SetBody(Dubbo2ErrorResult(errNodeIsNull))
+			return err
+		}
+
+		var output *dynamic.Message
+		output, lastErr = invoke(ctx.Context(), node, timeout, isTLS, c.authority, methodDesc, md, input)
+		if lastErr == nil {
+			data, err := output.MarshalJSONPB(marshaler)
+			if err != nil {
+				ctx.Response().SetBody(Dubbo2ErrorResult(err))
+				return err
+			}
+			var val interface{}
+			if err = json.Unmarshal(data, &val); err != nil {
+				ctx.Response().SetBody(Dubbo2ErrorResult(err))
+				return err
+			}
+			ctx.Response().SetBody(getResponse(val, ctx.Proxy().Attachments()))
+			return nil
+		}
+		if s, ok := status.FromError(lastErr); ok && s.Code() != codes.Unavailable {
+			// 服务端返回的业务错误不重试
+			break
+		}
+		log.Error("grpc upstream invoke error: ", lastErr)
+	}
+
+	ctx.Response().SetBody(Dubbo2ErrorResult(lastErr))
+	return lastErr
+}
+
+// findMethod 查找gRPC方法，未配置服务名及方法名时使用Dubbo接口名及方法名
+func (c *complete) findMethod(service dubbo2_context.IServiceWriter) (*desc.MethodDescriptor, error) {
+	serviceName, methodName := c.service, c.method
+	if serviceName == "" {
+		serviceName = service.Interface()
+	}
+	if methodName == "" {
+		methodName = service.Method()
+	}
+	d, err := c.descriptor.Descriptor().FindSymbol(serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("grpc service %s not found: %w", serviceName, err)
+	}
+	sd, ok := d.(*desc.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a grpc service", serviceName)
+	}
+	md := sd.FindMethodByName(methodName)
+	if md == nil {
+		return nil, fmt.Errorf("grpc method %s/%s not found", serviceName, methodName)
+	}
+	if md.IsClientStreaming() || md.IsServerStreaming() {
+		return nil, fmt.Errorf("grpc method %s/%s is a streaming method", serviceName, methodName)
+	}
+	return md, nil
+}
+
+// requestBody 按参数配置将Dubbo调用参数组装为gRPC请求消息的json
+func (c *complete) requestBody(paramBody *dubbo2_context.Dubbo2ParamBody) ([]byte, error) {
+	if paramBody == nil || len(paramBody.TypesList) != len(paramBody.ValuesList) {
+		return nil, errors.New("args.length != types.length")
+	}
+	paramMap := make(map[string]interface{})
+	for i := range paramBody.ValuesList {
+		paramMap[paramBody.TypesList[i]] = paramBody.ValuesList[i]
+	}
+
+	if len(c.params) == 1 && c.params[0].fieldName == "" {
+		object, ok := paramMap[c.params[0].className]
+		if !ok {
+			return nil, fmt.Errorf("参数解析错误，未找到的名称为 %s className", c.params[0].className)
+		}
+		return json.Marshal(formatData(object))
+	}
+	maps := make(map[string]interface{})
+	for _, p := range c.params {
+		object, ok := paramMap[p.className]
+		if !ok {
+			return nil, fmt.Errorf("参数解析错误，未找到的名称为 %s className", p.className)
+		}
+		maps[p.fieldName] = formatData(object)
+	}
+	return json.Marshal(maps)
+}
+
+// invoke 调用gRPC方法，连接从节点的连接池中获取，不在请求结束时关闭
+func invoke(ctx context.Context, node eocontext.INode, timeout time.Duration, isTLS bool, authority string, md *desc.MethodDescriptor, header metadata.MD, input *dynamic.Message) (*dynamic.Message, error) {
+	invokeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	conn, err := grpc_context.GetClientPool(node.Addr(), isTLS, authority).Get()
+	if err != nil {
+		node.Down()
+		return nil, err
+	}
+	stub := grpcdynamic.NewStub(conn)
+	resp, err := stub.InvokeRpc(metadata.NewOutgoingContext(invokeCtx, header), md, input)
+	if err != nil {
+		return nil, err
+	}
+	output, ok := resp.(*dynamic.Message)
+	if !ok {
+		output = dynamic.NewMessage(md.GetOutputType())
+		err = output.ConvertFrom(resp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return output, nil
+}
+
+// attachmentsToMD 将Dubbo调用的attachment转换为gRPC metadata
+func attachmentsToMD(attachments map[string]interface{}, additional map[string]string) metadata.MD {
+	md := metadata.MD{}
+	for key, value := range attachments {
+		k := strings.ToLower(key)
+		if _, has := skipAttachments[k]; has || strings.HasPrefix(k, ":") || strings.HasPrefix(k, "grpc-") {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			md.Append(k, v)
+		case []string:
+			md.Append(k, v...)
+		default:
+			if value != nil {
+				md.Append(k, utils.InterfaceToString(value))
+			}
+		}
+	}
+	for key, value := range additional {
+		md.Set(strings.ToLower(key), value)
+	}
+	return md
+}
+
+func Dubbo2ErrorResult(err error) protocol.RPCResult {
+	payload := impl.NewResponsePayload(nil, err, nil)
+	return protocol.RPCResult{
+		Attrs: payload.Attachments,
+		Err:   payload.Exception,
+		Rest:  payload.RspObj,
+	}
+}
+
+func getResponse(obj interface{}, attachments map[string]interface{}) protocol.RPCResult {
+	payload := impl.NewResponsePayload(obj, nil, attachments)
+	return protocol.RPCResult{
+		Attrs: payload.Attachments,
+		Err:   payload.Exception,
+		Rest:  payload.RspObj,
+	}
+}
+
+func formatData(value interface{}) interface{} {
+
+	switch valueTemp := value.(type) {
+	case map[interface{}]interface{}:
+		maps := make(map[string]interface{})
+		for k, v := range valueTemp {
+			maps[utils.InterfaceToString(k)] = formatData(v)
+		}
+		return maps
+	case []interface{}:
+		values := make([]interface{}, 0)
+
+		for _, v := range valueTemp {
+			values = append(values, formatData(v))
+		}
+		return values
+	default:
+		return value
+	}
+}
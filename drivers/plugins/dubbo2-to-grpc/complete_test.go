@@ -0,0 +1,134 @@
+package dubbo2_to_grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/eolinker/eosc/eocontext"
+	dubbo2_context "github.com/eolinker/eosc/eocontext/dubbo2-context"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+
+	grpc_context "github.com/eolinker/apinto/node/grpc-context"
+)
+
+type testNode struct {
+	eocontext.INode
+	addr string
+	down bool
+}
+
+func (n *testNode) Addr() string {
+	return n.addr
+}
+
+func (n *testNode) Down() {
+	n.down = true
+}
+
+func TestRequestBody(t *testing.T) {
+	body := &dubbo2_context.Dubbo2ParamBody{
+		TypesList: []string{"java.lang.String", "com.demo.User"},
+		ValuesList: []interface{}{
+			"apinto",
+			map[interface{}]interface{}{"name": "eolink", "tags": []interface{}{map[interface{}]interface{}{1: "a"}}},
+		},
+	}
+	tests := []struct {
+		name    string
+		params  []Param
+		want    string
+		wantErr bool
+	}{
+		{name: "message", params: []Param{{ClassName: "com.demo.User"}}, want: `{"name":"eolink","tags":[{"1":"a"}]}`},
+		{name: "fields", params: []Param{{ClassName: "java.lang.String", FieldName: "service"}, {ClassName: "com.demo.User", FieldName: "user"}}, want: `{"service":"apinto","user":{"name":"eolink","tags":[{"1":"a"}]}}`},
+		{name: "unknown class", params: []Param{{ClassName: "java.lang.Long", FieldName: "id"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		c := newComplete(nil, &Config{Params: tt.params})
+		data, err := c.requestBody(body)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: err %v, want error %v", tt.name, err, tt.wantErr)
+		}
+		if string(data) != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, data, tt.want)
+		}
+	}
+	if _, err := newComplete(nil, &Config{}).requestBody(&dubbo2_context.Dubbo2ParamBody{TypesList: []string{"a"}}); err == nil {
+		t.Error("mismatched arguments: expect error")
+	}
+}
+
+func TestAttachmentsToMD(t *testing.T) {
+	md := attachmentsToMD(map[string]interface{}{
+		"path":         "com.demo.UserService",
+		"interface":    "com.demo.UserService",
+		"X-Trace-Id":   "abc",
+		"tags":         []string{"a", "b"},
+		"retries":      3,
+		":authority":   "demo",
+		"grpc-timeout": "1S",
+		"empty":        nil,
+	}, map[string]string{"X-App": "apinto", "x-trace-id": "override"})
+	want := metadata.MD{
+		"x-trace-id": {"override"},
+		"tags":       {"a", "b"},
+		"retries":    {"3"},
+		"x-app":      {"apinto"},
+	}
+	if len(md) != len(want) {
+		t.Fatalf("got %v, want %v", md, want)
+	}
+	for k, v := range want {
+		got := md.Get(k)
+		if len(got) != len(v) {
+			t.Errorf("%s: got %v, want %v", k, got, v)
+			continue
+		}
+		for i := range v {
+			if got[i] != v[i] {
+				t.Errorf("%s: got %v, want %v", k, got, v)
+			}
+		}
+	}
+}
+
+func TestInvokeReusesConnection(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(ln)
+	defer server.Stop()
+
+	fd, err := desc.LoadFileDescriptor("grpc/health/v1/health.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := fd.FindService("grpc.health.v1.Health").FindMethodByName("Check")
+	node := &testNode{addr: ln.Addr().String()}
+	for i := 0; i < 8; i++ {
+		output, err := invoke(context.Background(), node, time.Second, false, "", md, metadata.MD{}, dynamic.NewMessage(md.GetInputType()))
+		if err != nil {
+			t.Fatalf("invoke %d: %v", i, err)
+		}
+		if status := output.GetFieldByName("status"); status != int32(grpc_health_v1.HealthCheckResponse_SERVING) {
+			t.Fatalf("invoke %d: status %v", i, status)
+		}
+	}
+	if node.down {
+		t.Error("node is down")
+	}
+	// 连接池容量为5，多次调用复用已建立的连接
+	if n := grpc_context.GetClientPool(node.addr, false, "").ConnCount(); n < 1 || n > 5 {
+		t.Errorf("connection count %d", n)
+	}
+}
@@ -0,0 +1,17 @@
+package dubbo2_to_grpc
+
+import "github.com/eolinker/eosc"
+
+type Config struct {
+	Service    string            `json:"service" label:"gRPC服务名" description:"为空时使用Dubbo接口名"`
+	Method     string            `json:"method" label:"gRPC方法名" description:"为空时使用Dubbo方法名"`
+	Authority  string            `json:"authority" label:"虚拟主机域名(Authority)"`
+	ProtobufID eosc.RequireId    `json:"protobuf_id" required:"true" label:"Protobuf ID" skill:"github.com/eolinker/apinto/grpc-transcode.transcode.IDescriptor"`
+	Headers    map[string]string `json:"headers" label:"额外头部"`
+	Params     []Param           `json:"params" label:"参数解析" required:"true"`
+}
+
+type Param struct {
+	ClassName string `json:"class_name" label:"class_name" required:"true"` //对应Java中类的class_name
+	FieldName string `json:"field_name" label:"字段名"`                        //gRPC请求消息中的字段名，为空时参数作为整个请求消息
+}
@@ -0,0 +1,60 @@
+package dubbo2_to_grpc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/eolinker/apinto/drivers"
+	grpc_descriptor "github.com/eolinker/apinto/grpc-descriptor"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/common/bean"
+)
+
+func check(v interface{}) (*Config, error) {
+	conf, err := drivers.Assert[Config](v)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.ProtobufID == "" {
+		return nil, errors.New("protobuf id is null")
+	}
+
+	if len(conf.Params) == 0 {
+		return nil, errors.New("params is null")
+	}
+
+	return conf, nil
+}
+
+func Create(id, name string, conf *Config, workers map[eosc.RequireId]eosc.IWorker) (eosc.IWorker, error) {
+	once.Do(func() {
+		bean.Autowired(&worker)
+	})
+	conf, err := check(conf)
+	if err != nil {
+		return nil, err
+	}
+	descriptor, err := getDescSource(string(conf.ProtobufID))
+	if err != nil {
+		return nil, err
+	}
+
+	pw := &ToGrpc{
+		WorkerBase: drivers.Worker(id, name),
+	}
+	pw.handler.Store(newComplete(descriptor, conf))
+	return pw, nil
+}
+
+func getDescSource(protobufID string) (grpc_descriptor.IDescriptor, error) {
+	w, ok := worker.Get(protobufID)
+	if ok {
+		v, ok := w.(grpc_descriptor.IDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("invalid protobuf id: %s", protobufID)
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("protobuf worker(%s) is not exist", protobufID)
+}
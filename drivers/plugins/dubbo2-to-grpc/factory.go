@@ -0,0 +1,30 @@
+package dubbo2_to_grpc
+
+import (
+	"sync"
+
+	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/log"
+)
+
+const (
+	Name = "dubbo2_to_grpc"
+)
+
+var (
+	once   = sync.Once{}
+	worker eosc.IWorkers
+)
+
+func Register(register eosc.IExtenderDriverRegister) {
+	err := register.RegisterExtenderDriver(Name, NewFactory())
+	if err != nil {
+		log.Warnf("register %s:%s", Name, err)
+		return
+	}
+}
+
+func NewFactory() eosc.IExtenderDriverFactory {
+	return drivers.NewFactory[Config](Create)
+}
@@ -0,0 +1,66 @@
+package dubbo2_to_grpc
+
+import (
+	"sync/atomic"
+
+	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/eocontext"
+	dubbo2_context "github.com/eolinker/eosc/eocontext/dubbo2-context"
+)
+
+var _ eocontext.IFilter = (*ToGrpc)(nil)
+var _ dubbo2_context.DubboFilter = (*ToGrpc)(nil)
+
+type ToGrpc struct {
+	drivers.WorkerBase
+	handler atomic.Pointer[complete]
+}
+
+func (t *ToGrpc) DoDubboFilter(ctx dubbo2_context.IDubbo2Context, next eocontext.IChain) (err error) {
+	ctx.SetCompleteHandler(t.handler.Load())
+
+	if next != nil {
+		return next.DoChain(ctx)
+	}
+
+	return nil
+}
+
+func (t *ToGrpc) DoFilter(ctx eocontext.EoContext, next eocontext.IChain) (err error) {
+	return dubbo2_context.DoDubboFilter(t, ctx, next)
+}
+
+func (t *ToGrpc) Destroy() {
+
+}
+
+func (t *ToGrpc) Start() error {
+	return nil
+}
+
+func (t *ToGrpc) Reset(v interface{}, workers map[eosc.RequireId]eosc.IWorker) error {
+	conf, err := check(v)
+	if err != nil {
+		return err
+	}
+	descriptor, err := getDescSource(string(conf.ProtobufID))
+	if err != nil {
+		return err
+	}
+	t.handler.Store(newComplete(descriptor, conf))
+	return nil
+}
+
+func (t *ToGrpc) Stop() error {
+	return nil
+}
+
+func (t *ToGrpc) CheckSkill(skill string) bool {
+	return dubbo2_context.FilterSkillName == skill
+}
+
+type param struct {
+	className string
+	fieldName string
+}
@@ -0,0 +1,25 @@
+package dubbo3_to_http
+
+import (
+	grpc_to_http "github.com/eolinker/apinto/drivers/plugins/gRPC-to-http"
+	"github.com/eolinker/eosc"
+)
+
+type Config struct {
+	Path       string            `json:"path" label:"请求路径" description:"为空时使用/{服务名}/{方法名}"`
+	Method     string            `json:"method" label:"请求方式" enum:"POST,PUT,PATCH"`
+	ProtobufID eosc.RequireId    `json:"protobuf_id" required:"true" label:"Protobuf ID" skill:"github.com/eolinker/apinto/grpc-transcode.transcode.IDescriptor"`
+	Headers    map[string]string `json:"headers" label:"额外头部"`
+	Query      map[string]string `json:"query" label:"query参数"`
+}
+
+// toGrpc Triple请求以gRPC方式接入，tri-头部随其他metadata一同转发为http头部
+func (c *Config) toGrpc() *grpc_to_http.Config {
+	return &grpc_to_http.Config{
+		Path:       c.Path,
+		Method:     c.Method,
+		ProtobufID: c.ProtobufID,
+		Headers:    c.Headers,
+		Query:      c.Query,
+	}
+}
@@ -0,0 +1,40 @@
+package dubbo3_to_http
+
+import (
+	"github.com/eolinker/apinto/drivers"
+	grpc_to_http "github.com/eolinker/apinto/drivers/plugins/gRPC-to-http"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/eocontext"
+	"github.com/eolinker/eosc/log"
+)
+
+const (
+	Name = "dubbo3_to_http"
+)
+
+func Register(register eosc.IExtenderDriverRegister) {
+	err := register.RegisterExtenderDriver(Name, NewFactory())
+	if err != nil {
+		log.Warnf("register %s %s", Name, err)
+		return
+	}
+}
+
+func NewFactory() eosc.IExtenderDriverFactory {
+	return drivers.NewFactory[Config](Create, Check)
+}
+
+func Check(cfg *Config, workers map[eosc.RequireId]eosc.IWorker) error {
+	return grpc_to_http.Check(cfg.toGrpc(), workers)
+}
+
+func Create(id, name string, conf *Config, workers map[eosc.RequireId]eosc.IWorker) (eosc.IWorker, error) {
+	w, err := grpc_to_http.Create(id, name, conf.toGrpc(), workers)
+	if err != nil {
+		return nil, err
+	}
+	return &toHttp{
+		IWorker: w,
+		filter:  w.(eocontext.IFilter),
+	}, nil
+}
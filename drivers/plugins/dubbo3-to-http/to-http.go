@@ -0,0 +1,29 @@
+package dubbo3_to_http
+
+import (
+	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/eocontext"
+)
+
+// toHttp 将Dubbo3 Triple请求转换为http请求，转换过程复用gRPC转http插件
+type toHttp struct {
+	eosc.IWorker
+	filter eocontext.IFilter
+}
+
+func (t *toHttp) DoFilter(ctx eocontext.EoContext, next eocontext.IChain) (err error) {
+	return t.filter.DoFilter(ctx, next)
+}
+
+func (t *toHttp) Destroy() {
+	t.filter.Destroy()
+}
+
+func (t *toHttp) Reset(conf interface{}, workers map[eosc.RequireId]eosc.IWorker) error {
+	cfg, err := drivers.Assert[Config](conf)
+	if err != nil {
+		return err
+	}
+	return t.IWorker.Reset(cfg.toGrpc(), workers)
+}
@@ -2,11 +2,12 @@ package grpc_to_http
 
 import (
 	"fmt"
-	"github.com/eolinker/eosc/log"
 	"sync"
 
 	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/apinto/plugin"
 	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/log"
 )
 
 const (
@@ -19,6 +20,7 @@ var (
 )
 
 func Register(register eosc.IExtenderDriverRegister) {
+	plugin.DeclareBodyRequired(Name)
 	err := register.RegisterExtenderDriver(Name, NewFactory())
 	if err != nil {
 		log.Warnf("register %s %s", Name, err)
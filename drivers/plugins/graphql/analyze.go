@@ -0,0 +1,269 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// maxFields 单个请求展开片段后允许遍历的字段总数，防止片段嵌套引用导致的指数级展开
+const maxFields = 100000
+
+var (
+	errOperationRequired = errors.New("operationName is required when query contains multiple operations")
+	errNoOperation       = errors.New("query contains no operation")
+	errTooManyFields     = errors.New("query contains too many fields")
+
+	// paginationArgs 作为复杂度乘数的分页参数
+	paginationArgs = []string{"first", "last", "limit"}
+)
+
+// Operation 从GraphQL查询中解析出的操作信息
+type Operation struct {
+	Name          string
+	Type          string
+	RootFields    []string
+	Depth         int
+	Complexity    int
+	Aliases       int
+	Introspection bool
+}
+
+// analyze 解析查询并计算待执行操作的深度、复杂度、别名数量等信息
+func analyze(query string, operationName string, variables map[string]interface{}) (*Operation, error) {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil {
+		return nil, err
+	}
+	op, err := selectOperation(doc, operationName)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]interface{}, len(op.VariableDefinitions)+len(variables))
+	for _, def := range op.VariableDefinitions {
+		if def.DefaultValue == nil {
+			continue
+		}
+		if v, err := def.DefaultValue.Value(nil); err == nil {
+			vars[def.Variable] = v
+		}
+	}
+	for k, v := range variables {
+		vars[k] = v
+	}
+
+	a := &analyzer{
+		fragments: doc.Fragments,
+		variables: vars,
+		visiting:  make(map[string]bool),
+	}
+	rootFields, err := a.rootFields(op.SelectionSet, nil)
+	if err != nil {
+		return nil, err
+	}
+	depth, complexity, err := a.walk(op.SelectionSet)
+	if err != nil {
+		return nil, err
+	}
+	return &Operation{
+		Name:          op.Name,
+		Type:          string(op.Operation),
+		RootFields:    rootFields,
+		Depth:         depth,
+		Complexity:    complexity,
+		Aliases:       a.aliases,
+		Introspection: a.introspection,
+	}, nil
+}
+
+func selectOperation(doc *ast.QueryDocument, operationName string) (*ast.OperationDefinition, error) {
+	if operationName != "" {
+		op := doc.Operations.ForName(operationName)
+		if op == nil {
+			return nil, fmt.Errorf("unknown operation named %q", operationName)
+		}
+		return op, nil
+	}
+	switch len(doc.Operations) {
+	case 0:
+		return nil, errNoOperation
+	case 1:
+		return doc.Operations[0], nil
+	default:
+		return nil, errOperationRequired
+	}
+}
+
+type analyzer struct {
+	fragments     ast.FragmentDefinitionList
+	variables     map[string]interface{}
+	visiting      map[string]bool
+	fields        int
+	aliases       int
+	introspection bool
+}
+
+// rootFields 返回操作的根字段名，片段中的根字段一并展开
+func (a *analyzer) rootFields(set ast.SelectionSet, names []string) ([]string, error) {
+	var err error
+	for _, selection := range set {
+		switch s := selection.(type) {
+		case *ast.Field:
+			if !contains(names, s.Name) {
+				names = append(names, s.Name)
+			}
+		case *ast.InlineFragment:
+			names, err = a.rootFields(s.SelectionSet, names)
+		case *ast.FragmentSpread:
+			err = a.fragment(s.Name, func(fragment *ast.FragmentDefinition) error {
+				names, err = a.rootFields(fragment.SelectionSet, names)
+				return err
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+// walk 返回选择集的深度及复杂度，同时统计别名及内省字段
+func (a *analyzer) walk(set ast.SelectionSet) (depth int, complexity int, err error) {
+	for _, selection := range set {
+		d, c := 0, 0
+		switch s := selection.(type) {
+		case *ast.Field:
+			d, c, err = a.field(s)
+		case *ast.InlineFragment:
+			d, c, err = a.walk(s.SelectionSet)
+		case *ast.FragmentSpread:
+			err = a.fragment(s.Name, func(fragment *ast.FragmentDefinition) error {
+				d, c, err = a.walk(fragment.SelectionSet)
+				return err
+			})
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		if d > depth {
+			depth = d
+		}
+		complexity = addCost(complexity, c)
+	}
+	return depth, complexity, nil
+}
+
+func (a *analyzer) field(field *ast.Field) (int, int, error) {
+	a.fields++
+	if a.fields > maxFields {
+		return 0, 0, errTooManyFields
+	}
+	if field.Alias != field.Name {
+		a.aliases++
+	}
+	switch field.Name {
+	case "__schema", "__type":
+		a.introspection = true
+	case "__typename":
+		return 0, 0, nil
+	}
+	if len(field.SelectionSet) == 0 {
+		return 1, 1, nil
+	}
+	depth, complexity, err := a.walk(field.SelectionSet)
+	if err != nil {
+		return 0, 0, err
+	}
+	return depth + 1, addCost(1, mulCost(complexity, a.multiplier(field))), nil
+}
+
+// fragment 展开命名片段，片段循环引用时返回错误
+func (a *analyzer) fragment(name string, handler func(fragment *ast.FragmentDefinition) error) error {
+	fragment := a.fragments.ForName(name)
+	if fragment == nil {
+		return fmt.Errorf("unknown fragment %q", name)
+	}
+	if a.visiting[name] {
+		return fmt.Errorf("cannot spread fragment %q within itself", name)
+	}
+	a.visiting[name] = true
+	defer delete(a.visiting, name)
+	return handler(fragment)
+}
+
+// multiplier 取字段分页参数的值作为子字段复杂度的乘数
+func (a *analyzer) multiplier(field *ast.Field) int {
+	for _, name := range paginationArgs {
+		arg := field.Arguments.ForName(name)
+		if arg == nil {
+			continue
+		}
+		v, err := arg.Value.Value(a.variables)
+		if err != nil {
+			continue
+		}
+		if n, ok := toInt(v); ok && n > 1 {
+			return n
+		}
+	}
+	return 1
+}
+
+func toInt(v interface{}) (int, bool) {
+	var f float64
+	switch n := v.(type) {
+	case int64:
+		f = float64(n)
+	case int:
+		f = float64(n)
+	case float64:
+		f = n
+	case json.Number:
+		var err error
+		f, err = n.Float64()
+		if err != nil {
+			return 0, false
+		}
+	case string:
+		var err error
+		f, err = strconv.ParseFloat(n, 64)
+		if err != nil {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	if f > math.MaxInt32 {
+		return math.MaxInt32, true
+	}
+	return int(f), true
+}
+
+func addCost(a, b int) int {
+	if a > math.MaxInt32-b {
+		return math.MaxInt32
+	}
+	return a + b
+}
+
+func mulCost(a, b int) int {
+	if b != 0 && a > math.MaxInt32/b {
+		return math.MaxInt32
+	}
+	return a * b
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package graphql
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	query := `
+query ListUsers($first: Int = 10) {
+  users(first: $first) {
+    id
+    friends: followers(limit: 5) { ...UserFields }
+  }
+  me: viewer { __typename name }
+}
+fragment UserFields on User { id name }
+mutation Save { save { id } }
+`
+	tests := []struct {
+		name      string
+		variables map[string]interface{}
+		want      *Operation
+	}{
+		{
+			name: "default variable",
+			want: &Operation{
+				Name:       "ListUsers",
+				Type:       "query",
+				RootFields: []string{"users", "viewer"},
+				Depth:      3,
+				// users: 1 + 10*(id + followers(1 + 5*2)) , viewer: 1 + name
+				Complexity: 1 + 10*(1+1+5*2) + 2,
+				Aliases:    2,
+			},
+		},
+		{
+			name:      "variable",
+			variables: map[string]interface{}{"first": json.Number("2")},
+			want: &Operation{
+				Name:       "ListUsers",
+				Type:       "query",
+				RootFields: []string{"users", "viewer"},
+				Depth:      3,
+				Complexity: 1 + 2*(1+1+5*2) + 2,
+				Aliases:    2,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := analyze(query, "ListUsers", tt.variables)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("analyze() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := analyze(query, "", nil); err == nil {
+		t.Error("operationName should be required for multiple operations")
+	}
+	op, err := analyze(`{ __schema { types { name } } }`, "", nil)
+	if err != nil || !op.Introspection {
+		t.Errorf("introspection not detected: %+v %v", op, err)
+	}
+	if _, err = analyze(`{ ...A } fragment A on Query { a { ...B } } fragment B on A { ...A }`, "", nil); err == nil {
+		t.Error("fragment cycle should fail")
+	}
+}
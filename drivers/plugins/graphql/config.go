@@ -0,0 +1,33 @@
+package graphql
+
+import (
+	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/eosc"
+)
+
+type Config struct {
+	MaxDepth       int                  `json:"max_depth" label:"最大查询深度" description:"0表示不限制"`
+	MaxComplexity  int                  `json:"max_complexity" label:"最大查询复杂度" description:"每个字段计1，带分页参数（first、last、limit）的字段，其子字段复杂度乘以参数值，0表示不限制"`
+	MaxAliases     int                  `json:"max_aliases" label:"最大别名数量" description:"0表示不限制"`
+	Introspection  IntrospectionConfig  `json:"introspection" label:"内省查询"`
+	PersistedQuery PersistedQueryConfig `json:"persisted_query" label:"持久化查询（APQ）"`
+}
+
+type IntrospectionConfig struct {
+	Block bool     `json:"block" label:"禁止内省查询"`
+	Apps  []string `json:"apps" label:"例外应用" description:"应用ID列表，开启禁止时为允许内省查询的应用，关闭禁止时为禁止内省查询的应用"`
+}
+
+type PersistedQueryConfig struct {
+	Enable bool           `json:"enable" label:"启用"`
+	Cache  eosc.RequireId `json:"cache" label:"缓存位置" skill:"github.com/eolinker/apinto/resources.resources.ICache" required:"false" switch:"enable === true"`
+	Expire int            `json:"expire" label:"缓存时间" description:"单位：秒，0表示不过期" switch:"enable === true"`
+}
+
+func Create(id, name string, conf *Config, workers map[eosc.RequireId]eosc.IWorker) (eosc.IWorker, error) {
+	e := &executor{
+		WorkerBase: drivers.Worker(id, name),
+	}
+	e.reset(conf)
+	return e, nil
+}
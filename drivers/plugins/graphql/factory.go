@@ -0,0 +1,20 @@
+package graphql
+
+import (
+	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/apinto/plugin"
+	"github.com/eolinker/eosc"
+)
+
+const (
+	Name = "graphql"
+)
+
+func Register(register eosc.IExtenderDriverRegister) {
+	plugin.DeclareBodyRequired(Name)
+	register.RegisterExtenderDriver(Name, NewFactory())
+}
+
+func NewFactory() eosc.IExtenderDriverFactory {
+	return drivers.NewFactory[Config](Create)
+}
@@ -0,0 +1,208 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/eocontext"
+	http_service "github.com/eolinker/eosc/eocontext/http-context"
+)
+
+var _ eocontext.IFilter = (*executor)(nil)
+var _ http_service.HttpFilter = (*executor)(nil)
+var _ eosc.IWorker = (*executor)(nil)
+
+type executor struct {
+	drivers.WorkerBase
+	rule atomic.Pointer[rule]
+}
+
+type rule struct {
+	maxDepth           int
+	maxComplexity      int
+	maxAliases         int
+	blockIntrospection bool
+	apps               map[string]struct{}
+	persisted          *persistedConfig
+}
+
+func (r *rule) introspectionAllowed(app string) bool {
+	_, has := r.apps[app]
+	if r.blockIntrospection {
+		return has
+	}
+	return !has
+}
+
+func (e *executor) DoFilter(ctx eocontext.EoContext, next eocontext.IChain) (err error) {
+	return http_service.DoHttpFilter(e, ctx, next)
+}
+
+func (e *executor) DoHttpFilter(ctx http_service.IHttpContext, next eocontext.IChain) error {
+	requests, batch, err := readRequests(ctx)
+	if err != nil {
+		if errors.Is(err, errNotGraphQL) {
+			if next != nil {
+				return next.DoChain(ctx)
+			}
+			return nil
+		}
+		return writeError(ctx, newError(http.StatusBadRequest, "BAD_REQUEST", err.Error()))
+	}
+
+	r := e.rule.Load()
+	rewrite := false
+	names := make([]string, 0, len(requests))
+	types := make([]string, 0, len(requests))
+	var rootFields []string
+	for _, req := range requests {
+		loaded := false
+		if req.hash != "" {
+			loaded, err = e.loadPersisted(ctx, req, r.persisted)
+			if err != nil {
+				return writeError(ctx, err)
+			}
+			rewrite = rewrite || loaded
+		}
+		if req.query == "" {
+			return writeError(ctx, newError(http.StatusBadRequest, "BAD_REQUEST", "query is required"))
+		}
+		op, err := analyze(req.query, req.operationName, req.variables)
+		if err != nil {
+			return writeError(ctx, newError(http.StatusBadRequest, "GRAPHQL_PARSE_FAILED", err.Error()))
+		}
+		if err = r.check(ctx, op); err != nil {
+			return writeError(ctx, err)
+		}
+		if req.hash != "" && r.persisted != nil && !loaded {
+			e.savePersisted(ctx, req, r.persisted)
+		}
+		names = append(names, op.Name)
+		types = append(types, op.Type)
+		for _, field := range op.RootFields {
+			if !contains(rootFields, field) {
+				rootFields = append(rootFields, field)
+			}
+		}
+	}
+
+	ctx.SetLabel("graphql_operation_name", strings.Join(names, ","))
+	ctx.SetLabel("graphql_operation_type", strings.Join(types, ","))
+	ctx.SetLabel("graphql_root_fields", strings.Join(rootFields, ","))
+
+	if rewrite {
+		// 上游不一定支持APQ，转发还原后的完整查询
+		if err = writeRequests(ctx, requests, batch); err != nil {
+			return writeError(ctx, newError(http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", err.Error()))
+		}
+	}
+	if next != nil {
+		return next.DoChain(ctx)
+	}
+	return nil
+}
+
+func (r *rule) check(ctx http_service.IHttpContext, op *Operation) error {
+	if r.maxDepth > 0 && op.Depth > r.maxDepth {
+		return newError(http.StatusBadRequest, "GRAPHQL_VALIDATION_FAILED", fmt.Sprintf("query depth %d exceeds maximum depth %d", op.Depth, r.maxDepth))
+	}
+	if r.maxComplexity > 0 && op.Complexity > r.maxComplexity {
+		return newError(http.StatusBadRequest, "GRAPHQL_VALIDATION_FAILED", fmt.Sprintf("query complexity %d exceeds maximum complexity %d", op.Complexity, r.maxComplexity))
+	}
+	if r.maxAliases > 0 && op.Aliases > r.maxAliases {
+		return newError(http.StatusBadRequest, "GRAPHQL_VALIDATION_FAILED", fmt.Sprintf("query aliases %d exceeds maximum aliases %d", op.Aliases, r.maxAliases))
+	}
+	if op.Introspection && !r.introspectionAllowed(ctx.GetLabel("application_id")) {
+		return newError(http.StatusForbidden, "FORBIDDEN", "introspection is not allowed")
+	}
+	return nil
+}
+
+// graphqlError 按GraphQL规范的errors格式返回给客户端
+type graphqlError struct {
+	status  int
+	code    string
+	message string
+}
+
+func newError(status int, code string, message string) *graphqlError {
+	return &graphqlError{status: status, code: code, message: message}
+}
+
+func (g *graphqlError) Error() string {
+	return g.message
+}
+
+func writeError(ctx http_service.IHttpContext, err error) error {
+	var gErr *graphqlError
+	if !errors.As(err, &gErr) {
+		gErr = newError(http.StatusBadRequest, "BAD_REQUEST", err.Error())
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"errors": []interface{}{
+			map[string]interface{}{
+				"message": gErr.message,
+				"extensions": map[string]interface{}{
+					"code": gErr.code,
+				},
+			},
+		},
+	})
+	ctx.Response().SetHeader("Content-Type", "application/json")
+	ctx.Response().SetStatus(gErr.status, http.StatusText(gErr.status))
+	ctx.Response().SetBody(body)
+	return err
+}
+
+func (e *executor) reset(conf *Config) {
+	apps := make(map[string]struct{}, len(conf.Introspection.Apps))
+	for _, app := range conf.Introspection.Apps {
+		apps[app] = struct{}{}
+	}
+	r := &rule{
+		maxDepth:           conf.MaxDepth,
+		maxComplexity:      conf.MaxComplexity,
+		maxAliases:         conf.MaxAliases,
+		blockIntrospection: conf.Introspection.Block,
+		apps:               apps,
+	}
+	if conf.PersistedQuery.Enable {
+		r.persisted = &persistedConfig{
+			expire:  time.Duration(conf.PersistedQuery.Expire) * time.Second,
+			cacheID: string(conf.PersistedQuery.Cache),
+		}
+	}
+	e.rule.Store(r)
+}
+
+func (e *executor) Start() error {
+	return nil
+}
+
+func (e *executor) Reset(conf interface{}, workers map[eosc.RequireId]eosc.IWorker) error {
+	cfg, ok := conf.(*Config)
+	if !ok {
+		return errors.New("invalid config")
+	}
+	e.reset(cfg)
+	return nil
+}
+
+func (e *executor) Stop() error {
+	return nil
+}
+
+func (e *executor) Destroy() {
+	return
+}
+
+func (e *executor) CheckSkill(skill string) bool {
+	return http_service.FilterSkillName == skill
+}
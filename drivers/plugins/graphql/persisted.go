@@ -0,0 +1,71 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/eolinker/apinto/resources"
+	scope_manager "github.com/eolinker/apinto/scope-manager"
+	http_service "github.com/eolinker/eosc/eocontext/http-context"
+	"github.com/eolinker/eosc/log"
+)
+
+const persistedKeyPrefix = "apinto:graphql:apq:"
+
+var (
+	errPersistedQueryNotFound     = newError(200, "PERSISTED_QUERY_NOT_FOUND", "PersistedQueryNotFound")
+	errPersistedQueryNotSupported = newError(200, "PERSISTED_QUERY_NOT_SUPPORTED", "PersistedQueryNotSupported")
+	errPersistedQueryHashMismatch = newError(400, "PERSISTED_QUERY_HASH_MISMATCH", "provided sha does not match query")
+)
+
+// loadPersisted 处理自动持久化查询：只有hash时从缓存中还原查询，同时带有查询时校验hash
+func (e *executor) loadPersisted(ctx http_service.IHttpContext, r *request, persisted *persistedConfig) (bool, error) {
+	if persisted == nil {
+		if r.query == "" {
+			return false, errPersistedQueryNotSupported
+		}
+		return false, nil
+	}
+	if r.query == "" {
+		query, err := persisted.getCache().Get(ctx.Context(), persistedKeyPrefix+r.hash).Result()
+		if err != nil || query == "" {
+			return false, errPersistedQueryNotFound
+		}
+		r.query = query
+		return true, nil
+	}
+	sum := sha256.Sum256([]byte(r.query))
+	if hex.EncodeToString(sum[:]) != r.hash {
+		return false, errPersistedQueryHashMismatch
+	}
+	return false, nil
+}
+
+// savePersisted 查询通过校验后写入缓存，供后续只携带hash的请求使用
+func (e *executor) savePersisted(ctx http_service.IHttpContext, r *request, persisted *persistedConfig) {
+	err := persisted.getCache().Set(ctx.Context(), persistedKeyPrefix+r.hash, []byte(r.query), persisted.expire).Result()
+	if err != nil {
+		log.Error("graphql save persisted query error: ", err)
+	}
+}
+
+type persistedConfig struct {
+	expire  time.Duration
+	cacheID string
+	once    sync.Once
+	cache   scope_manager.IProxyOutput[resources.ICache]
+}
+
+// getCache 首次使用时按配置的缓存获取，配置变更后随规则一起替换
+func (p *persistedConfig) getCache() resources.ICache {
+	p.once.Do(func() {
+		p.cache = scope_manager.Auto[resources.ICache](p.cacheID, "redis")
+	})
+	list := p.cache.List()
+	if len(list) > 0 {
+		return list[0]
+	}
+	return resources.LocalCache()
+}
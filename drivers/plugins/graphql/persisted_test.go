@@ -0,0 +1,35 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/eolinker/eosc"
+)
+
+func TestResetPersistedCache(t *testing.T) {
+	worker, err := Create("graphql@plugin", "graphql", &Config{PersistedQuery: PersistedQueryConfig{Enable: true, Cache: "a@output"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := worker.(*executor)
+	old := e.rule.Load().persisted
+	if old == nil || old.cacheID != "a@output" {
+		t.Fatalf("persisted config: %+v", old)
+	}
+
+	err = e.Reset(&Config{PersistedQuery: PersistedQueryConfig{Enable: true, Cache: "b@output", Expire: 60}}, map[eosc.RequireId]eosc.IWorker{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := e.rule.Load().persisted
+	if p == old || p.cacheID != "b@output" || p.cache != nil {
+		t.Errorf("cache not rebound after reset: %+v", p)
+	}
+
+	if err = e.Reset(&Config{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if p = e.rule.Load().persisted; p != nil {
+		t.Errorf("persisted query disabled: %+v", p)
+	}
+}
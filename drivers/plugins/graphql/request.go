@@ -0,0 +1,183 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	http_service "github.com/eolinker/eosc/eocontext/http-context"
+)
+
+var (
+	// errNotGraphQL 请求不是可识别的GraphQL请求，直接放行
+	errNotGraphQL = errors.New("not graphql request")
+)
+
+// request 单个GraphQL请求，batch请求中每个元素对应一个request
+type request struct {
+	raw           map[string]json.RawMessage
+	query         string
+	operationName string
+	variables     map[string]interface{}
+	hash          string
+}
+
+// readRequests 读取GraphQL请求，支持GET参数、application/json（含batch数组）及application/graphql
+func readRequests(ctx http_service.IHttpContext) ([]*request, bool, error) {
+	switch ctx.Request().Method() {
+	case http.MethodGet:
+		r, err := readQuery(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		return []*request{r}, false, nil
+	case http.MethodPost:
+	default:
+		return nil, false, errNotGraphQL
+	}
+
+	body, err := ctx.Proxy().Body().RawBody()
+	if err != nil {
+		return nil, false, err
+	}
+	contentType := ctx.Proxy().Body().ContentType()
+	if strings.HasPrefix(contentType, "application/graphql") {
+		return []*request{{query: string(body)}}, false, nil
+	}
+	if !strings.Contains(contentType, "json") {
+		return nil, false, errNotGraphQL
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var items []map[string]json.RawMessage
+		if err = json.Unmarshal(body, &items); err != nil {
+			return nil, false, err
+		}
+		requests := make([]*request, 0, len(items))
+		for _, item := range items {
+			r, err := parseRequest(item)
+			if err != nil {
+				return nil, false, err
+			}
+			requests = append(requests, r)
+		}
+		return requests, true, nil
+	}
+	var item map[string]json.RawMessage
+	if err = json.Unmarshal(body, &item); err != nil {
+		return nil, false, err
+	}
+	r, err := parseRequest(item)
+	if err != nil {
+		return nil, false, err
+	}
+	return []*request{r}, false, nil
+}
+
+func readQuery(ctx http_service.IHttpContext) (*request, error) {
+	uri := ctx.Proxy().URI()
+	r := &request{
+		query:         uri.GetQuery("query"),
+		operationName: uri.GetQuery("operationName"),
+	}
+	extensions := uri.GetQuery("extensions")
+	if r.query == "" && extensions == "" {
+		return nil, errNotGraphQL
+	}
+	if v := uri.GetQuery("variables"); v != "" {
+		if err := unmarshal([]byte(v), &r.variables); err != nil {
+			return nil, fmt.Errorf("invalid variables: %w", err)
+		}
+	}
+	if extensions != "" {
+		hash, err := persistedHash([]byte(extensions))
+		if err != nil {
+			return nil, err
+		}
+		r.hash = hash
+	}
+	return r, nil
+}
+
+func parseRequest(raw map[string]json.RawMessage) (*request, error) {
+	r := &request{raw: raw}
+	if v, has := raw["query"]; has {
+		if err := unmarshal(v, &r.query); err != nil {
+			return nil, fmt.Errorf("invalid query: %w", err)
+		}
+	}
+	if v, has := raw["operationName"]; has {
+		if err := unmarshal(v, &r.operationName); err != nil {
+			return nil, fmt.Errorf("invalid operationName: %w", err)
+		}
+	}
+	if v, has := raw["variables"]; has {
+		if err := unmarshal(v, &r.variables); err != nil {
+			return nil, fmt.Errorf("invalid variables: %w", err)
+		}
+	}
+	if v, has := raw["extensions"]; has {
+		hash, err := persistedHash(v)
+		if err != nil {
+			return nil, err
+		}
+		r.hash = hash
+	}
+	return r, nil
+}
+
+// persistedHash 读取extensions.persistedQuery.sha256Hash
+func persistedHash(data []byte) (string, error) {
+	var extensions struct {
+		PersistedQuery *struct {
+			Version    int    `json:"version"`
+			Sha256Hash string `json:"sha256Hash"`
+		} `json:"persistedQuery"`
+	}
+	if err := unmarshal(data, &extensions); err != nil {
+		return "", fmt.Errorf("invalid extensions: %w", err)
+	}
+	if extensions.PersistedQuery == nil {
+		return "", nil
+	}
+	if extensions.PersistedQuery.Version != 1 {
+		return "", fmt.Errorf("unsupported persisted query version %d", extensions.PersistedQuery.Version)
+	}
+	return strings.ToLower(extensions.PersistedQuery.Sha256Hash), nil
+}
+
+// writeRequests 将持久化查询还原后的完整查询写回转发请求
+func writeRequests(ctx http_service.IHttpContext, requests []*request, batch bool) error {
+	if ctx.Request().Method() == http.MethodGet {
+		ctx.Proxy().URI().SetQuery("query", requests[0].query)
+		return nil
+	}
+	items := make([]map[string]json.RawMessage, 0, len(requests))
+	for _, r := range requests {
+		query, _ := json.Marshal(r.query)
+		r.raw["query"] = query
+		items = append(items, r.raw)
+	}
+	var body []byte
+	var err error
+	if batch {
+		body, err = json.Marshal(items)
+	} else {
+		body, err = json.Marshal(items[0])
+	}
+	if err != nil {
+		return err
+	}
+	ctx.Proxy().Body().SetRaw(ctx.Proxy().Body().ContentType(), body)
+	return nil
+}
+
+func unmarshal(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
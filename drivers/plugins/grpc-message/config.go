@@ -0,0 +1,43 @@
+package grpc_message
+
+import (
+	"fmt"
+
+	"github.com/eolinker/apinto/drivers"
+
+	grpc_descriptor "github.com/eolinker/apinto/grpc-descriptor"
+	"github.com/eolinker/eosc"
+)
+
+type Config struct {
+	ProtobufID     eosc.RequireId `json:"protobuf_id" label:"Protobuf ID" skill:"github.com/eolinker/apinto/grpc-transcode.transcode.IDescriptor" description:"设置后按调用方法的描述解码流消息，无法解码的消息以InvalidArgument结束流"`
+	MaxMessageSize int            `json:"max_message_size" label:"最大消息长度" description:"单位：字节，超出时以ResourceExhausted结束流，0表示不限制" minimum:"0"`
+	MaxMessages    int64          `json:"max_messages" label:"最大请求消息数" description:"单个流允许客户端发送的消息数，超出时以ResourceExhausted结束流，0表示不限制" minimum:"0"`
+}
+
+func getDescriptor(id eosc.RequireId) (grpc_descriptor.IDescriptor, error) {
+	if id == "" {
+		return nil, nil
+	}
+	w, has := workers.Get(string(id))
+	if !has {
+		return nil, fmt.Errorf("%s:%w", id, eosc.ErrorWorkerNotExits)
+	}
+	d, ok := w.(grpc_descriptor.IDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("invalid protobuf id: %s", id)
+	}
+	return d, nil
+}
+
+func Create(id, name string, conf *Config, workers map[eosc.RequireId]eosc.IWorker) (eosc.IWorker, error) {
+	h, err := newHandler(conf)
+	if err != nil {
+		return nil, err
+	}
+	e := &executor{
+		WorkerBase: drivers.Worker(id, name),
+	}
+	e.handler.Store(h)
+	return e, nil
+}
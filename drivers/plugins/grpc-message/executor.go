@@ -0,0 +1,66 @@
+package grpc_message
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/eolinker/apinto/drivers"
+	grpc_context "github.com/eolinker/apinto/node/grpc-context"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/eocontext"
+	grpc_service "github.com/eolinker/eosc/eocontext/grpc-context"
+)
+
+var _ eocontext.IFilter = (*executor)(nil)
+var _ grpc_service.GrpcFilter = (*executor)(nil)
+
+type executor struct {
+	drivers.WorkerBase
+	handler atomic.Pointer[handler]
+}
+
+func (e *executor) DoFilter(ctx eocontext.EoContext, next eocontext.IChain) error {
+	return grpc_service.DoGrpcFilter(e, ctx, next)
+}
+
+func (e *executor) DoGrpcFilter(ctx grpc_service.IGrpcContext, next eocontext.IChain) error {
+	if mc, ok := ctx.(grpc_context.IMessageContext); ok {
+		err := e.handler.Load().bind(ctx, mc)
+		if err != nil {
+			return err
+		}
+	}
+	if next != nil {
+		return next.DoChain(ctx)
+	}
+	return nil
+}
+
+func (e *executor) Start() error {
+	return nil
+}
+
+func (e *executor) Reset(conf interface{}, workers map[eosc.RequireId]eosc.IWorker) error {
+	cfg, ok := conf.(*Config)
+	if !ok {
+		return errors.New("invalid config")
+	}
+	h, err := newHandler(cfg)
+	if err != nil {
+		return err
+	}
+	e.handler.Store(h)
+	return nil
+}
+
+func (e *executor) Stop() error {
+	return nil
+}
+
+func (e *executor) Destroy() {
+	return
+}
+
+func (e *executor) CheckSkill(skill string) bool {
+	return grpc_service.FilterSkillName == skill
+}
@@ -0,0 +1,40 @@
+package grpc_message
+
+import (
+	"sync"
+
+	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/common/bean"
+)
+
+const (
+	Name = "grpc_message"
+)
+
+var (
+	workers eosc.IWorkers
+	once    sync.Once
+)
+
+func Register(register eosc.IExtenderDriverRegister) {
+	register.RegisterExtenderDriver(Name, NewFactory())
+}
+
+type Factory struct {
+	eosc.IExtenderDriverFactory
+}
+
+func NewFactory() *Factory {
+	return &Factory{
+		IExtenderDriverFactory: drivers.NewFactory[Config](Create),
+	}
+}
+
+func (f *Factory) Create(profession string, name string, label string, desc string, params map[string]interface{}) (eosc.IExtenderDriver, error) {
+	once.Do(func() {
+		bean.Autowired(&workers)
+	})
+
+	return f.IExtenderDriverFactory.Create(profession, name, label, desc, params)
+}
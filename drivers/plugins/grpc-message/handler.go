@@ -0,0 +1,79 @@
+package grpc_message
+
+import (
+	grpc_descriptor "github.com/eolinker/apinto/grpc-descriptor"
+	grpc_context "github.com/eolinker/apinto/node/grpc-context"
+	grpc_service "github.com/eolinker/eosc/eocontext/grpc-context"
+	"github.com/jhump/protoreflect/desc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type handler struct {
+	descriptor     grpc_descriptor.IDescriptor
+	maxMessageSize int
+	maxMessages    int64
+}
+
+func newHandler(conf *Config) (*handler, error) {
+	d, err := getDescriptor(conf.ProtobufID)
+	if err != nil {
+		return nil, err
+	}
+	return &handler{
+		descriptor:     d,
+		maxMessageSize: conf.MaxMessageSize,
+		maxMessages:    conf.MaxMessages,
+	}, nil
+}
+
+// bind 在转发前设置方法描述并注册消息处理函数，方法按转发的服务名及方法名解析
+func (h *handler) bind(ctx grpc_service.IGrpcContext, mc grpc_context.IMessageContext) error {
+	if h.descriptor != nil {
+		md, err := h.findMethod(ctx.Proxy().Service(), ctx.Proxy().Method())
+		if err != nil {
+			return err
+		}
+		mc.SetMethodDescriptor(md)
+		mc.AddMessageHandler(func(ctx *grpc_context.Context, msg grpc_context.IMessage) error {
+			_, err := msg.Message()
+			if err != nil {
+				return status.Errorf(codes.InvalidArgument, "invalid %s message %d: %v", msg.Direction(), msg.Index(), err)
+			}
+			return nil
+		})
+	}
+	if h.maxMessageSize > 0 {
+		mc.AddMessageHandler(func(ctx *grpc_context.Context, msg grpc_context.IMessage) error {
+			if size := len(msg.Raw()); size > h.maxMessageSize {
+				return status.Errorf(codes.ResourceExhausted, "%s message %d larger than max (%d vs. %d)", msg.Direction(), msg.Index(), size, h.maxMessageSize)
+			}
+			return nil
+		})
+	}
+	if h.maxMessages > 0 {
+		mc.AddMessageHandler(func(ctx *grpc_context.Context, msg grpc_context.IMessage) error {
+			if msg.Direction() == grpc_context.RequestMessage && msg.Index() > h.maxMessages {
+				return status.Errorf(codes.ResourceExhausted, "request messages exceed the limit %d", h.maxMessages)
+			}
+			return nil
+		})
+	}
+	return nil
+}
+
+func (h *handler) findMethod(service, method string) (*desc.MethodDescriptor, error) {
+	d, err := h.descriptor.Descriptor().FindSymbol(service)
+	if err != nil {
+		return nil, status.Errorf(codes.Unimplemented, "service %s not found in protobuf: %v", service, err)
+	}
+	sd, ok := d.(*desc.ServiceDescriptor)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "%s is not a service", service)
+	}
+	md := sd.FindMethodByName(method)
+	if md == nil {
+		return nil, status.Errorf(codes.Unimplemented, "method %s not found in service %s", method, service)
+	}
+	return md, nil
+}
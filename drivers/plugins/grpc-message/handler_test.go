@@ -0,0 +1,44 @@
+package grpc_message
+
+import (
+	"testing"
+
+	"github.com/fullstorydev/grpcurl"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type testDescriptor struct {
+	source grpcurl.DescriptorSource
+}
+
+func (d *testDescriptor) Descriptor() grpcurl.DescriptorSource {
+	return d.source
+}
+
+func TestFindMethod(t *testing.T) {
+	parser := protoparse.Parser{Accessor: protoparse.FileContentsFromMap(map[string]string{"echo.proto": `syntax = "proto3";
+package test;
+message Msg { string value = 1; }
+service Echo { rpc Chat(stream Msg) returns (stream Msg); }`})}
+	fds, err := parser.ParseFiles("echo.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := grpcurl.DescriptorSourceFromFileDescriptors(fds...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &handler{descriptor: &testDescriptor{source: source}}
+
+	md, err := h.findMethod("test.Echo", "Chat")
+	if err != nil || md.GetInputType().GetFullyQualifiedName() != "test.Msg" {
+		t.Fatalf("find method %v, %v", md, err)
+	}
+	for _, name := range [][2]string{{"test.Echo", "Ping"}, {"test.Msg", "Chat"}, {"test.Unknown", "Chat"}} {
+		if _, err := h.findMethod(name[0], name[1]); status.Code(err) != codes.Unimplemented {
+			t.Errorf("%s/%s: expect unimplemented, got %v", name[0], name[1], err)
+		}
+	}
+}
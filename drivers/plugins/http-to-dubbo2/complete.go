@@ -3,10 +3,8 @@ package http_to_dubbo2
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"time"
 
-	hessian "github.com/apache/dubbo-go-hessian2"
 	"github.com/eolinker/eosc/eocontext"
 	http_service "github.com/eolinker/eosc/eocontext/http-context"
 	"github.com/eolinker/eosc/log"
@@ -19,13 +17,11 @@ var (
 type Complete struct {
 	retry   int
 	timeOut time.Duration
-	service string
-	method  string
-	params  []param
+	target  *target
 }
 
-func NewComplete(retry int, timeOut time.Duration, service string, method string, params []param) *Complete {
-	return &Complete{retry: retry, timeOut: timeOut, service: service, method: method, params: params}
+func NewComplete(retry int, timeOut time.Duration, target *target) *Complete {
+	return &Complete{retry: retry, timeOut: timeOut, target: target}
 }
 
 func (c *Complete) Complete(org eocontext.EoContext) error {
@@ -49,58 +45,15 @@ func (c *Complete) Complete(org eocontext.EoContext) error {
 	}()
 	body, _ := ctx.Proxy().Body().RawBody()
 
-	var types []string
-	var valuesList []hessian.Object
-
-	for _, v := range c.params {
-		types = append(types, v.className)
-	}
-
 	//从body中提取内容
-	if len(c.params) == 1 && c.params[0].fieldName == "" {
-		var val interface{}
-
-		if lastErr = json.Unmarshal(body, &val); lastErr != nil {
-			log.Errorf("doHttpFilter jsonUnmarshal err:%v body:%v", lastErr, body)
-			return lastErr
-		}
-
-		valuesList = append(valuesList, val)
-	} else if len(c.params) == 1 && c.params[0].fieldName != "" {
-		var maps map[string]interface{}
-
-		if lastErr = json.Unmarshal(body, &maps); lastErr != nil {
-			log.Errorf("doHttpFilter jsonUnmarshal err:%v body:%v", lastErr, body)
-			return lastErr
-		}
-
-		if val, ok := maps[c.params[0].fieldName]; ok {
-			valuesList = append(valuesList, val)
-		} else {
-			lastErr = errors.New(fmt.Sprintf("参数解析错误，body中未包含%s的参数名", c.params[0].fieldName))
-			return lastErr
-		}
-
-	} else {
-		var maps map[string]interface{}
-
-		if lastErr = json.Unmarshal(body, &maps); lastErr != nil {
-			log.Errorf("doHttpFilter jsonUnmarshal err:%v body:%v", lastErr, body)
-			return lastErr
-		}
-
-		for _, v := range c.params {
-			if val, ok := maps[v.fieldName]; ok {
-				valuesList = append(valuesList, val)
-			} else {
-				lastErr = errors.New(fmt.Sprintf("参数解析错误，body中未包含%s的参数名", c.params[0].fieldName))
-				return lastErr
-			}
-		}
-
+	values, lastErr := c.target.readArgs(body)
+	if lastErr != nil {
+		log.Errorf("doHttpFilter read args err:%v body:%s", lastErr, body)
+		return lastErr
 	}
+	hint := parameterTypesHint(ctx.Request().Header().GetHeader(parameterTypesHeader))
 
-	client := newDubbo2Client(c.service, c.method, types, valuesList)
+	var client *dubbo2Client
 
 	for index := 0; index <= c.retry; index++ {
 
@@ -115,6 +68,18 @@ func (c *Complete) Complete(org eocontext.EoContext) error {
 			return err
 		}
 
+		if client == nil {
+			// 服务定义需从提供者获取时，在选定节点后再确定参数类型
+			client, lastErr = c.target.client(ctx.Context(), node, c.timeOut, values, hint)
+			if lastErr != nil {
+				if errors.Is(lastErr, errorLoadDefinition) {
+					log.Error("http to dubbo2 load definition error: ", lastErr)
+					continue
+				}
+				return lastErr
+			}
+		}
+
 		var result interface{}
 		result, lastErr = client.dial(ctx.Context(), node, c.timeOut)
 		if lastErr == nil {
@@ -3,10 +3,18 @@ package http_to_dubbo2
 type Config struct {
 	Service string   `json:"service" label:"服务名称" required:"true"`
 	Method  string   `json:"method" label:"方法名称" required:"true"`
-	Params  []*Param `json:"params" label:"参数" required:"true"`
+	Version string   `json:"version" label:"服务版本"`
+	Group   string   `json:"group" label:"服务分组"`
+	Params  []*Param `json:"params" label:"参数" description:"参数类型来源为服务定义时可只填写字段名，class_name用于指定重载方法；未配置时body为json数组则按位置传参，否则作为唯一参数"`
+
+	Metadata        string `json:"metadata" label:"参数类型来源" enum:"config,descriptor,metadata_service" default:"config" description:"config：使用参数中的class_name；descriptor：使用本地服务定义；metadata_service：从提供者的元数据服务获取服务定义"`
+	Descriptor      string `json:"descriptor" label:"服务定义" format:"text" description:"Dubbo服务定义（FullServiceDefinition）的JSON，可以是数组" switch:"metadata==='descriptor'"`
+	MetadataGroup   string `json:"metadata_group" label:"元数据服务分组" description:"一般为提供者的应用名" switch:"metadata==='metadata_service'"`
+	MetadataVersion string `json:"metadata_version" label:"元数据服务版本" default:"1.0.0" switch:"metadata==='metadata_service'"`
+	MetadataRefresh int    `json:"metadata_refresh" label:"服务定义刷新间隔" description:"单位：秒，0表示只获取一次" default:"300" minimum:"0" switch:"metadata==='metadata_service'"`
 }
 
 type Param struct {
-	ClassName string `json:"class_name" label:"class_name" required:"true"` //对应Java中类的class_name
-	FieldName string `json:"field_name" label:"根字段名"`                       //读取body中json的根字段名
+	ClassName string `json:"class_name" label:"class_name"` //对应Java中类的class_name
+	FieldName string `json:"field_name" label:"根字段名"`       //读取body中json的根字段名
 }
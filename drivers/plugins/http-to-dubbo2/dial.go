@@ -18,6 +18,8 @@ type dubbo2Client struct {
 	methodName  string
 	typesList   []string
 	valuesList  []hessian.Object
+	version     string
+	group       string
 }
 
 func newDubbo2Client(serviceName string, methodName string, typesList []string, valuesList []hessian.Object) *dubbo2Client {
@@ -53,6 +55,12 @@ func (d *dubbo2Client) dial(ctx context.Context, node eocontext.INode, timeout t
 		node.Down()
 		return nil, err
 	}
+	if d.version != "" {
+		url.SetParam(constant.VersionKey, d.version)
+	}
+	if d.group != "" {
+		url.SetParam(constant.GroupKey, d.group)
+	}
 
 	dubboProtocol := dubbo.NewDubboProtocol()
 	invoker := dubboProtocol.Refer(url)
@@ -2,6 +2,9 @@ package http_to_dubbo2
 
 import (
 	"errors"
+	"fmt"
+	"time"
+
 	"github.com/eolinker/apinto/drivers"
 	"github.com/eolinker/eosc"
 )
@@ -19,41 +22,74 @@ func check(v interface{}) (*Config, error) {
 		return nil, errors.New("method is null")
 	}
 
-	if len(conf.Params) == 0 {
-		return nil, errors.New("params is null")
+	switch conf.Metadata {
+	case "", MetadataConfig:
+		if len(conf.Params) == 0 {
+			return nil, errors.New("params is null")
+		}
+		for _, p := range conf.Params {
+			if p.ClassName == "" {
+				return nil, errors.New("class_name of param is null")
+			}
+		}
+	case MetadataDescriptor:
+		if conf.Descriptor == "" {
+			return nil, errors.New("descriptor is null")
+		}
+	case MetadataService:
+		if conf.MetadataGroup == "" {
+			return nil, errors.New("metadata group is null")
+		}
+	default:
+		return nil, fmt.Errorf("unknown metadata source %s", conf.Metadata)
 	}
 
 	return conf, nil
 }
 
 func Create(id, name string, conf *Config, workers map[eosc.RequireId]eosc.IWorker) (eosc.IWorker, error) {
-
-	if conf.Service == "" {
-		return nil, errors.New("service is null")
+	conf, err := check(conf)
+	if err != nil {
+		return nil, err
 	}
-	if conf.Method == "" {
-		return nil, errors.New("method is null")
+	t, err := newTarget(conf)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(conf.Params) == 0 {
-		return nil, errors.New("params is null")
+	pw := &ToDubbo2{
+		WorkerBase: drivers.Worker(id, name),
 	}
+	pw.target.Store(t)
 
-	params := make([]param, 0, len(conf.Params))
+	return pw, nil
+}
 
+// newTarget 根据配置生成调用目标，参数类型来源为服务定义时加载服务定义
+func newTarget(conf *Config) (*target, error) {
+	params := make([]param, 0, len(conf.Params))
 	for _, p := range conf.Params {
 		params = append(params, param{
 			className: p.ClassName,
 			fieldName: p.FieldName,
 		})
 	}
-
-	pw := &ToDubbo2{
-		WorkerBase: drivers.Worker(id, name),
-		service:    conf.Service,
-		method:     conf.Method,
-		params:     params,
+	t := &target{
+		service: conf.Service,
+		method:  conf.Method,
+		version: conf.Version,
+		group:   conf.Group,
+		params:  params,
 	}
-
-	return pw, nil
+	switch conf.Metadata {
+	case MetadataDescriptor:
+		loader, err := newStaticLoader(conf.Descriptor, conf.Service)
+		if err != nil {
+			return nil, err
+		}
+		t.loader = loader
+	case MetadataService:
+		t.loader = newMetadataLoader(conf.Service, conf.Version, conf.Group, conf.MetadataGroup, conf.MetadataVersion, time.Duration(conf.MetadataRefresh)*time.Second)
+	}
+	return t, nil
 }
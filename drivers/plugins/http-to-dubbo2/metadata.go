@@ -0,0 +1,290 @@
+package http_to_dubbo2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	hessian "github.com/apache/dubbo-go-hessian2"
+	"github.com/eolinker/eosc/eocontext"
+)
+
+const (
+	MetadataConfig     = "config"
+	MetadataDescriptor = "descriptor"
+	MetadataService    = "metadata_service"
+
+	metadataServiceName    = "org.apache.dubbo.metadata.MetadataService"
+	defaultMetadataVersion = "1.0.0"
+)
+
+var errorDefinitionNotFound = errors.New("service definition not found")
+
+// ServiceDefinition Dubbo服务定义，格式与元数据服务getServiceDefinition返回的FullServiceDefinition一致
+type ServiceDefinition struct {
+	CanonicalName string              `json:"canonicalName"`
+	Methods       []*MethodDefinition `json:"methods"`
+	Types         []*TypeDefinition   `json:"types"`
+}
+
+type MethodDefinition struct {
+	Name           string   `json:"name"`
+	ParameterTypes []string `json:"parameterTypes"`
+	ReturnType     string   `json:"returnType"`
+}
+
+type TypeDefinition struct {
+	Type       string            `json:"type"`
+	Properties map[string]string `json:"properties"`
+	Enums      []string          `json:"enums"`
+	Items      []string          `json:"items"`
+}
+
+// UnmarshalJSON 兼容Dubbo 2.7中属性类型为嵌套类型定义的格式
+func (t *TypeDefinition) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Type       string                     `json:"type"`
+		Properties map[string]json.RawMessage `json:"properties"`
+		Enums      []string                   `json:"enums"`
+		Items      []json.RawMessage          `json:"items"`
+	}
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+	t.Type, t.Enums = v.Type, v.Enums
+	t.Properties = make(map[string]string, len(v.Properties))
+	for name, raw := range v.Properties {
+		t.Properties[name] = typeName(raw)
+	}
+	for _, raw := range v.Items {
+		t.Items = append(t.Items, typeName(raw))
+	}
+	return nil
+}
+
+func typeName(raw json.RawMessage) string {
+	var name string
+	if json.Unmarshal(raw, &name) == nil {
+		return name
+	}
+	var def struct {
+		Type string `json:"type"`
+	}
+	json.Unmarshal(raw, &def)
+	return def.Type
+}
+
+func (d *ServiceDefinition) typeMap() map[string]*TypeDefinition {
+	types := make(map[string]*TypeDefinition, len(d.Types))
+	for _, t := range d.Types {
+		types[t.Type] = t
+	}
+	return types
+}
+
+// resolve 按方法名、参数个数及参数值查找方法，方法存在重载时hint可指定参数类型列表
+func (d *ServiceDefinition) resolve(method string, hint []string, values []interface{}) (*MethodDefinition, error) {
+	candidates := make([]*MethodDefinition, 0, 1)
+	for _, m := range d.Methods {
+		if m.Name == method {
+			candidates = append(candidates, m)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("method %s not found in service %s", method, d.CanonicalName)
+	}
+	if len(hint) > 0 {
+		for _, m := range candidates {
+			if equalTypes(m.ParameterTypes, hint) {
+				return m, nil
+			}
+		}
+		return nil, fmt.Errorf("method %s(%s) not found in service %s", method, strings.Join(hint, ","), d.CanonicalName)
+	}
+	matched := make([]*MethodDefinition, 0, len(candidates))
+	for _, m := range candidates {
+		if len(m.ParameterTypes) == len(values) {
+			matched = append(matched, m)
+		}
+	}
+	if len(matched) > 1 {
+		// 参数个数相同的重载方法按参数值的json类型进一步区分
+		compatible := make([]*MethodDefinition, 0, len(matched))
+		for _, m := range matched {
+			if d.compatible(m, values) {
+				compatible = append(compatible, m)
+			}
+		}
+		matched = compatible
+	}
+	switch len(matched) {
+	case 0:
+		return nil, fmt.Errorf("method %s with %d parameters not found in service %s", method, len(values), d.CanonicalName)
+	case 1:
+		return matched[0], nil
+	}
+	signatures := make([]string, 0, len(matched))
+	for _, m := range matched {
+		signatures = append(signatures, fmt.Sprintf("%s(%s)", m.Name, strings.Join(m.ParameterTypes, ",")))
+	}
+	return nil, fmt.Errorf("ambiguous method %s: %s", method, strings.Join(signatures, "; "))
+}
+
+func (d *ServiceDefinition) compatible(m *MethodDefinition, values []interface{}) bool {
+	types := d.typeMap()
+	for i, v := range values {
+		if !compatible(v, m.ParameterTypes[i], types) {
+			return false
+		}
+	}
+	return true
+}
+
+// compatible 判断json值能否转换为javaType
+func compatible(value interface{}, javaType string, types map[string]*TypeDefinition) bool {
+	raw, _ := splitGeneric(strings.TrimSpace(javaType))
+	if value == nil || raw == "java.lang.Object" {
+		return true
+	}
+	array := strings.HasSuffix(raw, "[]") || isListType(raw)
+	switch value.(type) {
+	case json.Number, float64:
+		switch raw {
+		case "int", "short", "byte", "long", "float", "double",
+			"java.lang.Integer", "java.lang.Short", "java.lang.Byte", "java.lang.Long", "java.lang.Float", "java.lang.Double",
+			"java.math.BigDecimal", "java.math.BigInteger", "java.util.Date", "java.sql.Date", "java.sql.Timestamp":
+			return true
+		}
+		return false
+	case bool:
+		return raw == "boolean" || raw == "java.lang.Boolean"
+	case string:
+		switch raw {
+		case "char", "java.lang.Character", "java.lang.String", "java.lang.CharSequence",
+			"java.math.BigDecimal", "java.math.BigInteger", "java.util.Date", "java.sql.Date", "java.sql.Timestamp":
+			return true
+		}
+		def, has := types[raw]
+		return has && len(def.Enums) > 0
+	case []interface{}:
+		return array
+	case map[string]interface{}:
+		if array || strings.HasPrefix(raw, "java.lang.") || strings.HasPrefix(raw, "java.math.") {
+			return false
+		}
+		def, has := types[raw]
+		return !has || len(def.Enums) == 0
+	}
+	return false
+}
+
+func equalTypes(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if strings.ReplaceAll(a[i], " ", "") != strings.ReplaceAll(b[i], " ", "") {
+			return false
+		}
+	}
+	return true
+}
+
+// parseDefinition 解析服务定义，data可以是单个服务定义或服务定义数组
+func parseDefinition(data []byte, service string) (*ServiceDefinition, error) {
+	data = []byte(strings.TrimSpace(string(data)))
+	if len(data) > 0 && data[0] == '[' {
+		list := make([]*ServiceDefinition, 0)
+		err := json.Unmarshal(data, &list)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range list {
+			if d.CanonicalName == service {
+				return d, nil
+			}
+		}
+		return nil, fmt.Errorf("%s: %w", service, errorDefinitionNotFound)
+	}
+	d := new(ServiceDefinition)
+	err := json.Unmarshal(data, d)
+	if err != nil {
+		return nil, err
+	}
+	if d.CanonicalName != "" && d.CanonicalName != service {
+		return nil, fmt.Errorf("%s: %w", service, errorDefinitionNotFound)
+	}
+	return d, nil
+}
+
+// definitionLoader 从本地服务定义或提供者的元数据服务获取服务定义，元数据服务的结果按refresh间隔缓存
+type definitionLoader struct {
+	static *ServiceDefinition
+
+	service  string
+	metadata *dubbo2Client
+	refresh  time.Duration
+
+	lock     sync.Mutex
+	cached   *ServiceDefinition
+	loadTime time.Time
+}
+
+func newStaticLoader(descriptor string, service string) (*definitionLoader, error) {
+	d, err := parseDefinition([]byte(descriptor), service)
+	if err != nil {
+		return nil, fmt.Errorf("parse service definition error: %w", err)
+	}
+	return &definitionLoader{static: d}, nil
+}
+
+func newMetadataLoader(service, version, group, metadataGroup, metadataVersion string, refresh time.Duration) *definitionLoader {
+	if metadataVersion == "" {
+		metadataVersion = defaultMetadataVersion
+	}
+	client := newDubbo2Client(metadataServiceName, "getServiceDefinition",
+		[]string{"java.lang.String", "java.lang.String", "java.lang.String"},
+		[]hessian.Object{service, version, group})
+	client.version = metadataVersion
+	client.group = metadataGroup
+	return &definitionLoader{
+		service:  service,
+		metadata: client,
+		refresh:  refresh,
+	}
+}
+
+func (l *definitionLoader) load(ctx context.Context, node eocontext.INode, timeout time.Duration) (*ServiceDefinition, error) {
+	if l.static != nil {
+		return l.static, nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.cached != nil && (l.refresh <= 0 || time.Since(l.loadTime) < l.refresh) {
+		return l.cached, nil
+	}
+	result, err := l.metadata.dial(ctx, node, timeout)
+	if err != nil {
+		if l.cached != nil {
+			// 刷新失败时继续使用已获取的定义
+			return l.cached, nil
+		}
+		return nil, fmt.Errorf("load service definition from metadata service error: %w", err)
+	}
+	data, ok := result.(string)
+	if !ok || data == "" {
+		return nil, fmt.Errorf("%s: %w", l.service, errorDefinitionNotFound)
+	}
+	d, err := parseDefinition([]byte(data), l.service)
+	if err != nil {
+		return nil, err
+	}
+	l.cached = d
+	l.loadTime = time.Now()
+	return d, nil
+}
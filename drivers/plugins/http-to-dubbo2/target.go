@@ -0,0 +1,137 @@
+package http_to_dubbo2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	hessian "github.com/apache/dubbo-go-hessian2"
+	"github.com/eolinker/eosc/eocontext"
+)
+
+// parameterTypesHeader 客户端可通过该头部指定重载方法的参数类型，多个类型以逗号分隔
+const parameterTypesHeader = "X-Dubbo-Parameter-Types"
+
+var errorLoadDefinition = errors.New("load service definition failed")
+
+// target 泛化调用的目标方法
+type target struct {
+	service string
+	method  string
+	version string
+	group   string
+	params  []param
+	loader  *definitionLoader
+}
+
+// readArgs 从body中读取参数值：配置了参数字段名时按字段名读取，
+// 否则body为json数组时按位置传参，其他情况下body作为唯一参数
+func (t *target) readArgs(body []byte) ([]interface{}, error) {
+	var val interface{}
+	if len(bytes.TrimSpace(body)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		err := decoder.Decode(&val)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(t.params) == 1 && t.params[0].fieldName == "" {
+		return []interface{}{val}, nil
+	}
+	if len(t.params) > 0 {
+		maps, ok := val.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("参数解析错误，body需为json对象")
+		}
+		values := make([]interface{}, 0, len(t.params))
+		for _, p := range t.params {
+			v, ok := maps[p.fieldName]
+			if !ok {
+				return nil, fmt.Errorf("参数解析错误，body中未包含%s的参数名", p.fieldName)
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	}
+	if list, ok := val.([]interface{}); ok {
+		return list, nil
+	}
+	if val == nil {
+		return []interface{}{}, nil
+	}
+	return []interface{}{val}, nil
+}
+
+// client 确定参数类型并按类型转换参数值，生成泛化调用的客户端
+func (t *target) client(ctx context.Context, node eocontext.INode, timeout time.Duration, values []interface{}, hint []string) (*dubbo2Client, error) {
+	var types []string
+	var converter *typeConverter
+	if t.loader == nil {
+		types = make([]string, 0, len(t.params))
+		for _, p := range t.params {
+			types = append(types, p.className)
+		}
+		converter = newTypeConverter(nil)
+	} else {
+		def, err := t.loader.load(ctx, node, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errorLoadDefinition, err)
+		}
+		if len(hint) == 0 {
+			hint = t.classNames()
+		}
+		m, err := def.resolve(t.method, hint, values)
+		if err != nil {
+			return nil, err
+		}
+		types = m.ParameterTypes
+		converter = newTypeConverter(def.typeMap())
+	}
+	if len(types) != len(values) {
+		return nil, fmt.Errorf("参数解析错误，方法%s需要%d个参数，实际为%d个", t.method, len(types), len(values))
+	}
+	args := make([]hessian.Object, 0, len(values))
+	invokeTypes := make([]string, 0, len(types))
+	for i, v := range values {
+		arg, err := converter.convert(v, types[i], fmt.Sprintf("args[%d]", i))
+		if err != nil {
+			return nil, fmt.Errorf("参数解析错误，%w", err)
+		}
+		args = append(args, arg)
+		// 泛化调用的参数类型不包含泛型参数
+		raw, _ := splitGeneric(types[i])
+		invokeTypes = append(invokeTypes, raw)
+	}
+	client := newDubbo2Client(t.service, t.method, invokeTypes, args)
+	client.version = t.version
+	client.group = t.group
+	return client, nil
+}
+
+// classNames 参数均配置了class_name时，作为选择重载方法的参数类型
+func (t *target) classNames() []string {
+	if len(t.params) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(t.params))
+	for _, p := range t.params {
+		if p.className == "" {
+			return nil
+		}
+		names = append(names, p.className)
+	}
+	return names
+}
+
+func parameterTypesHint(header string) []string {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return nil
+	}
+	return splitTypes(header)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/eolinker/eosc"
 	"github.com/eolinker/eosc/eocontext"
 	http_context "github.com/eolinker/eosc/eocontext/http-context"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,9 +16,7 @@ var _ http_context.HttpFilter = (*ToDubbo2)(nil)
 
 type ToDubbo2 struct {
 	drivers.WorkerBase
-	service string
-	method  string
-	params  []param
+	target atomic.Pointer[target]
 }
 
 func (p *ToDubbo2) DoHttpFilter(ctx http_context.IHttpContext, next eocontext.IChain) error {
@@ -34,7 +33,7 @@ func (p *ToDubbo2) DoHttpFilter(ctx http_context.IHttpContext, next eocontext.IC
 		timeout = router.DefaultTimeout
 	}
 
-	complete := NewComplete(retry, timeout, p.service, p.method, p.params)
+	complete := NewComplete(retry, timeout, p.target.Load())
 	ctx.SetCompleteHandler(complete)
 
 	if next != nil {
@@ -62,18 +61,11 @@ func (p *ToDubbo2) Reset(v interface{}, workers map[eosc.RequireId]eosc.IWorker)
 	if err != nil {
 		return err
 	}
-	p.service = conf.Service
-	p.method = conf.Method
-
-	params := make([]param, 0, len(conf.Params))
-
-	for _, val := range conf.Params {
-		params = append(params, param{
-			className: val.ClassName,
-			fieldName: val.FieldName,
-		})
+	t, err := newTarget(conf)
+	if err != nil {
+		return err
 	}
-	p.params = params
+	p.target.Store(t)
 	return nil
 }
 
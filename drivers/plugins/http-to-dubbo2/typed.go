@@ -0,0 +1,302 @@
+package http_to_dubbo2
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	hessian "github.com/apache/dubbo-go-hessian2"
+)
+
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// typeConverter 按Java类型将json解析出的值转换为泛化调用的参数，类型定义来自Dubbo服务定义
+type typeConverter struct {
+	types map[string]*TypeDefinition
+}
+
+func newTypeConverter(types map[string]*TypeDefinition) *typeConverter {
+	return &typeConverter{types: types}
+}
+
+// convert 将json值转换为javaType对应的参数值，path用于错误提示
+func (c *typeConverter) convert(value interface{}, javaType string, path string) (hessian.Object, error) {
+	if value == nil {
+		return nil, nil
+	}
+	javaType = strings.TrimSpace(javaType)
+	raw, args := splitGeneric(javaType)
+	if strings.HasSuffix(raw, "[]") {
+		return c.convertList(value, strings.TrimSuffix(raw, "[]"), path)
+	}
+	switch raw {
+	case "int", "short", "byte", "java.lang.Integer", "java.lang.Short", "java.lang.Byte":
+		v, err := toInt(value, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return int32(v), nil
+	case "long", "java.lang.Long":
+		v, err := toInt(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return v, nil
+	case "float", "double", "java.lang.Float", "java.lang.Double":
+		v, err := toFloat(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return v, nil
+	case "boolean", "java.lang.Boolean":
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			return b, nil
+		}
+		return nil, fmt.Errorf("%s: %v is not a boolean", path, value)
+	case "char", "java.lang.Character", "java.lang.String", "java.lang.CharSequence":
+		return toString(value), nil
+	case "java.math.BigDecimal", "java.math.BigInteger":
+		// 以字符串传递以保留精度，由提供者端转换为对应类型
+		s := toString(value)
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return nil, fmt.Errorf("%s: %s is not a number", path, s)
+		}
+		return s, nil
+	case "java.util.Date", "java.sql.Date", "java.sql.Timestamp":
+		t, err := toTime(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return t, nil
+	case "java.lang.Object", "":
+		return normalize(value), nil
+	}
+	if isListType(raw) {
+		item := "java.lang.Object"
+		if len(args) > 0 {
+			item = args[0]
+		}
+		return c.convertList(value, item, path)
+	}
+	if isMapType(raw) {
+		key, item := "java.lang.String", "java.lang.Object"
+		if len(args) == 2 {
+			key, item = args[0], args[1]
+		}
+		return c.convertMap(value, key, item, path)
+	}
+	def, has := c.types[raw]
+	if !has {
+		// 没有类型定义时原样传递，由提供者端按参数类型转换
+		return normalize(value), nil
+	}
+	if len(def.Enums) > 0 {
+		s := toString(value)
+		for _, e := range def.Enums {
+			if e == s {
+				return s, nil
+			}
+		}
+		return nil, fmt.Errorf("%s: %s is not a constant of enum %s", path, s, raw)
+	}
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: %s requires a json object", path, raw)
+	}
+	pojo := make(map[string]interface{}, len(fields)+1)
+	pojo["class"] = raw
+	for name, v := range fields {
+		fieldType, has := def.Properties[name]
+		if !has {
+			// 未定义的字段不传递给提供者
+			continue
+		}
+		fv, err := c.convert(v, fieldType, path+"."+name)
+		if err != nil {
+			return nil, err
+		}
+		pojo[name] = fv
+	}
+	return pojo, nil
+}
+
+func (c *typeConverter) convertList(value interface{}, item string, path string) (hessian.Object, error) {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: requires a json array", path)
+	}
+	result := make([]interface{}, 0, len(list))
+	for i, v := range list {
+		iv, err := c.convert(v, item, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, iv)
+	}
+	return result, nil
+}
+
+func (c *typeConverter) convertMap(value interface{}, key string, item string, path string) (hessian.Object, error) {
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: requires a json object", path)
+	}
+	result := make(map[interface{}]interface{}, len(fields))
+	for k, v := range fields {
+		// json对象的key均为字符串，按声明的key类型转换
+		kv, err := c.convert(k, key, path+"."+k)
+		if err != nil {
+			return nil, err
+		}
+		iv, err := c.convert(v, item, path+"."+k)
+		if err != nil {
+			return nil, err
+		}
+		result[kv] = iv
+	}
+	return result, nil
+}
+
+func isListType(t string) bool {
+	switch t {
+	case "java.util.List", "java.util.ArrayList", "java.util.LinkedList", "java.util.Collection",
+		"java.util.Set", "java.util.HashSet", "java.util.LinkedHashSet", "java.util.TreeSet", "java.lang.Iterable":
+		return true
+	}
+	return false
+}
+
+func isMapType(t string) bool {
+	switch t {
+	case "java.util.Map", "java.util.HashMap", "java.util.LinkedHashMap", "java.util.TreeMap", "java.util.concurrent.ConcurrentHashMap":
+		return true
+	}
+	return false
+}
+
+// splitGeneric 拆分泛型类型，如java.util.Map<java.lang.String,java.util.List<com.acme.Item>>
+func splitGeneric(t string) (string, []string) {
+	start := strings.Index(t, "<")
+	if start < 0 || !strings.HasSuffix(t, ">") {
+		return t, nil
+	}
+	return t[:start], splitTypes(t[start+1 : len(t)-1])
+}
+
+// splitTypes 拆分以逗号分隔的类型列表，忽略泛型参数中的逗号
+func splitTypes(s string) []string {
+	types := make([]string, 0, 2)
+	depth, last := 0, 0
+	for i, ch := range s {
+		switch ch {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				types = append(types, strings.TrimSpace(s[last:i]))
+				last = i + 1
+			}
+		}
+	}
+	return append(types, strings.TrimSpace(s[last:]))
+}
+
+func toInt(value interface{}, bits int) (int64, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return strconv.ParseInt(v.String(), 10, bits)
+	case float64:
+		if v != float64(int64(v)) {
+			return 0, fmt.Errorf("%v is not an integer", v)
+		}
+		return int64(v), nil
+	case string:
+		return strconv.ParseInt(v, 10, bits)
+	}
+	return 0, fmt.Errorf("%v is not an integer", value)
+}
+
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return v.Float64()
+	case float64:
+		return v, nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	}
+	return 0, fmt.Errorf("%v is not a number", value)
+}
+
+// toTime 日期支持毫秒时间戳及常用的字符串格式
+func toTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case json.Number:
+		ms, err := v.Int64()
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.UnixMilli(ms), nil
+	case float64:
+		return time.UnixMilli(int64(v)), nil
+	case string:
+		for _, layout := range dateLayouts {
+			t, err := time.ParseInLocation(layout, v, time.Local)
+			if err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("%s is not a valid date", v)
+	}
+	return time.Time{}, fmt.Errorf("%v is not a valid date", value)
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// normalize 将json.Number转换为整数或浮点数
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for i := range v {
+			v[i] = normalize(v[i])
+		}
+		return v
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = normalize(v[k])
+		}
+		return v
+	}
+	return value
+}
@@ -0,0 +1,79 @@
+package http_to_dubbo2
+
+import (
+	"testing"
+	"time"
+)
+
+const testDefinition = `{
+	"canonicalName": "com.acme.OrderService",
+	"methods": [
+		{"name": "find", "parameterTypes": ["long"], "returnType": "com.acme.Order"},
+		{"name": "find", "parameterTypes": ["java.lang.String"], "returnType": "com.acme.Order"},
+		{"name": "create", "parameterTypes": ["com.acme.Order", "java.util.Map<java.lang.String,java.lang.Integer>"], "returnType": "long"}
+	],
+	"types": [
+		{"type": "com.acme.Order", "properties": {"id": "long", "amount": "java.math.BigDecimal", "createdAt": "java.util.Date", "status": "com.acme.Status", "items": "java.util.List<com.acme.Item>"}},
+		{"type": "com.acme.Item", "properties": {"sku": "java.lang.String", "count": "int"}},
+		{"type": "com.acme.Status", "enums": ["NEW", "PAID"]}
+	]
+}`
+
+func TestGenericArgs(t *testing.T) {
+	def, err := parseDefinition([]byte(testDefinition), "com.acme.OrderService")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tg := &target{service: "com.acme.OrderService", method: "create"}
+	values, err := tg.readArgs([]byte(`[{"id": 9007199254740993, "amount": 12.30, "createdAt": "2023-01-02 03:04:05", "status": "PAID", "items": [{"sku": "a", "count": 2, "unknown": 1}]}, {"x": 1}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := def.resolve("create", nil, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newTypeConverter(def.typeMap())
+	order, err := c.convert(values[0], m.ParameterTypes[0], "args[0]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pojo := order.(map[string]interface{})
+	if pojo["class"] != "com.acme.Order" || pojo["id"] != int64(9007199254740993) || pojo["amount"] != "12.30" || pojo["status"] != "PAID" {
+		t.Fatalf("unexpected order: %v", pojo)
+	}
+	if _, ok := pojo["createdAt"].(time.Time); !ok {
+		t.Fatalf("createdAt is not a date: %v", pojo["createdAt"])
+	}
+	item := pojo["items"].([]interface{})[0].(map[string]interface{})
+	if item["count"] != int32(2) || len(item) != 3 {
+		t.Fatalf("unexpected item: %v", item)
+	}
+	counts, err := c.convert(values[1], m.ParameterTypes[1], "args[1]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts.(map[interface{}]interface{})["x"] != int32(1) {
+		t.Fatalf("unexpected map: %v", counts)
+	}
+
+	_, err = c.convert(map[string]interface{}{"status": "CLOSED"}, "com.acme.Order", "args[0]")
+	if err == nil {
+		t.Fatal("expected enum error")
+	}
+
+	// 重载方法按参数值类型选择
+	m, err = def.resolve("find", nil, []interface{}{"A-1"})
+	if err != nil || m.ParameterTypes[0] != "java.lang.String" {
+		t.Fatalf("resolve find(String): %v %v", m, err)
+	}
+	values, _ = tg.readArgs([]byte(`12`))
+	m, err = def.resolve("find", nil, values)
+	if err != nil || m.ParameterTypes[0] != "long" {
+		t.Fatalf("resolve find(long): %v %v", m, err)
+	}
+	m, err = def.resolve("find", []string{"java.lang.String"}, values)
+	if err != nil || m.ParameterTypes[0] != "java.lang.String" {
+		t.Fatalf("resolve find with hint: %v %v", m, err)
+	}
+}
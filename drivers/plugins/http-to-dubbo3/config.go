@@ -0,0 +1,44 @@
+package http_to_dubbo3
+
+import (
+	http_to_grpc "github.com/eolinker/apinto/drivers/plugins/http-to-gRPC"
+	grpc_context "github.com/eolinker/apinto/node/grpc-context"
+	"github.com/eolinker/eosc"
+)
+
+type Config struct {
+	Service      string            `json:"service" label:"服务名称" description:"Triple服务的接口名，为空时从请求路径中读取；仅支持基于IDL（protobuf）定义的Triple服务，不支持以Hessian或JSON包装参数的非IDL调用"`
+	Method       string            `json:"method" label:"方法名称"`
+	Version      string            `json:"version" label:"服务版本"`
+	Group        string            `json:"group" label:"服务分组"`
+	Authority    string            `json:"authority" label:"虚拟主机域名(Authority)"`
+	Reflect      bool              `json:"reflect" label:"反射"`
+	ProtobufID   eosc.RequireId    `json:"protobuf_id" required:"false" label:"Protobuf ID" skill:"github.com/eolinker/apinto/grpc-transcode.transcode.IDescriptor" switch:"reflect === false"`
+	Headers      map[string]string `json:"headers" label:"额外头部"`
+	StreamFormat string            `json:"stream_format" label:"流式响应格式" enum:"ndjson,sse" default:"ndjson" description:"服务端流式方法的响应以换行分隔的JSON（ndjson）或SSE事件逐条返回"`
+}
+
+// toGrpc Triple兼容gRPC，以gRPC方式调用，服务版本及分组通过tri-头部传递。
+// 请求及响应按protobuf描述编解码，非IDL服务以Hessian等序列化包装的消息无法转换
+func (c *Config) toGrpc() *http_to_grpc.Config {
+	headers := make(map[string]string, len(c.Headers)+2)
+	for k, v := range c.Headers {
+		headers[k] = v
+	}
+	if c.Version != "" {
+		headers[grpc_context.TripleServiceVersion] = c.Version
+	}
+	if c.Group != "" {
+		headers[grpc_context.TripleServiceGroup] = c.Group
+	}
+	return &http_to_grpc.Config{
+		Service:      c.Service,
+		Method:       c.Method,
+		Authority:    c.Authority,
+		Format:       "json",
+		Reflect:      c.Reflect,
+		ProtobufID:   c.ProtobufID,
+		Headers:      headers,
+		StreamFormat: c.StreamFormat,
+	}
+}
@@ -0,0 +1,66 @@
+package http_to_dubbo3
+
+import (
+	"testing"
+
+	grpc_context "github.com/eolinker/apinto/node/grpc-context"
+)
+
+func TestConfigToGrpc(t *testing.T) {
+	cfg := &Config{
+		Service:      "org.apache.dubbo.demo.GreeterService",
+		Method:       "SayHello",
+		Version:      "1.0.0",
+		Group:        "gray",
+		ProtobufID:   "greeter@protobuf",
+		Headers:      map[string]string{"x-app": "apinto"},
+		StreamFormat: "sse",
+	}
+	conf := cfg.toGrpc()
+	if conf.Service != cfg.Service || conf.Method != cfg.Method || conf.ProtobufID != cfg.ProtobufID {
+		t.Fatalf("unexpected target: %s/%s %s", conf.Service, conf.Method, conf.ProtobufID)
+	}
+	if conf.Format != "json" || conf.StreamFormat != "sse" {
+		t.Errorf("format %s, stream format %s", conf.Format, conf.StreamFormat)
+	}
+	want := map[string]string{
+		"x-app":                           "apinto",
+		grpc_context.TripleServiceVersion: "1.0.0",
+		grpc_context.TripleServiceGroup:   "gray",
+	}
+	if len(conf.Headers) != len(want) {
+		t.Fatalf("headers %v, want %v", conf.Headers, want)
+	}
+	for k, v := range want {
+		if conf.Headers[k] != v {
+			t.Errorf("header %s: got %q, want %q", k, conf.Headers[k], v)
+		}
+	}
+	if len(cfg.Headers) != 1 {
+		t.Errorf("config headers modified: %v", cfg.Headers)
+	}
+
+	conf = (&Config{Reflect: true}).toGrpc()
+	if _, has := conf.Headers[grpc_context.TripleServiceVersion]; has {
+		t.Errorf("unexpected version header: %v", conf.Headers)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *Config
+		wantErr bool
+	}{
+		{name: "protobuf", cfg: &Config{ProtobufID: "greeter@protobuf"}},
+		{name: "reflect", cfg: &Config{Reflect: true, StreamFormat: "ndjson"}},
+		{name: "no protobuf", cfg: &Config{}, wantErr: true},
+		{name: "stream format", cfg: &Config{Reflect: true, StreamFormat: "xml"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		err := Check(tt.cfg, nil)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
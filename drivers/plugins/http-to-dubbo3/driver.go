@@ -0,0 +1,47 @@
+package http_to_dubbo3
+
+import (
+	"fmt"
+
+	"github.com/eolinker/apinto/drivers"
+	http_to_grpc "github.com/eolinker/apinto/drivers/plugins/http-to-gRPC"
+	"github.com/eolinker/apinto/plugin"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/eocontext"
+)
+
+const (
+	Name = "http_to_dubbo3"
+)
+
+func Register(register eosc.IExtenderDriverRegister) {
+	plugin.DeclareBodyRequired(Name)
+	register.RegisterExtenderDriver(Name, NewFactory())
+}
+
+func NewFactory() eosc.IExtenderDriverFactory {
+	return drivers.NewFactory[Config](Create, Check)
+}
+
+func Check(cfg *Config, workers map[eosc.RequireId]eosc.IWorker) error {
+	if !cfg.Reflect && cfg.ProtobufID == "" {
+		return fmt.Errorf("protobuf id is empty")
+	}
+	switch cfg.StreamFormat {
+	case "", http_to_grpc.StreamFormatNDJSON, http_to_grpc.StreamFormatSSE:
+	default:
+		return fmt.Errorf("invalid stream format: %s", cfg.StreamFormat)
+	}
+	return nil
+}
+
+func Create(id, name string, conf *Config, workers map[eosc.RequireId]eosc.IWorker) (eosc.IWorker, error) {
+	w, err := http_to_grpc.Create(id, name, conf.toGrpc(), workers)
+	if err != nil {
+		return nil, err
+	}
+	return &toDubbo3{
+		IWorker: w,
+		filter:  w.(eocontext.IFilter),
+	}, nil
+}
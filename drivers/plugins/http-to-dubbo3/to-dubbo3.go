@@ -0,0 +1,29 @@
+package http_to_dubbo3
+
+import (
+	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/eocontext"
+)
+
+// toDubbo3 将http请求转换为Dubbo3 Triple调用，转换过程复用http转gRPC插件
+type toDubbo3 struct {
+	eosc.IWorker
+	filter eocontext.IFilter
+}
+
+func (t *toDubbo3) DoFilter(ctx eocontext.EoContext, next eocontext.IChain) (err error) {
+	return t.filter.DoFilter(ctx, next)
+}
+
+func (t *toDubbo3) Destroy() {
+	t.filter.Destroy()
+}
+
+func (t *toDubbo3) Reset(conf interface{}, workers map[eosc.RequireId]eosc.IWorker) error {
+	cfg, err := drivers.Assert[Config](conf)
+	if err != nil {
+		return err
+	}
+	return t.IWorker.Reset(cfg.toGrpc(), workers)
+}
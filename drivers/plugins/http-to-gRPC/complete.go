@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -43,17 +44,19 @@ var (
 )
 
 type complete struct {
-	format     grpcurl.Format
-	descriptor grpc_descriptor.IDescriptor
-	authority  string
-	service    string
-	method     string
-	headers    map[string]string
-	reflect    bool
+	format       grpcurl.Format
+	descriptor   grpc_descriptor.IDescriptor
+	authority    string
+	service      string
+	method       string
+	headers      map[string]string
+	reflect      bool
+	transcoder   *transcoder
+	streamFormat string
 }
 
 func newComplete(descriptor grpc_descriptor.IDescriptor, conf *Config) *complete {
-	return &complete{
+	c := &complete{
 		format:     grpcurl.Format(conf.Format),
 		descriptor: descriptor,
 		authority:  conf.Authority,
@@ -62,6 +65,14 @@ func newComplete(descriptor grpc_descriptor.IDescriptor, conf *Config) *complete
 		reflect:    conf.Reflect,
 		headers:    conf.Headers,
 	}
+	c.streamFormat = conf.StreamFormat
+	if c.streamFormat != StreamFormatSSE {
+		c.streamFormat = StreamFormatNDJSON
+	}
+	if conf.Transcode && !conf.Reflect {
+		c.transcoder = new(transcoder)
+	}
+	return c
 }
 
 func getSymbol(path string, service string, method string) string {
@@ -101,6 +112,39 @@ func (h *complete) Complete(org eocontext.EoContext) error {
 		timeout = router.DefaultTimeout
 	}
 
+	symbol := getSymbol(ctx.Proxy().URI().Path(), h.service, h.method)
+	var rule *transcodeRule
+	if h.transcoder != nil {
+		var vars map[string]string
+		rule, vars, err = h.transcoder.match(h.descriptor.Descriptor(), ctx.Proxy().Method(), ctx.Proxy().URI().Path())
+		if err != nil {
+			return err
+		}
+		if rule == nil {
+			data, _ := json.Marshal(StatusErr{
+				Code: fmt.Sprintf("%s", codes.NotFound),
+				Msg:  fmt.Sprintf("no grpc method matches %s %s", ctx.Proxy().Method(), ctx.Proxy().URI().Path()),
+			})
+			ctx.Response().SetStatus(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+			ctx.Response().SetHeader("content-type", "application/json")
+			ctx.Response().SetBody(data)
+			return nil
+		}
+		query, _ := url.ParseQuery(ctx.Proxy().URI().RawQuery())
+		body, err = rule.buildRequest(vars, query, body)
+		if err != nil {
+			data, _ := json.Marshal(StatusErr{
+				Code: fmt.Sprintf("%s", codes.InvalidArgument),
+				Msg:  err.Error(),
+			})
+			ctx.Response().SetStatus(http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
+			ctx.Response().SetHeader("content-type", "application/json")
+			ctx.Response().SetBody(data)
+			return nil
+		}
+		symbol = rule.symbol
+	}
+
 	in := strings.NewReader(string(body))
 
 	balance := ctx.GetBalance()
@@ -109,7 +153,6 @@ func (h *complete) Complete(org eocontext.EoContext) error {
 	newCtx := ctx.Context()
 	opts := genDialOpts(balance.Scheme() == "https", h.authority)
 
-	symbol := getSymbol(ctx.Proxy().URI().Path(), h.service, h.method)
 	var lastErr error
 	var conn *grpc.ClientConn
 	for i := retry + 1; i > 0; i-- {
@@ -127,7 +170,13 @@ func (h *complete) Complete(org eocontext.EoContext) error {
 	if lastErr != nil {
 		return lastErr
 	}
-	defer conn.Close()
+	// 服务端流式调用的连接在调用结束后由invokeStream关闭
+	streaming := false
+	defer func() {
+		if !streaming {
+			conn.Close()
+		}
+	}()
 	var descSource grpcurl.DescriptorSource
 	if h.reflect {
 		refClient := grpcreflect.NewClientV1Alpha(newCtx, reflectpb.NewServerReflectionClient(conn))
@@ -145,6 +194,10 @@ func (h *complete) Complete(org eocontext.EoContext) error {
 	if err != nil {
 		return fmt.Errorf("failed to construct request parser and formatter for %s", h.format)
 	}
+	if isServerStreaming(descSource, symbol) {
+		streaming = true
+		return h.invokeStream(ctx, conn, descSource, symbol, md, formatter, rf.Next)
+	}
 	response := NewResponse()
 	handler := &grpcurl.DefaultEventHandler{
 		VerbosityLevel: 2,
@@ -174,6 +227,10 @@ func (h *complete) Complete(org eocontext.EoContext) error {
 		ctx.Response().SetHeader(key, value)
 	}
 	ctx.Response().SetHeader("content-type", "application/json")
+	if rule != nil {
+		ctx.Response().SetBody(rule.buildResponse(response.Body()))
+		return nil
+	}
 	ctx.Response().SetBody(response.Body())
 	return nil
 }
@@ -3,11 +3,13 @@ package http_to_grpc
 import "github.com/eolinker/eosc"
 
 type Config struct {
-	Service    string            `json:"service" label:"服务名称"`
-	Method     string            `json:"method" label:"方法名称"`
-	Authority  string            `json:"authority" label:"虚拟主机域名(Authority)"`
-	Format     string            `json:"format" label:"数据格式" enum:"json"`
-	Reflect    bool              `json:"reflect" label:"反射"`
-	ProtobufID eosc.RequireId    `json:"protobuf_id" required:"false" label:"Protobuf ID" skill:"github.com/eolinker/apinto/grpc-transcode.transcode.IDescriptor" switch:"reflect === false"`
-	Headers    map[string]string `json:"headers" label:"额外头部"`
+	Service      string            `json:"service" label:"服务名称"`
+	Method       string            `json:"method" label:"方法名称"`
+	Authority    string            `json:"authority" label:"虚拟主机域名(Authority)"`
+	Format       string            `json:"format" label:"数据格式" enum:"json"`
+	Reflect      bool              `json:"reflect" label:"反射"`
+	ProtobufID   eosc.RequireId    `json:"protobuf_id" required:"false" label:"Protobuf ID" skill:"github.com/eolinker/apinto/grpc-transcode.transcode.IDescriptor" switch:"reflect === false"`
+	Headers      map[string]string `json:"headers" label:"额外头部"`
+	StreamFormat string            `json:"stream_format" label:"流式响应格式" enum:"ndjson,sse" default:"ndjson" description:"服务端流式方法的响应以换行分隔的JSON（ndjson）或SSE事件逐条返回；客户端流式方法的请求body为依次拼接的多个JSON对象（可用换行分隔），逐个作为请求消息发送"`
+	Transcode    bool              `json:"transcode" label:"REST转码" description:"根据protobuf描述中的google.api.http注解匹配请求方法与路径，自动绑定路径参数、query参数及body，开启后忽略服务名称与方法名称" switch:"reflect === false"`
 }
@@ -0,0 +1,184 @@
+package http_to_grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/eolinker/eosc/log"
+	"github.com/fullstorydev/grpcurl"
+	"github.com/golang/protobuf/proto"
+	"github.com/jhump/protoreflect/desc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	node_http_context "github.com/eolinker/apinto/node/http-context"
+	http_context "github.com/eolinker/eosc/eocontext/http-context"
+)
+
+const (
+	StreamFormatNDJSON = "ndjson"
+	StreamFormatSSE    = "sse"
+)
+
+// isServerStreaming 判断方法是否为服务端流式方法
+func isServerStreaming(source grpcurl.DescriptorSource, symbol string) bool {
+	i := strings.LastIndex(symbol, "/")
+	if i < 0 {
+		return false
+	}
+	d, err := source.FindSymbol(symbol[:i])
+	if err != nil {
+		return false
+	}
+	sd, ok := d.(*desc.ServiceDescriptor)
+	if !ok {
+		return false
+	}
+	md := sd.FindMethodByName(symbol[i+1:])
+	return md != nil && md.IsServerStreaming()
+}
+
+// invokeStream 调用服务端流式方法，收到第一条响应后即开始以NDJSON或SSE格式逐条返回给客户端，
+// 在此之前结束的调用按一元调用的方式返回错误；响应流写完后才执行访问日志等OnStreamEnd回调
+func (h *complete) invokeStream(ctx http_context.IHttpContext, conn *grpc.ClientConn, descSource grpcurl.DescriptorSource, symbol string, md []string, formatter grpcurl.Formatter, next grpcurl.RequestSupplier) error {
+	setter, ok := ctx.(node_http_context.IResponseStreamSetter)
+	if !ok {
+		conn.Close()
+		return fmt.Errorf("response of %s can not be streamed", symbol)
+	}
+	invokeCtx, cancel := context.WithCancel(ctx.Context())
+	pr, pw := io.Pipe()
+	handler := &streamHandler{
+		format:    h.streamFormat,
+		formatter: formatter,
+		writer:    pw,
+		cancel:    cancel,
+		ready:     make(chan struct{}),
+	}
+	go func() {
+		defer conn.Close()
+		defer cancel()
+		err := grpcurl.InvokeRPC(invokeCtx, descSource, conn, symbol, md, handler, next)
+		if err != nil {
+			handler.finish(status.New(codes.Unavailable, fmt.Sprintf("error invoking method %s,error: %v", symbol, err)))
+		}
+		pw.Close()
+	}()
+	<-handler.ready
+
+	if !handler.started && handler.status.Code() != codes.OK {
+		// 未收到任何响应，直接返回错误
+		pr.Close()
+		data, _ := json.Marshal(StatusErr{
+			Code: fmt.Sprintf("%s", handler.status.Code()),
+			Msg:  handler.status.Message(),
+		})
+		ctx.Response().SetHeader("content-type", "application/json")
+		ctx.Response().SetBody(data)
+		return handler.status.Err()
+	}
+	for key, values := range handler.header {
+		ctx.Response().SetHeader(key, strings.Join(values, ","))
+	}
+	if h.streamFormat == StreamFormatSSE {
+		ctx.Response().SetHeader("content-type", "text/event-stream")
+		ctx.Response().SetHeader("cache-control", "no-cache")
+	} else {
+		ctx.Response().SetHeader("content-type", "application/x-ndjson")
+	}
+	ctx.Response().DelHeader("content-length")
+	setter.SetResponseStream(pr, h.streamFormat)
+	return nil
+}
+
+// streamHandler 将流式响应逐条写入writer，每条消息为单行JSON
+type streamHandler struct {
+	format    string
+	formatter grpcurl.Formatter
+	writer    io.Writer
+	cancel    context.CancelFunc
+	header    metadata.MD
+	status    *status.Status
+	ready     chan struct{}
+	once      sync.Once
+	started   bool
+}
+
+func (s *streamHandler) OnResolveMethod(*desc.MethodDescriptor) {}
+
+func (s *streamHandler) OnSendHeaders(metadata.MD) {}
+
+func (s *streamHandler) OnReceiveHeaders(md metadata.MD) {
+	s.header = md
+}
+
+func (s *streamHandler) OnReceiveResponse(message proto.Message) {
+	text, err := s.formatter(message)
+	if err != nil {
+		log.Error("format grpc stream message error: ", err)
+		return
+	}
+	var buf bytes.Buffer
+	err = json.Compact(&buf, []byte(text))
+	if err != nil {
+		log.Error("compact grpc stream message error: ", err)
+		return
+	}
+	s.once.Do(func() {
+		s.started = true
+		close(s.ready)
+	})
+	s.write(buf.Bytes(), "")
+}
+
+func (s *streamHandler) OnReceiveTrailers(stat *status.Status, md metadata.MD) {
+	s.finish(stat)
+}
+
+// finish 调用结束，若已开始返回流式响应且调用失败，以最后一条消息返回错误
+func (s *streamHandler) finish(stat *status.Status) {
+	s.once.Do(func() {
+		s.status = stat
+		close(s.ready)
+	})
+	if !s.started || stat.Code() == codes.OK {
+		return
+	}
+	data, _ := json.Marshal(StatusErr{
+		Code: fmt.Sprintf("%s", stat.Code()),
+		Msg:  stat.Message(),
+	})
+	if s.format == StreamFormatSSE {
+		s.write(data, "error")
+		return
+	}
+	data, _ = json.Marshal(map[string]json.RawMessage{"error": data})
+	s.write(data, "")
+}
+
+func (s *streamHandler) write(data []byte, event string) {
+	var buf bytes.Buffer
+	if s.format == StreamFormatSSE {
+		if event != "" {
+			buf.WriteString("event: " + event + "\n")
+		}
+		buf.WriteString("data: ")
+		buf.Write(data)
+		buf.WriteString("\n\n")
+	} else {
+		buf.Write(data)
+		buf.WriteString("\n")
+	}
+	_, err := s.writer.Write(buf.Bytes())
+	if err != nil {
+		// 客户端断开时取消调用
+		s.cancel()
+	}
+}
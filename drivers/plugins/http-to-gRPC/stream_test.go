@@ -0,0 +1,235 @@
+package http_to_grpc
+
+import (
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	http_context "github.com/eolinker/apinto/node/http-context"
+	"github.com/eolinker/eosc/eocontext"
+	"github.com/fullstorydev/grpcurl"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const echoProto = `syntax = "proto3";
+package test;
+message Msg { string value = 1; }
+service Echo {
+  rpc List(Msg) returns (stream Msg);
+  rpc Collect(stream Msg) returns (Msg);
+}`
+
+type testDescriptor struct {
+	source grpcurl.DescriptorSource
+}
+
+func (d *testDescriptor) Descriptor() grpcurl.DescriptorSource {
+	return d.source
+}
+
+type testNode struct {
+	eocontext.INode
+	addr string
+}
+
+func (n *testNode) Addr() string {
+	return n.addr
+}
+
+func (n *testNode) Down() {}
+
+type testBalance struct {
+	node *testNode
+}
+
+func (b *testBalance) Select(ctx eocontext.EoContext) (eocontext.INode, int, error) {
+	return b.node, 0, nil
+}
+
+func (b *testBalance) Scheme() string {
+	return "http"
+}
+
+func (b *testBalance) TimeOut() time.Duration {
+	return time.Second
+}
+
+func (b *testBalance) Nodes() []eocontext.INode {
+	return []eocontext.INode{b.node}
+}
+
+// startEcho List按请求值返回两条消息，值为fail时在返回消息后以Internal结束，值为missing时不返回消息直接以NotFound结束；
+// Collect将收到的所有消息拼接后返回
+func startEcho(t *testing.T) string {
+	server := grpc.NewServer(grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+		if method == "/test.Echo/Collect" {
+			var values []string
+			for {
+				msg := new(wrapperspb.StringValue)
+				err := stream.RecvMsg(msg)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return err
+				}
+				values = append(values, msg.Value)
+			}
+			return stream.SendMsg(wrapperspb.String(strings.Join(values, ",")))
+		}
+		msg := new(wrapperspb.StringValue)
+		if err := stream.RecvMsg(msg); err != nil {
+			return err
+		}
+		if msg.Value == "missing" {
+			return status.Error(codes.NotFound, "missing")
+		}
+		stream.SendHeader(metadata.Pairs("x-stream", "list"))
+		for _, suffix := range []string{"-1", "-2"} {
+			if err := stream.SendMsg(wrapperspb.String(msg.Value + suffix)); err != nil {
+				return err
+			}
+		}
+		stream.SetTrailer(metadata.Pairs("x-result", "done"))
+		if msg.Value == "fail" {
+			return status.Error(codes.Internal, "broken")
+		}
+		return nil
+	}))
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(l)
+	t.Cleanup(server.Stop)
+	return l.Addr().String()
+}
+
+func newEchoComplete(t *testing.T, streamFormat string) *complete {
+	parser := protoparse.Parser{Accessor: protoparse.FileContentsFromMap(map[string]string{"echo.proto": echoProto})}
+	fds, err := parser.ParseFiles("echo.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := grpcurl.DescriptorSourceFromFileDescriptors(fds...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newComplete(&testDescriptor{source: source}, &Config{Format: "json", StreamFormat: streamFormat})
+}
+
+func newEchoContext(addr string, path string, body string) (*http_context.HttpContext, *fasthttp.RequestCtx) {
+	fast := new(fasthttp.RequestCtx)
+	fast.Request.Header.SetMethod(fasthttp.MethodPost)
+	fast.Request.SetRequestURI(path)
+	fast.Request.SetBodyString(body)
+	ctx := http_context.NewContext(fast, 8080)
+	ctx.SetBalance(&testBalance{node: &testNode{addr: addr}})
+	return ctx, fast
+}
+
+func TestInvokeStream(t *testing.T) {
+	addr := startEcho(t)
+	tests := []struct {
+		format      string
+		value       string
+		contentType string
+		body        string
+	}{
+		{
+			format:      StreamFormatNDJSON,
+			value:       "a",
+			contentType: "application/x-ndjson",
+			body:        "{\"value\":\"a-1\"}\n{\"value\":\"a-2\"}\n",
+		},
+		{
+			format:      StreamFormatNDJSON,
+			value:       "fail",
+			contentType: "application/x-ndjson",
+			body:        "{\"value\":\"fail-1\"}\n{\"value\":\"fail-2\"}\n{\"error\":{\"code\":\"Internal\",\"msg\":\"broken\"}}\n",
+		},
+		{
+			format:      StreamFormatSSE,
+			value:       "a",
+			contentType: "text/event-stream",
+			body:        "data: {\"value\":\"a-1\"}\n\ndata: {\"value\":\"a-2\"}\n\n",
+		},
+		{
+			format:      StreamFormatSSE,
+			value:       "fail",
+			contentType: "text/event-stream",
+			body:        "data: {\"value\":\"fail-1\"}\n\ndata: {\"value\":\"fail-2\"}\n\nevent: error\ndata: {\"code\":\"Internal\",\"msg\":\"broken\"}\n\n",
+		},
+	}
+	for _, tt := range tests {
+		ctx, fast := newEchoContext(addr, "/test.Echo/List", `{"value":"`+tt.value+`"}`)
+		if err := newEchoComplete(t, tt.format).Complete(ctx); err != nil {
+			t.Fatalf("%s %s: %v", tt.format, tt.value, err)
+		}
+		if got := string(fast.Response.Header.ContentType()); got != tt.contentType {
+			t.Errorf("%s %s: content-type %s", tt.format, tt.value, got)
+		}
+		if got := string(fast.Response.Header.Peek("x-stream")); got != "list" {
+			t.Errorf("%s %s: response header x-stream %q", tt.format, tt.value, got)
+		}
+		// 访问日志等回调在流结束后执行
+		ended := false
+		if !ctx.OnStreamEnd(func() { ended = true }) {
+			t.Fatalf("%s %s: response is not streamed", tt.format, tt.value)
+		}
+		body, err := io.ReadAll(fast.Response.BodyStream())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ended {
+			t.Errorf("%s %s: stream end callback ran before the stream was closed", tt.format, tt.value)
+		}
+		fast.Response.CloseBodyStream()
+		if string(body) != tt.body {
+			t.Errorf("%s %s: body %q, want %q", tt.format, tt.value, body, tt.body)
+		}
+		if !ended {
+			t.Errorf("%s %s: stream end callback not called", tt.format, tt.value)
+		}
+		if ctx.GetLabel("response_stream") != tt.format || ctx.Response().ContentLength() != len(tt.body) {
+			t.Errorf("%s %s: label %q, length %d", tt.format, tt.value, ctx.GetLabel("response_stream"), ctx.Response().ContentLength())
+		}
+	}
+}
+
+func TestInvokeStreamError(t *testing.T) {
+	addr := startEcho(t)
+	// 未返回任何消息即结束的调用按一元调用返回错误
+	ctx, fast := newEchoContext(addr, "/test.Echo/List", `{"value":"missing"}`)
+	err := newEchoComplete(t, StreamFormatNDJSON).Complete(ctx)
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expect not found, got %v", err)
+	}
+	if ctx.OnStreamEnd(func() {}) {
+		t.Error("error response should not be streamed")
+	}
+	if got := string(fast.Response.Body()); got != `{"code":"NotFound","msg":"missing"}` {
+		t.Errorf("body %s", got)
+	}
+}
+
+func TestInvokeClientStream(t *testing.T) {
+	addr := startEcho(t)
+	// 客户端流式请求的body为依次拼接的多个JSON对象，由grpcurl逐个解析为请求消息，对象之间可用换行分隔
+	ctx, fast := newEchoContext(addr, "/test.Echo/Collect", "{\"value\":\"a\"}{\"value\":\"b\"}\n{\"value\":\"c\"}\n")
+	if err := newEchoComplete(t, StreamFormatNDJSON).Complete(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(strings.Fields(string(fast.Response.Body())), ""); got != `{"value":"a,b,c"}` {
+		t.Errorf("body %s", got)
+	}
+}
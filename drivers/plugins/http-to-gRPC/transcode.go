@@ -0,0 +1,363 @@
+package http_to_grpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fullstorydev/grpcurl"
+	"github.com/jhump/protoreflect/desc"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// transcodeRule 由google.api.http注解生成的REST映射规则
+type transcodeRule struct {
+	method       string
+	pattern      *regexp.Regexp
+	variables    []string
+	body         string
+	responseBody string
+	symbol       string
+	input        *desc.MessageDescriptor
+	output       *desc.MessageDescriptor
+}
+
+// transcoder 根据protobuf描述生成REST映射规则，描述变更后重新生成
+type transcoder struct {
+	lock   sync.Mutex
+	source grpcurl.DescriptorSource
+	rules  []*transcodeRule
+}
+
+func (t *transcoder) match(source grpcurl.DescriptorSource, method string, path string) (*transcodeRule, map[string]string, error) {
+	rules, err := t.getRules(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, r := range rules {
+		if r.method != "*" && r.method != method {
+			continue
+		}
+		values := r.pattern.FindStringSubmatch(path)
+		if values == nil {
+			continue
+		}
+		vars := make(map[string]string, len(r.variables))
+		for i, name := range r.variables {
+			vars[name] = values[i+1]
+		}
+		return r, vars, nil
+	}
+	return nil, nil, nil
+}
+
+func (t *transcoder) getRules(source grpcurl.DescriptorSource) ([]*transcodeRule, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.source == source && t.rules != nil {
+		return t.rules, nil
+	}
+	rules, err := parseTranscodeRules(source)
+	if err != nil {
+		return nil, err
+	}
+	t.source = source
+	t.rules = rules
+	return rules, nil
+}
+
+func parseTranscodeRules(source grpcurl.DescriptorSource) ([]*transcodeRule, error) {
+	services, err := source.ListServices()
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]*transcodeRule, 0)
+	for _, name := range services {
+		d, err := source.FindSymbol(name)
+		if err != nil {
+			return nil, err
+		}
+		sd, ok := d.(*desc.ServiceDescriptor)
+		if !ok {
+			continue
+		}
+		for _, md := range sd.GetMethods() {
+			httpRule := readHttpRule(md.GetMethodOptions())
+			if httpRule == nil {
+				continue
+			}
+			symbol := fmt.Sprintf("%s/%s", sd.GetFullyQualifiedName(), md.GetName())
+			for _, hr := range append([]*annotations.HttpRule{httpRule}, httpRule.GetAdditionalBindings()...) {
+				r, err := newTranscodeRule(hr, symbol, md)
+				if err != nil {
+					return nil, fmt.Errorf("method %s: %w", symbol, err)
+				}
+				rules = append(rules, r)
+			}
+		}
+	}
+	return rules, nil
+}
+
+// readHttpRule 读取方法上的google.api.http注解，重新解析选项以获得已注册的扩展类型
+func readHttpRule(options *descriptorpb.MethodOptions) *annotations.HttpRule {
+	if options == nil {
+		return nil
+	}
+	data, err := proto.Marshal(options)
+	if err != nil {
+		return nil
+	}
+	opts := &descriptorpb.MethodOptions{}
+	err = proto.UnmarshalOptions{Resolver: protoregistry.GlobalTypes}.Unmarshal(data, opts)
+	if err != nil || !proto.HasExtension(opts, annotations.E_Http) {
+		return nil
+	}
+	rule, _ := proto.GetExtension(opts, annotations.E_Http).(*annotations.HttpRule)
+	return rule
+}
+
+func newTranscodeRule(hr *annotations.HttpRule, symbol string, md *desc.MethodDescriptor) (*transcodeRule, error) {
+	var method, template string
+	switch p := hr.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		method, template = "GET", p.Get
+	case *annotations.HttpRule_Put:
+		method, template = "PUT", p.Put
+	case *annotations.HttpRule_Post:
+		method, template = "POST", p.Post
+	case *annotations.HttpRule_Delete:
+		method, template = "DELETE", p.Delete
+	case *annotations.HttpRule_Patch:
+		method, template = "PATCH", p.Patch
+	case *annotations.HttpRule_Custom:
+		method, template = strings.ToUpper(p.Custom.GetKind()), p.Custom.GetPath()
+	default:
+		return nil, fmt.Errorf("unsupported http rule pattern")
+	}
+	pattern, variables, err := compileTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+	return &transcodeRule{
+		method:       method,
+		pattern:      pattern,
+		variables:    variables,
+		body:         hr.GetBody(),
+		responseBody: hr.GetResponseBody(),
+		symbol:       symbol,
+		input:        md.GetInputType(),
+		output:       md.GetOutputType(),
+	}, nil
+}
+
+// compileTemplate 将路径模板转换为正则表达式，返回按捕获顺序排列的变量字段路径
+// 模板语法：Template = "/" Segments [ ":" Verb ]，Segment = "*" | "**" | LITERAL | "{" FieldPath [ "=" Segments ] "}"
+func compileTemplate(template string) (*regexp.Regexp, []string, error) {
+	if !strings.HasPrefix(template, "/") {
+		return nil, nil, fmt.Errorf("invalid path template %s", template)
+	}
+	path, verb := template, ""
+	if i := strings.LastIndex(template, ":"); i > strings.LastIndex(template, "}") && i > strings.LastIndex(template, "/") {
+		path, verb = template[:i], template[i+1:]
+	}
+	var builder strings.Builder
+	builder.WriteString("^")
+	variables := make([]string, 0)
+	rest := path
+	for len(rest) > 0 {
+		start := strings.Index(rest, "{")
+		if start < 0 {
+			builder.WriteString(segmentsPattern(rest))
+			break
+		}
+		end := strings.Index(rest, "}")
+		if end < start {
+			return nil, nil, fmt.Errorf("invalid path template %s", template)
+		}
+		builder.WriteString(segmentsPattern(rest[:start]))
+		variable := rest[start+1 : end]
+		fieldPath, segments := variable, "*"
+		if i := strings.Index(variable, "="); i >= 0 {
+			fieldPath, segments = variable[:i], variable[i+1:]
+		}
+		builder.WriteString("(" + segmentsPattern(segments) + ")")
+		variables = append(variables, fieldPath)
+		rest = rest[end+1:]
+	}
+	if verb != "" {
+		builder.WriteString(regexp.QuoteMeta(":" + verb))
+	}
+	builder.WriteString("$")
+	pattern, err := regexp.Compile(builder.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	return pattern, variables, nil
+}
+
+func segmentsPattern(segments string) string {
+	parts := strings.Split(segments, "/")
+	for i, p := range parts {
+		switch p {
+		case "*":
+			parts[i] = "[^/]+"
+		case "**":
+			parts[i] = ".+"
+		default:
+			parts[i] = regexp.QuoteMeta(p)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// buildRequest 按规则将路径参数、query参数及body合并为请求消息的json
+func (r *transcodeRule) buildRequest(vars map[string]string, query url.Values, body []byte) ([]byte, error) {
+	message := make(map[string]interface{})
+	bound := make(map[string]struct{})
+	switch r.body {
+	case "":
+	case "*":
+		if len(body) > 0 {
+			err := decodeBody(body, &message)
+			if err != nil {
+				return nil, fmt.Errorf("invalid request body: %w", err)
+			}
+		}
+	default:
+		if len(body) > 0 {
+			var value interface{}
+			err := decodeBody(body, &value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid request body: %w", err)
+			}
+			setField(message, strings.Split(r.body, "."), value)
+		}
+		bound[r.body] = struct{}{}
+	}
+	for name, value := range vars {
+		// 单段变量的值需要解码，多段变量保留其中的"/"
+		if v, err := url.PathUnescape(value); err == nil {
+			value = v
+		}
+		fields := strings.Split(name, ".")
+		setField(message, fields, fieldValue(r.input, fields, []string{value}))
+		bound[name] = struct{}{}
+	}
+	if r.body != "*" {
+		for name, values := range query {
+			if _, has := bound[name]; has {
+				continue
+			}
+			fields := strings.Split(name, ".")
+			if findField(r.input, fields) == nil {
+				continue
+			}
+			setField(message, fields, fieldValue(r.input, fields, values))
+		}
+	}
+	return json.Marshal(message)
+}
+
+// decodeBody 数值以json.Number读取，避免超出2^53的64位整数丢失精度
+func decodeBody(body []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// buildResponse 配置了response_body时仅返回响应消息中的对应字段
+func (r *transcodeRule) buildResponse(body []byte) []byte {
+	if r.responseBody == "" {
+		return body
+	}
+	fd := r.output.FindFieldByName(r.responseBody)
+	if fd == nil {
+		return body
+	}
+	fields := make(map[string]json.RawMessage)
+	err := json.Unmarshal(body, &fields)
+	if err != nil {
+		return body
+	}
+	if v, has := fields[fd.GetJSONName()]; has {
+		return v
+	}
+	if v, has := fields[fd.GetName()]; has {
+		return v
+	}
+	return []byte("null")
+}
+
+func findField(md *desc.MessageDescriptor, fields []string) *desc.FieldDescriptor {
+	var fd *desc.FieldDescriptor
+	for i, name := range fields {
+		if md == nil {
+			return nil
+		}
+		fd = md.FindFieldByName(name)
+		if fd == nil {
+			fd = md.FindFieldByJSONName(name)
+		}
+		if fd == nil {
+			return nil
+		}
+		if i < len(fields)-1 {
+			md = fd.GetMessageType()
+		}
+	}
+	return fd
+}
+
+// fieldValue 按字段类型转换字符串参数，未知字段按字符串处理
+func fieldValue(md *desc.MessageDescriptor, fields []string, values []string) interface{} {
+	fd := findField(md, fields)
+	if fd == nil {
+		return values[0]
+	}
+	if !fd.IsRepeated() {
+		return scalarValue(fd, values[0])
+	}
+	list := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		list = append(list, scalarValue(fd, v))
+	}
+	return list
+}
+
+func scalarValue(fd *desc.FieldDescriptor, value string) interface{} {
+	switch fd.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_BOOL:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case descriptorpb.FieldDescriptorProto_TYPE_INT32, descriptorpb.FieldDescriptorProto_TYPE_SINT32, descriptorpb.FieldDescriptorProto_TYPE_SFIXED32,
+		descriptorpb.FieldDescriptorProto_TYPE_UINT32, descriptorpb.FieldDescriptorProto_TYPE_FIXED32,
+		descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, descriptorpb.FieldDescriptorProto_TYPE_FLOAT:
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return json.Number(value)
+		}
+	}
+	// 64位整数、枚举、字符串及bytes均使用字符串表示
+	return value
+}
+
+func setField(message map[string]interface{}, fields []string, value interface{}) {
+	for _, name := range fields[:len(fields)-1] {
+		child, ok := message[name].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			message[name] = child
+		}
+		message = child
+	}
+	message[fields[len(fields)-1]] = value
+}
@@ -0,0 +1,140 @@
+package http_to_grpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"net/url"
+	"testing"
+
+	protocbuf "github.com/eolinker/apinto/drivers/transcode/protobuf"
+	grpc_descriptor "github.com/eolinker/apinto/grpc-descriptor"
+	"github.com/eolinker/eosc"
+	"github.com/fullstorydev/grpcurl"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+)
+
+const libraryProto = `syntax = "proto3";
+package library;
+import "google/api/annotations.proto";
+
+message Book {
+  string name = 1;
+  string title = 2;
+  int32 pages = 3;
+  int64 id = 4;
+}
+message GetBookRequest {
+  string name = 1;
+  bool full = 2;
+  repeated string tags = 3;
+}
+message CreateBookRequest {
+  string parent = 1;
+  Book book = 2;
+}
+message BookResponse {
+  Book book = 1;
+}
+
+service Library {
+  rpc GetBook(GetBookRequest) returns (BookResponse) {
+    option (google.api.http) = {
+      get: "/v1/{name=shelves/*/books/*}"
+      response_body: "book"
+    };
+  }
+  rpc CreateBook(CreateBookRequest) returns (BookResponse) {
+    option (google.api.http) = {
+      post: "/v1/{parent=shelves/*}/books"
+      body: "book"
+      additional_bindings { post: "/v1/books:create" body: "*" }
+    };
+  }
+}
+`
+
+func TestTranscodeRules(t *testing.T) {
+	p := &protoparse.Parser{
+		Accessor:     protoparse.FileContentsFromMap(map[string]string{"library.proto": libraryProto}),
+		LookupImport: desc.LoadFileDescriptor,
+	}
+	fds, err := p.ParseFiles("library.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := grpcurl.DescriptorSourceFromFileDescriptors(fds...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := new(transcoder)
+
+	tests := []struct {
+		method string
+		path   string
+		query  string
+		body   string
+		symbol string
+		want   string
+	}{
+		{method: "GET", path: "/v1/shelves/1/books/2", query: "full=true&tags=a&tags=b&unknown=1", symbol: "library.Library/GetBook", want: `{"full":true,"name":"shelves/1/books/2","tags":["a","b"]}`},
+		{method: "POST", path: "/v1/shelves/1/books", query: "parent=ignored", body: `{"title":"go","pages":10}`, symbol: "library.Library/CreateBook", want: `{"book":{"pages":10,"title":"go"},"parent":"shelves/1"}`},
+		{method: "POST", path: "/v1/shelves/1/books", body: `{"id":9007199254740993}`, symbol: "library.Library/CreateBook", want: `{"book":{"id":9007199254740993},"parent":"shelves/1"}`},
+		{method: "POST", path: "/v1/books:create", query: "parent=ignored", body: `{"parent":"shelves/2"}`, symbol: "library.Library/CreateBook", want: `{"parent":"shelves/2"}`},
+		{method: "DELETE", path: "/v1/shelves/1/books/2"},
+	}
+	for _, tt := range tests {
+		rule, vars, err := tr.match(source, tt.method, tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.symbol == "" {
+			if rule != nil {
+				t.Errorf("%s %s: unexpected match %s", tt.method, tt.path, rule.symbol)
+			}
+			continue
+		}
+		if rule == nil || rule.symbol != tt.symbol {
+			t.Fatalf("%s %s: rule %v, want %s", tt.method, tt.path, rule, tt.symbol)
+		}
+		query, _ := url.ParseQuery(tt.query)
+		data, err := rule.buildRequest(vars, query, []byte(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("%s %s: request %s, want %s", tt.method, tt.path, data, tt.want)
+		}
+	}
+
+	rule, _, _ := tr.match(source, "GET", "/v1/shelves/1/books/2")
+	if got := string(rule.buildResponse([]byte(`{"book":{"name":"b"}}`))); got != `{"name":"b"}` {
+		t.Errorf("response %s", got)
+	}
+}
+
+// TestTranscodeWorkerSource 使用protobuf驱动的描述源连续转换，描述源未变更时复用已生成的规则
+func TestTranscodeWorkerSource(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(libraryProto))
+	zw.Close()
+	worker, err := protocbuf.Create("library@transcode", "library", &protocbuf.Config{
+		ProtoFiles: eosc.EoFiles{{Name: "library.proto", Data: base64.StdEncoding.EncodeToString(buf.Bytes())}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := worker.(grpc_descriptor.IDescriptor).Descriptor()
+	tr := new(transcoder)
+	for i := 0; i < 2; i++ {
+		rule, vars, err := tr.match(source, "GET", "/v1/shelves/1/books/2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rule == nil || rule.symbol != "library.Library/GetBook" || vars["name"] != "shelves/1/books/2" {
+			t.Fatalf("request %d: unexpected rule %v, vars %v", i, rule, vars)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package http_to_thrift
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/eolinker/apinto/entries/ctx_key"
+	"github.com/eolinker/apinto/entries/router"
+	thrift_context "github.com/eolinker/apinto/node/thrift-context"
+	thrift_descriptor "github.com/eolinker/apinto/thrift-descriptor"
+	"github.com/eolinker/eosc/eocontext"
+	http_context "github.com/eolinker/eosc/eocontext/http-context"
+	"github.com/eolinker/eosc/log"
+)
+
+// exceptionHeader 服务端抛出异常时，响应头中返回异常类型
+const exceptionHeader = "X-Thrift-Exception"
+
+var (
+	errorTimeoutComplete = errors.New("complete timeout")
+	errorNoDescriptor    = errors.New("thrift descriptor is not ready")
+
+	seqId int32
+)
+
+type complete struct {
+	descriptor  thrift_descriptor.IDescriptor
+	service     string
+	method      string
+	codec       thrift_context.Codec
+	multiplexed bool
+	muxName     string
+}
+
+func newComplete(descriptor thrift_descriptor.IDescriptor, conf *Config) *complete {
+	return &complete{
+		descriptor:  descriptor,
+		service:     conf.Service,
+		method:      conf.Method,
+		codec:       thrift_context.Codec{Protocol: conf.Protocol, Transport: conf.Transport},
+		multiplexed: conf.Multiplexed,
+		muxName:     conf.MultiplexedName,
+	}
+}
+
+func (c *complete) Complete(org eocontext.EoContext) error {
+	ctx, err := http_context.Assert(org)
+	if err != nil {
+		return err
+	}
+
+	retryValue := ctx.Value(ctx_key.CtxKeyRetry)
+	retry, ok := retryValue.(int)
+	if !ok {
+		retry = router.DefaultRetry
+	}
+
+	timeoutValue := ctx.Value(ctx_key.CtxKeyTimeout)
+	timeout, ok := timeoutValue.(time.Duration)
+	if !ok || timeout == 0 {
+		timeout = router.DefaultTimeout
+	}
+
+	//设置响应开始时间
+	proxyTime := time.Now()
+	defer func() {
+		ctx.Response().SetResponseTime(time.Since(proxyTime))
+		ctx.SetLabel("handler", "proxy")
+	}()
+
+	fn, msg, err := c.request(ctx)
+	if err != nil {
+		setError(ctx, http.StatusBadRequest, err)
+		return err
+	}
+	ctx.SetLabel("thrift_service", fn.Service)
+	ctx.SetLabel("thrift_method", fn.Name)
+
+	balance := ctx.GetBalance()
+	var reply *thrift_context.Message
+	var lastErr error
+	for index := 0; index <= retry; index++ {
+
+		if time.Since(proxyTime) > timeout {
+			setError(ctx, http.StatusGatewayTimeout, errorTimeoutComplete)
+			return errorTimeoutComplete
+		}
+		node, _, err := balance.Select(ctx)
+		if err != nil {
+			log.Error("select error: ", err)
+			setError(ctx, http.StatusServiceUnavailable, err)
+			return err
+		}
+
+		reply, lastErr = thrift_context.Call(ctx.Context(), node.Addr(), timeout, c.codec, msg)
+		if lastErr == nil {
+			break
+		}
+		var opErr *net.OpError
+		if errors.As(lastErr, &opErr) && opErr.Op == "dial" {
+			node.Down()
+		}
+		log.Error("http to thrift call error: ", lastErr)
+	}
+	if lastErr != nil {
+		setError(ctx, http.StatusBadGateway, lastErr)
+		return lastErr
+	}
+	return c.response(ctx, fn, reply)
+}
+
+// request 按方法定义将body编码为Thrift请求消息，body为以参数名为key的json对象
+func (c *complete) request(ctx http_context.IHttpContext) (*thrift_descriptor.Function, *thrift_context.Message, error) {
+	descriptor := c.descriptor.Descriptor()
+	if descriptor == nil {
+		return nil, nil, errorNoDescriptor
+	}
+	fn, err := descriptor.FindFunction(c.service, c.method)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := ctx.Proxy().Body().RawBody()
+	if err != nil {
+		return nil, nil, err
+	}
+	args := make(map[string]interface{})
+	if len(bytes.TrimSpace(body)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		if err = decoder.Decode(&args); err != nil {
+			return nil, nil, fmt.Errorf("参数解析错误，body需为json对象: %w", err)
+		}
+	}
+	buffer := thrift.NewTMemoryBuffer()
+	err = fn.WriteArgs(ctx.Context(), thrift_context.NewProtocol(buffer, c.codec.Protocol), args)
+	if err != nil {
+		return nil, nil, fmt.Errorf("参数解析错误，%w", err)
+	}
+
+	name := fn.Name
+	if c.multiplexed {
+		service := c.muxName
+		if service == "" {
+			service = fn.Service
+		}
+		name = thrift_context.JoinName(service, fn.Name)
+	}
+	messageType := thrift.CALL
+	if fn.Oneway {
+		messageType = thrift.ONEWAY
+	}
+	return fn, &thrift_context.Message{
+		Name:  name,
+		Type:  messageType,
+		SeqId: atomic.AddInt32(&seqId, 1),
+		Body:  buffer.Bytes(),
+	}, nil
+}
+
+func (c *complete) response(ctx http_context.IHttpContext, fn *thrift_descriptor.Function, reply *thrift_context.Message) error {
+	if reply == nil {
+		// oneway方法没有响应
+		ctx.Response().SetStatus(http.StatusOK, http.StatusText(http.StatusOK))
+		ctx.Response().SetBody([]byte{})
+		return nil
+	}
+	if reply.Type == thrift.EXCEPTION {
+		err := c.codec.ReadException(reply)
+		setError(ctx, http.StatusInternalServerError, err)
+		return err
+	}
+	result, err := fn.ReadResult(ctx.Context(), thrift_context.NewBufferProtocol(reply.Body, c.codec.Protocol))
+	if err != nil {
+		var exception *thrift_descriptor.Exception
+		if !errors.As(err, &exception) {
+			setError(ctx, http.StatusInternalServerError, err)
+			return err
+		}
+		// IDL中声明的异常作为业务错误返回
+		data, _ := json.Marshal(map[string]interface{}{
+			exception.Field: exception.Value,
+		})
+		ctx.Response().SetHeader(exceptionHeader, exception.Type)
+		ctx.Response().SetHeader("Content-Type", "application/json")
+		ctx.Response().SetStatus(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		ctx.Response().SetBody(data)
+		return nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		setError(ctx, http.StatusInternalServerError, err)
+		return err
+	}
+	ctx.Response().SetHeader("Content-Type", "application/json")
+	ctx.Response().SetStatus(http.StatusOK, http.StatusText(http.StatusOK))
+	ctx.Response().SetBody(data)
+	return nil
+}
+
+func setError(ctx http_context.IHttpContext, status int, err error) {
+	ctx.Response().SetStatus(status, http.StatusText(status))
+	ctx.Response().SetBody([]byte(err.Error()))
+}
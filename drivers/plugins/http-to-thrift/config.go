@@ -0,0 +1,13 @@
+package http_to_thrift
+
+import "github.com/eolinker/eosc"
+
+type Config struct {
+	Descriptor      eosc.RequireId `json:"descriptor" required:"true" label:"Thrift IDL" skill:"github.com/eolinker/apinto/thrift-transcode.transcode.IDescriptor"`
+	Service         string         `json:"service" label:"服务名称" required:"true" description:"IDL中的服务名，同名服务可使用文件名或命名空间作为前缀，如 user.UserService"`
+	Method          string         `json:"method" label:"方法名称" required:"true"`
+	Protocol        string         `json:"protocol" label:"协议" enum:"binary,compact" default:"binary"`
+	Transport       string         `json:"transport" label:"传输方式" enum:"framed,buffered" default:"framed"`
+	Multiplexed     bool           `json:"multiplexed" label:"多路复用" description:"服务端使用TMultiplexedProcessor时开启，消息名为 服务名:方法名"`
+	MultiplexedName string         `json:"multiplexed_name" label:"注册的服务名" description:"服务端注册到TMultiplexedProcessor的服务名，为空时使用IDL中的服务名" switch:"multiplexed === true"`
+}
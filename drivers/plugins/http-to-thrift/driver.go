@@ -0,0 +1,74 @@
+package http_to_thrift
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/eolinker/apinto/drivers"
+	thrift_context "github.com/eolinker/apinto/node/thrift-context"
+	thrift_descriptor "github.com/eolinker/apinto/thrift-descriptor"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/common/bean"
+)
+
+func check(v interface{}) (*Config, error) {
+	conf, err := drivers.Assert[Config](v)
+	if err != nil {
+		return nil, err
+	}
+	if conf.Service == "" {
+		return nil, errors.New("service is null")
+	}
+	if conf.Method == "" {
+		return nil, errors.New("method is null")
+	}
+	switch conf.Protocol {
+	case "":
+		conf.Protocol = thrift_context.ProtocolBinary
+	case thrift_context.ProtocolBinary, thrift_context.ProtocolCompact:
+	default:
+		return nil, fmt.Errorf("unknown protocol %s", conf.Protocol)
+	}
+	switch conf.Transport {
+	case "":
+		conf.Transport = thrift_context.TransportFramed
+	case thrift_context.TransportFramed, thrift_context.TransportBuffered:
+	default:
+		return nil, fmt.Errorf("unknown transport %s", conf.Transport)
+	}
+	return conf, nil
+}
+
+func Create(id, name string, conf *Config, workers map[eosc.RequireId]eosc.IWorker) (eosc.IWorker, error) {
+	once.Do(func() {
+		bean.Autowired(&worker)
+	})
+	conf, err := check(conf)
+	if err != nil {
+		return nil, err
+	}
+	descriptor, err := getDescriptor(string(conf.Descriptor))
+	if err != nil {
+		return nil, err
+	}
+	t := &toThrift{
+		WorkerBase: drivers.Worker(id, name),
+	}
+	t.handler.Store(newComplete(descriptor, conf))
+	return t, nil
+}
+
+func getDescriptor(id string) (thrift_descriptor.IDescriptor, error) {
+	if id == "" {
+		return nil, errors.New("descriptor id is empty")
+	}
+	w, ok := worker.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("thrift worker(%s) is not exist", id)
+	}
+	v, ok := w.(thrift_descriptor.IDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("invalid thrift descriptor id: %s", id)
+	}
+	return v, nil
+}
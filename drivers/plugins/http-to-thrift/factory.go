@@ -0,0 +1,27 @@
+package http_to_thrift
+
+import (
+	"sync"
+
+	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/apinto/plugin"
+	"github.com/eolinker/eosc"
+)
+
+const (
+	Name = "http_to_thrift"
+)
+
+var (
+	once   = sync.Once{}
+	worker eosc.IWorkers
+)
+
+func Register(register eosc.IExtenderDriverRegister) {
+	plugin.DeclareBodyRequired(Name)
+	register.RegisterExtenderDriver(Name, NewFactory())
+}
+
+func NewFactory() eosc.IExtenderDriverFactory {
+	return drivers.NewFactory[Config](Create)
+}
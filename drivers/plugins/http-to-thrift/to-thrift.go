@@ -0,0 +1,60 @@
+package http_to_thrift
+
+import (
+	"sync/atomic"
+
+	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/eocontext"
+	http_context "github.com/eolinker/eosc/eocontext/http-context"
+)
+
+var _ eocontext.IFilter = (*toThrift)(nil)
+var _ http_context.HttpFilter = (*toThrift)(nil)
+
+type toThrift struct {
+	drivers.WorkerBase
+	handler atomic.Pointer[complete]
+}
+
+func (t *toThrift) DoFilter(ctx eocontext.EoContext, next eocontext.IChain) (err error) {
+	return http_context.DoHttpFilter(t, ctx, next)
+}
+
+func (t *toThrift) DoHttpFilter(ctx http_context.IHttpContext, next eocontext.IChain) error {
+	if handler := t.handler.Load(); handler != nil {
+		ctx.SetCompleteHandler(handler)
+	}
+	if next != nil {
+		return next.DoChain(ctx)
+	}
+	return nil
+}
+
+func (t *toThrift) Start() error {
+	return nil
+}
+
+func (t *toThrift) Reset(conf interface{}, workers map[eosc.RequireId]eosc.IWorker) error {
+	cfg, err := check(conf)
+	if err != nil {
+		return err
+	}
+	descriptor, err := getDescriptor(string(cfg.Descriptor))
+	if err != nil {
+		return err
+	}
+	t.handler.Store(newComplete(descriptor, cfg))
+	return nil
+}
+
+func (t *toThrift) Stop() error {
+	return nil
+}
+
+func (t *toThrift) Destroy() {
+}
+
+func (t *toThrift) CheckSkill(skill string) bool {
+	return http_context.FilterSkillName == skill
+}
@@ -2,6 +2,7 @@ package params_transformer
 
 import (
 	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/apinto/plugin"
 	"github.com/eolinker/eosc"
 	"github.com/eolinker/eosc/log"
 )
@@ -11,6 +12,7 @@ const (
 )
 
 func Register(register eosc.IExtenderDriverRegister) {
+	plugin.DeclareBodyRequired(Name)
 	log.Debug("register params_transformer is ", Name)
 	register.RegisterExtenderDriver(Name, NewFactory())
 }
@@ -2,6 +2,7 @@ package prometheus
 
 import (
 	metric_entry "github.com/eolinker/apinto/entries/metric-entry"
+	http_context "github.com/eolinker/apinto/node/http-context"
 	"github.com/eolinker/apinto/output"
 	"reflect"
 
@@ -33,13 +34,22 @@ func (p *prometheus) DoHttpFilter(ctx http_service.IHttpContext, next eocontext.
 		log.Error(err)
 	}
 
+	outputs := p.proxy.List()
+	// 响应以流式转发时，在流结束后收集指标，此时AI请求的token用量等标签已写入
+	if sr, ok := ctx.(http_context.IStreamResponse); ok && sr.OnStreamEnd(func() { p.collect(ctx, outputs) }) {
+		return nil
+	}
+	p.collect(ctx, outputs)
+	return nil
+}
+
+func (p *prometheus) collect(ctx http_service.IHttpContext, outputs []output.IMetrics) {
 	metricEntry, err := metric_entry.NewMetricEntry(ctx)
 	if err != nil {
 		log.Errorf("prometheus plugin id:%s DoHttpFilter fail. %w", p.Id(), err)
 		return
 	}
 
-	outputs := p.proxy.List()
 	for _, v := range outputs {
 		o, ok := v.(output.IMetrics)
 		if !ok {
@@ -48,8 +58,6 @@ func (p *prometheus) DoHttpFilter(ctx http_service.IHttpContext, next eocontext.
 		}
 		o.Collect(p.metrics, metricEntry)
 	}
-
-	return nil
 }
 
 func (p *prometheus) Destroy() {
@@ -3,6 +3,7 @@ package proxy_mirror
 import (
 	"github.com/eolinker/apinto/drivers"
 	"github.com/eolinker/apinto/drivers/discovery/static"
+	"github.com/eolinker/apinto/plugin"
 	"github.com/eolinker/eosc"
 )
 
@@ -18,6 +19,7 @@ var (
 )
 
 func Register(register eosc.IExtenderDriverRegister) {
+	plugin.DeclareBodyRequired(Name)
 	register.RegisterExtenderDriver(Name, NewFactory())
 }
 
@@ -1,10 +1,12 @@
 package cache
 
 import (
+	"reflect"
+
 	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/apinto/plugin"
 	"github.com/eolinker/eosc"
 	"github.com/eolinker/eosc/utils/schema"
-	"reflect"
 )
 
 const (
@@ -17,6 +19,7 @@ var (
 )
 
 func Register(register eosc.IExtenderDriverRegister) {
+	plugin.DeclareBodyRequired(Name)
 	register.RegisterExtenderDriver(Name, NewFactory())
 }
 func NewFactory() eosc.IExtenderDriverFactory {
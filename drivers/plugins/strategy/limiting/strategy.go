@@ -51,6 +51,9 @@ func (s *Strategy) DoFilter(ctx eoscContext.EoContext, next eoscContext.IChain)
 				redisScalars.TrafficsSecond, _ = iVectors.BuildVector("traffic", time.Second, time.Second/2)
 				redisScalars.TrafficsMinute, _ = iVectors.BuildVector("traffic", time.Minute, time.Second*10)
 				redisScalars.TrafficsHour, _ = iVectors.BuildVector("traffic", time.Hour, time.Minute*10)
+				redisScalars.TokenMinute, _ = iVectors.BuildVector("token", time.Minute, time.Second*10)
+				redisScalars.TokenHour, _ = iVectors.BuildVector("token", time.Hour, time.Minute*10)
+				redisScalars.TokenDay, _ = iVectors.BuildVector("token", time.Hour*24, time.Hour*2)
 				s.redisScalars = redisScalars
 			}
 			scalars = s.redisScalars
@@ -69,6 +72,9 @@ func (s *Strategy) DoFilter(ctx eoscContext.EoContext, next eoscContext.IChain)
 			s.localScalars.TrafficsSecond, _ = iVectors.BuildVector("traffic", time.Second, time.Second/2)
 			s.localScalars.TrafficsMinute, _ = iVectors.BuildVector("traffic", time.Minute, time.Second*10)
 			s.localScalars.TrafficsHour, _ = iVectors.BuildVector("traffic", time.Hour, time.Minute*10)
+			s.localScalars.TokenMinute, _ = iVectors.BuildVector("token", time.Minute, time.Second*10)
+			s.localScalars.TokenHour, _ = iVectors.BuildVector("token", time.Hour, time.Minute*10)
+			s.localScalars.TokenDay, _ = iVectors.BuildVector("token", time.Hour*24, time.Hour*2)
 		})
 		scalars = s.localScalars
 	}
@@ -0,0 +1,59 @@
+package websocket_message
+
+import (
+	"fmt"
+
+	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/apinto/drivers/strategy/data-mask-strategy/mask"
+	"github.com/eolinker/apinto/output"
+	"github.com/eolinker/eosc"
+)
+
+type Config struct {
+	MaxMessageSize int64     `json:"max_message_size" label:"最大消息长度" description:"单位：字节，超出时以1009关闭连接，0表示不限制" minimum:"0"`
+	RateLimit      RateLimit `json:"rate_limit" label:"消息速率限制"`
+	Log            Log       `json:"log" label:"消息日志"`
+}
+
+type RateLimit struct {
+	Limit  int    `json:"limit" label:"消息数" description:"每个连接在统计周期内允许客户端发送的消息数，0表示不限制" minimum:"0"`
+	Period string `json:"period" label:"统计周期" enum:"second,minute,hour" default:"second"`
+	Action string `json:"action" label:"超出处理" enum:"close,drop" default:"close" description:"close：以1008关闭连接；drop：丢弃超出的消息"`
+}
+
+type Log struct {
+	Output    []eosc.RequireId `json:"output" skill:"github.com/eolinker/apinto/http-entry.http-entry.IOutput" label:"输出器列表" description:"逐条输出转发的消息，连接关闭时输出连接时长及消息数"`
+	Messages  string           `json:"messages" label:"记录消息" enum:"all,request,response,none" default:"all"`
+	MaxLength int              `json:"max_length" label:"消息最大记录长度" description:"单位：字节，超出部分截断，0表示不限制" minimum:"0"`
+	Mask      mask.DataMask    `json:"mask" label:"消息脱敏规则" description:"仅作用于记录的消息，不影响转发内容"`
+}
+
+func getList(ids []eosc.RequireId) ([]output.IEntryOutput, error) {
+	ls := make([]output.IEntryOutput, 0, len(ids))
+	for _, id := range ids {
+		worker, has := workers.Get(string(id))
+		if !has {
+			return nil, fmt.Errorf("%s:%w", id, eosc.ErrorWorkerNotExits)
+		}
+
+		eto, ok := worker.(output.IEntryOutput)
+		if !ok {
+			return nil, fmt.Errorf("%s:worker not implement IEntryOutput", string(id))
+		}
+
+		ls = append(ls, eto)
+	}
+	return ls, nil
+}
+
+func Create(id, name string, conf *Config, workers map[eosc.RequireId]eosc.IWorker) (eosc.IWorker, error) {
+	h, err := newHandler(conf)
+	if err != nil {
+		return nil, err
+	}
+	e := &executor{
+		WorkerBase: drivers.Worker(id, name),
+	}
+	e.handler.Store(h)
+	return e, nil
+}
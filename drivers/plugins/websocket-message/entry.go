@@ -0,0 +1,63 @@
+package websocket_message
+
+import (
+	http_context "github.com/eolinker/apinto/node/http-context"
+	"github.com/eolinker/eosc"
+	"github.com/fasthttp/websocket"
+)
+
+var _ eosc.IEntry = (*entry)(nil)
+
+// entry 在请求日志字段的基础上增加websocket消息或连接的字段
+type entry struct {
+	parent eosc.IEntry
+	fields map[string]interface{}
+}
+
+func newMessageEntry(parent eosc.IEntry, msg http_context.IWebsocketMessage, content string) *entry {
+	messageType := "binary"
+	if msg.Type() == websocket.TextMessage {
+		messageType = "text"
+	}
+	return &entry{
+		parent: parent,
+		fields: map[string]interface{}{
+			"ws_event":         "message",
+			"ws_direction":     msg.Direction().String(),
+			"ws_message_index": msg.Index(),
+			"ws_message_type":  messageType,
+			"ws_message_size":  len(msg.Data()),
+			"ws_message":       content,
+		},
+	}
+}
+
+// newCloseEntry 连接关闭时输出的连接时长（毫秒）及两个方向的消息数
+func newCloseEntry(parent eosc.IEntry, ctx http_context.IWebsocketMessageContext, duration string) *entry {
+	request, response := ctx.MessageCount()
+	return &entry{
+		parent: parent,
+		fields: map[string]interface{}{
+			"ws_event":             "close",
+			"ws_duration":          duration,
+			"ws_request_messages":  request,
+			"ws_response_messages": response,
+			"ws_subprotocol":       ctx.Subprotocol(),
+		},
+	}
+}
+
+func (e *entry) Read(pattern string) interface{} {
+	if v, has := e.fields[pattern]; has {
+		return v
+	}
+	return e.parent.Read(pattern)
+}
+
+func (e *entry) ReadLabel(pattern string) string {
+	return eosc.String(e.Read(pattern))
+}
+
+func (e *entry) Children(child string) []eosc.IEntry {
+	return e.parent.Children(child)
+}
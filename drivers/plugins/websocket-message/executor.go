@@ -0,0 +1,85 @@
+package websocket_message
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/eolinker/apinto/drivers"
+	http_entry "github.com/eolinker/apinto/entries/http-entry"
+	http_context "github.com/eolinker/apinto/node/http-context"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/eocontext"
+	http_service "github.com/eolinker/eosc/eocontext/http-context"
+)
+
+var _ eocontext.IFilter = (*executor)(nil)
+var _ http_service.WebsocketFilter = (*executor)(nil)
+
+type executor struct {
+	drivers.WorkerBase
+	handler atomic.Pointer[handler]
+}
+
+func (e *executor) DoFilter(ctx eocontext.EoContext, next eocontext.IChain) error {
+	wsCtx, err := http_service.WebsocketAssert(ctx)
+	if err != nil {
+		// 非websocket路由直接放行
+		if next != nil {
+			return next.DoChain(ctx)
+		}
+		return nil
+	}
+	return e.DoWebsocketFilter(wsCtx, next)
+}
+
+func (e *executor) DoWebsocketFilter(ctx http_service.IWebsocketContext, next eocontext.IChain) error {
+	mc, ok := ctx.(http_context.IWebsocketMessageContext)
+	if !ok {
+		if next != nil {
+			return next.DoChain(ctx)
+		}
+		return nil
+	}
+	h := e.handler.Load()
+	h.bind(mc)
+	var err error
+	if next != nil {
+		err = next.DoChain(ctx)
+	}
+	if h.outputs != nil {
+		// 连接升级后，关闭时输出连接时长及消息数
+		mc.OnStreamEnd(func() {
+			h.output(newCloseEntry(http_entry.NewEntry(ctx), mc, ctx.GetLabel("websocket_duration")))
+		})
+	}
+	return err
+}
+
+func (e *executor) Start() error {
+	return nil
+}
+
+func (e *executor) Reset(conf interface{}, workers map[eosc.RequireId]eosc.IWorker) error {
+	cfg, ok := conf.(*Config)
+	if !ok {
+		return errors.New("invalid config")
+	}
+	h, err := newHandler(cfg)
+	if err != nil {
+		return err
+	}
+	e.handler.Store(h)
+	return nil
+}
+
+func (e *executor) Stop() error {
+	return nil
+}
+
+func (e *executor) Destroy() {
+	return
+}
+
+func (e *executor) CheckSkill(skill string) bool {
+	return http_service.FilterSkillName == skill
+}
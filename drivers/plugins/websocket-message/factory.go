@@ -0,0 +1,49 @@
+package websocket_message
+
+import (
+	"sync"
+
+	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/apinto/drivers/strategy/data-mask-strategy/mask/inner"
+	json_path "github.com/eolinker/apinto/drivers/strategy/data-mask-strategy/mask/json-path"
+	"github.com/eolinker/apinto/drivers/strategy/data-mask-strategy/mask/keyword"
+	"github.com/eolinker/apinto/drivers/strategy/data-mask-strategy/mask/regex"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/common/bean"
+)
+
+const (
+	Name = "websocket_message"
+)
+
+var (
+	workers eosc.IWorkers
+	once    sync.Once
+)
+
+func Register(register eosc.IExtenderDriverRegister) {
+	register.RegisterExtenderDriver(Name, NewFactory())
+}
+
+type Factory struct {
+	eosc.IExtenderDriverFactory
+}
+
+func NewFactory() *Factory {
+	return &Factory{
+		IExtenderDriverFactory: drivers.NewFactory[Config](Create),
+	}
+}
+
+func (f *Factory) Create(profession string, name string, label string, desc string, params map[string]interface{}) (eosc.IExtenderDriver, error) {
+	once.Do(func() {
+		bean.Autowired(&workers)
+		// 消息脱敏复用数据脱敏策略的匹配规则
+		inner.Register()
+		json_path.Register()
+		keyword.Register()
+		regex.Register()
+	})
+
+	return f.IExtenderDriverFactory.Create(profession, name, label, desc, params)
+}
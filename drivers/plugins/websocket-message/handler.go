@@ -0,0 +1,161 @@
+package websocket_message
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eolinker/apinto/drivers/strategy/data-mask-strategy/mask"
+	http_entry "github.com/eolinker/apinto/entries/http-entry"
+	http_context "github.com/eolinker/apinto/node/http-context"
+	"github.com/eolinker/apinto/output"
+	scope_manager "github.com/eolinker/apinto/scope-manager"
+	"github.com/eolinker/eosc/log"
+	"github.com/fasthttp/websocket"
+)
+
+var errRateLimit = errors.New("message rate limit exceeded")
+
+type handler struct {
+	maxMessageSize int64
+	limit          int
+	period         time.Duration
+	drop           bool
+
+	outputs       scope_manager.IProxyOutput[output.IEntryOutput]
+	logRequest    bool
+	logResponse   bool
+	maxLength     int
+	maskExecutors []mask.IMaskDriver
+}
+
+func newHandler(conf *Config) (*handler, error) {
+	h := &handler{
+		maxMessageSize: conf.MaxMessageSize,
+		limit:          conf.RateLimit.Limit,
+		period:         time.Second,
+		drop:           conf.RateLimit.Action == "drop",
+		maxLength:      conf.Log.MaxLength,
+	}
+	switch conf.RateLimit.Period {
+	case "minute":
+		h.period = time.Minute
+	case "hour":
+		h.period = time.Hour
+	}
+
+	list, err := getList(conf.Log.Output)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 {
+		h.outputs = scope_manager.NewProxy(list...)
+		switch conf.Log.Messages {
+		case "request":
+			h.logRequest = true
+		case "response":
+			h.logResponse = true
+		case "none":
+		default:
+			h.logRequest, h.logResponse = true, true
+		}
+	}
+
+	for _, rule := range conf.Log.Mask.Rules {
+		maskFunc, err := mask.GenMaskFunc(rule.Mask)
+		if err != nil {
+			return nil, err
+		}
+		fac, has := mask.GetMaskFactory(rule.Match.Type)
+		if !has {
+			return nil, fmt.Errorf("match type not found: %s", rule.Match.Type)
+		}
+		e, err := fac.Create(rule, maskFunc)
+		if err != nil {
+			return nil, err
+		}
+		h.maskExecutors = append(h.maskExecutors, e)
+	}
+	return h, nil
+}
+
+// bind 在连接升级前注册消息处理函数，速率限制按连接统计
+func (h *handler) bind(ctx http_context.IWebsocketMessageContext) {
+	if h.maxMessageSize > 0 {
+		ctx.SetMaxMessageSize(h.maxMessageSize)
+	}
+	if h.limit > 0 {
+		limiter := &windowLimiter{limit: h.limit, period: h.period}
+		ctx.AddMessageHandler(func(ctx *http_context.WebsocketContext, msg http_context.IWebsocketMessage) error {
+			if msg.Direction() != http_context.RequestMessage || limiter.allow(time.Now()) {
+				return nil
+			}
+			if h.drop {
+				msg.Drop()
+				return nil
+			}
+			return errRateLimit
+		})
+	}
+	if h.logRequest || h.logResponse {
+		ctx.AddMessageHandler(func(ctx *http_context.WebsocketContext, msg http_context.IWebsocketMessage) error {
+			if msg.Direction() == http_context.RequestMessage && !h.logRequest ||
+				msg.Direction() == http_context.ResponseMessage && !h.logResponse {
+				return nil
+			}
+			h.output(newMessageEntry(http_entry.NewEntry(ctx), msg, h.content(msg)))
+			return nil
+		})
+	}
+}
+
+// content 返回记录的消息内容，文本消息按规则脱敏，二进制消息以base64记录
+func (h *handler) content(msg http_context.IWebsocketMessage) string {
+	data := msg.Data()
+	if h.maxLength > 0 && len(data) > h.maxLength {
+		data = data[:h.maxLength]
+	}
+	if msg.Type() != websocket.TextMessage {
+		return base64.StdEncoding.EncodeToString(data)
+	}
+	// 脱敏不能修改转发的消息内容
+	data = append([]byte(nil), data...)
+	for _, e := range h.maskExecutors {
+		masked, err := e.Exec(data)
+		if err != nil {
+			log.Errorf("websocket message mask exec error: (%v),rule: (%s)", err, e.String())
+			continue
+		}
+		data = masked
+	}
+	return string(data)
+}
+
+func (h *handler) output(entry *entry) {
+	for _, o := range h.outputs.List() {
+		if err := o.Output(entry); err != nil {
+			log.Error("websocket message output error: ", err)
+		}
+	}
+}
+
+// windowLimiter 固定窗口计数，仅在单个连接的请求方向上使用，无需加锁
+type windowLimiter struct {
+	limit  int
+	period time.Duration
+	start  time.Time
+	count  int
+}
+
+func (l *windowLimiter) allow(now time.Time) bool {
+	if now.Sub(l.start) >= l.period {
+		l.start = now
+		l.count = 0
+	}
+	if l.count >= l.limit {
+		return false
+	}
+	l.count++
+	return true
+}
@@ -22,7 +22,7 @@ type Config struct {
 
 // Rule 规则
 type Rule struct {
-	Type  string `json:"type" yaml:"type" label:"类型" enum:"header"`
-	Name  string `json:"name" yaml:"name" label:"参数名"`
+	Type  string `json:"type" yaml:"type" label:"类型" enum:"header,attachment,group,version,argument"`
+	Name  string `json:"name" yaml:"name" label:"参数名" description:"类型为argument时为参数下标，可追加字段路径，如 0.user.id；类型为group、version时忽略"`
 	Value string `json:"value" yaml:"value" label:"值规" `
 }
@@ -0,0 +1,73 @@
+package dubbo3_router
+
+import (
+	grpc_router "github.com/eolinker/apinto/drivers/router/grpc-router"
+	grpc_context "github.com/eolinker/apinto/node/grpc-context"
+	"github.com/eolinker/apinto/plugin"
+	"github.com/eolinker/eosc"
+)
+
+type Config struct {
+	Listen int `json:"listen" yaml:"listen" title:"port" description:"使用端口" default:"80" label:"端口号" maximum:"65535"`
+
+	Host []string `json:"host" yaml:"host" label:"域名"`
+
+	ServiceName string            `json:"service_name" yaml:"service_name" label:"服务名" description:"Triple服务的接口名，支持checker语法，为空时匹配所有服务"`
+	MethodName  string            `json:"method_name" yaml:"method_name" label:"方法名" description:"支持checker语法，为空时匹配所有方法"`
+	Version     string            `json:"version" yaml:"version" label:"服务版本" description:"匹配tri-service-version头部，为空时不限制"`
+	Group       string            `json:"group" yaml:"group" label:"服务分组" description:"匹配tri-service-group头部，为空时不限制"`
+	Rules       []Rule            `json:"rules" yaml:"rules" label:"路由规则"`
+	Service     eosc.RequireId    `json:"service" yaml:"service" skill:"github.com/eolinker/apinto/service.service.IService" required:"true" label:"目标服务"`
+	Template    eosc.RequireId    `json:"template" yaml:"template" skill:"github.com/eolinker/apinto/template.template.ITemplate" required:"false" label:"插件模版"`
+	Disable     bool              `json:"disable" yaml:"disable" label:"禁用路由"`
+	Plugins     plugin.Plugins    `json:"plugins" yaml:"plugins" label:"插件配置"`
+	Retry       int               `json:"retry" label:"重试次数" yaml:"retry"`
+	TimeOut     int               `json:"time_out" label:"超时时间"`
+	Labels      map[string]string `json:"labels" label:"路由标签"`
+}
+
+// Rule 规则
+type Rule struct {
+	Type  string `json:"type" yaml:"type" label:"类型" enum:"header,metadata,authority"`
+	Name  string `json:"name" yaml:"name" label:"参数名" description:"metadata名称，类型为authority时忽略"`
+	Value string `json:"value" yaml:"value" label:"值规" `
+}
+
+// toGrpc Triple兼容gRPC，转换为gRPC路由配置，服务版本及分组转换为tri-头部的匹配规则
+func (c *Config) toGrpc() *grpc_router.Config {
+	rules := make([]grpc_router.Rule, 0, len(c.Rules)+2)
+	for _, r := range c.Rules {
+		rules = append(rules, grpc_router.Rule{
+			Type:  r.Type,
+			Name:  r.Name,
+			Value: r.Value,
+		})
+	}
+	if c.Version != "" {
+		rules = append(rules, grpc_router.Rule{Type: "metadata", Name: grpc_context.TripleServiceVersion, Value: c.Version})
+	}
+	if c.Group != "" {
+		rules = append(rules, grpc_router.Rule{Type: "metadata", Name: grpc_context.TripleServiceGroup, Value: c.Group})
+	}
+	labels := make(map[string]string, len(c.Labels)+1)
+	for k, v := range c.Labels {
+		labels[k] = v
+	}
+	if _, has := labels["protocol"]; !has {
+		labels["protocol"] = "triple"
+	}
+	return &grpc_router.Config{
+		Listen:      c.Listen,
+		Host:        c.Host,
+		ServiceName: c.ServiceName,
+		MethodName:  c.MethodName,
+		Rules:       rules,
+		Service:     c.Service,
+		Template:    c.Template,
+		Disable:     c.Disable,
+		Plugins:     c.Plugins,
+		Retry:       c.Retry,
+		TimeOut:     c.TimeOut,
+		Labels:      labels,
+	}
+}
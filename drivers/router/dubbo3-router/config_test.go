@@ -0,0 +1,103 @@
+package dubbo3_router
+
+import (
+	"testing"
+
+	grpc_context "github.com/eolinker/eosc/eocontext/grpc-context"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/eolinker/apinto/router"
+	grpc_router "github.com/eolinker/apinto/router/grpc-router"
+	"github.com/eolinker/eosc/eocontext"
+)
+
+type testHandler string
+
+func (h testHandler) Serve(ctx eocontext.EoContext) {}
+
+type testRequest struct {
+	grpc_context.IRequest
+	headers metadata.MD
+	service string
+	method  string
+}
+
+func (r *testRequest) Headers() metadata.MD {
+	return r.headers
+}
+
+func (r *testRequest) Host() string {
+	return ""
+}
+
+func (r *testRequest) Service() string {
+	return r.service
+}
+
+func (r *testRequest) Method() string {
+	return r.method
+}
+
+func TestRouterMatch(t *testing.T) {
+	configs := map[string]*Config{
+		"gray": {
+			Listen:      9000,
+			ServiceName: "org.apache.dubbo.demo.GreeterService",
+			MethodName:  "SayHello",
+			Version:     "1.0.0",
+			Group:       "gray",
+		},
+		"blue": {
+			Listen:      9000,
+			ServiceName: "org.apache.dubbo.demo.GreeterService",
+			MethodName:  "SayHello",
+			Group:       "blue",
+		},
+	}
+	root := grpc_router.NewRoot()
+	for id, c := range configs {
+		conf := c.toGrpc()
+		if conf.Labels["protocol"] != "triple" {
+			t.Errorf("%s: labels %v", id, conf.Labels)
+		}
+		appends := make([]router.AppendRule, 0, len(conf.Rules))
+		for _, r := range conf.Rules {
+			appends = append(appends, router.AppendRule{Type: r.Type, Name: r.Name, Pattern: r.Value})
+		}
+		err := root.Add(id, testHandler(id), conf.Listen, conf.Host, conf.ServiceName, conf.MethodName, appends)
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+	}
+	matcher := root.Build()
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		want    string
+	}{
+		{name: "version and group", method: "SayHello", headers: map[string]string{"tri-service-version": "1.0.0", "tri-service-group": "gray"}, want: "gray"},
+		{name: "other group", method: "SayHello", headers: map[string]string{"tri-service-version": "1.0.0", "tri-service-group": "blue"}, want: "blue"},
+		{name: "other version", method: "SayHello", headers: map[string]string{"tri-service-version": "2.0.0", "tri-service-group": "gray"}},
+		{name: "no triple headers", method: "SayHello"},
+		{name: "other method", method: "SayBye", headers: map[string]string{"tri-service-version": "1.0.0", "tri-service-group": "gray"}},
+	}
+	for _, tt := range tests {
+		req := &testRequest{
+			headers: metadata.New(tt.headers),
+			service: "org.apache.dubbo.demo.GreeterService",
+			method:  tt.method,
+		}
+		h, ok := matcher.Match(9000, req)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("%s: unexpected match %v", tt.name, h)
+			}
+			continue
+		}
+		if !ok || h != testHandler(tt.want) {
+			t.Errorf("%s: got %v, want %s", tt.name, h, tt.want)
+		}
+	}
+}
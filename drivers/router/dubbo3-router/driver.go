@@ -0,0 +1,35 @@
+package dubbo3_router
+
+import (
+	"sync"
+
+	"github.com/eolinker/apinto/drivers/router/grpc-router/manager"
+	"github.com/eolinker/apinto/plugin"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/log"
+
+	grpc_router "github.com/eolinker/apinto/drivers/router/grpc-router"
+)
+
+var (
+	routerManager manager.IManger
+	pluginManager plugin.IPluginManager
+	once          sync.Once
+)
+
+func Check(v *Config, workers map[eosc.RequireId]eosc.IWorker) error {
+	return grpc_router.Check(v.toGrpc(), workers)
+}
+
+// Create 创建一个dubbo3路由驱动实例
+func Create(id, name string, v *Config, workers map[eosc.RequireId]eosc.IWorker) (eosc.IWorker, error) {
+	log.Debug("create dubbo3 router worker: ", pluginManager)
+	r := &Dubbo3Router{
+		GrpcRouter: grpc_router.NewGrpcRouter(id, name, routerManager, pluginManager),
+	}
+	err := r.GrpcRouter.Reset(v.toGrpc(), workers)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
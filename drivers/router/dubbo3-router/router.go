@@ -0,0 +1,21 @@
+package dubbo3_router
+
+import (
+	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/eosc"
+
+	grpc_router "github.com/eolinker/apinto/drivers/router/grpc-router"
+)
+
+// Dubbo3Router Dubbo3 Triple路由，Triple请求经由gRPC服务接入，与gRPC路由共用路由表
+type Dubbo3Router struct {
+	*grpc_router.GrpcRouter
+}
+
+func (d *Dubbo3Router) Reset(conf interface{}, workers map[eosc.RequireId]eosc.IWorker) error {
+	cfg, err := drivers.Assert[Config](conf)
+	if err != nil {
+		return err
+	}
+	return d.GrpcRouter.Reset(cfg.toGrpc(), workers)
+}
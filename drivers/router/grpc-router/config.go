@@ -10,8 +10,8 @@ type Config struct {
 
 	Host []string `json:"host" yaml:"host" label:"域名"`
 
-	ServiceName string            `json:"service_name" yaml:"service_name" label:"服务名"`
-	MethodName  string            `json:"method_name" yaml:"method_name" label:"方法名"`
+	ServiceName string            `json:"service_name" yaml:"service_name" label:"服务名" description:"支持checker语法，如 acme.billing.* 匹配包下的所有服务，为空时匹配所有服务"`
+	MethodName  string            `json:"method_name" yaml:"method_name" label:"方法名" description:"支持checker语法，如 Get* 或 ~=^(Get|List)，为空时匹配所有方法"`
 	Rules       []Rule            `json:"rules" yaml:"rules" label:"路由规则"`
 	Service     eosc.RequireId    `json:"service" yaml:"service" skill:"github.com/eolinker/apinto/service.service.IService" required:"true" label:"目标服务"`
 	Template    eosc.RequireId    `json:"template" yaml:"template" skill:"github.com/eolinker/apinto/template.template.ITemplate" required:"false" label:"插件模版"`
@@ -20,11 +20,14 @@ type Config struct {
 	Retry       int               `json:"retry" label:"重试次数" yaml:"retry"`
 	TimeOut     int               `json:"time_out" label:"超时时间"`
 	Labels      map[string]string `json:"labels" label:"路由标签"`
+
+	GrpcWeb      bool     `json:"grpc_web" yaml:"grpc_web" label:"gRPC-Web" description:"允许浏览器通过gRPC-Web（含base64编码的grpc-web-text）在http端口上调用该路由"`
+	AllowOrigins []string `json:"allow_origins" yaml:"allow_origins" label:"跨域来源" description:"允许跨域调用的Origin，为空时允许所有来源；仅显式配置的来源允许携带凭证" switch:"grpc_web===true"`
 }
 
 // Rule 规则
 type Rule struct {
-	Type  string `json:"type" yaml:"type" label:"类型" enum:"header"`
-	Name  string `json:"name" yaml:"name" label:"参数名"`
+	Type  string `json:"type" yaml:"type" label:"类型" enum:"header,metadata,authority"`
+	Name  string `json:"name" yaml:"name" label:"参数名" description:"metadata名称，类型为authority时忽略"`
 	Value string `json:"value" yaml:"value" label:"值规" `
 }
@@ -31,12 +31,7 @@ func Check(v *Config, workers map[eosc.RequireId]eosc.IWorker) error {
 // Create 创建一个http路由驱动实例
 func Create(id, name string, v *Config, workers map[eosc.RequireId]eosc.IWorker) (eosc.IWorker, error) {
 	log.Debug("create http router worker: ", pluginManager)
-	r := &GrpcRouter{
-		id:            id,
-		name:          name,
-		routerManager: routerManager,
-		pluginManager: pluginManager,
-	}
+	r := NewGrpcRouter(id, name, routerManager, pluginManager)
 
 	err := r.reset(v, workers)
 	if err != nil {
@@ -45,6 +40,16 @@ func Create(id, name string, v *Config, workers map[eosc.RequireId]eosc.IWorker)
 	return r, err
 }
 
+// NewGrpcRouter 创建gRPC路由实例，兼容gRPC的协议（如Dubbo3 Triple）的路由可复用
+func NewGrpcRouter(id, name string, routerManager manager.IManger, pluginManager plugin.IPluginManager) *GrpcRouter {
+	return &GrpcRouter{
+		id:            id,
+		name:          name,
+		routerManager: routerManager,
+		pluginManager: pluginManager,
+	}
+}
+
 // check 检查http路由驱动配置
 func check(v interface{}, workers map[eosc.RequireId]eosc.IWorker) (*Config, service.IService, template.ITemplate, error) {
 	conf, ok := v.(*Config)
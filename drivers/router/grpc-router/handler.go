@@ -29,6 +29,11 @@ type grpcRouter struct {
 	retry    int
 	labels   map[string]string
 	timeout  time.Duration
+	grpcWeb  *manager.GrpcWebOption
+}
+
+func (h *grpcRouter) GrpcWeb() *manager.GrpcWebOption {
+	return h.grpcWeb
 }
 
 func (h *grpcRouter) Serve(ctx eocontext.EoContext) {
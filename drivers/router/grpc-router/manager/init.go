@@ -37,6 +37,7 @@ func init() {
 		server.Serve(ln)
 	}
 	router.Register(router.GRPC, serverHandler)
+	router.RegisterHttpInterceptor(routerManager.GrpcWebHandler)
 
 	var pluginManager plugin.IPluginManager
 	bean.Autowired(&pluginManager)
@@ -75,6 +75,10 @@ func (m *Manager) FastHandler(port int, srv interface{}, stream grpc.ServerStrea
 	}
 
 	r, has := m.matcher.Match(port, ctx.Request())
+	return m.serve(port, ctx, r, has)
+}
+
+func (m *Manager) serve(port int, ctx *grpc_context.Context, r router.IRouterHandler, has bool) error {
 	if !has {
 		errHandler := NewErrHandler(status.Error(codes.NotFound, "not found"))
 		ctx.SetFinish(errHandler)
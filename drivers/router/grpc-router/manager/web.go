@@ -0,0 +1,166 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	grpc_context "github.com/eolinker/apinto/node/grpc-context"
+	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc/metadata"
+)
+
+// IGrpcWebHandler 路由处理器实现该接口并返回非空配置时，允许浏览器通过gRPC-Web调用该路由
+type IGrpcWebHandler interface {
+	GrpcWeb() *GrpcWebOption
+}
+
+// GrpcWebOption gRPC-Web跨域配置
+type GrpcWebOption struct {
+	// AllowOrigins 允许跨域调用的Origin，为空或包含"*"时允许所有来源，仅显式配置的来源允许携带凭证
+	AllowOrigins []string
+}
+
+// allowOrigin 返回是否允许该来源跨域调用，以及该来源是否为显式配置的来源
+func (o *GrpcWebOption) allowOrigin(origin string) (allowed bool, explicit bool) {
+	if len(o.AllowOrigins) == 0 {
+		return true, false
+	}
+	for _, v := range o.AllowOrigins {
+		if strings.EqualFold(v, origin) {
+			return true, true
+		}
+		if v == "*" {
+			allowed = true
+		}
+	}
+	return allowed, false
+}
+
+const grpcWebExposeHeaders = "grpc-status, grpc-message, grpc-status-details-bin"
+
+var skipGrpcWebRequestHeaders = map[string]struct{}{
+	"connection":        {},
+	"keep-alive":        {},
+	"transfer-encoding": {},
+	"upgrade":           {},
+	"te":                {},
+	"host":              {},
+	"content-length":    {},
+	"content-type":      {},
+}
+
+// GrpcWebHandler 处理http端口上的gRPC-Web请求及其跨域预检请求，转换为原生gRPC请求后交由匹配的gRPC路由处理；
+// 未匹配到开启gRPC-Web的路由时返回false，交由http路由继续处理
+func (m *Manager) GrpcWebHandler(port int, fast *fasthttp.RequestCtx) bool {
+	isWeb, text := grpc_context.IsGrpcWeb(string(fast.Request.Header.ContentType()))
+	preflight := !isWeb && isGrpcWebPreflight(fast)
+	if (!isWeb || !fast.IsPost()) && !preflight {
+		return false
+	}
+	if m.matcher == nil {
+		return false
+	}
+	var body []byte
+	if isWeb {
+		body = fast.Request.Body()
+	}
+	pr, pw := io.Pipe()
+	stream, err := grpc_context.NewWebStream(context.Background(), string(fast.Path()), string(fast.Host()), readGrpcWebHeaders(fast), fast.RemoteAddr(), body, text, pw)
+	if err != nil {
+		fast.Error(err.Error(), fasthttp.StatusBadRequest)
+		return true
+	}
+	// 未进入路由处理时关闭读取端，使trailer帧写入立即返回
+	abort := func() {
+		pr.Close()
+		stream.Finish(nil)
+	}
+	ctx := grpc_context.NewContext(nil, stream)
+	r, has := m.matcher.Match(port, ctx.Request())
+	var option *GrpcWebOption
+	if has {
+		if h, ok := r.(IGrpcWebHandler); ok {
+			option = h.GrpcWeb()
+		}
+	}
+	if option == nil {
+		abort()
+		return false
+	}
+
+	origin := fast.Request.Header.Peek(fasthttp.HeaderOrigin)
+	if len(origin) > 0 {
+		allowed, explicit := option.allowOrigin(string(origin))
+		if !allowed {
+			abort()
+			fast.Error("origin not allowed", fasthttp.StatusForbidden)
+			return true
+		}
+		if explicit {
+			// 仅显式配置的来源允许携带cookie等凭证调用
+			fast.Response.Header.Set(fasthttp.HeaderAccessControlAllowOrigin, string(origin))
+			fast.Response.Header.Set(fasthttp.HeaderAccessControlAllowCredentials, "true")
+			fast.Response.Header.Add(fasthttp.HeaderVary, fasthttp.HeaderOrigin)
+		} else {
+			fast.Response.Header.Set(fasthttp.HeaderAccessControlAllowOrigin, "*")
+		}
+	}
+	if preflight {
+		abort()
+		fast.Response.Header.Set(fasthttp.HeaderAccessControlAllowMethods, "POST, OPTIONS")
+		fast.Response.Header.SetBytesV(fasthttp.HeaderAccessControlAllowHeaders, fast.Request.Header.Peek(fasthttp.HeaderAccessControlRequestHeaders))
+		fast.Response.Header.Set(fasthttp.HeaderAccessControlMaxAge, "86400")
+		fast.SetStatusCode(fasthttp.StatusNoContent)
+		return true
+	}
+
+	go func() {
+		err := m.serve(port, ctx, r, has)
+		if err == io.EOF {
+			err = nil
+		}
+		stream.Finish(err)
+	}()
+	<-stream.Ready()
+
+	exposeHeaders := grpcWebExposeHeaders
+	for k, vs := range stream.Header() {
+		for _, v := range vs {
+			fast.Response.Header.Add(k, v)
+		}
+		exposeHeaders += ", " + k
+	}
+	if len(origin) > 0 {
+		fast.Response.Header.Set(fasthttp.HeaderAccessControlExposeHeaders, exposeHeaders)
+	}
+	if text {
+		fast.SetContentType(grpc_context.GrpcWebTextContentType + "+proto")
+	} else {
+		fast.SetContentType(grpc_context.GrpcWebContentType + "+proto")
+	}
+	fast.SetStatusCode(fasthttp.StatusOK)
+	// 响应帧写入后立即以chunk形式发送，支持服务端流式响应
+	fast.Response.SetBodyStream(pr, -1)
+	return true
+}
+
+func isGrpcWebPreflight(fast *fasthttp.RequestCtx) bool {
+	if !fast.IsOptions() || len(fast.Request.Header.Peek(fasthttp.HeaderAccessControlRequestMethod)) == 0 {
+		return false
+	}
+	return bytes.Contains(bytes.ToLower(fast.Request.Header.Peek(fasthttp.HeaderAccessControlRequestHeaders)), []byte("x-grpc-web"))
+}
+
+func readGrpcWebHeaders(fast *fasthttp.RequestCtx) metadata.MD {
+	md := metadata.MD{}
+	fast.Request.Header.VisitAll(func(key, value []byte) {
+		k := strings.ToLower(string(key))
+		if _, has := skipGrpcWebRequestHeaders[k]; has {
+			return
+		}
+		md.Append(k, string(value))
+	})
+	return md
+}
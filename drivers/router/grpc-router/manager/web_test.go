@@ -0,0 +1,24 @@
+package manager
+
+import "testing"
+
+func TestGrpcWebAllowOrigin(t *testing.T) {
+	tests := []struct {
+		origins  []string
+		origin   string
+		allowed  bool
+		explicit bool
+	}{
+		{origins: nil, origin: "https://evil.com", allowed: true},
+		{origins: []string{"*"}, origin: "https://evil.com", allowed: true},
+		{origins: []string{"*", "https://app.com"}, origin: "https://APP.com", allowed: true, explicit: true},
+		{origins: []string{"https://app.com"}, origin: "https://evil.com"},
+	}
+	for _, tt := range tests {
+		option := &GrpcWebOption{AllowOrigins: tt.origins}
+		allowed, explicit := option.allowOrigin(tt.origin)
+		if allowed != tt.allowed || explicit != tt.explicit {
+			t.Errorf("%v %s: got %v %v, want %v %v", tt.origins, tt.origin, allowed, explicit, tt.allowed, tt.explicit)
+		}
+	}
+}
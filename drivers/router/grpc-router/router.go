@@ -60,6 +60,9 @@ func (h *GrpcRouter) reset(cfg *Config, workers map[eosc.RequireId]eosc.IWorker)
 		disable:         cfg.Disable,
 		labels:          cfg.Labels,
 	}
+	if cfg.GrpcWeb {
+		handler.grpcWeb = &manager.GrpcWebOption{AllowOrigins: cfg.AllowOrigins}
+	}
 
 	if !cfg.Disable {
 
@@ -20,17 +20,26 @@ type Config struct {
 
 	Template  eosc.RequireId `json:"template" yaml:"template" skill:"github.com/eolinker/apinto/template.template.ITemplate" required:"false" label:"插件模版"`
 	Websocket bool           `json:"websocket" yaml:"websocket" label:"Websocket" switch:"service!==''"`
+	Http3     bool           `json:"http3" yaml:"http3" label:"HTTP/3" description:"在https端口上同时监听QUIC，并通过Alt-Svc头部通告"`
 	Disable   bool           `json:"disable" yaml:"disable" label:"禁用路由"`
 	Plugins   plugin.Plugins `json:"plugins" yaml:"plugins" label:"插件配置"`
 
 	Retry   int               `json:"retry" label:"重试次数" yaml:"retry" switch:"service!==''"`
 	TimeOut int               `json:"time_out" label:"超时时间" switch:"service!==''"`
 	Labels  map[string]string `json:"labels" label:"路由标签"`
+
+	MaxBodySize  int64 `json:"max_body_size" yaml:"max_body_size" label:"请求体最大长度" description:"单位：字节，0表示不限制" minimum:"0"`
+	ReadTimeout  int   `json:"read_timeout" yaml:"read_timeout" label:"读空闲超时" description:"读取客户端请求体及上游响应的空闲超时，单位：毫秒，0表示不限制" minimum:"0" switch:"service!==''"`
+	WriteTimeout int   `json:"write_timeout" yaml:"write_timeout" label:"写空闲超时" description:"写入上游请求及客户端响应的空闲超时，单位：毫秒，0表示不限制" minimum:"0" switch:"service!==''"`
+	Stream       bool  `json:"stream" yaml:"stream" label:"流式转发" description:"请求体与响应体不缓冲，直接在客户端与上游之间转发，开启后不可使用需要读取body的插件，且不进行重试" switch:"service!==''"`
+
+	SSEHeartbeat       int  `json:"sse_heartbeat" yaml:"sse_heartbeat" label:"SSE心跳间隔" description:"上游SSE响应超过该时间未返回数据时向客户端发送注释行保持连接，单位：毫秒，0表示不发送" minimum:"0" switch:"service!==''"`
+	ChunkedPassthrough bool `json:"chunked_passthrough" yaml:"chunked_passthrough" label:"chunked响应直通" description:"上游以chunked方式返回且未声明长度的响应不缓冲，直接转发给客户端；SSE响应始终直通" switch:"service!==''"`
 }
 
 // Rule 规则
 type Rule struct {
-	Type  string `json:"type" yaml:"type" label:"类型" enum:"header,query,cookie"`
-	Name  string `json:"name" yaml:"name" label:"参数名"`
+	Type  string `json:"type" yaml:"type" label:"类型" enum:"header,query,cookie,subprotocol"`
+	Name  string `json:"name" yaml:"name" label:"参数名" description:"类型为subprotocol时无需填写，匹配websocket握手请求中客户端声明的任一子协议"`
 	Value string `json:"value" yaml:"value" label:"值规" `
 }
@@ -1,7 +1,9 @@
 package http_router
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/eolinker/eosc/log"
@@ -19,6 +21,9 @@ var (
 	routerManager manager.IManger
 	pluginManager plugin.IPluginManager
 	once          sync.Once
+
+	errorStreamWithWebsocket = errors.New("can not be enabled with websocket")
+	errorStreamBodyRequired  = errors.New("require request or response body and can not be used with stream")
 )
 
 func Check(v *Config, workers map[eosc.RequireId]eosc.IWorker) error {
@@ -62,6 +67,16 @@ func check(v interface{}, workers map[eosc.RequireId]eosc.IWorker) (*Config, ser
 		}
 		//return nil, nil, nil, fmt.Errorf("target %s: %w", conf.Service, eosc.ErrorRequire)
 	}
+	if conf.Stream {
+		if conf.Websocket {
+			return nil, nil, nil, fmt.Errorf("stream: %w", errorStreamWithWebsocket)
+		}
+		if pluginManager != nil {
+			if names := pluginManager.BodyRequired(conf.Plugins); len(names) > 0 {
+				return nil, nil, nil, fmt.Errorf("stream: plugins %s %w", strings.Join(names, ","), errorStreamBodyRequired)
+			}
+		}
+	}
 
 	var tmp template.ITemplate
 	if conf.Template != "" {
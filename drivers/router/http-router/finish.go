@@ -8,6 +8,15 @@ import (
 var defaultFinisher = &Finisher{}
 
 type Finisher struct {
+	altSvc func(port int) string
+}
+
+// newFinisher altSvc不为空时，响应按请求端口的http3监听情况通告Alt-Svc头部
+func newFinisher(altSvc func(port int) string) *Finisher {
+	if altSvc == nil {
+		return defaultFinisher
+	}
+	return &Finisher{altSvc: altSvc}
 }
 
 func (f *Finisher) Finish(org eocontext.EoContext) error {
@@ -15,6 +24,11 @@ func (f *Finisher) Finish(org eocontext.EoContext) error {
 	if err != nil {
 		return err
 	}
+	if f.altSvc != nil {
+		if altSvc := f.altSvc(ctx.LocalPort()); altSvc != "" {
+			ctx.Response().SetHeader("Alt-Svc", altSvc)
+		}
+	}
 	ctx.FastFinish()
 
 	return nil
@@ -0,0 +1,28 @@
+package http_router
+
+import (
+	"fmt"
+	"testing"
+
+	http_context "github.com/eolinker/apinto/node/http-context"
+	"github.com/valyala/fasthttp"
+)
+
+func TestFinishAltSvc(t *testing.T) {
+	f := newFinisher(func(port int) string {
+		if port != 443 {
+			return ""
+		}
+		return fmt.Sprintf("h3=\":%d\"; ma=86400", port)
+	})
+	for port, want := range map[int]string{443: "h3=\":443\"; ma=86400", 80: ""} {
+		fastCtx := new(fasthttp.RequestCtx)
+		ctx := http_context.NewContext(fastCtx, port)
+		if err := f.Finish(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if got := string(fastCtx.Response.Header.Peek("Alt-Svc")); got != want {
+			t.Errorf("port %d: alt-svc %q, want %q", port, got, want)
+		}
+	}
+}
@@ -31,6 +31,18 @@ type httpHandler struct {
 	labels      map[string]string
 	retry       int
 	timeout     time.Duration
+	proxyOption *http_service.ProxyOption
+}
+
+func (h *httpHandler) Stream() bool {
+	return h.proxyOption != nil && h.proxyOption.Stream
+}
+
+func (h *httpHandler) MaxBodySize() int64 {
+	if h.proxyOption == nil {
+		return 0
+	}
+	return h.proxyOption.MaxBodySize
 }
 
 func (h *httpHandler) Serve(ctx eocontext.EoContext) {
@@ -53,6 +65,14 @@ func (h *httpHandler) Serve(ctx eocontext.EoContext) {
 			return
 		}
 		ctx = wsCtx
+	} else if hc, ok := httpContext.(*http_service.HttpContext); ok {
+		err = hc.SetProxyOption(h.proxyOption)
+		if err != nil {
+			httpContext.Response().SetStatus(http.StatusRequestEntityTooLarge, "")
+			httpContext.Response().SetBody([]byte(err.Error()))
+			httpContext.FastFinish()
+			return
+		}
 	}
 
 	for key, value := range h.labels {
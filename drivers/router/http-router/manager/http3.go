@@ -0,0 +1,240 @@
+package manager
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/eolinker/eosc/log"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/valyala/fasthttp"
+)
+
+const maxHttp3RequestBodySize = 100 * 1024 * 1024
+
+type http3Listener struct {
+	server *http3.Server
+	conn   net.PacketConn
+}
+
+// http3Servers 管理各端口上的QUIC监听，仅在端口已开启tls且存在启用http3的路由时监听
+type http3Servers struct {
+	lock      sync.Mutex
+	handler   func(port int) http.Handler
+	tlsConfig *tls.Config
+	tlsPorts  map[int]struct{}
+	routers   map[string]int
+	listeners map[int]*http3Listener
+}
+
+func newHttp3Servers(handler func(port int) http.Handler) *http3Servers {
+	return &http3Servers{
+		handler:   handler,
+		tlsPorts:  make(map[int]struct{}),
+		routers:   make(map[string]int),
+		listeners: make(map[int]*http3Listener),
+	}
+}
+
+func (s *http3Servers) SetTLSPort(port int, tlsConfig *tls.Config) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.tlsConfig = tlsConfig
+	s.tlsPorts[port] = struct{}{}
+	s.refresh()
+}
+
+func (s *http3Servers) Set(id string, port int, enable bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if enable {
+		s.routers[id] = port
+	} else {
+		delete(s.routers, id)
+	}
+	s.refresh()
+}
+
+func (s *http3Servers) refresh() {
+	ports := make(map[int]struct{})
+	for _, port := range s.routers {
+		if _, has := s.tlsPorts[port]; has {
+			ports[port] = struct{}{}
+		}
+	}
+	for port, l := range s.listeners {
+		if _, has := ports[port]; !has {
+			l.server.Close()
+			l.conn.Close()
+			delete(s.listeners, port)
+		}
+	}
+	for port := range ports {
+		if _, has := s.listeners[port]; has {
+			continue
+		}
+		l, err := s.listen(port)
+		if err != nil {
+			log.Errorf("http3 listen port %d: %v", port, err)
+			continue
+		}
+		s.listeners[port] = l
+	}
+}
+
+func (s *http3Servers) listen(port int) (*http3Listener, error) {
+	conn, err := net.ListenPacket("udp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return nil, err
+	}
+	server := &http3.Server{
+		Port:      port,
+		TLSConfig: s.tlsConfig,
+		Handler:   s.handler(port),
+	}
+	go func() {
+		err := server.Serve(conn)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("http3 serve port %d: %v", port, err)
+		}
+	}()
+	log.Debug("http3 listen: ", conn.LocalAddr().String())
+	return &http3Listener{server: server, conn: conn}, nil
+}
+
+// listening 端口上是否已开启QUIC监听
+func (s *http3Servers) listening(port int) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, has := s.listeners[port]
+	return has
+}
+
+// AltSvc 端口上已开启http3监听时返回在tcp响应中通告http3端口的Alt-Svc头部值，否则返回空
+func (m *Manager) AltSvc(port int) string {
+	if !m.http3Servers.listening(port) {
+		return ""
+	}
+	return fmt.Sprintf("h3=\":%d\"; ma=86400", port)
+}
+
+var skipHttp3ResponseHeaders = map[string]struct{}{
+	"Connection":        {},
+	"Keep-Alive":        {},
+	"Transfer-Encoding": {},
+	"Upgrade":           {},
+	"Content-Length":    {},
+}
+
+// Http3Handler 将http3请求转换为fasthttp请求后与tcp请求同样经拦截器及路由处理，保证gRPC-Web、插件及策略对http3请求同样生效
+func (m *Manager) Http3Handler(port int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > maxHttp3RequestBodySize {
+			http.Error(w, fasthttp.ErrBodyTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		var remoteAddr net.Addr = zeroAddr
+		if addr, err := net.ResolveUDPAddr("udp", r.RemoteAddr); err == nil {
+			remoteAddr = addr
+		}
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Init2(&http3Conn{remoteAddr: remoteAddr}, nil, true)
+		req := &ctx.Request
+		req.Header.SetMethod(r.Method)
+		req.SetRequestURI(r.URL.RequestURI())
+		for key, values := range r.Header {
+			for _, v := range values {
+				req.Header.Add(key, v)
+			}
+		}
+		req.Header.SetHost(r.Host)
+		// 请求体与tcp请求一样以stream传入，由路由按请求体限制及流式转发配置读取
+		req.SetBodyStream(http.MaxBytesReader(w, r.Body, maxHttp3RequestBodySize), int(r.ContentLength))
+		req.URI().SetScheme("https")
+		m.serve(port, ctx)
+		// 关闭未写出的流式响应，触发流结束回调
+		defer ctx.Response.Reset()
+
+		header := w.Header()
+		ctx.Response.Header.VisitAll(func(key, value []byte) {
+			k := string(key)
+			if _, has := skipHttp3ResponseHeaders[k]; has {
+				return
+			}
+			header.Add(k, string(value))
+		})
+		w.WriteHeader(ctx.Response.StatusCode())
+		if r.Method == http.MethodHead {
+			return
+		}
+		var err error
+		if ctx.Response.IsBodyStream() {
+			// 流式响应每次写入后立即刷新，保证SSE等响应实时到达客户端
+			if flusher, ok := w.(http.Flusher); ok {
+				err = ctx.Response.BodyWriteTo(&flushWriter{w: w, flusher: flusher})
+			} else {
+				err = ctx.Response.BodyWriteTo(w)
+			}
+		} else {
+			err = ctx.Response.BodyWriteTo(w)
+		}
+		if err != nil {
+			log.Error("http3 write response body error: ", err)
+		}
+	})
+}
+
+type flushWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	f.flusher.Flush()
+	return n, err
+}
+
+var zeroAddr = &net.UDPAddr{IP: net.IPv4zero}
+
+// http3Conn 为转换后的fasthttp请求提供地址信息，读写及超时设置均为空操作
+type http3Conn struct {
+	remoteAddr net.Addr
+}
+
+func (c *http3Conn) Read(b []byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (c *http3Conn) Write(b []byte) (int, error) {
+	return 0, net.ErrClosed
+}
+
+func (c *http3Conn) Close() error {
+	return nil
+}
+
+func (c *http3Conn) LocalAddr() net.Addr {
+	return zeroAddr
+}
+
+func (c *http3Conn) RemoteAddr() net.Addr {
+	return c.remoteAddr
+}
+
+func (c *http3Conn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *http3Conn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *http3Conn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
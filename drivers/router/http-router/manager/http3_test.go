@@ -0,0 +1,141 @@
+package manager
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eolinker/apinto/drivers/router"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/valyala/fasthttp"
+)
+
+func interceptGrpcWeb(t *testing.T) *int {
+	intercepted := new(int)
+	t.Cleanup(router.RegisterHttpInterceptor(func(port int, ctx *fasthttp.RequestCtx) bool {
+		if string(ctx.Request.Header.ContentType()) != "application/grpc-web+proto" {
+			return false
+		}
+		*intercepted = port
+		ctx.Response.Header.Set("grpc-status", "0")
+		ctx.Response.SetBodyString(string(ctx.Request.Body()))
+		return true
+	}))
+	return intercepted
+}
+
+func TestHttp3Intercept(t *testing.T) {
+	intercepted := interceptGrpcWeb(t)
+
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/demo.Greeter/SayHello", strings.NewReader("frame"))
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+	w := httptest.NewRecorder()
+	NewManager().Http3Handler(8443).ServeHTTP(w, req)
+
+	if *intercepted != 8443 {
+		t.Fatalf("http3 request not intercepted, port %d", *intercepted)
+	}
+	if w.Code != http.StatusOK || w.Header().Get("grpc-status") != "0" || w.Body.String() != "frame" {
+		t.Errorf("unexpected response: %d %v %q", w.Code, w.Header(), w.Body.String())
+	}
+}
+
+func TestHttp3MaxBodySize(t *testing.T) {
+	reader := &countReader{reader: strings.NewReader(strings.Repeat("a", 64*1024))}
+	handler := &limitHandler{maxBodySize: 1024, reader: reader}
+	m := NewManager()
+	if err := m.Set("upload", 8443, nil, nil, []string{http.MethodPost}, "/upload", nil, handler); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/upload", reader)
+	req.ContentLength = 64 * 1024
+	m.Http3Handler(8443).ServeHTTP(httptest.NewRecorder(), req)
+
+	if handler.err != fasthttp.ErrBodyTooLarge {
+		t.Fatalf("err %v, want %v", handler.err, fasthttp.ErrBodyTooLarge)
+	}
+	if reader.n != 0 {
+		t.Errorf("oversized body read before rejected: %d bytes", reader.n)
+	}
+}
+
+func testTLSConfig(t *testing.T) *tls.Config {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+}
+
+func freeUDPPort(t *testing.T) int {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+func TestHttp3Listener(t *testing.T) {
+	interceptGrpcWeb(t)
+	port, plainPort := freeUDPPort(t), freeUDPPort(t)
+	m := NewManager()
+	m.http3Servers.SetTLSPort(port, testTLSConfig(t))
+
+	m.SetHttp3("tls", port, true)
+	m.SetHttp3("plain", plainPort, true)
+	defer m.SetHttp3("tls", port, false)
+	if got := m.AltSvc(plainPort); got != "" {
+		t.Errorf("alt-svc advertised on port without tls: %q", got)
+	}
+	if !m.http3Servers.listening(port) || m.AltSvc(port) == "" {
+		t.Fatalf("http3 not listening on tls port %d", port)
+	}
+
+	rt := &http3.RoundTripper{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	defer rt.Close()
+	req, _ := http.NewRequest(http.MethodPost, "https://127.0.0.1:"+strconv.Itoa(port)+"/demo.Greeter/SayHello", strings.NewReader("frame"))
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+	resp, err := (&http.Client{Transport: rt, Timeout: 5 * time.Second}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "frame" {
+		t.Errorf("unexpected response: %d %q", resp.StatusCode, body)
+	}
+
+	m.SetHttp3("tls", port, false)
+	if m.http3Servers.listening(port) || m.AltSvc(port) != "" {
+		t.Fatal("http3 still listening after the last router disabled it")
+	}
+	conn, err := net.ListenPacket("udp", "127.0.0.1:"+strconv.Itoa(port))
+	if err != nil {
+		t.Fatalf("udp port not released: %v", err)
+	}
+	conn.Close()
+}
@@ -26,11 +26,12 @@ func init() {
 			ReadBufferSize:               16 * 1024,
 
 			Handler: func(ctx *fasthttp.RequestCtx) {
-				routerManager.FastHandler(port, ctx)
+				routerManager.serve(port, ctx)
 			}}
 		server.Serve(ln)
 	}
 	router.Register(router.Http, serverHandler)
+	router.RegisterTLSPort(routerManager.http3Servers.SetTLSPort)
 
 	var pluginManager plugin.IPluginManager
 	bean.Autowired(&pluginManager)
@@ -45,3 +46,11 @@ func init() {
 
 	})
 }
+
+// serve tcp及http3请求的统一入口，先交由拦截器处理（如gRPC-Web），未被处理时再按http路由匹配
+func (m *Manager) serve(port int, ctx *fasthttp.RequestCtx) {
+	if router.InterceptHttp(port, ctx) {
+		return
+	}
+	m.FastHandler(port, ctx)
+}
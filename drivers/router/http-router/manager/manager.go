@@ -22,6 +22,15 @@ type IManger interface {
 	Delete(id string)
 	AddPreRouter(id string, method []string, path string, handler router.IRouterPreHandler)
 	DeletePreRouter(id string)
+	SetHttp3(id string, port int, enable bool)
+	AltSvc(port int) string
+}
+
+// IStreamHandler 路由处理器实现该接口时按路由配置读取请求体：Stream返回true时请求体以流式转发，路由匹配后不预先读取；
+// 否则请求体按MaxBodySize限制读取，超出限制时不再读取
+type IStreamHandler interface {
+	Stream() bool
+	MaxBodySize() int64
 }
 
 type Manager struct {
@@ -31,6 +40,7 @@ type Manager struct {
 
 	routersData   IRouterData
 	globalFilters atomic.Pointer[eoscContext.IChainPro]
+	http3Servers  *http3Servers
 }
 
 func (m *Manager) SetGlobalFilters(globalFilters *eoscContext.IChainPro) {
@@ -39,8 +49,15 @@ func (m *Manager) SetGlobalFilters(globalFilters *eoscContext.IChainPro) {
 
 // NewManager 创建路由管理器
 func NewManager() *Manager {
-	return &Manager{routersData: new(RouterData),
+	m := &Manager{routersData: new(RouterData),
 		IPreRouterData: newImlPreRouterData()}
+	m.http3Servers = newHttp3Servers(m.Http3Handler)
+	return m
+}
+
+// SetHttp3 设置路由是否在其端口上启用http3监听
+func (m *Manager) SetHttp3(id string, port int, enable bool) {
+	m.http3Servers.Set(id, port, enable)
 }
 
 func (m *Manager) Set(id string, port int, protocols []string, hosts []string, method []string, path string, append []AppendRule, router router.IRouterHandler) error {
@@ -61,6 +78,7 @@ func (m *Manager) Set(id string, port int, protocols []string, hosts []string, m
 }
 
 func (m *Manager) Delete(id string) {
+	m.http3Servers.Set(id, 0, false)
 	m.lock.Lock()
 	defer m.lock.Unlock()
 	routersData := m.routersData.Delete(id)
@@ -76,20 +94,22 @@ func (m *Manager) Delete(id string) {
 
 func (m *Manager) FastHandler(port int, ctx *fasthttp.RequestCtx) {
 	httpContext := http_context.NewContext(ctx, port)
-	if !m.IPreRouterData.Server(httpContext) {
-		return
+	var r router.IRouterHandler
+	has := false
+	if m.matcher != nil {
+		log.Debug("port is ", port, " request: ", httpContext.Request())
+		r, has = m.matcher.Match(port, httpContext.Request())
 	}
-	if m.matcher == nil {
-		httpContext.SetFinish(notFound)
-		httpContext.SetCompleteHandler(notFound)
-		globalFilters := m.globalFilters.Load()
-		if globalFilters != nil {
-			(*globalFilters).Chain(httpContext, completeCaller)
-		}
+	sh, ok := r.(IStreamHandler)
+	if !has || !ok {
+		httpContext.BufferBody(0)
+	} else if !sh.Stream() {
+		// 超出限制的错误由路由处理器设置转发配置时返回
+		httpContext.BufferBody(sh.MaxBodySize())
+	}
+	if !m.IPreRouterData.Server(httpContext) {
 		return
 	}
-	log.Debug("port is ", port, " request: ", httpContext.Request())
-	r, has := m.matcher.Match(port, httpContext.Request())
 	if !has {
 		httpContext.SetFinish(notFound)
 		httpContext.SetCompleteHandler(notFound)
@@ -0,0 +1,64 @@
+package manager
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	http_context "github.com/eolinker/apinto/node/http-context"
+	"github.com/eolinker/eosc/eocontext"
+	"github.com/valyala/fasthttp"
+)
+
+type countReader struct {
+	reader io.Reader
+	n      int
+}
+
+func (r *countReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.n += n
+	return n, err
+}
+
+type limitHandler struct {
+	maxBodySize int64
+	read        int
+	err         error
+	reader      *countReader
+}
+
+func (h *limitHandler) Stream() bool {
+	return false
+}
+
+func (h *limitHandler) MaxBodySize() int64 {
+	return h.maxBodySize
+}
+
+func (h *limitHandler) Serve(ctx eocontext.EoContext) {
+	h.read = h.reader.n
+	h.err = ctx.(*http_context.HttpContext).SetProxyOption(&http_context.ProxyOption{MaxBodySize: h.maxBodySize})
+}
+
+func TestFastHandlerMaxBodySize(t *testing.T) {
+	reader := &countReader{reader: strings.NewReader(strings.Repeat("a", 64*1024))}
+	handler := &limitHandler{maxBodySize: 1024, reader: reader}
+	m := NewManager()
+	if err := m.Set("upload", 8080, nil, nil, []string{fasthttp.MethodPost}, "/upload", nil, handler); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.Header.SetMethod(fasthttp.MethodPost)
+	ctx.Request.SetRequestURI("http://example.com/upload")
+	ctx.Request.SetBodyStream(reader, 64*1024)
+	m.FastHandler(8080, ctx)
+
+	if handler.err != fasthttp.ErrBodyTooLarge {
+		t.Fatalf("err %v, want %v", handler.err, fasthttp.ErrBodyTooLarge)
+	}
+	if handler.read != 0 || reader.n != 0 {
+		t.Errorf("oversized body read before rejected: %d bytes", reader.n)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	http_service "github.com/eolinker/apinto/node/http-context"
 	"github.com/eolinker/apinto/service"
 
 	"github.com/eolinker/apinto/drivers/router/http-router/websocket"
@@ -55,17 +56,36 @@ func (h *HttpRouter) reset(cfg *Config, workers map[eosc.RequireId]eosc.IWorker)
 
 	methods := cfg.Method
 
+	http3 := cfg.Http3 && !cfg.Websocket
+	var altSvc func(port int) string
+	if http3 {
+		altSvc = h.routerManager.AltSvc
+	}
 	handler := &httpHandler{
 		routerName:  h.name,
 		routerId:    h.id,
 		serviceName: strings.TrimSuffix(string(cfg.Service), "@service"),
-		finisher:    defaultFinisher,
+		finisher:    newFinisher(altSvc),
 		disable:     cfg.Disable,
 		websocket:   cfg.Websocket,
 		retry:       cfg.Retry,
 		labels:      cfg.Labels,
 		timeout:     time.Duration(cfg.TimeOut) * time.Millisecond,
 	}
+	if cfg.MaxBodySize > 0 || cfg.ReadTimeout > 0 || cfg.WriteTimeout > 0 || cfg.Stream || cfg.SSEHeartbeat > 0 || cfg.ChunkedPassthrough {
+		handler.proxyOption = &http_service.ProxyOption{
+			MaxBodySize:        cfg.MaxBodySize,
+			ReadTimeout:        time.Duration(cfg.ReadTimeout) * time.Millisecond,
+			WriteTimeout:       time.Duration(cfg.WriteTimeout) * time.Millisecond,
+			Stream:             cfg.Stream && cfg.Service != "",
+			Heartbeat:          time.Duration(cfg.SSEHeartbeat) * time.Millisecond,
+			ChunkedPassthrough: cfg.ChunkedPassthrough,
+		}
+		if handler.proxyOption.Stream {
+			// 流式转发的请求体无法重放，不进行重试
+			handler.retry = 0
+		}
+	}
 
 	if !cfg.Disable {
 
@@ -120,6 +140,7 @@ func (h *HttpRouter) reset(cfg *Config, workers map[eosc.RequireId]eosc.IWorker)
 	if err != nil {
 		return err
 	}
+	h.routerManager.SetHttp3(h.id, cfg.Listen, http3)
 	return nil
 }
 func (h *HttpRouter) Stop() error {
@@ -64,6 +64,10 @@ func (h *Complete) Complete(org eocontext.EoContext) error {
 		conn, resp, lastErr = DialWithTimeout(node, scheme, ctx.Proxy().URI().Path(), ctx.Proxy().URI().RawQuery(), ctx.Proxy().Header().Headers(), timeOut)
 		if lastErr == nil {
 			resp.Body.Close()
+			if subprotocol := conn.Subprotocol(); subprotocol != "" {
+				// 客户端声明的子协议透传给上游，以上游选择的子协议响应客户端
+				ctx.Response().SetHeader("Sec-WebSocket-Protocol", subprotocol)
+			}
 			ctx.SetUpstreamConn(&Conn{conn})
 			break
 		}
@@ -22,7 +22,6 @@ var skipHeaders = []string{
 	"Sec-Websocket-Key",
 	"Sec-Websocket-Version",
 	"Sec-Websocket-Extensions",
-	"Sec-Websocket-Protocol",
 }
 
 func DialWithTimeout(node eocontext.INode, scheme, path string, query string, header http.Header, timeout time.Duration) (*websocket.Conn, *http.Response, error) {
@@ -0,0 +1,35 @@
+package router
+
+import (
+	"github.com/valyala/fasthttp"
+)
+
+// HttpInterceptor 在http路由匹配前处理请求，返回true表示请求已被处理，不再交由http路由
+// 用于在http端口上承载其他协议的请求，如gRPC-Web
+type HttpInterceptor func(port int, ctx *fasthttp.RequestCtx) bool
+
+var httpInterceptors []*HttpInterceptor
+
+// RegisterHttpInterceptor 注册拦截器，返回的函数用于取消注册
+func RegisterHttpInterceptor(interceptor HttpInterceptor) func() {
+	p := &interceptor
+	httpInterceptors = append(httpInterceptors, p)
+	return func() {
+		for i, v := range httpInterceptors {
+			if v == p {
+				httpInterceptors = append(httpInterceptors[:i:i], httpInterceptors[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// InterceptHttp 依次执行已注册的拦截器，返回请求是否已被处理
+func InterceptHttp(port int, ctx *fasthttp.RequestCtx) bool {
+	for _, interceptor := range httpInterceptors {
+		if (*interceptor)(port, ctx) {
+			return true
+		}
+	}
+	return false
+}
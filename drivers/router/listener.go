@@ -13,6 +13,7 @@ import (
 	"github.com/eolinker/eosc/log"
 
 	"github.com/eolinker/apinto/certs"
+	thrift_context "github.com/eolinker/apinto/node/thrift-context"
 	"github.com/eolinker/eosc/common/bean"
 	"github.com/eolinker/eosc/config"
 	"github.com/eolinker/eosc/traffic"
@@ -26,6 +27,7 @@ const (
 	GRPC RouterType = iota
 	Http
 	Dubbo2
+	Thrift
 	TslTCP
 	AnyTCP
 	depth
@@ -48,12 +50,22 @@ func Register(tp RouterType, handler RouterServerHandler) error {
 
 type RouterServerHandler func(port int, listener net.Listener)
 
+// TLSPortHandler tls端口监听完成后回调，用于在相同端口上提供基于UDP的服务（如HTTP/3）
+type TLSPortHandler func(port int, tlsConfig *tls.Config)
+
+var tlsPortHandlers []TLSPortHandler
+
+func RegisterTLSPort(handler TLSPortHandler) {
+	tlsPortHandlers = append(tlsPortHandlers, handler)
+}
+
 func init() {
 	matchWriters[AnyTCP] = matchersToMatchWriters(cmux.Any())
 
 	matchWriters[TslTCP] = matchersToMatchWriters(cmux.TLS())
 	matchWriters[Http] = matchersToMatchWriters(cmux.HTTP1Fast(http.MethodPatch))
 	matchWriters[Dubbo2] = matchersToMatchWriters(cmux.PrefixMatcher(string([]byte{0xda, 0xbb})))
+	matchWriters[Thrift] = matchersToMatchWriters(thriftMatcher)
 	matchWriters[GRPC] = []cmux.MatchWriter{cmux.HTTP2MatchHeaderFieldPrefixSendSettings("content-type", "application/grpc")}
 	var tf traffic.ITraffic
 	var listenCfg *config.ListenUrl
@@ -79,11 +91,17 @@ func initListener(tf traffic.ITraffic, listenCfg *config.ListenUrl) {
 	}
 	if len(ssl) > 0 {
 		tlsConfig := &tls.Config{GetCertificate: certs.GetCertificateFunc()}
-
+		sslPorts := make(map[int]struct{})
 		for _, l := range ssl {
 			log.Debug("ssl listen: ", l.Addr().String())
 			port := readPort(l.Addr())
 			listenerByPort[port] = append(listenerByPort[port], tls.NewListener(l, tlsConfig))
+			sslPorts[port] = struct{}{}
+		}
+		for port := range sslPorts {
+			for _, handler := range tlsPortHandlers {
+				handler(port, tlsConfig)
+			}
 		}
 	}
 	for port, lns := range listenerByPort {
@@ -126,3 +144,22 @@ func matchersToMatchWriters(matchers ...cmux.Matcher) []cmux.MatchWriter {
 	}
 	return mws
 }
+
+// thriftMatcher 按消息头识别binary、compact协议的Thrift请求，兼容framed传输
+// 与cmux.PrefixMatcher一样逐步读取，已读取的字节不可能是Thrift请求时立即返回，不等待后续数据
+func thriftMatcher(r io.Reader) bool {
+	var header [thrift_context.HeaderSize]byte
+	last := 0
+	for last < len(header) {
+		n, err := r.Read(header[last:])
+		last += n
+		matched, more := thrift_context.MatchHeader(header[:last])
+		if matched || !more {
+			return matched
+		}
+		if err != nil {
+			return false
+		}
+	}
+	return false
+}
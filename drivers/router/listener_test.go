@@ -0,0 +1,57 @@
+package router
+
+import (
+	"errors"
+	"testing"
+)
+
+var errBlocked = errors.New("blocked")
+
+// chunkReader 每次读取返回一个字节，数据读完后模拟等待后续数据的客户端
+type chunkReader struct {
+	data    []byte
+	blocked bool
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		r.blocked = true
+		return 0, errBlocked
+	}
+	p[0] = r.data[0]
+	r.data = r.data[1:]
+	return 1, nil
+}
+
+func TestThriftMatcher(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    bool
+		blocked bool
+	}{
+		{name: "binary", data: []byte{0x80, 0x01, 0x00, 0x01, 0x00, 0x00}, want: true},
+		{name: "binary oneway", data: []byte{0x80, 0x01, 0x00, 0x04}, want: true},
+		{name: "binary reply", data: []byte{0x80, 0x01, 0x00, 0x02}},
+		{name: "compact", data: []byte{0x82, 0x21}, want: true},
+		{name: "compact reply", data: []byte{0x82, 0x41}},
+		{name: "framed binary", data: []byte{0x00, 0x00, 0x00, 0x20, 0x80, 0x01, 0x00, 0x01}, want: true},
+		{name: "framed compact", data: []byte{0x00, 0x00, 0x00, 0x20, 0x82, 0x21}, want: true},
+		{name: "framed empty", data: []byte{0x00, 0x00, 0x00, 0x00, 0x80, 0x01, 0x00, 0x01}},
+		{name: "frame too large", data: []byte{0x00, 0xff, 0xff, 0xff, 0x80, 0x01, 0x00, 0x01}},
+		{name: "binary payload", data: []byte{0x01, 0x02, 0x03, 0x04, 0x80, 0x01, 0x00, 0x01}},
+		{name: "short payload", data: []byte("hi")},
+		{name: "http", data: []byte("GET / HTTP/1.1\r\n")},
+		{name: "tls", data: []byte{0x16, 0x03, 0x01}},
+		{name: "partial header", data: []byte{0x00, 0x00, 0x00, 0x20, 0x80}, blocked: true},
+	}
+	for _, tt := range tests {
+		r := &chunkReader{data: tt.data}
+		if got := thriftMatcher(r); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+		if r.blocked != tt.blocked {
+			t.Errorf("%s: blocked %v, want %v", tt.name, r.blocked, tt.blocked)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package thrift_router
+
+import (
+	"errors"
+	"time"
+
+	"github.com/eolinker/apinto/drivers/router/thrift-router/manager"
+	"github.com/eolinker/apinto/entries/ctx_key"
+	thrift_context "github.com/eolinker/apinto/node/thrift-context"
+	"github.com/eolinker/apinto/router"
+	"github.com/eolinker/apinto/service"
+	"github.com/eolinker/eosc/eocontext"
+)
+
+var _ router.IRouterHandler = (*thriftHandler)(nil)
+
+type thriftHandler struct {
+	completeHandler eocontext.CompleteHandler
+	finishHandler   eocontext.FinishHandler
+	routerName      string
+	routerId        string
+	serviceName     string
+	disable         bool
+	service         service.IService
+	filters         eocontext.IChainPro
+	retry           int
+	timeout         time.Duration
+	labels          map[string]string
+}
+
+var completeCaller = manager.NewCompleteCaller()
+
+func (t *thriftHandler) Serve(ctx eocontext.EoContext) {
+
+	thriftCtx, err := thrift_context.Assert(ctx)
+	if err != nil {
+		return
+	}
+
+	if t.disable {
+		thriftCtx.Response().SetResponseError(errors.New("router disable"))
+		return
+	}
+	for key, value := range t.labels {
+		ctx.SetLabel(key, value)
+	}
+
+	//set retry timeout
+	ctx.WithValue(ctx_key.CtxKeyRetry, t.retry)
+	ctx.WithValue(ctx_key.CtxKeyTimeout, t.timeout)
+
+	//Set Label
+	request := thriftCtx.HeaderReader()
+	ctx.SetLabel("api", t.routerName)
+	ctx.SetLabel("api_id", t.routerId)
+	ctx.SetLabel("service", t.serviceName)
+	ctx.SetLabel("service_id", t.service.Id())
+	ctx.SetLabel("ip", request.RemoteIP())
+	ctx.SetLabel("thrift_service", request.Service())
+	ctx.SetLabel("thrift_method", request.Method())
+	ctx.SetLabel("thrift_protocol", request.Protocol())
+
+	ctx.SetCompleteHandler(t.completeHandler)
+	ctx.SetFinish(t.finishHandler)
+	ctx.SetBalance(t.service)
+	ctx.SetUpstreamHostHandler(t.service)
+
+	_ = t.filters.Chain(ctx, completeCaller)
+
+}
@@ -0,0 +1,7 @@
+package manager
+
+import (
+	"github.com/eolinker/apinto/router"
+)
+
+type AppendRule = router.AppendRule
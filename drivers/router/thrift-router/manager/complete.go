@@ -0,0 +1,83 @@
+package manager
+
+import (
+	"errors"
+	"time"
+
+	thrift_context "github.com/eolinker/apinto/node/thrift-context"
+	"github.com/eolinker/eosc/eocontext"
+	"github.com/eolinker/eosc/log"
+)
+
+var (
+	ErrorTimeoutComplete = errors.New("complete timeout")
+	errNodeIsNull        = errors.New("node is null")
+)
+
+type Complete struct {
+	retry   int
+	timeOut time.Duration
+}
+
+func NewComplete(retry int, timeOut time.Duration) *Complete {
+	return &Complete{retry: retry, timeOut: timeOut}
+}
+
+func (h *Complete) Complete(org eocontext.EoContext) error {
+	ctx, err := thrift_context.Assert(org)
+	if err != nil {
+		return err
+	}
+
+	//设置响应开始时间
+	proxyTime := time.Now()
+	defer func() {
+		ctx.Response().SetResponseTime(time.Since(proxyTime))
+	}()
+
+	balance := ctx.GetBalance()
+	var lastErr error
+
+	timeOut := balance.TimeOut()
+	if h.timeOut > 0 {
+		timeOut = h.timeOut
+	}
+	for index := 0; index <= h.retry; index++ {
+
+		if h.timeOut > 0 && time.Since(proxyTime) > h.timeOut {
+			ctx.Response().SetResponseError(ErrorTimeoutComplete)
+			return ErrorTimeoutComplete
+		}
+		node, _, err := balance.Select(ctx)
+		if err != nil {
+			log.Error("select error: ", err)
+			ctx.Response().SetResponseError(errNodeIsNull)
+			return err
+		}
+
+		lastErr = ctx.Invoke(node, timeOut)
+		if lastErr == nil {
+			return nil
+		}
+		log.Error("thrift upstream send error: ", lastErr)
+	}
+
+	ctx.Response().SetResponseError(lastErr)
+
+	return lastErr
+}
+
+type CompleteCaller struct {
+}
+
+func NewCompleteCaller() *CompleteCaller {
+	return &CompleteCaller{}
+}
+
+func (h *CompleteCaller) DoFilter(ctx eocontext.EoContext, next eocontext.IChain) (err error) {
+	return ctx.GetComplete().Complete(ctx)
+}
+
+func (h *CompleteCaller) Destroy() {
+
+}
@@ -0,0 +1,36 @@
+package manager
+
+import (
+	"net"
+
+	"github.com/eolinker/apinto/drivers/router"
+	"github.com/eolinker/apinto/plugin"
+	"github.com/eolinker/eosc/common/bean"
+	"github.com/eolinker/eosc/eocontext"
+	"github.com/eolinker/eosc/log"
+)
+
+var (
+	chainProxy eocontext.IChainPro
+	manager    = NewManager()
+)
+
+func init() {
+
+	serverHandler := func(port int, listener net.Listener) {
+		manager.Serve(port, listener)
+	}
+	router.Register(router.Thrift, serverHandler)
+
+	var pluginManager plugin.IPluginManager
+	bean.Autowired(&pluginManager)
+
+	var m IManger = manager
+	bean.Injection(&m)
+
+	bean.AddInitializingBeanFunc(func() {
+		log.Debug("init thrift router manager")
+		chainProxy = pluginManager.Global()
+		manager.SetGlobalFilters(&chainProxy)
+	})
+}
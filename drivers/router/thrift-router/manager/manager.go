@@ -0,0 +1,168 @@
+package manager
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	thrift_context "github.com/eolinker/apinto/node/thrift-context"
+	"github.com/eolinker/apinto/router"
+	eoscContext "github.com/eolinker/eosc/eocontext"
+	"github.com/eolinker/eosc/log"
+)
+
+var _ IManger = (*thriftManger)(nil)
+
+var completeCaller = NewCompleteCaller()
+
+var (
+	errNotFound = errors.New("not found")
+)
+
+type IManger interface {
+	Set(id string, port int, serviceName, methodName string, rule []AppendRule, handler router.IRouterHandler) error
+	Delete(id string)
+}
+
+func NewManager() *thriftManger {
+	return &thriftManger{
+		routersData: new(RouterData),
+	}
+}
+
+type thriftManger struct {
+	lock          sync.RWMutex
+	matcher       router.IMatcher
+	routersData   IRouterData
+	globalFilters atomic.Pointer[eoscContext.IChainPro]
+}
+
+func (t *thriftManger) SetGlobalFilters(globalFilters *eoscContext.IChainPro) {
+	t.globalFilters.Store(globalFilters)
+}
+
+func (t *thriftManger) Set(id string, port int, serviceName, methodName string, rule []AppendRule, handler router.IRouterHandler) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	routersData := t.routersData.Set(id, port, serviceName, methodName, rule, handler)
+	matchers, err := routersData.Parse()
+	if err != nil {
+		log.Error("parse router data error: ", err)
+		return err
+	}
+	t.matcher = matchers
+	t.routersData = routersData
+	return nil
+}
+
+func (t *thriftManger) Delete(id string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	routersData := t.routersData.Delete(id)
+	matchers, err := routersData.Parse()
+	if err != nil {
+		log.Errorf("delete router:%s %s", id, err.Error())
+		return
+	}
+
+	t.matcher = matchers
+	t.routersData = routersData
+}
+
+// Serve 接收端口上的Thrift连接
+func (t *thriftManger) Serve(port int, listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Error("thrift accept error: ", err)
+			continue
+		}
+		go t.serveConn(port, conn)
+	}
+}
+
+// serveConn 按顺序处理连接上的请求，协议及传输方式由连接上的第一个消息确定
+func (t *thriftManger) serveConn(port int, conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	codec, err := thrift_context.DetectCodec(reader)
+	if err != nil {
+		log.Debug("thrift detect codec error: ", err)
+		return
+	}
+	for {
+		msg, err := codec.ReadMessage(reader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				log.Error("thrift read message error: ", err)
+			}
+			return
+		}
+		reply := t.Handler(port, conn, codec, msg)
+		if reply == nil {
+			continue
+		}
+		if err = codec.WriteMessage(conn, reply); err != nil {
+			log.Error("thrift write message error: ", err)
+			return
+		}
+	}
+}
+
+func (t *thriftManger) Handler(port int, conn net.Conn, codec thrift_context.Codec, msg *thrift_context.Message) *thrift_context.Message {
+	ctx := thrift_context.NewContext(conn, port, codec, msg)
+	log.DebugF("thrift Handler port=%d name=%s seqid=%d", port, msg.Name, msg.SeqId)
+
+	t.lock.RLock()
+	matcher := t.matcher
+	t.lock.RUnlock()
+
+	var match router.IRouterHandler
+	has := false
+	if matcher != nil {
+		match, has = matcher.Match(port, ctx.HeaderReader())
+	}
+	if !has {
+		errHandler := NewErrHandler(errNotFound)
+		ctx.SetFinish(errHandler)
+		ctx.SetCompleteHandler(errHandler)
+		ctx.Response().SetResponseError(errNotFound)
+
+		globalFilters := t.globalFilters.Load()
+		if globalFilters != nil {
+			if err := (*globalFilters).Chain(ctx, completeCaller); err != nil {
+				ctx.Response().SetResponseError(err)
+			}
+		}
+	} else {
+		match.Serve(ctx)
+	}
+
+	var finishErr error
+	if finish := ctx.GetFinish(); finish != nil {
+		finishErr = finish.Finish(ctx)
+	}
+	return ctx.ReplyMessage(finishErr)
+}
+
+type ErrHandler struct {
+	err error
+}
+
+func NewErrHandler(err error) *ErrHandler {
+	return &ErrHandler{err: err}
+}
+
+func (e *ErrHandler) Complete(ctx eoscContext.EoContext) error {
+	return e.err
+}
+
+func (e *ErrHandler) Finish(ctx eoscContext.EoContext) error {
+	return e.err
+}
@@ -0,0 +1,110 @@
+package thrift_router
+
+import (
+	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/apinto/drivers/router/thrift-router/manager"
+	"github.com/eolinker/apinto/plugin"
+	"github.com/eolinker/apinto/service"
+	"github.com/eolinker/apinto/template"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/eocontext"
+	"strings"
+	"time"
+)
+
+type ThriftRouter struct {
+	id            string
+	name          string
+	manger        manager.IManger
+	pluginManager plugin.IPluginManager
+}
+
+func (h *ThriftRouter) Destroy() error {
+
+	h.manger.Delete(h.id)
+	return nil
+}
+
+func (h *ThriftRouter) Id() string {
+	return h.id
+}
+
+func (h *ThriftRouter) Start() error {
+	return nil
+}
+
+func (h *ThriftRouter) Reset(conf interface{}, workers map[eosc.RequireId]eosc.IWorker) error {
+	cfg, err := drivers.Assert[Config](conf)
+	if err != nil {
+		return err
+	}
+	return h.reset(cfg, workers)
+
+}
+
+func (h *ThriftRouter) reset(cfg *Config, workers map[eosc.RequireId]eosc.IWorker) error {
+
+	handler := &thriftHandler{
+		completeHandler: manager.NewComplete(cfg.Retry, time.Duration(cfg.TimeOut)*time.Millisecond),
+		finishHandler:   newFinishHandler(),
+		routerName:      h.name,
+		routerId:        h.id,
+		serviceName:     strings.TrimSuffix(string(cfg.Service), "@service"),
+		disable:         cfg.Disable,
+		filters:         nil,
+		retry:           cfg.Retry,
+		timeout:         time.Duration(cfg.TimeOut) * time.Millisecond,
+		labels:          cfg.Labels,
+	}
+
+	if !cfg.Disable {
+
+		serviceWorker, has := workers[cfg.Service]
+		if !has || !serviceWorker.CheckSkill(service.ServiceSkill) {
+			return eosc.ErrorNotGetSillForRequire
+		}
+
+		if cfg.Plugins == nil {
+			cfg.Plugins = map[string]*plugin.Config{}
+		}
+
+		var plugins eocontext.IChainPro
+		if cfg.Template != "" {
+			templateWorker, has := workers[cfg.Template]
+			if !has || !templateWorker.CheckSkill(template.TemplateSkill) {
+				return eosc.ErrorNotGetSillForRequire
+			}
+			tp := templateWorker.(template.ITemplate)
+			plugins = tp.Create(h.id, cfg.Plugins)
+		} else {
+			plugins = h.pluginManager.CreateRequest(h.id, cfg.Plugins)
+		}
+
+		serviceHandler := serviceWorker.(service.IService)
+
+		handler.service = serviceHandler
+		handler.filters = plugins
+	}
+
+	appendRule := make([]manager.AppendRule, 0, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		appendRule = append(appendRule, manager.AppendRule{
+			Type:    r.Type,
+			Name:    r.Name,
+			Pattern: r.Value,
+		})
+	}
+	err := h.manger.Set(h.id, cfg.Listen, cfg.ServiceName, cfg.MethodName, appendRule, handler)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+func (h *ThriftRouter) Stop() error {
+	h.Destroy()
+	return nil
+}
+
+func (h *ThriftRouter) CheckSkill(skill string) bool {
+	return false
+}
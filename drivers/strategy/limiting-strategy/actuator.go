@@ -4,6 +4,7 @@ import (
 	"sort"
 	"sync"
 
+	ai_provider "github.com/eolinker/apinto/drivers/ai-provider"
 	"github.com/eolinker/apinto/resources"
 	"github.com/eolinker/eosc/eocontext"
 )
@@ -84,11 +85,23 @@ func (a *tActuatorSet) Strategy(ctx eocontext.EoContext, next eocontext.IChain,
 			break
 		}
 	}
+	quota := newTokenQuota(ctx, handlers, scalars)
+	if quota != nil {
+		err := ai_provider.SetTokenQuota(ctx, quota)
+		if err != nil {
+			ctx.SetLabel("handler", "limiting")
+			return err
+		}
+	}
 
+	var err error
 	if next != nil {
-		return next.DoChain(ctx)
+		err = next.DoChain(ctx)
 	}
-	return nil
+	if quota != nil {
+		quota.settle(ctx)
+	}
+	return err
 }
 
 type handlerListSort []*LimitingHandler
@@ -114,6 +127,10 @@ type Scalars struct {
 	TrafficsSecond resources.Vector
 	TrafficsMinute resources.Vector
 	TrafficsHour   resources.Vector
+
+	TokenMinute resources.Vector
+	TokenHour   resources.Vector
+	TokenDay    resources.Vector
 }
 
 func DoStrategy(ctx eocontext.EoContext, next eocontext.IChain, scalars *Scalars) error {
@@ -11,10 +11,18 @@ type Threshold struct {
 	Hour   int64 `json:"hour" label:"每小时限制"`
 }
 
+// TokenThreshold AI请求的token用量限制
+type TokenThreshold struct {
+	Minute int64 `json:"minute" label:"每分钟限制"`
+	Hour   int64 `json:"hour" label:"每小时限制"`
+	Day    int64 `json:"day" label:"每天限制"`
+}
+
 type Rule struct {
 	Metrics  []string          `json:"metrics" label:"限流计数器名"`
 	Query    Threshold         `json:"query" label:"请求限制" description:"按请求次数"`
 	Traffic  Threshold         `json:"traffic" label:"流量限制" description:"按请求内容大小"`
+	Token    TokenThreshold    `json:"token" label:"Token限制" description:"按AI请求的token用量，请求前按提示词预估用量检查，响应后按实际用量校正；计数器可使用ai_provider、ai_model标签"`
 	Response response.Response `json:"response" label:"响应内容"`
 }
 
@@ -17,6 +17,7 @@ type LimitingHandler struct {
 	metrics  metrics.Metrics
 	query    ThresholdUint
 	traffic  ThresholdUint
+	token    TokenThreshold
 	response response.IResponse
 	priority int
 	stop     bool
@@ -60,6 +61,7 @@ func NewLimitingHandler(name string, conf *Config) (*LimitingHandler, error) {
 		metrics:  mts,
 		query:    parseThreshold(conf.Rule.Query),
 		traffic:  parseThreshold(conf.Rule.Traffic, 1024*1024),
+		token:    conf.Rule.Token,
 		response: response.Parse(&conf.Rule.Response),
 		priority: conf.Priority,
 		stop:     conf.Stop,
@@ -0,0 +1,112 @@
+package limiting_strategy
+
+import (
+	"strconv"
+
+	http_entry "github.com/eolinker/apinto/entries/http-entry"
+	node_http_context "github.com/eolinker/apinto/node/http-context"
+	"github.com/eolinker/apinto/resources"
+	"github.com/eolinker/eosc/eocontext"
+	http_service "github.com/eolinker/eosc/eocontext/http-context"
+	"github.com/eolinker/eosc/log"
+)
+
+type tokenReserved struct {
+	vector resources.Vector
+	key    string
+}
+
+// tokenQuota 单个AI请求的token配额，计数器在供应商及模型确定后计算，请求前预占预估用量，响应后按实际用量校正
+type tokenQuota struct {
+	handlers []*LimitingHandler
+	scalars  *Scalars
+	estimate int64
+	reserved []tokenReserved
+}
+
+// newTokenQuota 返回命中的token限制规则，没有命中时返回nil
+func newTokenQuota(ctx eocontext.EoContext, handlers []*LimitingHandler, scalars *Scalars) *tokenQuota {
+	if _, err := http_service.Assert(ctx); err != nil {
+		return nil
+	}
+	var matched []*LimitingHandler
+	for _, h := range handlers {
+		if h.token.Minute <= 0 && h.token.Hour <= 0 && h.token.Day <= 0 {
+			continue
+		}
+		if h.Filter().Check(ctx) {
+			matched = append(matched, h)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	return &tokenQuota{handlers: matched, scalars: scalars}
+}
+
+func (q *tokenQuota) Acquire(ctx eocontext.EoContext, estimate int64) error {
+	httpContext, err := http_service.Assert(ctx)
+	if err != nil {
+		return err
+	}
+	metricsAlready := newSet(len(q.handlers))
+	entry := http_entry.NewEntry(httpContext)
+	reserved := make([]tokenReserved, 0, len(q.handlers)*3)
+	for _, h := range q.handlers {
+		key := h.Metrics().Key()
+		if metricsAlready.Has(key) {
+			continue
+		}
+		metricsAlready.Add(key)
+		metricsValue := h.Metrics().Metrics(entry)
+		for _, t := range []struct {
+			vector    resources.Vector
+			period    string
+			threshold int64
+		}{
+			{q.scalars.TokenMinute, "minute", h.token.Minute},
+			{q.scalars.TokenHour, "hour", h.token.Hour},
+			{q.scalars.TokenDay, "day", h.token.Day},
+		} {
+			if t.threshold <= 0 || t.vector == nil {
+				continue
+			}
+			if t.vector.Get(metricsValue)+estimate > t.threshold {
+				setLimitingStrategyContent(httpContext, h.name, h.Response())
+				log.DebugF("refuse by limiting strategy %s of %s token.", h.name, t.period)
+				ctx.WithValue("is_block", true)
+				ctx.SetLabel("block_name", h.name)
+				return ErrorLimitingRefuse
+			}
+			reserved = append(reserved, tokenReserved{vector: t.vector, key: metricsValue})
+		}
+	}
+	// 全部通过后再预占，避免被拒绝的请求占用其他计数器
+	for _, r := range reserved {
+		r.vector.Add(r.key, estimate)
+	}
+	q.estimate = estimate
+	q.reserved = reserved
+	return nil
+}
+
+// settle 按ai_formatter写入的实际用量校正预占的用量，流式返回时在流结束后校正，未返回用量时退还预占
+func (q *tokenQuota) settle(ctx eocontext.EoContext) {
+	if len(q.reserved) == 0 {
+		return
+	}
+	fn := func() {
+		used, _ := strconv.ParseInt(ctx.GetLabel("ai_total_tokens"), 10, 64)
+		delta := used - q.estimate
+		if delta == 0 {
+			return
+		}
+		for _, r := range q.reserved {
+			r.vector.Add(r.key, delta)
+		}
+	}
+	if sr, ok := ctx.(node_http_context.IStreamResponse); ok && sr.OnStreamEnd(fn) {
+		return
+	}
+	fn()
+}
@@ -0,0 +1,147 @@
+package limiting_strategy
+
+import (
+	"errors"
+	"testing"
+
+	node_http_context "github.com/eolinker/apinto/node/http-context"
+	"github.com/valyala/fasthttp"
+)
+
+type testVector map[string]int64
+
+func (v testVector) Add(key string, delta int64) {
+	v[key] += delta
+}
+
+func (v testVector) CompareAndAdd(key string, threshold, delta int64) bool {
+	if v[key] > threshold {
+		return false
+	}
+	v[key] += delta
+	return true
+}
+
+func (v testVector) Get(key string) int64 {
+	return v[key]
+}
+
+func (v testVector) total() int64 {
+	var n int64
+	for _, value := range v {
+		n += value
+	}
+	return n
+}
+
+// streamContext 模拟响应体仍在流式转发的请求
+type streamContext struct {
+	*node_http_context.HttpContext
+	onEnd []func()
+}
+
+func (s *streamContext) OnStreamEnd(fn func()) bool {
+	s.onEnd = append(s.onEnd, fn)
+	return true
+}
+
+func newTokenHandler(t *testing.T, name string, metrics string, token TokenThreshold) *LimitingHandler {
+	h, err := NewLimitingHandler(name, &Config{Rule: Rule{Metrics: []string{metrics}, Token: token}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func newTokenContext() *node_http_context.HttpContext {
+	ctx := node_http_context.NewContext(new(fasthttp.RequestCtx), 0)
+	ctx.SetLabel("ai_provider", "openai")
+	ctx.SetLabel("ai_model", "gpt-4o")
+	return ctx
+}
+
+func TestTokenQuotaRejectBeforeReserve(t *testing.T) {
+	scalars := &Scalars{TokenMinute: testVector{}, TokenHour: testVector{}, TokenDay: testVector{}}
+	handlers := []*LimitingHandler{
+		newTokenHandler(t, "model", "{ai_model}", TokenThreshold{Minute: 100}),
+		newTokenHandler(t, "provider", "{ai_provider}", TokenThreshold{Hour: 50}),
+	}
+	ctx := newTokenContext()
+	q := newTokenQuota(ctx, handlers, scalars)
+	if q == nil {
+		t.Fatal("no token quota")
+	}
+	if err := q.Acquire(ctx, 60); !errors.Is(err, ErrorLimitingRefuse) {
+		t.Fatalf("acquire: %v", err)
+	}
+	if ctx.GetLabel("block_name") != "provider" {
+		t.Errorf("block name %q", ctx.GetLabel("block_name"))
+	}
+	for name, v := range map[string]testVector{"minute": scalars.TokenMinute.(testVector), "hour": scalars.TokenHour.(testVector)} {
+		if v.total() != 0 {
+			t.Errorf("%s counter reserved by refused request: %v", name, v)
+		}
+	}
+	q.settle(ctx)
+	if n := scalars.TokenMinute.(testVector).total(); n != 0 {
+		t.Errorf("settle after refuse: %d", n)
+	}
+
+	if err := q.Acquire(ctx, 40); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if m, h := scalars.TokenMinute.(testVector).total(), scalars.TokenHour.(testVector).total(); m != 40 || h != 40 {
+		t.Errorf("reserved minute %d hour %d, want 40", m, h)
+	}
+}
+
+func TestTokenQuotaSettle(t *testing.T) {
+	tests := []struct {
+		name  string
+		total string
+		want  int64
+	}{
+		{name: "more", total: "45", want: 45},
+		{name: "less", total: "12", want: 12},
+		{name: "no usage", want: 0},
+	}
+	for _, tt := range tests {
+		vector := testVector{}
+		handlers := []*LimitingHandler{newTokenHandler(t, "model", "{ai_model}", TokenThreshold{Day: 1000})}
+		ctx := newTokenContext()
+		q := newTokenQuota(ctx, handlers, &Scalars{TokenDay: vector})
+		if err := q.Acquire(ctx, 30); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if tt.total != "" {
+			ctx.SetLabel("ai_total_tokens", tt.total)
+		}
+		q.settle(ctx)
+		if n := vector.total(); n != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, n, tt.want)
+		}
+	}
+}
+
+func TestTokenQuotaSettleStream(t *testing.T) {
+	vector := testVector{}
+	handlers := []*LimitingHandler{newTokenHandler(t, "model", "{ai_model}", TokenThreshold{Minute: 1000})}
+	ctx := &streamContext{HttpContext: newTokenContext()}
+	q := newTokenQuota(ctx, handlers, &Scalars{TokenMinute: vector})
+	if err := q.Acquire(ctx, 30); err != nil {
+		t.Fatal(err)
+	}
+	q.settle(ctx)
+	if len(ctx.onEnd) != 1 {
+		t.Fatalf("settle registered %d stream callbacks", len(ctx.onEnd))
+	}
+	if n := vector.total(); n != 30 {
+		t.Errorf("settled before stream end: %d", n)
+	}
+	// 用量在流结束时由ai_formatter写入
+	ctx.SetLabel("ai_total_tokens", "80")
+	ctx.onEnd[0]()
+	if n := vector.total(); n != 80 {
+		t.Errorf("got %d, want 80", n)
+	}
+}
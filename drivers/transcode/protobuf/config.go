@@ -8,7 +8,12 @@ import (
 
 // Config protobuf驱动配置
 type Config struct {
-	ProtoFiles eosc.EoFiles `json:"proto_files" label:"proto文件列表"`
+	ProtoFiles     eosc.EoFiles   `json:"proto_files" label:"proto文件列表"`
+	DescriptorSets eosc.EoFiles   `json:"descriptor_sets" label:"描述文件集" description:"protoc --descriptor_set_out或buf build生成的FileDescriptorSet二进制文件"`
+	Reflect        bool           `json:"reflect" label:"服务反射" description:"通过目标服务的gRPC反射接口获取描述并缓存，与上传的文件共同使用，同名文件以反射结果为准"`
+	Service        eosc.RequireId `json:"service" label:"反射服务" required:"false" skill:"github.com/eolinker/apinto/service.service.IService" switch:"reflect === true"`
+	Authority      string         `json:"authority" label:"虚拟主机域名(Authority)" switch:"reflect === true"`
+	Refresh        int            `json:"refresh" label:"刷新间隔" description:"重新获取反射描述的间隔，单位：秒，0表示不刷新" default:"300" minimum:"0" switch:"reflect === true"`
 }
 
 func (c *Config) String() string {
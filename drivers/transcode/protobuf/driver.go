@@ -7,14 +7,12 @@ import (
 
 // Create 创建service_http驱动的实例
 func Create(id, name string, v *Config, workers map[eosc.RequireId]eosc.IWorker) (eosc.IWorker, error) {
-
-	source, err := parseFiles(v.ProtoFiles)
+	w := &Worker{
+		WorkerBase: drivers.Worker(id, name),
+	}
+	err := w.reset(v, workers)
 	if err != nil {
 		return nil, err
 	}
-
-	return &Worker{
-		WorkerBase: drivers.Worker(id, name),
-		source:     source,
-	}, nil
+	return w, nil
 }
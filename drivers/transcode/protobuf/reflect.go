@@ -0,0 +1,139 @@
+package protocbuf
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/eolinker/apinto/service"
+	"github.com/eolinker/eosc/eocontext"
+	"github.com/eolinker/eosc/log"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/grpcreflect"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+const (
+	reflectTimeout = 10 * time.Second
+	// reflectRetryInterval 首次获取失败时的重试间隔
+	reflectRetryInterval = 10 * time.Second
+)
+
+// reflector 通过服务端反射获取描述，并按刷新间隔定期更新
+type reflector struct {
+	service   service.IService
+	authority string
+	refresh   time.Duration
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+func newReflector(service service.IService, authority string, refresh time.Duration) *reflector {
+	return &reflector{service: service, authority: authority, refresh: refresh}
+}
+
+func (r *reflector) start(handler func([]*desc.FileDescriptor)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		loaded := false
+		for {
+			files, err := r.load(ctx)
+			if err != nil {
+				log.Error("protobuf reflect error: ", err)
+			} else {
+				handler(files)
+				loaded = true
+			}
+			interval := r.refresh
+			if !loaded {
+				interval = reflectRetryInterval
+			}
+			if loaded && interval <= 0 {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+}
+
+func (r *reflector) stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+}
+
+// load 依次尝试服务的各个节点，返回第一个成功获取的全部服务描述
+func (r *reflector) load(ctx context.Context) ([]*desc.FileDescriptor, error) {
+	nodes := r.service.Nodes()
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("service %s has no node", r.service.Id())
+	}
+	var lastErr error
+	for _, node := range nodes {
+		if node.Status() != eocontext.Running {
+			continue
+		}
+		files, err := r.loadNode(ctx, node.Addr())
+		if err == nil {
+			return files, nil
+		}
+		lastErr = fmt.Errorf("node %s: %w", node.Addr(), err)
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("service %s has no available node", r.service.Id())
+	}
+	return nil, lastErr
+}
+
+func (r *reflector) loadNode(ctx context.Context, addr string) ([]*desc.FileDescriptor, error) {
+	ctx, cancel := context.WithTimeout(ctx, reflectTimeout)
+	defer cancel()
+	opts := []grpc.DialOption{grpc.WithBlock()}
+	if r.service.Scheme() == "https" {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{})))
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+	if r.authority != "" {
+		opts = append(opts, grpc.WithAuthority(r.authority))
+	}
+	conn, err := grpc.DialContext(ctx, addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	client := grpcreflect.NewClientV1Alpha(ctx, reflectpb.NewServerReflectionClient(conn))
+	defer client.Reset()
+	services, err := client.ListServices()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]struct{})
+	files := make([]*desc.FileDescriptor, 0, len(services))
+	for _, name := range services {
+		sd, err := client.ResolveService(name)
+		if err != nil {
+			return nil, err
+		}
+		fd := sd.GetFile()
+		if _, has := names[fd.GetName()]; has {
+			continue
+		}
+		names[fd.GetName()] = struct{}{}
+		files = append(files, fd)
+	}
+	return files, nil
+}
@@ -0,0 +1,154 @@
+package protocbuf
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/apinto/service"
+	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/eocontext"
+	"github.com/jhump/protoreflect/desc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+type testNode struct {
+	eocontext.INode
+	addr string
+}
+
+func (n *testNode) Addr() string {
+	return n.addr
+}
+
+func (n *testNode) Status() eocontext.NodeStatus {
+	return eocontext.Running
+}
+
+type testService struct {
+	service.IService
+	nodes []eocontext.INode
+}
+
+func (s *testService) Id() string {
+	return "health@service"
+}
+
+func (s *testService) Nodes() []eocontext.INode {
+	return s.nodes
+}
+
+func (s *testService) Scheme() string {
+	return "http"
+}
+
+func (s *testService) CheckSkill(skill string) bool {
+	return service.CheckSkill(skill)
+}
+
+// startReflection 启动注册了健康检查及反射服务的上游
+func startReflection(t *testing.T) string {
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	reflection.Register(server)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(l)
+	t.Cleanup(server.Stop)
+	return l.Addr().String()
+}
+
+func TestReflectorLoad(t *testing.T) {
+	addr := startReflection(t)
+	svc := &testService{nodes: []eocontext.INode{&testNode{addr: addr}}}
+	files, err := newReflector(svc, "", 0).load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, fd := range files {
+		if fd.FindService("grpc.health.v1.Health") != nil {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("health service not reflected from %d files", len(files))
+	}
+
+	if _, err := newReflector(&testService{}, "", 0).load(context.Background()); err == nil {
+		t.Error("expect error for service without node")
+	}
+}
+
+func TestReflectorStart(t *testing.T) {
+	addr := startReflection(t)
+	r := newReflector(&testService{nodes: []eocontext.INode{&testNode{addr: addr}}}, "", 0)
+	loaded := make(chan []*desc.FileDescriptor, 1)
+	r.start(func(files []*desc.FileDescriptor) {
+		loaded <- files
+	})
+	select {
+	case files := <-loaded:
+		if len(files) == 0 {
+			t.Error("no file reflected")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("reflector did not load")
+	}
+	r.stop()
+}
+
+func TestWorkerSetReflected(t *testing.T) {
+	addr := startReflection(t)
+	svc := &testService{nodes: []eocontext.INode{&testNode{addr: addr}}}
+	files, err := newReflector(svc, "", 0).load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &Worker{WorkerBase: drivers.Worker("health@transcode", "health")}
+	if err := w.reset(&Config{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	r := newReflector(svc, "", 0)
+	w.reflector = r
+
+	w.setReflected(r, files)
+	source := w.Descriptor()
+	if _, err := source.FindSymbol("grpc.health.v1.Health"); err != nil {
+		t.Fatal(err)
+	}
+	// 描述未变更时不重建描述源
+	w.setReflected(r, append([]*desc.FileDescriptor(nil), files...))
+	if w.Descriptor() != source {
+		t.Error("descriptor source rebuilt for unchanged files")
+	}
+	// 已被替换的反射不能覆盖当前的描述源
+	w.setReflected(newReflector(svc, "", 0), files[:1])
+	if w.Descriptor() != source {
+		t.Error("stale reflector replaced the descriptor source")
+	}
+
+	// 重置后新的反射首次获取前，保留上次反射获取的描述
+	workers := map[eosc.RequireId]eosc.IWorker{"health@service": &testService{}}
+	if err := w.reset(&Config{Reflect: true, Service: "health@service"}, workers); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	if _, err := w.Descriptor().FindSymbol("grpc.health.v1.Health"); err != nil {
+		t.Errorf("reflected descriptor dropped on reset: %v", err)
+	}
+
+	if err := w.reset(&Config{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Descriptor().FindSymbol("grpc.health.v1.Health"); err == nil {
+		t.Error("reflected descriptor kept after reflect disabled")
+	}
+}
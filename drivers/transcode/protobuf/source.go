@@ -0,0 +1,71 @@
+package protocbuf
+
+import (
+	"errors"
+
+	"github.com/fullstorydev/grpcurl"
+	"github.com/jhump/protoreflect/desc"
+)
+
+// multiSource 依次从多个描述源中查找，服务列表及扩展取并集，同名符号以靠前的描述源为准。
+// 以指针使用，描述变更时生成新的实例，使用方可直接比较描述源是否变更
+type multiSource struct {
+	sources []grpcurl.DescriptorSource
+}
+
+func newMultiSource(sources ...grpcurl.DescriptorSource) *multiSource {
+	return &multiSource{sources: sources}
+}
+
+func (ms *multiSource) ListServices() ([]string, error) {
+	names := make(map[string]struct{})
+	services := make([]string, 0)
+	for _, s := range ms.sources {
+		list, err := s.ListServices()
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range list {
+			if _, has := names[name]; has {
+				continue
+			}
+			names[name] = struct{}{}
+			services = append(services, name)
+		}
+	}
+	return services, nil
+}
+
+func (ms *multiSource) FindSymbol(fullyQualifiedName string) (desc.Descriptor, error) {
+	var lastErr error
+	for _, s := range ms.sources {
+		d, err := s.FindSymbol(fullyQualifiedName)
+		if err == nil {
+			return d, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = errors.New("Symbol not found: " + fullyQualifiedName)
+	}
+	return nil, lastErr
+}
+
+func (ms *multiSource) AllExtensionsForType(typeName string) ([]*desc.FieldDescriptor, error) {
+	tags := make(map[int32]struct{})
+	exts := make([]*desc.FieldDescriptor, 0)
+	for _, s := range ms.sources {
+		list, err := s.AllExtensionsForType(typeName)
+		if err != nil {
+			continue
+		}
+		for _, ext := range list {
+			if _, has := tags[ext.GetNumber()]; has {
+				continue
+			}
+			tags[ext.GetNumber()] = struct{}{}
+			exts = append(exts, ext)
+		}
+	}
+	return exts, nil
+}
@@ -3,21 +3,41 @@ package protocbuf
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 
+	"github.com/jhump/protoreflect/desc"
 	"github.com/jhump/protoreflect/desc/protoparse"
+	// 注册google.api.http等注解，proto文件可直接引用google/api/annotations.proto
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
 
 	"github.com/eolinker/apinto/drivers"
 	grpc_descriptor "github.com/eolinker/apinto/grpc-descriptor"
+	"github.com/eolinker/apinto/service"
 	"github.com/eolinker/eosc"
+	"github.com/eolinker/eosc/log"
 	"github.com/fullstorydev/grpcurl"
 )
 
+var errorServiceRequired = errors.New("service is required when reflect is enabled")
+
 type Worker struct {
 	drivers.WorkerBase
-	source grpcurl.DescriptorSource
+	lock      sync.RWMutex
+	source    grpcurl.DescriptorSource
+	files     grpcurl.DescriptorSource
+	reflector *reflector
+	// reflected 最近一次反射获取的描述，按文件名排序，用于判断描述是否变更
+	reflected       []*desc.FileDescriptor
+	reflectedSource grpcurl.DescriptorSource
 }
 
 func (w *Worker) Descriptor() grpcurl.DescriptorSource {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
 	return w.source
 }
 
@@ -30,16 +50,118 @@ func (w *Worker) Reset(conf interface{}, workers map[eosc.RequireId]eosc.IWorker
 	if !ok {
 		return errors.New("illegal config type")
 	}
-	source, err := parseFiles(cfg.ProtoFiles)
+	return w.reset(cfg, workers)
+}
+
+func (w *Worker) reset(cfg *Config, workers map[eosc.RequireId]eosc.IWorker) error {
+	files, err := parseFiles(cfg.ProtoFiles)
+	if err != nil {
+		return err
+	}
+	sets, err := parseDescriptorSets(cfg.DescriptorSets)
+	if err != nil {
+		return err
+	}
+	fileSource, err := grpcurl.DescriptorSourceFromFileDescriptors(files...)
+	if err != nil {
+		return err
+	}
+	setSource, err := grpcurl.DescriptorSourceFromFileDescriptors(sets...)
 	if err != nil {
 		return err
 	}
-	w.source = source
+	static := newMultiSource(fileSource, setSource)
+
+	var r *reflector
+	if cfg.Reflect {
+		if cfg.Service == "" {
+			return errorServiceRequired
+		}
+		serviceWorker, has := workers[cfg.Service]
+		if !has || !serviceWorker.CheckSkill(service.ServiceSkill) {
+			return eosc.ErrorNotGetSillForRequire
+		}
+		r = newReflector(serviceWorker.(service.IService), cfg.Authority, time.Duration(cfg.Refresh)*time.Second)
+	}
+	// 先停止旧的反射，避免其进行中的更新覆盖新的描述源
+	w.lock.Lock()
+	old := w.reflector
+	w.reflector = nil
+	w.lock.Unlock()
+	if old != nil {
+		old.stop()
+	}
+
+	w.lock.Lock()
+	w.files = static
+	w.source = static
+	if r != nil && w.reflectedSource != nil {
+		// 新的反射首次获取成功前，继续使用上次反射获取的描述
+		w.source = newMultiSource(w.reflectedSource, static)
+	} else {
+		w.reflected = nil
+		w.reflectedSource = nil
+	}
+	w.reflector = r
+	w.lock.Unlock()
+	if r != nil {
+		r.start(func(files []*desc.FileDescriptor) {
+			w.setReflected(r, files)
+		})
+	}
 	return nil
 }
 
+// setReflected 反射获取的描述优先于上传的文件作为新的描述源，描述未变更时不重建
+func (w *Worker) setReflected(r *reflector, reflected []*desc.FileDescriptor) {
+	sort.Slice(reflected, func(i, j int) bool {
+		return reflected[i].GetName() < reflected[j].GetName()
+	})
+	w.lock.RLock()
+	current, unchanged := w.reflector == r, sameFiles(w.reflected, reflected)
+	w.lock.RUnlock()
+	if !current || unchanged {
+		return
+	}
+	source, err := grpcurl.DescriptorSourceFromFileDescriptors(reflected...)
+	if err != nil {
+		log.Errorf("protobuf transcode %s: build reflected descriptor error: %v", w.Id(), err)
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.reflector != r {
+		return
+	}
+	w.reflected = reflected
+	w.reflectedSource = source
+	w.source = newMultiSource(source, w.files)
+}
+
+// sameFiles 比较两组按文件名排序的描述内容是否一致
+func sameFiles(a, b []*desc.FileDescriptor) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].GetName() != b[i].GetName() || !proto.Equal(a[i].AsFileDescriptorProto(), b[i].AsFileDescriptorProto()) {
+			return false
+		}
+	}
+	return true
+}
+
 func (w *Worker) Stop() error {
+	w.lock.Lock()
+	r := w.reflector
+	w.reflector = nil
 	w.source = nil
+	w.reflected = nil
+	w.reflectedSource = nil
+	w.lock.Unlock()
+	if r != nil {
+		r.stop()
+	}
 	return nil
 }
 
@@ -47,7 +169,10 @@ func (w *Worker) CheckSkill(skill string) bool {
 	return grpc_descriptor.Skill == skill
 }
 
-func parseFiles(files eosc.EoFiles) (grpcurl.DescriptorSource, error) {
+func parseFiles(files eosc.EoFiles) ([]*desc.FileDescriptor, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
 	descSourceFiles := map[string]string{}
 	fileNames := make([]string, 0, len(files))
 	for _, f := range files {
@@ -59,11 +184,34 @@ func parseFiles(files eosc.EoFiles) (grpcurl.DescriptorSource, error) {
 		fileNames = append(fileNames, f.Name)
 
 	}
-	p := &protoparse.Parser{Accessor: protoparse.FileContentsFromMap(descSourceFiles)}
-	descSources, err := p.ParseFiles(fileNames...)
-	if err != nil {
-		return nil, err
+	p := &protoparse.Parser{
+		Accessor: protoparse.FileContentsFromMap(descSourceFiles),
+		// 未上传的依赖文件从已注册的描述中查找
+		LookupImport: desc.LoadFileDescriptor,
 	}
-	return grpcurl.DescriptorSourceFromFileDescriptors(descSources...)
+	return p.ParseFiles(fileNames...)
+}
 
+// parseDescriptorSets 解析FileDescriptorSet二进制文件，文件集需包含全部依赖
+func parseDescriptorSets(files eosc.EoFiles) ([]*desc.FileDescriptor, error) {
+	result := make([]*desc.FileDescriptor, 0)
+	for _, f := range files {
+		v, err := f.DecodeData()
+		if err != nil {
+			return nil, fmt.Errorf("file(%s) data decode error: %v", f.Name, err)
+		}
+		set := &descriptorpb.FileDescriptorSet{}
+		err = proto.Unmarshal(v, set)
+		if err != nil {
+			return nil, fmt.Errorf("file(%s) is not a FileDescriptorSet: %v", f.Name, err)
+		}
+		fds, err := desc.CreateFileDescriptorsFromSet(set)
+		if err != nil {
+			return nil, fmt.Errorf("file(%s) descriptor error: %v", f.Name, err)
+		}
+		for _, fd := range fds {
+			result = append(result, fd)
+		}
+	}
+	return result, nil
 }
@@ -0,0 +1,17 @@
+package thrift
+
+import (
+	"encoding/json"
+
+	"github.com/eolinker/eosc"
+)
+
+// Config thrift驱动配置
+type Config struct {
+	IDLFiles eosc.EoFiles `json:"idl_files" label:"IDL文件列表" description:"thrift IDL文件，include的文件需一并上传"`
+}
+
+func (c *Config) String() string {
+	data, _ := json.Marshal(c)
+	return string(data)
+}
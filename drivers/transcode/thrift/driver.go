@@ -0,0 +1,18 @@
+package thrift
+
+import (
+	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/eosc"
+)
+
+// Create 创建thrift编码器驱动的实例
+func Create(id, name string, v *Config, workers map[eosc.RequireId]eosc.IWorker) (eosc.IWorker, error) {
+	w := &Worker{
+		WorkerBase: drivers.Worker(id, name),
+	}
+	err := w.reset(v)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
@@ -0,0 +1,19 @@
+package thrift
+
+import (
+	"github.com/eolinker/apinto/drivers"
+	"github.com/eolinker/eosc"
+)
+
+var DriverName = "thrift_transcode"
+
+// Register 注册thrift驱动工厂
+func Register(register eosc.IExtenderDriverRegister) {
+	register.RegisterExtenderDriver(DriverName, NewFactory())
+}
+
+// NewFactory 创建thrift编码器驱动工厂
+func NewFactory() eosc.IExtenderDriverFactory {
+
+	return drivers.NewFactory[Config](Create)
+}
@@ -0,0 +1,71 @@
+package thrift
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/eolinker/apinto/drivers"
+	thrift_descriptor "github.com/eolinker/apinto/thrift-descriptor"
+	"github.com/eolinker/eosc"
+)
+
+var errorIDLRequired = errors.New("idl files is required")
+
+type Worker struct {
+	drivers.WorkerBase
+	lock       sync.RWMutex
+	descriptor *thrift_descriptor.Descriptor
+}
+
+func (w *Worker) Descriptor() *thrift_descriptor.Descriptor {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	return w.descriptor
+}
+
+func (w *Worker) Start() error {
+	return nil
+}
+
+func (w *Worker) Reset(conf interface{}, workers map[eosc.RequireId]eosc.IWorker) error {
+	cfg, ok := conf.(*Config)
+	if !ok {
+		return errors.New("illegal config type")
+	}
+	return w.reset(cfg)
+}
+
+func (w *Worker) reset(cfg *Config) error {
+	descriptor, err := parseFiles(cfg.IDLFiles)
+	if err != nil {
+		return err
+	}
+	w.lock.Lock()
+	w.descriptor = descriptor
+	w.lock.Unlock()
+	return nil
+}
+
+func (w *Worker) Stop() error {
+	return nil
+}
+
+func (w *Worker) CheckSkill(skill string) bool {
+	return thrift_descriptor.Skill == skill
+}
+
+func parseFiles(files eosc.EoFiles) (*thrift_descriptor.Descriptor, error) {
+	if len(files) == 0 {
+		return nil, errorIDLRequired
+	}
+	contents := make(map[string]string, len(files))
+	for _, f := range files {
+		v, err := f.DecodeData()
+		if err != nil {
+			return nil, fmt.Errorf("file(%s) data decode error: %v", f.Name, err)
+		}
+		contents[f.Name] = string(v)
+	}
+	return thrift_descriptor.NewDescriptor(contents)
+}
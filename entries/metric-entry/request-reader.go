@@ -1,6 +1,7 @@
 package metric_entry
 
 import (
+	"strconv"
 	"time"
 
 	http_context "github.com/eolinker/eosc/eocontext/http-context"
@@ -29,6 +30,21 @@ var reqColRead = map[string]reqCollectorReadFunc{
 		}
 		return float64(length - 1), true
 	},
+	"ai_prompt_tokens":     readLabelFloat("ai_prompt_tokens"),
+	"ai_completion_tokens": readLabelFloat("ai_completion_tokens"),
+	"ai_total_tokens":      readLabelFloat("ai_total_tokens"),
+	"ai_cost":              readLabelFloat("ai_cost"),
+}
+
+// readLabelFloat 读取由插件写入的数值标签，如ai_formatter写入的token用量及费用，标签不存在时不收集
+func readLabelFloat(label string) reqCollectorReadFunc {
+	return func(ctx http_context.IHttpContext) (float64, bool) {
+		value, err := strconv.ParseFloat(ctx.GetLabel(label), 64)
+		if err != nil {
+			return 0, false
+		}
+		return value, true
+	}
 }
 
 var reqLabelRead = map[string]reqLabelReadFunc{
@@ -0,0 +1,42 @@
+package metric_entry
+
+import (
+	"testing"
+
+	http_context "github.com/eolinker/apinto/node/http-context"
+	"github.com/valyala/fasthttp"
+)
+
+func TestReadLabelFloat(t *testing.T) {
+	ctx := http_context.NewContext(new(fasthttp.RequestCtx), 8080)
+	ctx.SetLabel("ai_prompt_tokens", "100")
+	ctx.SetLabel("ai_completion_tokens", "50")
+	ctx.SetLabel("ai_total_tokens", "150")
+	ctx.SetLabel("ai_cost", "0.0006")
+	entry, err := NewMetricEntry(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{
+		"ai_prompt_tokens":     100,
+		"ai_completion_tokens": 50,
+		"ai_total_tokens":      150,
+		"ai_cost":              0.0006,
+	}
+	for collector, value := range want {
+		got, has := entry.GetFloat(collector)
+		if !has || got != value {
+			t.Errorf("%s: got %v %v, want %v", collector, got, has, value)
+		}
+	}
+
+	// 未经ai_formatter处理的请求没有用量标签，不收集
+	ctx = http_context.NewContext(new(fasthttp.RequestCtx), 8080)
+	ctx.SetLabel("ai_cost", "-")
+	entry, _ = NewMetricEntry(ctx)
+	for collector := range want {
+		if _, has := entry.GetFloat(collector); has {
+			t.Errorf("%s collected without label", collector)
+		}
+	}
+}
@@ -92,6 +92,8 @@ func NewContext(req *invocation.RPCInvocation, port int) dubbo2_context.IDubbo2C
 	remoteIp := remoteAddr[:strings.Index(remoteAddr, ":")]
 
 	requestReader := NewRequestReader(serviceReader, localAddr, remoteIp, copyMaps)
+	// 原始请求保留参数列表的副本，供路由匹配参数值，插件修改转发参数时不影响原始请求
+	requestReader.body = NewDubboParamBody(append([]string(nil), typesList...), valuesList)
 
 	addr, _ := netip.ParseAddrPort(localAddr)
 
@@ -22,8 +22,38 @@ func ProxyTimeout(scheme string, host string, node eocontext.INode, req *fasthtt
 	return err
 }
 
+// ProxyIdleTimeout 转发请求，与上游连接的每次读写均以空闲超时重新计算截止时间；
+// resp.StreamBody为true时响应体不缓冲，由调用方负责读取并关闭
+func ProxyIdleTimeout(scheme string, host string, node eocontext.INode, req *fasthttp.Request, resp *fasthttp.Response, timeout time.Duration, idle IdleTimeout) error {
+	addr := fmt.Sprintf("%s://%s", scheme, node.Addr())
+	err := idleClient(idle).ProxyTimeout(addr, host, req, resp, timeout)
+	if err != nil {
+		node.Down()
+	}
+	return err
+}
+
 var defaultClient Client
 
+var (
+	idleClients     = make(map[IdleTimeout]*Client)
+	idleClientsLock sync.Mutex
+)
+
+func idleClient(idle IdleTimeout) *Client {
+	if idle.Read <= 0 && idle.Write <= 0 {
+		return &defaultClient
+	}
+	idleClientsLock.Lock()
+	defer idleClientsLock.Unlock()
+	c, has := idleClients[idle]
+	if !has {
+		c = &Client{idle: idle}
+		idleClients[idle] = c
+	}
+	return c
+}
+
 const (
 	DefaultMaxConns           = 10240
 	DefaultMaxConnWaitTimeout = time.Second * 60
@@ -41,6 +71,7 @@ type Client struct {
 	mLock sync.Mutex
 	m     map[string]*fasthttp.HostClient
 	ms    map[string]*fasthttp.HostClient
+	idle  IdleTimeout
 }
 
 func readAddress(addr string) (scheme, host string) {
@@ -93,6 +124,9 @@ func (c *Client) getHostClient(addr string, rewriteHost string) (*fasthttp.HostC
 				}
 			}
 		}
+		if c.idle.Read > 0 || c.idle.Write > 0 {
+			dial = idleDial(dial, c.idle)
+		}
 
 		hc = &fasthttp.HostClient{
 			Addr:  httpAddr,
@@ -0,0 +1,47 @@
+package fasthttp_client
+
+import (
+	"net"
+	"time"
+)
+
+// IdleTimeout 连接读写的空闲超时，每次读写前以当前时间重新计算截止时间
+type IdleTimeout struct {
+	Read  time.Duration
+	Write time.Duration
+}
+
+type idleConn struct {
+	net.Conn
+	idle IdleTimeout
+}
+
+func idleDial(dial func(addr string) (net.Conn, error), idle IdleTimeout) func(addr string) (net.Conn, error) {
+	return func(addr string) (net.Conn, error) {
+		conn, err := dial(addr)
+		if err != nil {
+			return nil, err
+		}
+		return &idleConn{Conn: conn, idle: idle}, nil
+	}
+}
+
+func (c *idleConn) Read(b []byte) (int, error) {
+	if c.idle.Read > 0 {
+		err := c.Conn.SetReadDeadline(time.Now().Add(c.idle.Read))
+		if err != nil {
+			return 0, err
+		}
+	}
+	return c.Conn.Read(b)
+}
+
+func (c *idleConn) Write(b []byte) (int, error) {
+	if c.idle.Write > 0 {
+		err := c.Conn.SetWriteDeadline(time.Now().Add(c.idle.Write))
+		if err != nil {
+			return 0, err
+		}
+	}
+	return c.Conn.Write(b)
+}
@@ -12,6 +12,11 @@ var (
 	clientPool IClient = NewClient()
 )
 
+// GetClientPool 获取目标地址的连接池，连接在请求间复用
+func GetClientPool(target string, isTls bool, host ...string) IClientPool {
+	return clientPool.Get(target, isTls, host...)
+}
+
 type IClient interface {
 	Get(target string, isTls bool, host ...string) IClientPool
 	Close()
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jhump/protoreflect/desc"
 
 	"google.golang.org/grpc/metadata"
 
@@ -57,6 +58,10 @@ type Context struct {
 	port                      int
 	finish                    bool
 	errChan                   chan error
+	messageHandlers           []MessageHandler
+	methodDescriptor          *desc.MethodDescriptor
+	requestMessages           int64
+	responseMessages          int64
 }
 
 func (c *Context) RealIP() string {
@@ -227,15 +232,16 @@ func (c *Context) doInvoke(address string, timeout time.Duration) error {
 	}
 
 	//c.proxy.Headers().Set("grpc-timeout", fmt.Sprintf("%d", timeout))
-	clientCtx, _ := context.WithCancel(metadata.NewOutgoingContext(c.Context(), c.proxy.Headers().Copy()))
+	clientCtx, clientCancel := context.WithCancel(metadata.NewOutgoingContext(c.Context(), c.proxy.Headers().Copy()))
 	serverHeaders := &metadata.MD{}
 	serverTrailers := &metadata.MD{}
 	clientStream, err := grpc.NewClientStream(clientCtx, clientStreamDescForProxying, clientConn, c.proxy.FullMethodName(), grpc.Header(serverHeaders), grpc.Trailer(serverTrailers))
 	if err != nil {
+		clientCancel()
 		return err
 	}
 	c.finish = true
-	go c.readError(c.serverStream, clientStream, serverHeaders, serverTrailers, c.response)
+	go c.readError(c.serverStream, clientStream, clientCancel, serverHeaders, serverTrailers, c.response)
 	return nil
 }
 
@@ -267,6 +273,10 @@ func (c *Context) reset() {
 	c.upstreamHostHandler = nil
 	c.finishHandler = nil
 	c.completeHandler = nil
+	c.messageHandlers = nil
+	c.methodDescriptor = nil
+	c.requestMessages = 0
+	c.responseMessages = 0
 
 	pool.Put(c)
 }
@@ -0,0 +1,158 @@
+package grpc_context
+
+import (
+	"sync/atomic"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// MessageDirection 流消息的转发方向
+type MessageDirection int
+
+const (
+	// RequestMessage 客户端发往上游的消息
+	RequestMessage MessageDirection = iota
+	// ResponseMessage 上游返回客户端的消息
+	ResponseMessage
+)
+
+func (d MessageDirection) String() string {
+	if d == ResponseMessage {
+		return "response"
+	}
+	return "request"
+}
+
+// IMessage 流中转发的单条消息
+type IMessage interface {
+	Direction() MessageDirection
+	// Index 该方向上的消息序号，从1开始
+	Index() int64
+	// Raw 消息的protobuf编码
+	Raw() []byte
+	// SetRaw 替换转发的消息内容，data不是合法的protobuf编码时返回错误
+	SetRaw(data []byte) error
+	// Message 使用方法描述解码消息，未设置方法描述时返回nil
+	Message() (*dynamic.Message, error)
+	// SetMessage 以修改后的消息替换转发的消息内容
+	SetMessage(msg *dynamic.Message) error
+}
+
+// MessageHandler 流消息处理函数，返回错误时终止流并将错误返回给客户端
+type MessageHandler func(ctx *Context, msg IMessage) error
+
+// IMessageContext gRPC上下文的流消息扩展，插件可通过该接口注册逐条消息的处理函数
+type IMessageContext interface {
+	// AddMessageHandler 注册流消息处理函数，需在转发前注册，按注册顺序执行
+	AddMessageHandler(handler MessageHandler)
+	// SetMethodDescriptor 设置当前方法的描述，用于解码流消息
+	SetMethodDescriptor(md *desc.MethodDescriptor)
+	// MessageCount 返回已转发的请求及响应消息数
+	MessageCount() (request int64, response int64)
+}
+
+var _ IMessageContext = (*Context)(nil)
+
+func (c *Context) AddMessageHandler(handler MessageHandler) {
+	c.messageHandlers = append(c.messageHandlers, handler)
+}
+
+func (c *Context) SetMethodDescriptor(md *desc.MethodDescriptor) {
+	c.methodDescriptor = md
+}
+
+func (c *Context) MessageCount() (int64, int64) {
+	return atomic.LoadInt64(&c.requestMessages), atomic.LoadInt64(&c.responseMessages)
+}
+
+// handleMessage 统计消息数并依次执行消息处理函数
+func (c *Context) handleMessage(direction MessageDirection, frame *emptypb.Empty) error {
+	counter := &c.requestMessages
+	if direction == ResponseMessage {
+		counter = &c.responseMessages
+	}
+	index := atomic.AddInt64(counter, 1)
+	if len(c.messageHandlers) == 0 {
+		return nil
+	}
+	msg := &message{direction: direction, index: index, frame: frame}
+	if c.methodDescriptor != nil {
+		msg.desc = c.methodDescriptor.GetInputType()
+		if direction == ResponseMessage {
+			msg.desc = c.methodDescriptor.GetOutputType()
+		}
+	}
+	for _, h := range c.messageHandlers {
+		err := h(c, msg)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type message struct {
+	direction MessageDirection
+	index     int64
+	frame     *emptypb.Empty
+	desc      *desc.MessageDescriptor
+	decoded   *dynamic.Message
+}
+
+func (m *message) Direction() MessageDirection {
+	return m.direction
+}
+
+func (m *message) Index() int64 {
+	return m.index
+}
+
+func (m *message) Raw() []byte {
+	// 转发的消息内容均保存为未知字段，编码结果即为原始消息
+	data, _ := proto.Marshal(m.frame)
+	return data
+}
+
+func (m *message) SetRaw(data []byte) error {
+	frame := &emptypb.Empty{}
+	err := proto.Unmarshal(data, frame)
+	if err != nil {
+		return err
+	}
+	m.decoded = nil
+	m.frame.Reset()
+	proto.Merge(m.frame, frame)
+	return nil
+}
+
+func (m *message) Message() (*dynamic.Message, error) {
+	if m.desc == nil {
+		return nil, nil
+	}
+	if m.decoded != nil {
+		return m.decoded, nil
+	}
+	msg := dynamic.NewMessage(m.desc)
+	err := msg.Unmarshal(m.Raw())
+	if err != nil {
+		return nil, err
+	}
+	m.decoded = msg
+	return msg, nil
+}
+
+func (m *message) SetMessage(msg *dynamic.Message) error {
+	data, err := msg.Marshal()
+	if err != nil {
+		return err
+	}
+	err = m.SetRaw(data)
+	if err != nil {
+		return err
+	}
+	m.decoded = msg
+	return nil
+}
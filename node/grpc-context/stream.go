@@ -1,7 +1,9 @@
 package grpc_context
 
 import (
+	"context"
 	"io"
+	"strconv"
 
 	"google.golang.org/protobuf/types/known/emptypb"
 
@@ -21,18 +23,45 @@ var (
 	}
 )
 
-func (c *Context) readError(serverStream grpc.ServerStream, clientStream grpc.ClientStream, serverHeaders *metadata.MD, trailers *metadata.MD, response grpc_context.IResponse) {
-	c.errChan <- handlerStream(serverStream, clientStream, serverHeaders, trailers, response)
+func (c *Context) readError(serverStream grpc.ServerStream, clientStream grpc.ClientStream, cancel context.CancelFunc, serverHeaders *metadata.MD, trailers *metadata.MD, response grpc_context.IResponse) {
+	err := c.handlerStream(serverStream, clientStream, cancel, serverHeaders, trailers, response)
+	requestMessages, responseMessages := c.MessageCount()
+	c.SetLabel("grpc_request_messages", strconv.FormatInt(requestMessages, 10))
+	c.SetLabel("grpc_response_messages", strconv.FormatInt(responseMessages, 10))
+	c.errChan <- err
 	close(c.errChan)
 }
 
-func handlerStream(serverStream grpc.ServerStream, clientStream grpc.ClientStream, serverHeaders *metadata.MD, trailers *metadata.MD, response grpc_context.IResponse) error {
+// messageError 流消息处理函数返回的错误
+type messageError struct {
+	err error
+}
+
+func (e *messageError) Error() string {
+	return e.err.Error()
+}
+
+// toStatusError 消息处理函数返回的错误若未携带gRPC状态，以Aborted状态返回给客户端
+func (e *messageError) toStatusError() error {
+	if _, ok := status.FromError(e.err); ok {
+		return e.err
+	}
+	return status.Error(codes.Aborted, e.err.Error())
+}
+
+func (c *Context) handlerStream(serverStream grpc.ServerStream, clientStream grpc.ClientStream, cancel context.CancelFunc, serverHeaders *metadata.MD, trailers *metadata.MD, response grpc_context.IResponse) error {
+	// 转发结束后释放上游流，上游仍在发送时使其中止
+	defer cancel()
 
 	// Explicitly *do not close* s2cErrChan and c2sErrChan, otherwise the select below will not terminate.
 	// Channels do not have to be closed, it is just a control flow mechanism, see
 	// https://groups.google.com/forum/#!msg/golang-nuts/pZwdYRGxCIk/qpbHxRRPJdUJ
-	s2cErrChan := forwardServerToClient(serverStream, clientStream)
-	c2sErrChan := forwardClientToServer(clientStream, serverStream)
+	s2cErrChan := forwardServerToClient(serverStream, clientStream, func(f *emptypb.Empty) error {
+		return c.handleMessage(RequestMessage, f)
+	})
+	c2sErrChan := forwardClientToServer(clientStream, serverStream, func(f *emptypb.Empty) error {
+		return c.handleMessage(ResponseMessage, f)
+	})
 	// We don't know which side is going to stop sending first, so we need a select between the two.
 	for i := 0; i < 2; i++ {
 		select {
@@ -41,10 +70,15 @@ func handlerStream(serverStream grpc.ServerStream, clientStream grpc.ClientStrea
 				// this is the happy case where the sender has encountered io.EOF, and won't be sending anymore./
 				// the clientStream>serverStream may continue pumping though.
 				clientStream.CloseSend()
+			} else if me, ok := s2cErr.(*messageError); ok {
+				// 请求消息被处理函数拒绝，取消上游流，不再等待上游响应
+				cancel()
+				return me.toStatusError()
 			} else {
 				// however, we may have gotten a receive error (stream disconnected, a read error etc) in which case we need
 				// to cancel the clientStream to the backend, let all of its goroutines be freed up by the CancelFunc and
 				// exit with an error to the stack
+				cancel()
 				return status.Errorf(codes.Internal, "failed proxying s2c: %v", s2cErr)
 			}
 		case c2sErr := <-c2sErrChan:
@@ -59,6 +93,10 @@ func handlerStream(serverStream grpc.ServerStream, clientStream grpc.ClientStrea
 			serverStream.SendHeader(metadata.Join(response.Headers(), *serverHeaders))
 			//}
 			serverStream.SetTrailer(metadata.Join(response.Trailer(), *trailers))
+			if me, ok := c2sErr.(*messageError); ok {
+				cancel()
+				return me.toStatusError()
+			}
 			// c2sErr will contain RPC error from client code. If not io.EOF return the RPC error as server stream error.
 			if c2sErr != io.EOF {
 				return c2sErr
@@ -69,7 +107,7 @@ func handlerStream(serverStream grpc.ServerStream, clientStream grpc.ClientStrea
 	return status.Errorf(codes.Internal, "gRPC proxying should never reach this stage.")
 }
 
-func forwardClientToServer(src grpc.ClientStream, dst grpc.ServerStream) chan error {
+func forwardClientToServer(src grpc.ClientStream, dst grpc.ServerStream, hook func(f *emptypb.Empty) error) chan error {
 	ret := make(chan error, 1)
 	go func() {
 
@@ -93,6 +131,10 @@ func forwardClientToServer(src grpc.ClientStream, dst grpc.ServerStream) chan er
 				ret <- err // this can be io.EOF which is happy case
 				break
 			}
+			if err := hook(f); err != nil {
+				ret <- &messageError{err: err}
+				break
+			}
 			if err := dst.SendMsg(f); err != nil {
 				ret <- err
 				break
@@ -102,7 +144,7 @@ func forwardClientToServer(src grpc.ClientStream, dst grpc.ServerStream) chan er
 	return ret
 }
 
-func forwardServerToClient(src grpc.ServerStream, dst grpc.ClientStream) chan error {
+func forwardServerToClient(src grpc.ServerStream, dst grpc.ClientStream, hook func(f *emptypb.Empty) error) chan error {
 	ret := make(chan error, 1)
 	go func() {
 		f := &emptypb.Empty{}
@@ -112,6 +154,10 @@ func forwardServerToClient(src grpc.ServerStream, dst grpc.ClientStream) chan er
 				ret <- err // this can be io.EOF which is happy case
 				break
 			}
+			if err := hook(f); err != nil {
+				ret <- &messageError{err: err}
+				break
+			}
 			if err := dst.SendMsg(f); err != nil {
 				ret <- err
 				break
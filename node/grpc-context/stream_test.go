@@ -0,0 +1,197 @@
+package grpc_context
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eolinker/eosc/eocontext"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const echoProto = `syntax = "proto3";
+package test;
+message Msg { string value = 1; }
+service Echo { rpc Chat(stream Msg) returns (stream Msg); }`
+
+type nodeHost struct{}
+
+func (nodeHost) PassHost() (eocontext.PassHostMod, string) {
+	return eocontext.NodeHost, ""
+}
+
+// startEcho 启动上游服务，逐条返回收到的消息，block为true时收完消息后等待流被取消
+func startEcho(t *testing.T, block bool) (string, chan error) {
+	done := make(chan error, 1)
+	server := grpc.NewServer(grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+		for {
+			msg := new(wrapperspb.StringValue)
+			err := stream.RecvMsg(msg)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				done <- err
+				return err
+			}
+			if err := stream.SendMsg(msg); err != nil {
+				done <- err
+				return err
+			}
+		}
+		if block {
+			<-stream.Context().Done()
+			done <- stream.Context().Err()
+			return nil
+		}
+		done <- nil
+		return nil
+	}))
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(l)
+	t.Cleanup(server.Stop)
+	return l.Addr().String(), done
+}
+
+func echoMethod(t *testing.T) *desc.MethodDescriptor {
+	parser := protoparse.Parser{Accessor: protoparse.FileContentsFromMap(map[string]string{"echo.proto": echoProto})}
+	fds, err := parser.ParseFiles("echo.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fds[0].FindService("test.Echo").FindMethodByName("Chat")
+}
+
+func newEchoContext(t *testing.T, values ...string) (*Context, *bufferCloser) {
+	var body []byte
+	for _, v := range values {
+		data, _ := proto.Marshal(wrapperspb.String(v))
+		body = append(body, webFrame(webFrameData, data)...)
+	}
+	writer := new(bufferCloser)
+	stream, err := NewWebStream(context.Background(), "/test.Echo/Chat", "", metadata.MD{}, nil, body, false, writer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := NewContext(nil, stream)
+	ctx.SetUpstreamHostHandler(nodeHost{})
+	return ctx, writer
+}
+
+// responseValues 解析写入客户端的数据帧
+func responseValues(t *testing.T, out []byte) []string {
+	var values []string
+	for len(out) >= webFrameHeader && out[0] == webFrameData {
+		size := int(binary.BigEndian.Uint32(out[1:]))
+		msg := new(wrapperspb.StringValue)
+		if err := proto.Unmarshal(out[webFrameHeader:webFrameHeader+size], msg); err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, msg.Value)
+		out = out[webFrameHeader+size:]
+	}
+	return values
+}
+
+func TestStreamMessageHandler(t *testing.T) {
+	addr, done := startEcho(t, false)
+	ctx, writer := newEchoContext(t, "a", "b", "c")
+	ctx.SetMethodDescriptor(echoMethod(t))
+	// 两个方向的处理函数在不同的goroutine中执行
+	var calls int64
+	ctx.AddMessageHandler(func(ctx *Context, msg IMessage) error {
+		atomic.AddInt64(&calls, 1)
+		m, err := msg.Message()
+		if err != nil {
+			return err
+		}
+		if msg.Direction() == RequestMessage && msg.Index() == 2 {
+			m.SetFieldByName("value", "B")
+			return msg.SetMessage(m)
+		}
+		return nil
+	})
+	ctx.AddMessageHandler(func(ctx *Context, msg IMessage) error {
+		if msg.Direction() == ResponseMessage && msg.Index() == 3 {
+			return msg.SetRaw([]byte{0xff})
+		}
+		return nil
+	})
+	if err := ctx.doInvoke(addr, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	err := ctx.FastFinish()
+	if status.Code(err) != codes.Aborted {
+		t.Fatalf("expect aborted by invalid raw message, got %v", err)
+	}
+	if got := responseValues(t, writer.Bytes()); strings.Join(got, ",") != "a,B" {
+		t.Errorf("responses %v", got)
+	}
+	if ctx.GetLabel("grpc_request_messages") != "3" || ctx.GetLabel("grpc_response_messages") != "3" {
+		t.Errorf("unexpected counters %v", ctx.Labels())
+	}
+	if calls != 6 {
+		t.Errorf("handler called %d times", calls)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("upstream: %v", err)
+	}
+}
+
+func TestStreamMessageHandlerCancel(t *testing.T) {
+	addr, done := startEcho(t, true)
+	ctx, _ := newEchoContext(t, "a", "b")
+	ctx.AddMessageHandler(func(ctx *Context, msg IMessage) error {
+		if msg.Direction() == RequestMessage && msg.Index() == 2 {
+			return errors.New("rejected")
+		}
+		return nil
+	})
+	if err := ctx.doInvoke(addr, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	err := ctx.FastFinish()
+	if status.Code(err) != codes.Aborted || !strings.Contains(err.Error(), "rejected") {
+		t.Fatalf("unexpected error %v", err)
+	}
+	select {
+	case err := <-done:
+		if status.Code(err) != codes.Canceled && !errors.Is(err, context.Canceled) {
+			t.Errorf("upstream stream ended with %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("upstream stream not cancelled after the handler rejected a message")
+	}
+}
+
+func TestMessageSetRaw(t *testing.T) {
+	data, _ := proto.Marshal(wrapperspb.String("a"))
+	msg := &message{frame: new(emptypb.Empty)}
+	if err := msg.SetRaw(data); err != nil || !bytes.Equal(msg.Raw(), data) {
+		t.Fatalf("SetRaw %v, raw %q", err, msg.Raw())
+	}
+	if err := msg.SetRaw([]byte{0xff}); err == nil {
+		t.Fatal("expect unmarshal error")
+	}
+	if !bytes.Equal(msg.Raw(), data) {
+		t.Errorf("invalid data replaced the message: %q", msg.Raw())
+	}
+}
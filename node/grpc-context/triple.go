@@ -0,0 +1,7 @@
+package grpc_context
+
+// Dubbo3 Triple协议兼容gRPC，服务版本、分组等信息以tri-前缀的头部传递
+const (
+	TripleServiceVersion = "tri-service-version"
+	TripleServiceGroup   = "tri-service-group"
+)
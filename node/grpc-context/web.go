@@ -0,0 +1,254 @@
+package grpc_context
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/proto"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	GrpcWebContentType     = "application/grpc-web"
+	GrpcWebTextContentType = "application/grpc-web-text"
+
+	webFrameData    byte = 0x00
+	webFrameTrailer byte = 0x80
+	webFrameHeader       = 5
+)
+
+var _ grpc.ServerStream = (*WebStream)(nil)
+
+// IsGrpcWeb 判断content-type是否为gRPC-Web请求，text表示body为base64编码
+func IsGrpcWeb(contentType string) (isWeb bool, text bool) {
+	if !strings.HasPrefix(contentType, GrpcWebContentType) {
+		return false, false
+	}
+	return true, strings.HasPrefix(contentType, GrpcWebTextContentType)
+}
+
+// WebStream 将gRPC-Web请求适配为grpc.ServerStream，请求帧从body中读取，响应帧写入writer，结束时写入trailer帧
+type WebStream struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	method  string
+	text    bool
+	body    *bytes.Reader
+	writer  io.WriteCloser
+	codec   encoding.Codec
+	lock    sync.Mutex
+	header  metadata.MD
+	trailer metadata.MD
+	ready   chan struct{}
+	sent    bool
+}
+
+// NewWebStream 创建gRPC-Web请求流，headers为请求头部（键需小写），body为请求原始body
+func NewWebStream(ctx context.Context, method string, authority string, headers metadata.MD, remoteAddr net.Addr, body []byte, text bool, writer io.WriteCloser) (*WebStream, error) {
+	if text {
+		decoded, err := decodeBase64(body)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid grpc-web-text body: %v", err)
+		}
+		body = decoded
+	}
+	md := headers.Copy()
+	md.Set(":authority", authority)
+	md.Set("content-type", "application/grpc")
+	ctx = metadata.NewIncomingContext(ctx, md)
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: remoteAddr})
+	ctx, cancel := context.WithCancel(ctx)
+	s := &WebStream{
+		cancel:  cancel,
+		method:  method,
+		text:    text,
+		body:    bytes.NewReader(body),
+		writer:  writer,
+		codec:   encoding.GetCodec(proto.Name),
+		header:  metadata.MD{},
+		trailer: metadata.MD{},
+		ready:   make(chan struct{}),
+	}
+	s.ctx = grpc.NewContextWithServerTransportStream(ctx, &webTransportStream{stream: s})
+	return s, nil
+}
+
+// Ready 响应头部确定后关闭，此时可读取Header并开始写出响应body
+func (s *WebStream) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// Header 返回响应头部，需在Ready之后读取
+func (s *WebStream) Header() metadata.MD {
+	return s.header
+}
+
+func (s *WebStream) SetHeader(md metadata.MD) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.sent {
+		return fmt.Errorf("grpc-web: header already sent")
+	}
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *WebStream) SendHeader(md metadata.MD) error {
+	err := s.SetHeader(md)
+	if err != nil {
+		return err
+	}
+	s.sendHeader()
+	return nil
+}
+
+func (s *WebStream) sendHeader() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.sent {
+		return
+	}
+	s.sent = true
+	close(s.ready)
+}
+
+func (s *WebStream) SetTrailer(md metadata.MD) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.trailer = metadata.Join(s.trailer, md)
+}
+
+func (s *WebStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *WebStream) SendMsg(m interface{}) error {
+	data, err := s.codec.Marshal(m)
+	if err != nil {
+		return err
+	}
+	s.sendHeader()
+	return s.writeFrame(webFrameData, data)
+}
+
+func (s *WebStream) RecvMsg(m interface{}) error {
+	header := make([]byte, webFrameHeader)
+	for {
+		_, err := io.ReadFull(s.body, header)
+		if err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return status.Error(codes.InvalidArgument, "grpc-web: incomplete frame")
+			}
+			return err
+		}
+		data := make([]byte, binary.BigEndian.Uint32(header[1:]))
+		_, err = io.ReadFull(s.body, data)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, "grpc-web: incomplete frame")
+		}
+		if header[0]&webFrameTrailer != 0 {
+			continue
+		}
+		if header[0]&0x01 != 0 {
+			return status.Error(codes.Unimplemented, "grpc-web: compressed message is not supported")
+		}
+		return s.codec.Unmarshal(data, m)
+	}
+}
+
+// Finish 写入包含grpc-status的trailer帧并关闭writer
+func (s *WebStream) Finish(err error) {
+	defer s.cancel()
+	s.sendHeader()
+	st, _ := status.FromError(err)
+	var buf bytes.Buffer
+	buf.WriteString("grpc-status: " + strconv.Itoa(int(st.Code())) + "\r\n")
+	if st.Message() != "" {
+		buf.WriteString("grpc-message: " + url.PathEscape(st.Message()) + "\r\n")
+	}
+	s.lock.Lock()
+	for k, vs := range s.trailer {
+		for _, v := range vs {
+			buf.WriteString(strings.ToLower(k) + ": " + v + "\r\n")
+		}
+	}
+	s.lock.Unlock()
+	s.writeFrame(webFrameTrailer, buf.Bytes())
+	s.writer.Close()
+}
+
+func (s *WebStream) writeFrame(flag byte, data []byte) error {
+	frame := make([]byte, webFrameHeader+len(data))
+	frame[0] = flag
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	copy(frame[webFrameHeader:], data)
+	if s.text {
+		encoded := make([]byte, base64.StdEncoding.EncodedLen(len(frame)))
+		base64.StdEncoding.Encode(encoded, frame)
+		frame = encoded
+	}
+	_, err := s.writer.Write(frame)
+	if err != nil {
+		// 客户端断开时取消上游调用
+		s.cancel()
+	}
+	return err
+}
+
+// decodeBase64 grpc-web-text的body可能由多段带填充的base64拼接而成，逐段解码
+func decodeBase64(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	result := make([]byte, 0, base64.StdEncoding.DecodedLen(len(data)))
+	for len(data) > 0 {
+		end := len(data)
+		if i := bytes.IndexByte(data, '='); i >= 0 {
+			end = i
+			for end < len(data) && data[end] == '=' {
+				end++
+			}
+		}
+		decoded, err := base64.StdEncoding.DecodeString(string(data[:end]))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, decoded...)
+		data = data[end:]
+	}
+	return result, nil
+}
+
+// webTransportStream 供grpc.MethodFromServerStream读取请求方法
+type webTransportStream struct {
+	stream *WebStream
+}
+
+func (t *webTransportStream) Method() string {
+	return t.stream.method
+}
+
+func (t *webTransportStream) SetHeader(md metadata.MD) error {
+	return t.stream.SetHeader(md)
+}
+
+func (t *webTransportStream) SendHeader(md metadata.MD) error {
+	return t.stream.SendHeader(md)
+}
+
+func (t *webTransportStream) SetTrailer(md metadata.MD) error {
+	t.stream.SetTrailer(md)
+	return nil
+}
@@ -0,0 +1,117 @@
+package grpc_context
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type bufferCloser struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *bufferCloser) Close() error {
+	b.closed = true
+	return nil
+}
+
+func webFrame(flag byte, data []byte) []byte {
+	frame := make([]byte, webFrameHeader+len(data))
+	frame[0] = flag
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	copy(frame[webFrameHeader:], data)
+	return frame
+}
+
+func TestDecodeBase64(t *testing.T) {
+	// grpc-web-text的body可能由多段带填充的base64拼接而成
+	body := base64.StdEncoding.EncodeToString([]byte("a")) + base64.StdEncoding.EncodeToString([]byte("bc")) + base64.StdEncoding.EncodeToString([]byte("def"))
+	data, err := decodeBase64([]byte(body + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "abcdef" {
+		t.Errorf("got %q", data)
+	}
+	if _, err := decodeBase64([]byte("!!!")); err == nil {
+		t.Error("expect invalid base64 error")
+	}
+}
+
+func TestWebStream(t *testing.T) {
+	request, _ := proto.Marshal(wrapperspb.String("ping"))
+	for _, text := range []bool{false, true} {
+		body := append(webFrame(webFrameTrailer, []byte("ignored")), webFrame(webFrameData, request)...)
+		if text {
+			body = []byte(base64.StdEncoding.EncodeToString(body))
+		}
+		writer := new(bufferCloser)
+		stream, err := NewWebStream(context.Background(), "/test.Echo/Ping", "example.com", metadata.MD{}, nil, body, text, writer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := new(wrapperspb.StringValue)
+		if err := stream.RecvMsg(msg); err != nil || msg.Value != "ping" {
+			t.Fatalf("text %v: recv %v, %v", text, msg, err)
+		}
+		if err := stream.RecvMsg(msg); err != io.EOF {
+			t.Errorf("text %v: expect EOF, got %v", text, err)
+		}
+		if err := stream.SendMsg(wrapperspb.String("pong")); err != nil {
+			t.Fatal(err)
+		}
+		stream.Finish(status.Error(codes.NotFound, "not found"))
+		if !writer.closed {
+			t.Error("writer is not closed")
+		}
+
+		out := writer.Bytes()
+		if text {
+			out, err = decodeBase64(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		response, _ := proto.Marshal(wrapperspb.String("pong"))
+		if !bytes.HasPrefix(out, webFrame(webFrameData, response)) {
+			t.Fatalf("text %v: unexpected data frame %q", text, out)
+		}
+		trailer := out[webFrameHeader+len(response):]
+		if trailer[0] != webFrameTrailer || int(binary.BigEndian.Uint32(trailer[1:])) != len(trailer)-webFrameHeader {
+			t.Fatalf("text %v: unexpected trailer frame %q", text, trailer)
+		}
+		if !strings.Contains(string(trailer), "grpc-status: 5\r\n") || !strings.Contains(string(trailer), "grpc-message: not%20found\r\n") {
+			t.Errorf("text %v: unexpected trailer %q", text, trailer)
+		}
+	}
+}
+
+func TestWebStreamInvalidFrame(t *testing.T) {
+	for body, code := range map[string]codes.Code{
+		string(webFrame(webFrameData, []byte("abc"))[:6]): codes.InvalidArgument,
+		string(webFrame(0x01, []byte("abc"))):             codes.Unimplemented,
+	} {
+		stream, err := NewWebStream(context.Background(), "/test.Echo/Ping", "", metadata.MD{}, nil, []byte(body), false, new(bufferCloser))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = stream.RecvMsg(new(wrapperspb.StringValue))
+		if status.Code(err) != code {
+			t.Errorf("got %v, want %v", err, code)
+		}
+	}
+	if _, err := NewWebStream(context.Background(), "/test.Echo/Ping", "", metadata.MD{}, nil, []byte("!!!"), true, new(bufferCloser)); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expect invalid argument, got %v", err)
+	}
+}
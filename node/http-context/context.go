@@ -16,8 +16,6 @@ import (
 
 	"github.com/eolinker/eosc/utils/config"
 
-	fasthttp_client "github.com/eolinker/apinto/node/fasthttp-client"
-
 	eoscContext "github.com/eolinker/eosc/eocontext"
 	http_service "github.com/eolinker/eosc/eocontext/http-context"
 	"github.com/google/uuid"
@@ -43,6 +41,15 @@ type HttpContext struct {
 	labels              map[string]string
 	port                int
 	entry               eosc.IEntry
+
+	proxyOption    *ProxyOption
+	streamRequest  *fasthttp.Request
+	responseStream *responseStream
+	bodyBuffered   bool
+	bodyErr        error
+	acceptTime     time.Time
+
+	websocketSession *websocketSession
 }
 
 func (ctx *HttpContext) RealIP() string {
@@ -150,7 +157,7 @@ func (ctx *HttpContext) SendTo(scheme string, node eoscContext.INode, timeout ti
 		//ctx.proxyRequest.Header().SetHost(targetHost)
 	}
 	beginTime := time.Now()
-	ctx.response.responseError = fasthttp_client.ProxyTimeout(scheme, rewriteHost, node, request, &ctx.fastHttpRequestCtx.Response, timeout)
+	ctx.response.responseError = ctx.proxy(scheme, rewriteHost, node, request, timeout)
 	var responseHeader fasthttp.ResponseHeader
 	if ctx.response.Response != nil {
 		responseHeader = ctx.response.Response.Header
@@ -173,7 +180,9 @@ func (ctx *HttpContext) SendTo(scheme string, node eoscContext.INode, timeout ti
 		ctx.response.remoteIP = ip
 		ctx.response.remotePort = port
 	}
-	agent.responseBody = string(ctx.response.Response.Body())
+	if ctx.responseStream == nil {
+		agent.responseBody = string(ctx.response.Response.Body())
+	}
 
 	agent.setResponseLength(ctx.fastHttpRequestCtx.Response.Header.ContentLength())
 
@@ -191,7 +200,7 @@ func (ctx *HttpContext) Context() context.Context {
 }
 
 func (ctx *HttpContext) AcceptTime() time.Time {
-	return ctx.fastHttpRequestCtx.Time()
+	return ctx.acceptTime
 }
 
 func (ctx *HttpContext) Value(key interface{}) interface{} {
@@ -256,12 +265,8 @@ func NewContext(ctx *fasthttp.RequestCtx, port int) *HttpContext {
 	httpContext.fastHttpRequestCtx = ctx
 	httpContext.requestID = uuid.New().String()
 
-	// 原始请求最大读取body为8k，使用clone request
+	// 请求体在路由匹配后通过BufferBody或SetProxyOption按需读取
 	request := fasthttp.AcquireRequest()
-
-	if ctx.Request.IsBodyStream() && ctx.Request.Header.ContentLength() > 8*1024 {
-		ctx.Request.Body()
-	}
 	ctx.Request.CopyTo(request)
 	httpContext.requestReader.reset(request, remoteAddr)
 
@@ -271,9 +276,16 @@ func NewContext(ctx *fasthttp.RequestCtx, port int) *HttpContext {
 	httpContext.response.reset(&ctx.Response)
 	httpContext.labels = make(map[string]string)
 	httpContext.port = port
+	httpContext.bodyBuffered = false
+	httpContext.bodyErr = nil
 	//记录请求时间
 	httpContext.ctx = context.Background()
-	httpContext.WithValue("request_time", ctx.Time())
+	// 非fasthttp server创建的RequestCtx（如http3转换的请求）未记录请求时间
+	httpContext.acceptTime = ctx.Time()
+	if httpContext.acceptTime.IsZero() {
+		httpContext.acceptTime = time.Now()
+	}
+	httpContext.WithValue("request_time", httpContext.acceptTime)
 
 	return httpContext
 
@@ -286,18 +298,26 @@ func (ctx *HttpContext) RequestId() string {
 
 // Finish finish
 func (ctx *HttpContext) FastFinish() {
+	ctx.finishStream()
 	if ctx.response.responseError != nil {
 		ctx.fastHttpRequestCtx.SetStatusCode(504)
 		ctx.fastHttpRequestCtx.SetBodyString(ctx.response.responseError.Error())
 		return
 	}
+	if ctx.finishResponse(ctx.release) {
+		return
+	}
+	ctx.release()
+}
 
+func (ctx *HttpContext) release() {
 	ctx.port = 0
 	ctx.ctx = nil
 	ctx.balance = nil
 	ctx.upstreamHostHandler = nil
 	ctx.finishHandler = nil
 	ctx.completeHandler = nil
+	ctx.websocketSession = nil
 	fasthttp.ReleaseRequest(ctx.requestReader.req)
 
 	ctx.requestReader.Finish()
@@ -305,7 +325,6 @@ func (ctx *HttpContext) FastFinish() {
 	ctx.response.Finish()
 	ctx.fastHttpRequestCtx = nil
 	pool.Put(ctx)
-
 }
 
 func parseAddr(addr string) (string, int) {
@@ -66,6 +66,14 @@ func (r *ProxyRequest) reset(request *fasthttp.Request, remoteAddr string) {
 //	}
 //}
 
+// swap 替换转发请求，保留已解析的客户端地址信息
+func (r *ProxyRequest) swap(request *fasthttp.Request) {
+	r.req = request
+	r.body.reset(request)
+	r.headers.reset(&request.Header)
+	r.uri.uri = request.URI()
+}
+
 func (r *ProxyRequest) SetMethod(s string) {
 	r.Request().Header.SetMethod(s)
 }
@@ -50,6 +50,8 @@ func (r *Response) Finish() error {
 	r.Response = nil
 	r.responseError = nil
 	r.proxyStatusCode = 0
+	r.length = 0
+	r.responseTime = 0
 	return nil
 }
 func (r *Response) reset(resp *fasthttp.Response) {
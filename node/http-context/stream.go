@@ -0,0 +1,487 @@
+package http_context
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	fasthttp_client "github.com/eolinker/apinto/node/fasthttp-client"
+	eoscContext "github.com/eolinker/eosc/eocontext"
+	"github.com/valyala/fasthttp"
+)
+
+// ProxyOption 路由级别的请求体限制、读写空闲超时及流式转发配置
+type ProxyOption struct {
+	// MaxBodySize 请求体最大长度，单位字节，0表示不限制
+	MaxBodySize int64
+	// ReadTimeout 读取客户端请求体及上游响应的空闲超时
+	ReadTimeout time.Duration
+	// WriteTimeout 写入上游请求及客户端响应的空闲超时
+	WriteTimeout time.Duration
+	// Stream 开启后请求体与响应体不缓冲，直接在客户端与上游之间转发
+	Stream bool
+	// Heartbeat SSE响应超过该时间未收到上游数据时向客户端发送注释行，0表示不发送
+	Heartbeat time.Duration
+	// ChunkedPassthrough 开启后未声明长度的chunked响应不缓冲，直接转发给客户端
+	ChunkedPassthrough bool
+}
+
+const (
+	// DefaultEventStreamIdleTimeout SSE请求未配置读空闲超时时使用的上游读空闲超时，避免长连接受转发超时限制
+	DefaultEventStreamIdleTimeout = 5 * time.Minute
+
+	eventStreamContentType = "text/event-stream"
+	// awsEventStreamContentType AWS事件流（如Bedrock的流式响应），与SSE一样需要逐块转发
+	awsEventStreamContentType = "application/vnd.amazon.eventstream"
+	// ndjsonContentType 按行分隔的JSON流（如Ollama的流式响应）
+	ndjsonContentType = "application/x-ndjson"
+)
+
+var heartbeatEvent = []byte(":\n\n")
+
+// IStreamResponse 响应体以流式转发时，允许在流结束后再执行日志等收尾逻辑
+type IStreamResponse interface {
+	// OnStreamEnd 响应体仍在流式转发时注册回调并返回true，回调在响应体写完后执行；否则返回false
+	OnStreamEnd(fn func()) bool
+}
+
+// IResponseStreamWrapper 响应体以流式转发时，允许插件逐块转换写回客户端的数据
+type IResponseStreamWrapper interface {
+	// WrapResponseStream 响应体仍在流式转发时使用wrap包装上游数据流并返回true，包装后响应体以chunked写回客户端；否则返回false
+	WrapResponseStream(wrap func(reader io.Reader) io.Reader) bool
+}
+
+// IResponseStreamSetter 允许插件以自身产生的数据作为流式响应体，流结束后执行OnStreamEnd注册的回调
+type IResponseStreamSetter interface {
+	// SetResponseStream 以reader作为响应体以chunked写回客户端，响应体写完或请求结束时关闭reader
+	SetResponseStream(reader io.ReadCloser, label string)
+}
+
+func (o *ProxyOption) idle() fasthttp_client.IdleTimeout {
+	if o == nil {
+		return fasthttp_client.IdleTimeout{}
+	}
+	return fasthttp_client.IdleTimeout{Read: o.ReadTimeout, Write: o.WriteTimeout}
+}
+
+// BufferBody 读取完整的请求体，未开启流式转发时在进入插件链前调用
+// maxBodySize大于0时，请求体超出限制返回fasthttp.ErrBodyTooLarge，Content-Length超出限制时不读取请求体，未声明长度时最多读取maxBodySize+1字节
+func (ctx *HttpContext) BufferBody(maxBodySize int64) error {
+	if !ctx.bodyBuffered {
+		ctx.bodyBuffered = true
+		ctx.bodyErr = ctx.readBody(maxBodySize)
+		if errors.Is(ctx.bodyErr, fasthttp.ErrBodyTooLarge) {
+			// 未读完的请求体不能作为下一个请求解析
+			ctx.fastHttpRequestCtx.SetConnectionClose()
+		}
+	}
+	if ctx.bodyErr != nil {
+		return ctx.bodyErr
+	}
+	if maxBodySize > 0 && int64(len(ctx.requestReader.req.Body())) > maxBodySize {
+		return fasthttp.ErrBodyTooLarge
+	}
+	return nil
+}
+
+func (ctx *HttpContext) readBody(maxBodySize int64) error {
+	request := &ctx.fastHttpRequestCtx.Request
+	if maxBodySize > 0 && int64(request.Header.ContentLength()) > maxBodySize {
+		return fasthttp.ErrBodyTooLarge
+	}
+	if !request.IsBodyStream() {
+		return nil
+	}
+	// 原始请求最大读取body为8k，超出部分需要从stream中读取
+	var reader io.Reader = request.BodyStream()
+	if maxBodySize > 0 {
+		reader = io.LimitReader(reader, maxBodySize+1)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	if maxBodySize > 0 && int64(len(body)) > maxBodySize {
+		return fasthttp.ErrBodyTooLarge
+	}
+	request.SetBodyRaw(body)
+	ctx.requestReader.req.SetBody(body)
+	return nil
+}
+
+// SetProxyOption 设置路由级别的转发配置，请求体超出限制时返回fasthttp.ErrBodyTooLarge
+func (ctx *HttpContext) SetProxyOption(option *ProxyOption) error {
+	ctx.proxyOption = option
+	if option == nil {
+		return ctx.BufferBody(0)
+	}
+	if !option.Stream {
+		return ctx.BufferBody(option.MaxBodySize)
+	}
+	if option.MaxBodySize > 0 && int64(ctx.fastHttpRequestCtx.Request.Header.ContentLength()) > option.MaxBodySize {
+		return fasthttp.ErrBodyTooLarge
+	}
+
+	request := &ctx.fastHttpRequestCtx.Request
+	if !request.IsBodyStream() {
+		return nil
+	}
+	// 替换转发请求的body stream，原始stream由fasthttp在请求结束后关闭
+	streamRequest := fasthttp.AcquireRequest()
+	request.Header.CopyTo(&streamRequest.Header)
+	streamRequest.SetBodyStream(&requestStream{
+		reader:      request.BodyStream(),
+		conn:        ctx.fastHttpRequestCtx.Conn(),
+		readTimeout: option.ReadTimeout,
+		maxBodySize: option.MaxBodySize,
+	}, request.Header.ContentLength())
+	ctx.proxyRequest.swap(streamRequest)
+	ctx.streamRequest = streamRequest
+	return nil
+}
+
+func (ctx *HttpContext) isStream() bool {
+	return ctx.proxyOption != nil && ctx.proxyOption.Stream
+}
+
+func isEventStream(contentType []byte) bool {
+	return bytes.Contains(contentType, []byte(eventStreamContentType))
+}
+
+// proxy 转发请求，开启流式转发、上游返回SSE等流式格式或开启直通的chunked响应时响应体不缓冲，其余响应读取完整响应体
+func (ctx *HttpContext) proxy(scheme string, host string, node eoscContext.INode, request *fasthttp.Request, timeout time.Duration) error {
+	if stream := ctx.responseStream; stream != nil {
+		// 重试时丢弃上次转发的响应流，释放上游连接
+		ctx.responseStream = nil
+		stream.discard()
+		ctx.fastHttpRequestCtx.Response.ResetBody()
+	}
+	idle := ctx.proxyOption.idle()
+	if ctx.isStream() {
+		// 流式转发仅受空闲超时限制
+		timeout = 0
+	} else if idle.Read == 0 && isEventStream(request.Header.Peek(fasthttp.HeaderAccept)) {
+		idle.Read = DefaultEventStreamIdleTimeout
+	}
+	begin := time.Now()
+	upstream := fasthttp.AcquireResponse()
+	upstream.StreamBody = true
+	err := fasthttp_client.ProxyIdleTimeout(scheme, host, node, request, upstream, timeout, idle)
+	if err != nil {
+		fasthttp.ReleaseResponse(upstream)
+		return err
+	}
+	response := &ctx.fastHttpRequestCtx.Response
+	upstream.Header.CopyTo(&response.Header)
+	reader := upstream.BodyStream()
+	if reader != nil && (ctx.isStream() || ctx.isPassthrough(upstream)) {
+		ctx.passthrough(upstream, begin)
+		return nil
+	}
+	defer fasthttp.ReleaseResponse(upstream)
+	if reader == nil {
+		// HEAD请求及204、304等无响应体的响应，fasthttp不设置body stream
+		response.SetBody(upstream.Body())
+		return nil
+	}
+	body, err := io.ReadAll(reader)
+	upstream.CloseBodyStream()
+	if err != nil {
+		return err
+	}
+	response.SetBodyRaw(body)
+	return nil
+}
+
+func (ctx *HttpContext) isPassthrough(upstream *fasthttp.Response) bool {
+	contentType := upstream.Header.ContentType()
+	if isEventStream(contentType) || bytes.Contains(contentType, []byte(awsEventStreamContentType)) || bytes.Contains(contentType, []byte(ndjsonContentType)) {
+		return true
+	}
+	return ctx.proxyOption != nil && ctx.proxyOption.ChunkedPassthrough && upstream.Header.ContentLength() == -1
+}
+
+// passthrough 上游响应体通过responseStream写回客户端，SSE响应按配置发送心跳
+func (ctx *HttpContext) passthrough(upstream *fasthttp.Response, begin time.Time) {
+	stream := &responseStream{
+		upstream: upstream,
+		reader:   upstream.BodyStream(),
+		conn:     ctx.fastHttpRequestCtx.Conn(),
+	}
+	// 流结束时将响应长度及响应时间更新为整个流的统计，供后续回调读取
+	stream.onEnd = append(stream.onEnd, func() {
+		ctx.response.length = int(stream.length)
+		ctx.response.responseTime = time.Since(begin)
+	})
+	label := "chunked"
+	if isEventStream(upstream.Header.ContentType()) {
+		label = "sse"
+		if ctx.proxyOption != nil && ctx.proxyOption.Heartbeat > 0 {
+			stream.heartbeat = newHeartbeatReader(stream.reader, ctx.proxyOption.Heartbeat, stream.closeUpstream)
+			stream.reader = stream.heartbeat
+		}
+	}
+	if ctx.isStream() {
+		label = "stream"
+	}
+	if ctx.proxyOption != nil {
+		stream.writeTimeout = ctx.proxyOption.WriteTimeout
+	}
+	ctx.SetLabel("response_stream", label)
+	ctx.responseStream = stream
+	ctx.fastHttpRequestCtx.Response.SetBodyStream(stream, upstream.Header.ContentLength())
+}
+
+// SetResponseStream 插件产生的响应流同样延迟释放上下文，流结束时更新响应长度及响应时间
+func (ctx *HttpContext) SetResponseStream(reader io.ReadCloser, label string) {
+	begin := time.Now()
+	stream := &responseStream{
+		reader: reader,
+		closer: reader,
+		conn:   ctx.fastHttpRequestCtx.Conn(),
+	}
+	stream.onEnd = append(stream.onEnd, func() {
+		ctx.response.length = int(stream.length)
+		ctx.response.responseTime = time.Since(begin)
+	})
+	if ctx.proxyOption != nil {
+		stream.writeTimeout = ctx.proxyOption.WriteTimeout
+	}
+	if ctx.responseStream != nil {
+		ctx.responseStream.discard()
+	}
+	ctx.SetLabel("response_stream", label)
+	ctx.responseStream = stream
+	ctx.fastHttpRequestCtx.Response.SetBodyStream(stream, -1)
+}
+
+// OnStreamEnd 响应体仍在流式转发时注册回调，回调执行时响应长度及响应时间已更新为整个流的统计
+func (ctx *HttpContext) OnStreamEnd(fn func()) bool {
+	if ctx.websocketSession != nil {
+		return ctx.websocketSession.onStreamEnd(fn)
+	}
+	if ctx.responseStream == nil || ctx.responseStream.closed {
+		return false
+	}
+	ctx.responseStream.onEnd = append(ctx.responseStream.onEnd, fn)
+	return true
+}
+
+// WrapResponseStream 包装上游数据流，响应长度统计的是包装后写回客户端的数据
+func (ctx *HttpContext) WrapResponseStream(wrap func(reader io.Reader) io.Reader) bool {
+	stream := ctx.responseStream
+	if stream == nil || stream.closed {
+		return false
+	}
+	stream.reader = wrap(stream.reader)
+	ctx.fastHttpRequestCtx.Response.Header.SetContentLength(-1)
+	return true
+}
+
+func (ctx *HttpContext) finishStream() {
+	if ctx.streamRequest != nil {
+		fasthttp.ReleaseRequest(ctx.streamRequest)
+		ctx.streamRequest = nil
+	}
+	if ctx.proxyOption != nil && ctx.proxyOption.ReadTimeout > 0 {
+		if conn := ctx.fastHttpRequestCtx.Conn(); conn != nil {
+			conn.SetReadDeadline(time.Time{})
+		}
+	}
+	ctx.proxyOption = nil
+	ctx.bodyBuffered = false
+	ctx.bodyErr = nil
+}
+
+// finishResponse 响应体仍在流式转发时将上下文的释放延迟到流结束，返回是否已延迟
+func (ctx *HttpContext) finishResponse(release func()) bool {
+	if session := ctx.websocketSession; session != nil {
+		// websocket连接关闭后再释放上下文
+		return session.deferRelease(release)
+	}
+	stream := ctx.responseStream
+	ctx.responseStream = nil
+	if stream == nil || stream.closed {
+		return false
+	}
+	stream.release = release
+	return true
+}
+
+// requestStream 客户端请求体，每次读取前刷新读空闲超时，并限制请求体长度
+type requestStream struct {
+	reader      io.Reader
+	conn        net.Conn
+	readTimeout time.Duration
+	maxBodySize int64
+	length      int64
+}
+
+func (r *requestStream) Read(p []byte) (int, error) {
+	if r.readTimeout > 0 && r.conn != nil {
+		r.conn.SetReadDeadline(time.Now().Add(r.readTimeout))
+	}
+	n, err := r.reader.Read(p)
+	r.length += int64(n)
+	if r.maxBodySize > 0 && r.length > r.maxBodySize {
+		return n, fasthttp.ErrBodyTooLarge
+	}
+	return n, err
+}
+
+// responseStream 上游响应体，每次读取前刷新客户端连接的写空闲超时，关闭时释放上游连接并执行流结束回调
+type responseStream struct {
+	upstream *fasthttp.Response
+	// closer 插件通过SetResponseStream设置的数据流，无上游响应
+	closer       io.Closer
+	reader       io.Reader
+	heartbeat    *heartbeatReader
+	conn         net.Conn
+	writeTimeout time.Duration
+	length       int64
+	closed       bool
+	onEnd        []func()
+	// release 请求处理结束后延迟到流结束时释放上下文
+	release func()
+}
+
+func (r *responseStream) Read(p []byte) (int, error) {
+	if r.writeTimeout > 0 && r.conn != nil {
+		r.conn.SetWriteDeadline(time.Now().Add(r.writeTimeout))
+	}
+	n, err := r.reader.Read(p)
+	r.length += int64(n)
+	return n, err
+}
+
+func (r *responseStream) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if r.writeTimeout > 0 && r.conn != nil {
+		r.conn.SetWriteDeadline(time.Time{})
+	}
+	var err error
+	if r.heartbeat != nil {
+		// 上游连接由心跳读取协程在退出时关闭
+		r.heartbeat.Close()
+	} else {
+		err = r.closeUpstream()
+	}
+	for _, fn := range r.onEnd {
+		fn()
+	}
+	r.onEnd = nil
+	if r.release != nil {
+		r.release()
+	}
+	return err
+}
+
+// discard 丢弃未写回客户端的响应流，不执行流结束回调
+func (r *responseStream) discard() {
+	r.onEnd = nil
+	r.release = nil
+	r.Close()
+}
+
+func (r *responseStream) closeUpstream() error {
+	if r.upstream == nil {
+		return r.closer.Close()
+	}
+	err := r.upstream.CloseBodyStream()
+	fasthttp.ReleaseResponse(r.upstream)
+	return err
+}
+
+// heartbeatReader 由独立协程读取上游数据，超过interval未收到数据时返回SSE注释行作为心跳
+type heartbeatReader struct {
+	interval time.Duration
+	data     chan []byte
+	done     chan struct{}
+	pending  []byte
+	// tail 已返回数据的末尾，仅在完整事件之后发送心跳
+	tail []byte
+	err  error
+}
+
+func newHeartbeatReader(reader io.Reader, interval time.Duration, closeUpstream func() error) *heartbeatReader {
+	h := &heartbeatReader{
+		interval: interval,
+		data:     make(chan []byte),
+		done:     make(chan struct{}),
+	}
+	go h.pump(reader, closeUpstream)
+	return h
+}
+
+func (h *heartbeatReader) pump(reader io.Reader, closeUpstream func() error) {
+	defer closeUpstream()
+	defer close(h.data)
+	for {
+		buf := make([]byte, 4096)
+		n, err := reader.Read(buf)
+		if n > 0 {
+			select {
+			case h.data <- buf[:n]:
+			case <-h.done:
+				return
+			}
+		}
+		if err != nil {
+			h.err = err
+			return
+		}
+	}
+}
+
+func (h *heartbeatReader) Read(p []byte) (int, error) {
+	if len(h.pending) > 0 {
+		n := copy(p, h.pending)
+		h.pending = h.pending[n:]
+		h.forward(p[:n])
+		return n, nil
+	}
+	timer := time.NewTimer(h.interval)
+	defer timer.Stop()
+	for {
+		select {
+		case data, ok := <-h.data:
+			if !ok {
+				if h.err != nil {
+					return 0, h.err
+				}
+				return 0, io.EOF
+			}
+			n := copy(p, data)
+			h.pending = data[n:]
+			h.forward(p[:n])
+			return n, nil
+		case <-timer.C:
+			if h.boundary() {
+				return copy(p, heartbeatEvent), nil
+			}
+			// 事件未写完时不插入心跳，继续等待上游数据
+			timer.Reset(h.interval)
+		}
+	}
+}
+
+func (h *heartbeatReader) forward(data []byte) {
+	h.tail = append(h.tail, data...)
+	if len(h.tail) > 4 {
+		h.tail = append(h.tail[:0], h.tail[len(h.tail)-4:]...)
+	}
+}
+
+// boundary 已返回的数据为空或以空行结束
+func (h *heartbeatReader) boundary() bool {
+	return len(h.tail) == 0 || bytes.HasSuffix(h.tail, []byte("\n\n")) || bytes.HasSuffix(h.tail, []byte("\r\n\r\n"))
+}
+
+func (h *heartbeatReader) Close() {
+	close(h.done)
+}
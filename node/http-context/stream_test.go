@@ -0,0 +1,309 @@
+package http_context
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	eoscContext "github.com/eolinker/eosc/eocontext"
+	"github.com/valyala/fasthttp"
+)
+
+type testNode struct {
+	addr string
+}
+
+func (n *testNode) GetAttrs() eoscContext.Attrs                 { return nil }
+func (n *testNode) GetAttrByName(name string) (string, bool)    { return "", false }
+func (n *testNode) ID() string                                  { return n.addr }
+func (n *testNode) IP() string                                  { return "127.0.0.1" }
+func (n *testNode) Port() int                                   { return 0 }
+func (n *testNode) Addr() string                                { return n.addr }
+func (n *testNode) Status() eoscContext.NodeStatus              { return eoscContext.Running }
+func (n *testNode) Up()                                         {}
+func (n *testNode) Down()                                       {}
+func (n *testNode) Leave()                                      {}
+func (n *testNode) PassHost() (eoscContext.PassHostMod, string) { return eoscContext.NodeHost, "" }
+
+func startServer(t *testing.T, handler fasthttp.RequestHandler) (string, func()) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fasthttp.Server{Handler: handler, StreamRequestBody: true, MaxRequestBodySize: 1024}
+	go server.Serve(ln)
+	return ln.Addr().String(), func() { server.Shutdown() }
+}
+
+func TestStreamProxy(t *testing.T) {
+	upstream, closeUpstream := startServer(t, func(ctx *fasthttp.RequestCtx) {
+		body, _ := io.ReadAll(ctx.RequestBodyStream())
+		ctx.SetBodyString("size:" + strconv.Itoa(len(body)))
+	})
+	defer closeUpstream()
+	node := &testNode{addr: upstream}
+
+	gateway, closeGateway := startServer(t, func(fast *fasthttp.RequestCtx) {
+		ctx := NewContext(fast, 0)
+		err := ctx.SetProxyOption(&ProxyOption{MaxBodySize: 64 * 1024, ReadTimeout: time.Second, WriteTimeout: time.Second, Stream: true})
+		if err != nil {
+			fast.SetStatusCode(fasthttp.StatusRequestEntityTooLarge)
+			ctx.FastFinish()
+			return
+		}
+		ctx.SetUpstreamHostHandler(node)
+		ctx.SendTo("http", node, time.Second)
+		ctx.FastFinish()
+	})
+	defer closeGateway()
+
+	tests := []struct {
+		size   int
+		status int
+		body   string
+	}{
+		{size: 100, status: fasthttp.StatusOK, body: "size:100"},
+		{size: 32 * 1024, status: fasthttp.StatusOK, body: "size:32768"},
+		{size: 128 * 1024, status: fasthttp.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		req := fasthttp.AcquireRequest()
+		resp := fasthttp.AcquireResponse()
+		req.SetRequestURI("http://" + gateway + "/upload")
+		req.Header.SetMethod(fasthttp.MethodPost)
+		req.SetBody(bytes.Repeat([]byte("a"), tt.size))
+		err := fasthttp.DoTimeout(req, resp, 5*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode() != tt.status {
+			t.Errorf("size %d: status %d, want %d", tt.size, resp.StatusCode(), tt.status)
+		}
+		if tt.body != "" && string(resp.Body()) != tt.body {
+			t.Errorf("size %d: body %s, want %s", tt.size, resp.Body(), tt.body)
+		}
+		fasthttp.ReleaseRequest(req)
+		fasthttp.ReleaseResponse(resp)
+	}
+}
+
+func TestEventStreamPassthrough(t *testing.T) {
+	upstream, closeUpstream := startServer(t, func(ctx *fasthttp.RequestCtx) {
+		ctx.SetContentType("text/event-stream")
+		ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+			for i := 0; i < 2; i++ {
+				w.WriteString("data: " + strconv.Itoa(i) + "\n\n")
+				w.Flush()
+				time.Sleep(150 * time.Millisecond)
+			}
+		})
+	})
+	defer closeUpstream()
+	node := &testNode{addr: upstream}
+
+	ended := make(chan int, 1)
+	gateway, closeGateway := startServer(t, func(fast *fasthttp.RequestCtx) {
+		ctx := NewContext(fast, 0)
+		ctx.SetProxyOption(&ProxyOption{Heartbeat: 50 * time.Millisecond})
+		ctx.SetUpstreamHostHandler(node)
+		ctx.SendTo("http", node, time.Second)
+		if !ctx.OnStreamEnd(func() { ended <- ctx.Response().ContentLength() }) {
+			t.Error("response is not streamed")
+		}
+		ctx.FastFinish()
+	})
+	defer closeGateway()
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+	req.SetRequestURI("http://" + gateway + "/events")
+	req.Header.Set(fasthttp.HeaderAccept, "text/event-stream")
+	err := fasthttp.DoTimeout(req, resp, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := string(resp.Body())
+	if !strings.Contains(body, "data: 0\n\n") || !strings.Contains(body, "data: 1\n\n") {
+		t.Errorf("missing events: %q", body)
+	}
+	if !strings.Contains(body, ":\n\n") {
+		t.Errorf("missing heartbeat: %q", body)
+	}
+	select {
+	case length := <-ended:
+		if length != len(body) {
+			t.Errorf("stream length %d, want %d", length, len(body))
+		}
+	case <-time.After(time.Second):
+		t.Error("stream end callback not called")
+	}
+}
+
+func TestNoBodyResponse(t *testing.T) {
+	upstream, closeUpstream := startServer(t, func(ctx *fasthttp.RequestCtx) {
+		if string(ctx.Path()) == "/empty" {
+			ctx.SetStatusCode(fasthttp.StatusNoContent)
+			return
+		}
+		ctx.SetBodyString("ok")
+	})
+	defer closeUpstream()
+	node := &testNode{addr: upstream}
+
+	for _, option := range []*ProxyOption{nil, {Stream: true}} {
+		gateway, closeGateway := startServer(t, func(fast *fasthttp.RequestCtx) {
+			ctx := NewContext(fast, 0)
+			ctx.SetProxyOption(option)
+			ctx.SetUpstreamHostHandler(node)
+			if err := ctx.SendTo("http", node, time.Second); err != nil {
+				t.Error(err)
+			}
+			ctx.FastFinish()
+		})
+		tests := []struct {
+			method string
+			path   string
+			status int
+		}{
+			{method: fasthttp.MethodHead, path: "/", status: fasthttp.StatusOK},
+			{method: fasthttp.MethodGet, path: "/empty", status: fasthttp.StatusNoContent},
+		}
+		for _, tt := range tests {
+			req := fasthttp.AcquireRequest()
+			resp := fasthttp.AcquireResponse()
+			req.SetRequestURI("http://" + gateway + tt.path)
+			req.Header.SetMethod(tt.method)
+			resp.SkipBody = tt.method == fasthttp.MethodHead
+			err := fasthttp.DoTimeout(req, resp, 5*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode() != tt.status || len(resp.Body()) != 0 {
+				t.Errorf("%s %s: status %d, body %q", tt.method, tt.path, resp.StatusCode(), resp.Body())
+			}
+			fasthttp.ReleaseRequest(req)
+			fasthttp.ReleaseResponse(resp)
+		}
+		closeGateway()
+	}
+}
+
+func TestProxyRetryDiscardsStream(t *testing.T) {
+	upstream, closeUpstream := startServer(t, func(ctx *fasthttp.RequestCtx) {
+		ctx.SetContentType("text/event-stream")
+		ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+			w.WriteString("data: " + string(ctx.Path()) + "\n\n")
+		})
+	})
+	defer closeUpstream()
+	node := &testNode{addr: upstream}
+
+	gateway, closeGateway := startServer(t, func(fast *fasthttp.RequestCtx) {
+		ctx := NewContext(fast, 0)
+		ctx.SetProxyOption(nil)
+		ctx.SetUpstreamHostHandler(node)
+		ctx.SendTo("http", node, time.Second)
+		first := ctx.responseStream
+		ctx.proxyRequest.URI().SetPath("/retry")
+		ctx.SendTo("http", node, time.Second)
+		if first == nil || !first.closed || ctx.responseStream == first {
+			t.Error("previous stream is not discarded")
+		}
+		ctx.FastFinish()
+	})
+	defer closeGateway()
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+	req.SetRequestURI("http://" + gateway + "/events")
+	err := fasthttp.DoTimeout(req, resp, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body()) != "data: /retry\n\n" {
+		t.Errorf("unexpected body: %q", resp.Body())
+	}
+}
+
+func TestHeartbeatEventBoundary(t *testing.T) {
+	reader, writer := io.Pipe()
+	h := newHeartbeatReader(reader, 20*time.Millisecond, func() error { return reader.Close() })
+	defer h.Close()
+	go func() {
+		writer.Write([]byte("data: a"))
+		time.Sleep(80 * time.Millisecond)
+		writer.Write([]byte("bc\n\n"))
+		time.Sleep(80 * time.Millisecond)
+		writer.Close()
+	}()
+	body, err := io.ReadAll(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(body), "data: abc\n\n:\n\n") {
+		t.Errorf("heartbeat inside event: %q", body)
+	}
+}
+
+// countReader 记录已读取的字节数
+type countReader struct {
+	reader io.Reader
+	n      int
+}
+
+func (r *countReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.n += n
+	return n, err
+}
+
+func TestMaxBodySize(t *testing.T) {
+	body := strings.Repeat("a", 64*1024)
+	tests := []struct {
+		name          string
+		contentLength int
+		maxBodySize   int64
+		wantErr       bool
+		maxRead       int
+	}{
+		{name: "oversized content length", contentLength: len(body), maxBodySize: 1024, wantErr: true, maxRead: 0},
+		{name: "oversized chunked", contentLength: -1, maxBodySize: 1024, wantErr: true, maxRead: 1025},
+		{name: "within limit", contentLength: len(body), maxBodySize: int64(len(body)), maxRead: len(body)},
+		{name: "no limit", contentLength: -1, maxRead: len(body)},
+	}
+	for _, tt := range tests {
+		reader := &countReader{reader: strings.NewReader(body)}
+		fast := new(fasthttp.RequestCtx)
+		fast.Request.Header.SetMethod(fasthttp.MethodPost)
+		fast.Request.SetBodyStream(reader, tt.contentLength)
+		ctx := NewContext(fast, 0)
+
+		err := ctx.SetProxyOption(&ProxyOption{MaxBodySize: tt.maxBodySize})
+		if tt.wantErr != (err == fasthttp.ErrBodyTooLarge) {
+			t.Errorf("%s: err %v", tt.name, err)
+		}
+		if reader.n > tt.maxRead {
+			t.Errorf("%s: read %d bytes, want at most %d", tt.name, reader.n, tt.maxRead)
+		}
+		if tt.wantErr {
+			if !fast.Response.ConnectionClose() {
+				t.Errorf("%s: connection not closed", tt.name)
+			}
+			continue
+		}
+		for name, b := range map[string]interface{ RawBody() ([]byte, error) }{"request": ctx.Request().Body(), "proxy": ctx.Proxy().Body()} {
+			if got, _ := b.RawBody(); string(got) != body {
+				t.Errorf("%s: %s body length %d", tt.name, name, len(got))
+			}
+		}
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"strconv"
 	"sync"
+	"time"
 
 	eoscContext "github.com/eolinker/eosc/eocontext"
 	"github.com/valyala/fasthttp"
@@ -23,7 +25,11 @@ var _ http_context.IWebsocketContext = (*WebsocketContext)(nil)
 
 type WebsocketContext struct {
 	*HttpContext
-	upstreamConn net.Conn
+	upstreamConn     net.Conn
+	messageHandlers  []WebsocketMessageHandler
+	maxMessageSize   int64
+	requestMessages  int64
+	responseMessages int64
 }
 
 var upgrader = websocket.FastHTTPUpgrader{
@@ -31,32 +37,53 @@ var upgrader = websocket.FastHTTPUpgrader{
 }
 
 func (w *WebsocketContext) Upgrade() error {
+	session := &websocketSession{}
 	err := upgrader.Upgrade(w.fastHttpRequestCtx, func(conn *websocket.Conn) {
+		defer session.end()
 		if w.upstreamConn == nil {
 			// 上游连接失败，直接返回
 			log.Error("fail to connect upstream")
 			return
 		}
-		defer conn.Close()
-		defer w.upstreamConn.Close()
-		wg := &sync.WaitGroup{}
-		wg.Add(2)
-		go func() {
-			size, err := io.Copy(conn.UnderlyingConn(), w.upstreamConn)
-			log.Infof("finish copy upstream: size is %d,err is %v", size, err)
-			wg.Done()
-		}()
-		go func() {
-			size, err := io.Copy(w.upstreamConn, conn.UnderlyingConn())
-			log.Infof("finish copy upstream: size is %d,err is %v", size, err)
-			wg.Done()
-		}()
-		wg.Wait()
+		start := time.Now()
+		if upstream, ok := w.upstreamConn.(messageConn); ok {
+			w.relayMessages(conn, upstream)
+		} else {
+			w.relay(conn)
+		}
+		requestMessages, responseMessages := w.MessageCount()
+		w.SetLabel("websocket_duration", strconv.FormatInt(time.Since(start).Milliseconds(), 10))
+		w.SetLabel("websocket_request_messages", strconv.FormatInt(requestMessages, 10))
+		w.SetLabel("websocket_response_messages", strconv.FormatInt(responseMessages, 10))
 	})
-
+	if err == nil {
+		if subprotocol := w.Subprotocol(); subprotocol != "" {
+			w.SetLabel("websocket_subprotocol", subprotocol)
+		}
+		w.websocketSession = session
+	}
 	return err
 }
 
+// relay 上游连接不支持按消息读写时直接转发字节流
+func (w *WebsocketContext) relay(conn *websocket.Conn) {
+	defer conn.Close()
+	defer w.upstreamConn.Close()
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		size, err := io.Copy(conn.UnderlyingConn(), w.upstreamConn)
+		log.Infof("finish copy upstream: size is %d,err is %v", size, err)
+		wg.Done()
+	}()
+	go func() {
+		size, err := io.Copy(w.upstreamConn, conn.UnderlyingConn())
+		log.Infof("finish copy upstream: size is %d,err is %v", size, err)
+		wg.Done()
+	}()
+	wg.Wait()
+}
+
 func (w *WebsocketContext) IsWebsocket() bool {
 	return websocket.FastHTTPIsWebSocketUpgrade(w.fastHttpRequestCtx)
 }
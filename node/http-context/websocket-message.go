@@ -0,0 +1,295 @@
+package http_context
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fasthttp/websocket"
+)
+
+// MessageDirection websocket消息的转发方向
+type MessageDirection int
+
+const (
+	// RequestMessage 客户端发往上游的消息
+	RequestMessage MessageDirection = iota
+	// ResponseMessage 上游返回客户端的消息
+	ResponseMessage
+)
+
+const websocketWriteWait = 10 * time.Second
+
+func (d MessageDirection) String() string {
+	if d == ResponseMessage {
+		return "response"
+	}
+	return "request"
+}
+
+// IWebsocketMessage 连接上转发的单条数据消息
+type IWebsocketMessage interface {
+	Direction() MessageDirection
+	// Index 该方向上的消息序号，从1开始
+	Index() int64
+	// Type 消息类型，websocket.TextMessage或websocket.BinaryMessage
+	Type() int
+	Data() []byte
+	// SetData 替换转发的消息内容
+	SetData(data []byte)
+	// Drop 丢弃该消息，不再转发
+	Drop()
+}
+
+// WebsocketMessageHandler 消息处理函数，返回错误时关闭连接，错误为*websocket.CloseError时使用其关闭码，否则使用1008
+type WebsocketMessageHandler func(ctx *WebsocketContext, msg IWebsocketMessage) error
+
+// IWebsocketMessageContext websocket上下文的消息扩展，插件可通过该接口注册逐条消息的处理函数
+type IWebsocketMessageContext interface {
+	// AddMessageHandler 注册消息处理函数，需在升级前注册，按注册顺序执行
+	AddMessageHandler(handler WebsocketMessageHandler)
+	// SetMaxMessageSize 设置单条消息的最大长度，超出时以1009关闭连接，0表示不限制
+	SetMaxMessageSize(size int64)
+	// MessageCount 返回已转发的请求及响应消息数
+	MessageCount() (request int64, response int64)
+	// Subprotocol 与上游协商的子协议
+	Subprotocol() string
+	// OnStreamEnd 连接升级后注册回调并返回true，回调在连接关闭后执行
+	OnStreamEnd(fn func()) bool
+}
+
+var _ IWebsocketMessageContext = (*WebsocketContext)(nil)
+
+func (w *WebsocketContext) AddMessageHandler(handler WebsocketMessageHandler) {
+	w.messageHandlers = append(w.messageHandlers, handler)
+}
+
+func (w *WebsocketContext) SetMaxMessageSize(size int64) {
+	if w.maxMessageSize == 0 || (size > 0 && size < w.maxMessageSize) {
+		w.maxMessageSize = size
+	}
+}
+
+func (w *WebsocketContext) MessageCount() (int64, int64) {
+	return atomic.LoadInt64(&w.requestMessages), atomic.LoadInt64(&w.responseMessages)
+}
+
+func (w *WebsocketContext) Subprotocol() string {
+	if conn, ok := w.upstreamConn.(messageConn); ok {
+		return conn.Subprotocol()
+	}
+	return ""
+}
+
+// messageConn 可按消息读写的上游连接
+type messageConn interface {
+	ReadMessage() (int, []byte, error)
+	WriteMessage(messageType int, data []byte) error
+	WriteControl(messageType int, data []byte, deadline time.Time) error
+	SetReadLimit(limit int64)
+	SetPingHandler(h func(appData string) error)
+	SetPongHandler(h func(appData string) error)
+	SetCloseHandler(h func(code int, text string) error)
+	Subprotocol() string
+	Close() error
+}
+
+// relayMessages 按消息在客户端与上游之间转发，控制帧直接转发给对端
+func (w *WebsocketContext) relayMessages(client *websocket.Conn, upstream messageConn) {
+	if w.maxMessageSize > 0 {
+		client.SetReadLimit(w.maxMessageSize)
+		upstream.SetReadLimit(w.maxMessageSize)
+	}
+	bindControl(client, upstream)
+	bindControl(upstream, client)
+
+	errChan := make(chan error, 2)
+	go func() {
+		errChan <- w.forward(RequestMessage, client, upstream)
+	}()
+	go func() {
+		errChan <- w.forward(ResponseMessage, upstream, client)
+	}()
+	err := <-errChan
+	closeMessage := closeMessageOf(err)
+	deadline := time.Now().Add(websocketWriteWait)
+	client.WriteControl(websocket.CloseMessage, closeMessage, deadline)
+	upstream.WriteControl(websocket.CloseMessage, closeMessage, deadline)
+	client.Close()
+	upstream.Close()
+	<-errChan
+}
+
+func (w *WebsocketContext) forward(direction MessageDirection, src, dst messageConn) error {
+	counter := &w.requestMessages
+	if direction == ResponseMessage {
+		counter = &w.responseMessages
+	}
+	for {
+		messageType, data, err := src.ReadMessage()
+		if err != nil {
+			return err
+		}
+		msg := &message{
+			direction:   direction,
+			index:       atomic.AddInt64(counter, 1),
+			messageType: messageType,
+			data:        data,
+		}
+		for _, h := range w.messageHandlers {
+			if err = h(w, msg); err != nil {
+				return &messageError{err: err}
+			}
+			if msg.dropped {
+				break
+			}
+		}
+		if msg.dropped {
+			continue
+		}
+		if err = dst.WriteMessage(messageType, msg.data); err != nil {
+			return err
+		}
+	}
+}
+
+// bindControl 将src收到的ping、pong及close帧转发给dst
+func bindControl(src, dst messageConn) {
+	src.SetPingHandler(func(appData string) error {
+		return ignoreClosed(dst.WriteControl(websocket.PingMessage, []byte(appData), time.Now().Add(websocketWriteWait)))
+	})
+	src.SetPongHandler(func(appData string) error {
+		return ignoreClosed(dst.WriteControl(websocket.PongMessage, []byte(appData), time.Now().Add(websocketWriteWait)))
+	})
+	src.SetCloseHandler(func(code int, text string) error {
+		// 关闭帧在连接关闭时统一发送
+		return nil
+	})
+}
+
+func ignoreClosed(err error) error {
+	if errors.Is(err, websocket.ErrCloseSent) {
+		return nil
+	}
+	return err
+}
+
+// messageError 消息处理函数返回的错误
+type messageError struct {
+	err error
+}
+
+func (e *messageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *messageError) Unwrap() error {
+	return e.err
+}
+
+// closeMessageOf 根据转发结束的原因生成发送给两端的关闭帧
+func closeMessageOf(err error) []byte {
+	var closeErr *websocket.CloseError
+	if errors.As(err, &closeErr) {
+		// 1005、1006、1015为保留关闭码，不能出现在关闭帧中
+		switch closeErr.Code {
+		case websocket.CloseNoStatusReceived:
+			return websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+		case websocket.CloseAbnormalClosure, websocket.CloseTLSHandshake:
+			return websocket.FormatCloseMessage(websocket.CloseGoingAway, "")
+		}
+		return websocket.FormatCloseMessage(closeErr.Code, closeText(closeErr.Text))
+	}
+	if errors.Is(err, websocket.ErrReadLimit) {
+		return websocket.FormatCloseMessage(websocket.CloseMessageTooBig, "message too big")
+	}
+	var msgErr *messageError
+	if errors.As(err, &msgErr) {
+		return websocket.FormatCloseMessage(websocket.ClosePolicyViolation, closeText(msgErr.Error()))
+	}
+	return websocket.FormatCloseMessage(websocket.CloseGoingAway, "")
+}
+
+// closeText 关闭帧的负载不能超过125字节，去掉关闭码后原因最长123字节
+func closeText(text string) string {
+	if len(text) <= 123 {
+		return text
+	}
+	return strings.ToValidUTF8(text[:123], "")
+}
+
+type message struct {
+	direction   MessageDirection
+	index       int64
+	messageType int
+	data        []byte
+	dropped     bool
+}
+
+func (m *message) Direction() MessageDirection {
+	return m.direction
+}
+
+func (m *message) Index() int64 {
+	return m.index
+}
+
+func (m *message) Type() int {
+	return m.messageType
+}
+
+func (m *message) Data() []byte {
+	return m.data
+}
+
+func (m *message) SetData(data []byte) {
+	m.data = data
+}
+
+func (m *message) Drop() {
+	m.dropped = true
+}
+
+// websocketSession 连接升级后，上下文的释放及收尾回调延迟到连接关闭后执行
+type websocketSession struct {
+	lock    sync.Mutex
+	ended   bool
+	onEnd   []func()
+	release func()
+}
+
+func (s *websocketSession) onStreamEnd(fn func()) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.ended {
+		return false
+	}
+	s.onEnd = append(s.onEnd, fn)
+	return true
+}
+
+// deferRelease 连接未关闭时将上下文的释放延迟到连接关闭，返回是否已延迟
+func (s *websocketSession) deferRelease(release func()) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.ended {
+		return false
+	}
+	s.release = release
+	return true
+}
+
+func (s *websocketSession) end() {
+	s.lock.Lock()
+	s.ended = true
+	onEnd, release := s.onEnd, s.release
+	s.lock.Unlock()
+	for _, fn := range onEnd {
+		fn()
+	}
+	if release != nil {
+		release()
+	}
+}
@@ -0,0 +1,120 @@
+package http_context
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+)
+
+type testUpstreamConn struct {
+	*websocket.Conn
+}
+
+func (c *testUpstreamConn) Read(b []byte) (int, error) {
+	return c.Conn.UnderlyingConn().Read(b)
+}
+
+func (c *testUpstreamConn) Write(b []byte) (int, error) {
+	return c.Conn.UnderlyingConn().Write(b)
+}
+
+func (c *testUpstreamConn) SetDeadline(t time.Time) error {
+	return c.Conn.UnderlyingConn().SetDeadline(t)
+}
+
+func wsURL(server *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestWebsocketRelayMessages(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	// 上游原样返回收到的消息
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(messageType, data)
+		}
+	}))
+	defer upstream.Close()
+
+	done := make(chan *WebsocketContext, 1)
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		up, _, err := websocket.DefaultDialer.Dial(wsURL(upstream), nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		client, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		ctx := &WebsocketContext{upstreamConn: &testUpstreamConn{up}}
+		ctx.SetMaxMessageSize(64)
+		ctx.AddMessageHandler(func(ctx *WebsocketContext, msg IWebsocketMessage) error {
+			if msg.Direction() != RequestMessage {
+				return nil
+			}
+			switch string(msg.Data()) {
+			case "skip":
+				msg.Drop()
+			case "bad":
+				return errors.New("bad message")
+			default:
+				msg.SetData(bytes.ToUpper(msg.Data()))
+			}
+			return nil
+		})
+		ctx.relayMessages(client, ctx.upstreamConn.(messageConn))
+		done <- ctx
+	}))
+	defer gateway.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL(gateway), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for _, m := range []string{"hello", "skip", "world"} {
+		if err = conn.WriteMessage(websocket.TextMessage, []byte(m)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, want := range []string{"HELLO", "WORLD"} {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("got %s, want %s", data, want)
+		}
+	}
+	conn.WriteMessage(websocket.TextMessage, []byte("bad"))
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+		t.Errorf("expect policy violation close, got %v", err)
+	}
+
+	select {
+	case ctx := <-done:
+		request, response := ctx.MessageCount()
+		if request != 4 || response != 2 {
+			t.Errorf("message count: request=%d response=%d", request, response)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("relay not finished")
+	}
+}
@@ -0,0 +1,129 @@
+package thrift_context
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+)
+
+var (
+	defaultDialTimeout  = 5 * time.Second
+	defaultMaxIdleConns = 16
+
+	defaultPool = newConnPool(defaultMaxIdleConns)
+)
+
+// Call 将消息发送到addr并读取响应，oneway消息不等待响应
+func Call(ctx context.Context, addr string, timeout time.Duration, codec Codec, msg *Message) (*Message, error) {
+	// 服务端按连接上的第一个消息确定协议，不同协议的请求不共用连接
+	key := addr + "/" + codec.Protocol + "/" + codec.Transport
+	conn, reused, err := defaultPool.get(key, addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := call(ctx, conn, timeout, codec, msg)
+	if err != nil && reused && isClosed(err) {
+		// 空闲连接可能已被服务端关闭，重新建立连接后重试一次
+		conn.Close()
+		conn, err = defaultPool.dial(addr, timeout)
+		if err != nil {
+			return nil, err
+		}
+		reply, err = call(ctx, conn, timeout, codec, msg)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	defaultPool.put(key, conn)
+	return reply, nil
+}
+
+func call(ctx context.Context, conn *clientConn, timeout time.Duration, codec Codec, msg *Message) (*Message, error) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	if d, ok := ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+	defer conn.SetDeadline(time.Time{})
+
+	if err := codec.WriteMessage(conn, msg); err != nil {
+		return nil, err
+	}
+	if msg.Type == thrift.ONEWAY {
+		return nil, nil
+	}
+	reply, err := codec.ReadMessage(conn.reader)
+	if err != nil {
+		return nil, err
+	}
+	if reply.SeqId != msg.SeqId {
+		return nil, fmt.Errorf("thrift reply seqid %d mismatch request %d", reply.SeqId, msg.SeqId)
+	}
+	return reply, nil
+}
+
+func isClosed(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed)
+}
+
+type clientConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// connPool 按地址及协议缓存空闲连接
+type connPool struct {
+	lock    sync.Mutex
+	maxIdle int
+	idle    map[string][]*clientConn
+}
+
+func newConnPool(maxIdle int) *connPool {
+	return &connPool{maxIdle: maxIdle, idle: make(map[string][]*clientConn)}
+}
+
+func (p *connPool) get(key string, addr string, timeout time.Duration) (*clientConn, bool, error) {
+	p.lock.Lock()
+	conns := p.idle[key]
+	if n := len(conns); n > 0 {
+		conn := conns[n-1]
+		p.idle[key] = conns[:n-1]
+		p.lock.Unlock()
+		return conn, true, nil
+	}
+	p.lock.Unlock()
+	conn, err := p.dial(addr, timeout)
+	return conn, false, err
+}
+
+func (p *connPool) dial(addr string, timeout time.Duration) (*clientConn, error) {
+	if timeout <= 0 || timeout > defaultDialTimeout {
+		timeout = defaultDialTimeout
+	}
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &clientConn{Conn: conn, reader: bufio.NewReader(conn)}, nil
+}
+
+func (p *connPool) put(key string, conn *clientConn) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if conn.reader.Buffered() > 0 || len(p.idle[key]) >= p.maxIdle {
+		conn.Close()
+		return
+	}
+	p.idle[key] = append(p.idle[key], conn)
+}
@@ -0,0 +1,221 @@
+package thrift_context
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/eolinker/eosc/eocontext"
+	"github.com/eolinker/eosc/log"
+	"github.com/eolinker/eosc/utils/config"
+	"github.com/google/uuid"
+)
+
+var _ IThriftContext = (*Context)(nil)
+
+type Context struct {
+	ctx                 context.Context
+	codec               Codec
+	completeHandler     eocontext.CompleteHandler
+	finishHandler       eocontext.FinishHandler
+	balance             eocontext.BalanceHandler
+	upstreamHostHandler eocontext.UpstreamHostHandler
+	requestReader       *RequestReader
+	proxy               *Proxy
+	response            *Response
+	labels              map[string]string
+	localAddr           net.Addr
+	port                int
+	requestID           string
+	acceptTime          time.Time
+}
+
+func NewContext(conn net.Conn, port int, codec Codec, msg *Message) *Context {
+	t := time.Now()
+	requestReader := NewRequestReader(codec, msg, conn.RemoteAddr().String())
+	ctx := &Context{
+		codec:         codec,
+		requestReader: requestReader,
+		proxy:         NewProxy(requestReader.Service(), requestReader.Method(), msg.Body),
+		response:      &Response{},
+		labels:        make(map[string]string),
+		localAddr:     conn.LocalAddr(),
+		port:          port,
+		requestID:     uuid.New().String(),
+		acceptTime:    t,
+	}
+	ctx.ctx = context.Background()
+	ctx.WithValue("request_time", t)
+	return ctx
+}
+
+func (c *Context) Codec() Codec {
+	return c.codec
+}
+
+func (c *Context) HeaderReader() IRequestReader {
+	return c.requestReader
+}
+
+func (c *Context) Proxy() IProxy {
+	return c.proxy
+}
+
+func (c *Context) Response() IResponse {
+	return c.response
+}
+
+// Invoke 将转发请求发送到节点，请求的协议及传输方式与原始请求一致
+func (c *Context) Invoke(node eocontext.INode, timeout time.Duration) error {
+	log.Debug("node: ", node.Addr())
+	msg := &Message{
+		Name:  JoinName(c.proxy.Service(), c.proxy.Method()),
+		Type:  c.requestReader.MessageType(),
+		SeqId: c.requestReader.SeqId(),
+		Body:  c.proxy.Body(),
+	}
+	reply, err := Call(c.Context(), node.Addr(), timeout, c.codec, msg)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			node.Down()
+		}
+		return err
+	}
+	if reply != nil {
+		c.response.SetBody(reply.Type, reply.Body)
+	}
+	return nil
+}
+
+// ReplyMessage 生成返回给客户端的消息，响应为空时返回异常消息，oneway请求返回nil
+func (c *Context) ReplyMessage(err error) *Message {
+	if c.requestReader.MessageType() == thrift.ONEWAY {
+		return nil
+	}
+	body := c.response.Body()
+	if body == nil {
+		if err == nil {
+			err = c.response.ResponseError()
+		}
+		if err == nil {
+			err = errors.New("no result")
+		}
+		return c.codec.ExceptionMessage(c.requestReader.Method(), c.requestReader.SeqId(), err)
+	}
+	return &Message{
+		Name:  c.requestReader.Method(),
+		Type:  c.response.MessageType(),
+		SeqId: c.requestReader.SeqId(),
+		Body:  body,
+	}
+}
+
+func (c *Context) RequestId() string {
+	return c.requestID
+}
+
+func (c *Context) AcceptTime() time.Time {
+	return c.acceptTime
+}
+
+func (c *Context) Context() context.Context {
+	if c.ctx == nil {
+		c.ctx = context.Background()
+	}
+	return c.ctx
+}
+
+func (c *Context) Value(key interface{}) interface{} {
+	return c.Context().Value(key)
+}
+
+func (c *Context) WithValue(key, val interface{}) {
+	c.ctx = context.WithValue(c.Context(), key, val)
+}
+
+func (c *Context) Scheme() string {
+	return "thrift"
+}
+
+func (c *Context) Assert(i interface{}) error {
+	if v, ok := i.(*IThriftContext); ok {
+		*v = c
+		return nil
+	}
+	return fmt.Errorf("not suport:%s", config.TypeNameOf(i))
+}
+
+func (c *Context) SetLabel(name, value string) {
+	c.labels[name] = value
+}
+
+func (c *Context) GetLabel(name string) string {
+	return c.labels[name]
+}
+
+func (c *Context) Labels() map[string]string {
+	return c.labels
+}
+
+func (c *Context) GetComplete() eocontext.CompleteHandler {
+	return c.completeHandler
+}
+
+func (c *Context) SetCompleteHandler(handler eocontext.CompleteHandler) {
+	c.completeHandler = handler
+}
+
+func (c *Context) GetFinish() eocontext.FinishHandler {
+	return c.finishHandler
+}
+
+func (c *Context) SetFinish(handler eocontext.FinishHandler) {
+	c.finishHandler = handler
+}
+
+func (c *Context) GetBalance() eocontext.BalanceHandler {
+	return c.balance
+}
+
+func (c *Context) SetBalance(handler eocontext.BalanceHandler) {
+	c.balance = handler
+}
+
+func (c *Context) GetUpstreamHostHandler() eocontext.UpstreamHostHandler {
+	return c.upstreamHostHandler
+}
+
+func (c *Context) SetUpstreamHostHandler(handler eocontext.UpstreamHostHandler) {
+	c.upstreamHostHandler = handler
+}
+
+func (c *Context) RealIP() string {
+	return c.requestReader.RemoteIP()
+}
+
+func (c *Context) LocalIP() net.IP {
+	if addr, ok := c.localAddr.(*net.TCPAddr); ok {
+		return addr.IP
+	}
+	return net.IPv4zero
+}
+
+func (c *Context) LocalAddr() net.Addr {
+	return c.localAddr
+}
+
+func (c *Context) LocalPort() int {
+	return c.port
+}
+
+func (c *Context) IsCloneable() bool {
+	return false
+}
+
+func (c *Context) Clone() (eocontext.EoContext, error) {
+	return nil, fmt.Errorf("%s %w", "ThriftContext", eocontext.ErrEoCtxUnCloneable)
+}
@@ -0,0 +1,58 @@
+package thrift_context
+
+import (
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/eolinker/eosc/eocontext"
+)
+
+func Assert(ctx eocontext.EoContext) (IThriftContext, error) {
+	var thriftContext IThriftContext
+	err := ctx.Assert(&thriftContext)
+	return thriftContext, err
+}
+
+type IThriftContext interface {
+	eocontext.EoContext
+	HeaderReader() IRequestReader // 读取原始请求
+	Proxy() IProxy                // 读写转发请求
+	Response() IResponse          // 处理返回结果，可读可写
+	Invoke(node eocontext.INode, timeout time.Duration) error
+}
+
+type IRequestReader interface {
+	// Service 多路复用（TMultiplexedProtocol）时消息名中的服务名，否则为空
+	Service() string
+	Method() string
+	// Name 原始消息名
+	Name() string
+	SeqId() int32
+	MessageType() thrift.TMessageType
+	Protocol() string
+	Transport() string
+	Body() []byte
+	RemoteIP() string
+	RemoteAddr() string
+}
+
+type IProxy interface {
+	Service() string
+	SetService(service string)
+	Method() string
+	SetMethod(method string)
+	// Body 参数结构体的编码数据，不包含消息头
+	Body() []byte
+	SetBody(body []byte)
+}
+
+type IResponse interface {
+	ResponseError() error
+	SetResponseError(err error)
+	SetResponseTime(duration time.Duration)
+	ResponseTime() time.Duration
+	MessageType() thrift.TMessageType
+	// Body 返回结构体的编码数据，不包含消息头
+	Body() []byte
+	SetBody(messageType thrift.TMessageType, body []byte)
+}
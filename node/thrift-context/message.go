@@ -0,0 +1,271 @@
+package thrift_context
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/apache/thrift/lib/go/thrift"
+)
+
+const (
+	ProtocolBinary  = "binary"
+	ProtocolCompact = "compact"
+
+	TransportFramed   = "framed"
+	TransportBuffered = "buffered"
+
+	// MaxFrameSize 单个消息的最大长度
+	MaxFrameSize = thrift.DEFAULT_MAX_FRAME_SIZE
+
+	binaryVersion1 = 0x80
+	compactID      = 0x82
+
+	// HeaderSize 识别请求最多需要的字节数：framed传输的4字节长度及binary协议的4字节版本号与消息类型
+	HeaderSize = 8
+)
+
+var (
+	ErrUnknownProtocol = errors.New("unknown thrift protocol")
+	ErrFrameTooLarge   = errors.New("thrift frame too large")
+)
+
+var configuration = &thrift.TConfiguration{
+	MaxFrameSize: MaxFrameSize,
+}
+
+// Message Thrift消息，Body为参数或返回结构体的编码数据
+type Message struct {
+	Name  string
+	Type  thrift.TMessageType
+	SeqId int32
+	Body  []byte
+}
+
+// Codec 消息的协议及传输方式
+type Codec struct {
+	Protocol  string
+	Transport string
+}
+
+// MatchHeader 根据已读取的起始字节判断是否为Thrift请求，字节数不足以判断时more为true
+func MatchHeader(data []byte) (matched bool, more bool) {
+	_, matched, more = match(data)
+	return
+}
+
+// DetectCodec 根据连接的起始字节判断协议及传输方式
+func DetectCodec(r *bufio.Reader) (Codec, error) {
+	for n := 1; n <= HeaderSize; n++ {
+		data, err := r.Peek(n)
+		if err != nil {
+			return Codec{}, err
+		}
+		codec, matched, more := match(data)
+		if matched {
+			return codec, nil
+		}
+		if !more {
+			break
+		}
+	}
+	return Codec{}, ErrUnknownProtocol
+}
+
+func match(data []byte) (Codec, bool, bool) {
+	if len(data) == 0 {
+		return Codec{}, false, true
+	}
+	if data[0] == binaryVersion1 || data[0] == compactID {
+		protocol, matched, more := messageBegin(data)
+		return Codec{Protocol: protocol, Transport: TransportBuffered}, matched, more
+	}
+	// framed传输以4字节的消息长度开头，长度不超过MaxFrameSize，因此首字节必为0
+	if data[0] != 0 {
+		return Codec{}, false, false
+	}
+	if len(data) < 4 {
+		return Codec{}, false, true
+	}
+	if length := binary.BigEndian.Uint32(data[:4]); length == 0 || length > MaxFrameSize {
+		return Codec{}, false, false
+	}
+	protocol, matched, more := messageBegin(data[4:])
+	return Codec{Protocol: protocol, Transport: TransportFramed}, matched, more
+}
+
+// messageBegin 识别请求消息头：binary协议以版本号0x80010000与消息类型开头，compact协议以0x82开头，随后是版本及消息类型
+func messageBegin(data []byte) (protocol string, matched bool, more bool) {
+	if len(data) == 0 {
+		return "", false, true
+	}
+	switch data[0] {
+	case binaryVersion1:
+		if len(data) < 4 {
+			return ProtocolBinary, false, bytes.HasPrefix([]byte{binaryVersion1, 0x01, 0x00}, data)
+		}
+		return ProtocolBinary, data[1] == 0x01 && data[2] == 0x00 && isRequest(thrift.TMessageType(data[3])), false
+	case compactID:
+		if len(data) < 2 {
+			return ProtocolCompact, false, true
+		}
+		version, messageType := data[1]&0x1f, thrift.TMessageType(data[1]>>5)
+		return ProtocolCompact, version == 1 && isRequest(messageType), false
+	}
+	return "", false, false
+}
+
+func isRequest(messageType thrift.TMessageType) bool {
+	return messageType == thrift.CALL || messageType == thrift.ONEWAY
+}
+
+// NewProtocol 创建指定协议的编解码器
+func NewProtocol(trans thrift.TTransport, protocol string) thrift.TProtocol {
+	if protocol == ProtocolCompact {
+		return thrift.NewTCompactProtocolConf(trans, configuration)
+	}
+	return thrift.NewTBinaryProtocolConf(trans, configuration)
+}
+
+// NewBufferProtocol 创建读取data的编解码器
+func NewBufferProtocol(data []byte, protocol string) thrift.TProtocol {
+	return NewProtocol(&thrift.TMemoryBuffer{Buffer: bytes.NewBuffer(data)}, protocol)
+}
+
+// ReadMessage 读取一个完整的消息
+func (c Codec) ReadMessage(r *bufio.Reader) (*Message, error) {
+	ctx := context.Background()
+	if c.Transport == TransportFramed {
+		var size [4]byte
+		if _, err := io.ReadFull(r, size[:]); err != nil {
+			return nil, err
+		}
+		length := binary.BigEndian.Uint32(size[:])
+		if length > MaxFrameSize {
+			return nil, fmt.Errorf("%w: %d", ErrFrameTooLarge, length)
+		}
+		frame := make([]byte, length)
+		if _, err := io.ReadFull(r, frame); err != nil {
+			return nil, err
+		}
+		buffer := &thrift.TMemoryBuffer{Buffer: bytes.NewBuffer(frame)}
+		name, messageType, seqId, err := NewProtocol(buffer, c.Protocol).ReadMessageBegin(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &Message{Name: name, Type: messageType, SeqId: seqId, Body: buffer.Bytes()}, nil
+	}
+	// 非framed传输时消息没有长度，跳过消息体以确定消息的结束位置
+	trans := &recordTransport{reader: r}
+	proto := NewProtocol(trans, c.Protocol)
+	name, messageType, seqId, err := proto.ReadMessageBegin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	trans.buffer.Reset()
+	if err = proto.Skip(ctx, thrift.STRUCT); err != nil {
+		return nil, err
+	}
+	if err = proto.ReadMessageEnd(ctx); err != nil {
+		return nil, err
+	}
+	body := make([]byte, trans.buffer.Len())
+	copy(body, trans.buffer.Bytes())
+	return &Message{Name: name, Type: messageType, SeqId: seqId, Body: body}, nil
+}
+
+// WriteMessage 写入一个完整的消息
+func (c Codec) WriteMessage(w io.Writer, msg *Message) error {
+	ctx := context.Background()
+	buffer := thrift.NewTMemoryBuffer()
+	if c.Transport == TransportFramed {
+		// 预留帧长度
+		buffer.Write(make([]byte, 4))
+	}
+	proto := NewProtocol(buffer, c.Protocol)
+	if err := proto.WriteMessageBegin(ctx, msg.Name, msg.Type, msg.SeqId); err != nil {
+		return err
+	}
+	buffer.Write(msg.Body)
+	if err := proto.WriteMessageEnd(ctx); err != nil {
+		return err
+	}
+	if err := proto.Flush(ctx); err != nil {
+		return err
+	}
+	data := buffer.Bytes()
+	if c.Transport == TransportFramed {
+		binary.BigEndian.PutUint32(data, uint32(len(data)-4))
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// ExceptionMessage 生成返回TApplicationException的消息
+func (c Codec) ExceptionMessage(name string, seqId int32, err error) *Message {
+	var appErr thrift.TApplicationException
+	if !errors.As(err, &appErr) {
+		appErr = thrift.NewTApplicationException(thrift.INTERNAL_ERROR, err.Error())
+	}
+	buffer := thrift.NewTMemoryBuffer()
+	appErr.Write(context.Background(), NewProtocol(buffer, c.Protocol))
+	return &Message{Name: name, Type: thrift.EXCEPTION, SeqId: seqId, Body: buffer.Bytes()}
+}
+
+// ReadException 读取类型为EXCEPTION的消息中的TApplicationException
+func (c Codec) ReadException(msg *Message) error {
+	appErr := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "")
+	if err := appErr.Read(context.Background(), NewBufferProtocol(msg.Body, c.Protocol)); err != nil {
+		return err
+	}
+	return appErr
+}
+
+// recordTransport 记录从reader中读取的数据
+type recordTransport struct {
+	reader *bufio.Reader
+	buffer bytes.Buffer
+}
+
+func (t *recordTransport) Read(p []byte) (int, error) {
+	n, err := t.reader.Read(p)
+	t.buffer.Write(p[:n])
+	return n, err
+}
+
+func (t *recordTransport) ReadByte() (byte, error) {
+	b, err := t.reader.ReadByte()
+	if err == nil {
+		t.buffer.WriteByte(b)
+	}
+	return b, err
+}
+
+func (t *recordTransport) Write(p []byte) (int, error) {
+	return 0, errors.New("record transport is read only")
+}
+
+func (t *recordTransport) Close() error {
+	return nil
+}
+
+func (t *recordTransport) Flush(ctx context.Context) error {
+	return nil
+}
+
+func (t *recordTransport) RemainingBytes() uint64 {
+	return math.MaxUint64
+}
+
+func (t *recordTransport) Open() error {
+	return nil
+}
+
+func (t *recordTransport) IsOpen() bool {
+	return true
+}
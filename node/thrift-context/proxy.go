@@ -0,0 +1,37 @@
+package thrift_context
+
+var _ IProxy = (*Proxy)(nil)
+
+type Proxy struct {
+	service string
+	method  string
+	body    []byte
+}
+
+func NewProxy(service string, method string, body []byte) *Proxy {
+	return &Proxy{service: service, method: method, body: body}
+}
+
+func (p *Proxy) Service() string {
+	return p.service
+}
+
+func (p *Proxy) SetService(service string) {
+	p.service = service
+}
+
+func (p *Proxy) Method() string {
+	return p.method
+}
+
+func (p *Proxy) SetMethod(method string) {
+	p.method = method
+}
+
+func (p *Proxy) Body() []byte {
+	return p.body
+}
+
+func (p *Proxy) SetBody(body []byte) {
+	p.body = body
+}
@@ -0,0 +1,91 @@
+package thrift_context
+
+import (
+	"net"
+	"strings"
+
+	"github.com/apache/thrift/lib/go/thrift"
+)
+
+var _ IRequestReader = (*RequestReader)(nil)
+
+type RequestReader struct {
+	codec      Codec
+	msg        *Message
+	service    string
+	method     string
+	remoteIP   string
+	remoteAddr string
+}
+
+func NewRequestReader(codec Codec, msg *Message, remoteAddr string) *RequestReader {
+	service, method := SplitName(msg.Name)
+	remoteIP := remoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		remoteIP = host
+	}
+	return &RequestReader{
+		codec:      codec,
+		msg:        msg,
+		service:    service,
+		method:     method,
+		remoteIP:   remoteIP,
+		remoteAddr: remoteAddr,
+	}
+}
+
+// SplitName 拆分多路复用的消息名，格式为 服务名:方法名
+func SplitName(name string) (string, string) {
+	if i := strings.Index(name, thrift.MULTIPLEXED_SEPARATOR); i > 0 {
+		return name[:i], name[i+len(thrift.MULTIPLEXED_SEPARATOR):]
+	}
+	return "", name
+}
+
+// JoinName 生成消息名，service为空时不使用多路复用
+func JoinName(service, method string) string {
+	if service == "" {
+		return method
+	}
+	return service + thrift.MULTIPLEXED_SEPARATOR + method
+}
+
+func (r *RequestReader) Service() string {
+	return r.service
+}
+
+func (r *RequestReader) Method() string {
+	return r.method
+}
+
+func (r *RequestReader) Name() string {
+	return r.msg.Name
+}
+
+func (r *RequestReader) SeqId() int32 {
+	return r.msg.SeqId
+}
+
+func (r *RequestReader) MessageType() thrift.TMessageType {
+	return r.msg.Type
+}
+
+func (r *RequestReader) Protocol() string {
+	return r.codec.Protocol
+}
+
+func (r *RequestReader) Transport() string {
+	return r.codec.Transport
+}
+
+func (r *RequestReader) Body() []byte {
+	return r.msg.Body
+}
+
+func (r *RequestReader) RemoteIP() string {
+	return r.remoteIP
+}
+
+func (r *RequestReader) RemoteAddr() string {
+	return r.remoteAddr
+}
@@ -0,0 +1,45 @@
+package thrift_context
+
+import (
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+)
+
+var _ IResponse = (*Response)(nil)
+
+type Response struct {
+	responseError error
+	duration      time.Duration
+	messageType   thrift.TMessageType
+	body          []byte
+}
+
+func (r *Response) ResponseError() error {
+	return r.responseError
+}
+
+func (r *Response) SetResponseError(err error) {
+	r.responseError = err
+}
+
+func (r *Response) SetResponseTime(duration time.Duration) {
+	r.duration = duration
+}
+
+func (r *Response) ResponseTime() time.Duration {
+	return r.duration
+}
+
+func (r *Response) MessageType() thrift.TMessageType {
+	return r.messageType
+}
+
+func (r *Response) Body() []byte {
+	return r.body
+}
+
+func (r *Response) SetBody(messageType thrift.TMessageType, body []byte) {
+	r.messageType = messageType
+	r.body = body
+}
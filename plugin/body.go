@@ -0,0 +1,29 @@
+package plugin
+
+import (
+	"strings"
+	"sync"
+)
+
+var (
+	bodyRequiredDrivers = make(map[string]struct{})
+	bodyRequiredLock    sync.RWMutex
+)
+
+// DeclareBodyRequired 声明插件驱动需要读取完整的请求体或响应体，此类插件不能用于开启流式转发的路由
+func DeclareBodyRequired(driver string) {
+	bodyRequiredLock.Lock()
+	defer bodyRequiredLock.Unlock()
+	bodyRequiredDrivers[driver] = struct{}{}
+}
+
+// IsBodyRequired 判断插件驱动是否需要读取完整的请求体或响应体，id可以是驱动名称或完整的驱动id
+func IsBodyRequired(id string) bool {
+	if i := strings.LastIndex(id, ":"); i >= 0 {
+		id = id[i+1:]
+	}
+	bodyRequiredLock.RLock()
+	defer bodyRequiredLock.RUnlock()
+	_, has := bodyRequiredDrivers[id]
+	return has
+}
@@ -15,6 +15,8 @@ type IPluginManager interface {
 	CreateRequest(id string, conf map[string]*Config) eocontext.IChainPro
 	Global() eocontext.IChainPro
 	GetConfigType(name string) (reflect.Type, bool)
+	// BodyRequired 返回在该配置下生效、且需要读取完整body的插件名称
+	BodyRequired(conf map[string]*Config) []string
 }
 
 func MergeConfig(high, low map[string]*Config) map[string]*Config {
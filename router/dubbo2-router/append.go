@@ -1,11 +1,15 @@
 package dubbo2_router
 
 import (
-	"github.com/eolinker/apinto/utils"
-	dubbo2_context "github.com/eolinker/eosc/eocontext/dubbo2-context"
+	"errors"
+	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/eolinker/apinto/utils"
+	dubbo2_context "github.com/eolinker/eosc/eocontext/dubbo2-context"
+
 	"github.com/eolinker/apinto/checker"
 	"github.com/eolinker/apinto/router"
 )
@@ -14,8 +18,27 @@ type RuleType = string
 
 const (
 	HttpHeader RuleType = "header"
+	Attachment RuleType = "attachment"
+	Group      RuleType = "group"
+	Version    RuleType = "version"
+	Argument   RuleType = "argument"
 )
 
+var errorArgumentIndex = errors.New("argument name must start with the argument index")
+
+// checkRule 检查规则的匹配值，argument规则还需以参数下标开头
+func checkRule(r router.AppendRule) error {
+	if _, err := checker.Parse(r.Pattern); err != nil {
+		return fmt.Errorf("rule %s[%s]=%s %w", r.Type, r.Name, r.Pattern, err)
+	}
+	if strings.ToLower(r.Type) == Argument {
+		if _, _, err := parseArgumentName(r.Name); err != nil {
+			return fmt.Errorf("rule %s[%s] %w", r.Type, r.Name, err)
+		}
+	}
+	return nil
+}
+
 func Parse(rules []router.AppendRule) router.MatcherChecker {
 	if len(rules) == 0 {
 		return &router.EmptyChecker{}
@@ -24,13 +47,32 @@ func Parse(rules []router.AppendRule) router.MatcherChecker {
 
 	for _, r := range rules {
 		ck, _ := checker.Parse(r.Pattern)
+		if ck == nil {
+			continue
+		}
 
 		switch strings.ToLower(r.Type) {
-		case HttpHeader:
+		case HttpHeader, Attachment:
 			rls = append(rls, &HeaderChecker{
 				name:    r.Name,
 				Checker: ck,
 			})
+		case Group:
+			rls = append(rls, &ServiceChecker{
+				read: func(service dubbo2_context.IServiceReader) string {
+					return service.Group()
+				},
+				Checker: ck,
+			})
+		case Version:
+			rls = append(rls, &ServiceChecker{
+				read: func(service dubbo2_context.IServiceReader) string {
+					return service.Version()
+				},
+				Checker: ck,
+			})
+		case Argument:
+			rls = append(rls, newArgumentChecker(r.Name, ck))
 		}
 	}
 	sort.Sort(rls)
@@ -55,3 +97,88 @@ func (h *HeaderChecker) MatchCheck(req interface{}) bool {
 	has := len(v) > 0
 	return h.Checker.Check(v, has)
 }
+
+// ServiceChecker 匹配请求的服务分组或版本
+type ServiceChecker struct {
+	read func(service dubbo2_context.IServiceReader) string
+	checker.Checker
+}
+
+func (s *ServiceChecker) Weight() int {
+	return int(checker.CheckTypeAll-s.Checker.CheckType()) * len(s.Checker.Value())
+}
+
+func (s *ServiceChecker) MatchCheck(req interface{}) bool {
+	request, ok := req.(dubbo2_context.IRequestReader)
+	if !ok || request.Service() == nil {
+		return false
+	}
+	v := s.read(request.Service())
+	return s.Checker.Check(v, len(v) > 0)
+}
+
+// ArgumentChecker 匹配调用参数的值，参数名为参数下标，可追加字段路径读取对象参数中的字段，如 0.user.id
+type ArgumentChecker struct {
+	index  int
+	fields []string
+	checker.Checker
+}
+
+func newArgumentChecker(name string, ck checker.Checker) *ArgumentChecker {
+	index, fields, err := parseArgumentName(name)
+	if err != nil {
+		// 规则在添加路由时已检查，不会出现无效下标
+		index = -1
+	}
+	return &ArgumentChecker{index: index, fields: fields, Checker: ck}
+}
+
+func parseArgumentName(name string) (int, []string, error) {
+	parts := strings.Split(strings.TrimSpace(name), ".")
+	index, err := strconv.Atoi(parts[0])
+	if err != nil || index < 0 {
+		return 0, nil, errorArgumentIndex
+	}
+	return index, parts[1:], nil
+}
+
+func (a *ArgumentChecker) Weight() int {
+	return int(checker.CheckTypeAll-a.Checker.CheckType()) * len(a.Checker.Value())
+}
+
+func (a *ArgumentChecker) MatchCheck(req interface{}) bool {
+	request, ok := req.(dubbo2_context.IRequestReader)
+	if !ok {
+		return false
+	}
+	v, has := a.read(request)
+	if has {
+		has = len(v) > 0
+	}
+	return a.Checker.Check(v, has)
+}
+
+func (a *ArgumentChecker) read(request dubbo2_context.IRequestReader) (string, bool) {
+	body, ok := request.Body().(*dubbo2_context.Dubbo2ParamBody)
+	if !ok || a.index < 0 || a.index >= len(body.ValuesList) {
+		return "", false
+	}
+	value := body.ValuesList[a.index]
+	for _, field := range a.fields {
+		switch v := value.(type) {
+		case map[interface{}]interface{}:
+			value, ok = v[field]
+		case map[string]interface{}:
+			value, ok = v[field]
+		default:
+			ok = false
+		}
+		if !ok {
+			return "", false
+		}
+	}
+	if value == nil {
+		return "", false
+	}
+	return utils.InterfaceToString(value), true
+}
@@ -0,0 +1,102 @@
+package dubbo2_router
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/eolinker/apinto/router"
+	eoscContext "github.com/eolinker/eosc/eocontext"
+	dubbo2_context "github.com/eolinker/eosc/eocontext/dubbo2-context"
+)
+
+type testHandler string
+
+func (h testHandler) Serve(ctx eoscContext.EoContext) {}
+
+type testService struct {
+	group   string
+	version string
+}
+
+func (s *testService) Path() string      { return "acme.UserService" }
+func (s *testService) Interface() string { return "acme.UserService" }
+func (s *testService) Group() string     { return s.group }
+func (s *testService) Version() string   { return s.version }
+func (s *testService) Method() string    { return "GetUser" }
+
+type testRequest struct {
+	service     *testService
+	attachments map[string]interface{}
+	args        []interface{}
+}
+
+func (r *testRequest) Service() dubbo2_context.IServiceReader { return r.service }
+func (r *testRequest) Body() interface{} {
+	return &dubbo2_context.Dubbo2ParamBody{ValuesList: r.args}
+}
+func (r *testRequest) Host() string                        { return "" }
+func (r *testRequest) Attachments() map[string]interface{} { return r.attachments }
+func (r *testRequest) RemoteIP() string                    { return "127.0.0.1" }
+func (r *testRequest) Attachment(name string) (interface{}, bool) {
+	v, has := r.attachments[name]
+	return v, has
+}
+
+func TestAppendMatch(t *testing.T) {
+	routes := map[string][]router.AppendRule{
+		"attachment": {{Type: Attachment, Name: "env", Pattern: "gray"}},
+		"group":      {{Type: Group, Pattern: "vip"}},
+		"version":    {{Type: Version, Pattern: "2.*"}},
+		"argument":   {{Type: Argument, Name: "1.user.id", Pattern: "1001"}},
+	}
+	root := NewRoot()
+	for id, rules := range routes {
+		if err := root.Add(id, testHandler(id), 20880, "acme.UserService", "GetUser", rules); err != nil {
+			t.Fatal(err)
+		}
+	}
+	matcher := root.Build()
+
+	tests := []struct {
+		name    string
+		request *testRequest
+		want    string
+	}{
+		{name: "attachment", request: &testRequest{service: &testService{}, attachments: map[string]interface{}{"env": "gray"}}, want: "attachment"},
+		{name: "group", request: &testRequest{service: &testService{group: "vip"}}, want: "group"},
+		{name: "version", request: &testRequest{service: &testService{version: "2.1.0"}}, want: "version"},
+		{name: "argument", request: &testRequest{service: &testService{}, args: []interface{}{"x", map[interface{}]interface{}{"user": map[string]interface{}{"id": 1001}}}}, want: "argument"},
+		{name: "argument missing", request: &testRequest{service: &testService{}, args: []interface{}{"x"}}, want: ""},
+		{name: "version mismatch", request: &testRequest{service: &testService{version: "1.0.0"}}, want: ""},
+	}
+	for _, tt := range tests {
+		var got string
+		if h, ok := matcher.Match(20880, tt.request); ok {
+			got = string(h.(testHandler))
+		}
+		if got != tt.want {
+			t.Errorf("%s: matched %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAppendRuleError(t *testing.T) {
+	tests := []struct {
+		rule router.AppendRule
+		err  error
+	}{
+		{rule: router.AppendRule{Type: Attachment, Name: "env", Pattern: "~=("}},
+		{rule: router.AppendRule{Type: Argument, Name: "user.id", Pattern: "1001"}, err: errorArgumentIndex},
+		{rule: router.AppendRule{Type: Argument, Name: "-1", Pattern: "1001"}, err: errorArgumentIndex},
+	}
+	for _, tt := range tests {
+		err := NewRoot().Add("bad", testHandler("bad"), 20880, "acme.UserService", "GetUser", []router.AppendRule{tt.rule})
+		if err == nil {
+			t.Errorf("%s[%s]=%s: expect config error", tt.rule.Type, tt.rule.Name, tt.rule.Pattern)
+			continue
+		}
+		if tt.err != nil && !errors.Is(err, tt.err) {
+			t.Errorf("%s[%s]: got %v, want %v", tt.rule.Type, tt.rule.Name, err, tt.err)
+		}
+	}
+}
@@ -204,6 +204,11 @@ func (h *Hosts) Add(id string, handler router.IRouterHandler, service string, me
 }
 
 func (p *Paths) Add(id string, handler router.IRouterHandler, append []router.AppendRule) error {
+	for _, r := range append {
+		if err := checkRule(r); err != nil {
+			return err
+		}
+	}
 
 	key := router.Key(append)
 	h, has := p.handlers[key]
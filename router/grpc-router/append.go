@@ -14,8 +14,13 @@ type RuleType = string
 
 const (
 	HttpHeader RuleType = "header"
+	Metadata   RuleType = "metadata"
+	Authority  RuleType = "authority"
 )
 
+// authorityKey 伪头部:authority不在metadata中，按请求的host匹配
+const authorityKey = ":authority"
+
 func Parse(rules []router.AppendRule) router.MatcherChecker {
 	if len(rules) == 0 {
 		return &router.EmptyChecker{}
@@ -25,12 +30,21 @@ func Parse(rules []router.AppendRule) router.MatcherChecker {
 	for _, r := range rules {
 		ck, _ := checker.Parse(r.Pattern)
 
+		if ck == nil {
+			continue
+		}
 		switch strings.ToLower(r.Type) {
-		case HttpHeader:
+		case HttpHeader, Metadata:
+			if strings.EqualFold(r.Name, authorityKey) {
+				rls = append(rls, &AuthorityChecker{Checker: ck})
+				continue
+			}
 			rls = append(rls, &HeaderChecker{
-				name:    r.Name,
+				name:    strings.ToLower(r.Name),
 				Checker: ck,
 			})
+		case Authority:
+			rls = append(rls, &AuthorityChecker{Checker: ck})
 		}
 	}
 	sort.Sort(rls)
@@ -51,7 +65,33 @@ func (h *HeaderChecker) MatchCheck(req interface{}) bool {
 	if !ok {
 		return false
 	}
-	v := request.Headers().Get(h.name)
-	has := len(v) > 0
-	return h.Checker.Check(strings.Join(v, ";"), has)
+	values := request.Headers().Get(h.name)
+	if len(values) == 0 {
+		return h.Checker.Check("", false)
+	}
+	// 多值metadata任一值满足规则即匹配
+	for _, v := range values {
+		if h.Checker.Check(v, true) {
+			return true
+		}
+	}
+	return h.Checker.Check(strings.Join(values, ";"), true)
+}
+
+// AuthorityChecker 按请求的:authority（含端口）匹配
+type AuthorityChecker struct {
+	checker.Checker
+}
+
+func (a *AuthorityChecker) Weight() int {
+	return int(checker.CheckTypeAll-a.Checker.CheckType()) * len(a.Checker.Value())
+}
+
+func (a *AuthorityChecker) MatchCheck(req interface{}) bool {
+	request, ok := req.(grpc_context.IRequest)
+	if !ok {
+		return false
+	}
+	authority := request.Host()
+	return a.Checker.Check(authority, authority != "")
 }
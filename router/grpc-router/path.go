@@ -0,0 +1,66 @@
+package grpc_router
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eolinker/apinto/checker"
+	"github.com/eolinker/apinto/router"
+)
+
+// newPathChecker 根据服务名与方法名规则生成路径检查器，服务名与方法名分别支持checker语法，
+// 如服务名 acme.billing.* 匹配整个包下的服务，方法名 ~=^Get 按正则匹配方法
+func newPathChecker(service string, method string) (checker.Checker, error) {
+	sc, err := checker.Parse(service)
+	if err != nil {
+		return nil, fmt.Errorf("service=%s %w", service, err)
+	}
+	mc, err := checker.Parse(method)
+	if err != nil {
+		return nil, fmt.Errorf("method=%s %w", method, err)
+	}
+	if sc.CheckType() == checker.CheckTypeEqual && mc.CheckType() == checker.CheckTypeEqual {
+		return checker.Parse(fmt.Sprintf("%s/%s", sc.Value(), mc.Value()))
+	}
+	if sc.CheckType() == checker.CheckTypeAll && mc.CheckType() == checker.CheckTypeAll {
+		return checker.Parse(router.All)
+	}
+	return &pathChecker{service: sc, method: mc}, nil
+}
+
+// pathChecker 分别检查请求路径中的服务名与方法名
+type pathChecker struct {
+	service checker.Checker
+	method  checker.Checker
+}
+
+func (p *pathChecker) Key() string {
+	return fmt.Sprintf("%s/%s", p.service.Key(), p.method.Key())
+}
+
+func (p *pathChecker) Value() string {
+	return fmt.Sprintf("%s/%s", p.service.Value(), p.method.Value())
+}
+
+// CheckType 以服务名与方法名中较宽松的规则作为路径的匹配类型，任意匹配的部分不参与比较；
+// 其中一部分为任意匹配时，全等匹配的另一部分按前缀匹配的优先级处理
+func (p *pathChecker) CheckType() checker.CheckType {
+	ct := checker.CheckTypeEqual
+	for _, c := range []checker.Checker{p.service, p.method} {
+		if c.CheckType() != checker.CheckTypeAll && c.CheckType() > ct {
+			ct = c.CheckType()
+		}
+	}
+	if ct == checker.CheckTypeEqual {
+		return checker.CheckTypePrefix
+	}
+	return ct
+}
+
+func (p *pathChecker) Check(v string, has bool) bool {
+	service, method := v, ""
+	if i := strings.LastIndex(v, "/"); i >= 0 {
+		service, method = v[:i], v[i+1:]
+	}
+	return p.service.Check(service, has && service != "") && p.method.Check(method, has && method != "")
+}
@@ -0,0 +1,38 @@
+package grpc_router
+
+import (
+	"testing"
+
+	"github.com/eolinker/apinto/checker"
+)
+
+func TestPathChecker(t *testing.T) {
+	tests := []struct {
+		service string
+		method  string
+		path    string
+		want    bool
+		tp      checker.CheckType
+	}{
+		{service: "acme.billing.Invoice", method: "Get", path: "acme.billing.Invoice/Get", want: true, tp: checker.CheckTypeEqual},
+		{service: "acme.billing.*", method: "*", path: "acme.billing.Invoice/Get", want: true, tp: checker.CheckTypePrefix},
+		{service: "acme.billing.*", method: "", path: "acme.order.Order/Get", want: false, tp: checker.CheckTypePrefix},
+		{service: "acme.billing.Invoice", method: "", path: "acme.billing.Invoice/List", want: true, tp: checker.CheckTypePrefix},
+		{service: "acme.*", method: "~=^(Get|List)", path: "acme.billing.Invoice/List", want: true, tp: checker.CheckTypeRegular},
+		{service: "acme.*", method: "~=^(Get|List)", path: "acme.billing.Invoice/Delete", want: false, tp: checker.CheckTypeRegular},
+		{service: "*", method: "Get", path: "acme.billing.Invoice/Get", want: true, tp: checker.CheckTypePrefix},
+		{service: "*", method: "", path: "acme.billing.Invoice/Get", want: true, tp: checker.CheckTypeAll},
+	}
+	for _, tt := range tests {
+		ck, err := newPathChecker(tt.service, tt.method)
+		if err != nil {
+			t.Fatalf("%s/%s: %v", tt.service, tt.method, err)
+		}
+		if ck.CheckType() != tt.tp {
+			t.Errorf("%s/%s: check type %d, want %d", tt.service, tt.method, ck.CheckType(), tt.tp)
+		}
+		if got := ck.Check(tt.path, true); got != tt.want {
+			t.Errorf("%s/%s check %s: got %v, want %v", tt.service, tt.method, tt.path, got, tt.want)
+		}
+	}
+}
@@ -181,10 +181,10 @@ func (p *Ports) add(id string, handler router.IRouterHandler, host string, servi
 
 func (h *Hosts) add(id string, handler router.IRouterHandler, service string, method string, append []router.AppendRule) error {
 	if method == "" {
-		method = "*"
+		method = router.All
 	}
 	path := fmt.Sprintf("%s/%s", service, method)
-	ck, err := checker.Parse(path)
+	ck, err := newPathChecker(service, method)
 	if err != nil {
 		return fmt.Errorf("path=%s %w", path, err)
 	}
@@ -214,6 +214,12 @@ func (h *Hosts) Add(id string, handler router.IRouterHandler, service string, me
 }
 
 func (p *Paths) Add(id string, handler router.IRouterHandler, append []router.AppendRule) error {
+	for _, r := range append {
+		_, err := checker.Parse(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("append %s[%s] %w", r.Type, r.Name, err)
+		}
+	}
 
 	key := router.Key(append)
 	h, has := p.handlers[key]
@@ -15,8 +15,12 @@ const (
 	HttpHeader RuleType = "header"
 	HttpQuery  RuleType = "query"
 	HttpCookie RuleType = "cookie"
+	// HttpSubprotocol 匹配websocket握手请求Sec-WebSocket-Protocol中客户端声明的任一子协议
+	HttpSubprotocol RuleType = "subprotocol"
 )
 
+const subprotocolHeader = "Sec-WebSocket-Protocol"
+
 func Parse(rules []router.AppendRule) router.MatcherChecker {
 	if len(rules) == 0 {
 		return &router.EmptyChecker{}
@@ -42,6 +46,10 @@ func Parse(rules []router.AppendRule) router.MatcherChecker {
 				name:    r.Name,
 				Checker: ck,
 			})
+		case HttpSubprotocol:
+			rls = append(rls, &SubprotocolChecker{
+				Checker: ck,
+			})
 		}
 	}
 	sort.Sort(rls)
@@ -104,3 +112,28 @@ func (q *QueryChecker) MatchCheck(req interface{}) bool {
 	has := len(v) > 0
 	return q.Checker.Check(v, has)
 }
+
+type SubprotocolChecker struct {
+	checker.Checker
+}
+
+func (s *SubprotocolChecker) Weight() int {
+	return int(checker.CheckTypeAll-s.Checker.CheckType()) * len(s.Checker.Value())
+}
+
+func (s *SubprotocolChecker) MatchCheck(req interface{}) bool {
+	request, ok := req.(http_service.IRequestReader)
+	if !ok {
+		return false
+	}
+	v := request.Header().GetHeader(subprotocolHeader)
+	if len(v) == 0 {
+		return s.Checker.Check(v, false)
+	}
+	for _, protocol := range strings.Split(v, ",") {
+		if s.Checker.Check(strings.TrimSpace(protocol), true) {
+			return true
+		}
+	}
+	return false
+}
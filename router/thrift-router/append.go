@@ -0,0 +1,76 @@
+package thrift_router
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/eolinker/apinto/checker"
+	thrift_context "github.com/eolinker/apinto/node/thrift-context"
+	"github.com/eolinker/apinto/router"
+)
+
+type RuleType = string
+
+const (
+	Protocol  RuleType = "protocol"
+	Transport RuleType = "transport"
+	IP        RuleType = "ip"
+)
+
+func Parse(rules []router.AppendRule) router.MatcherChecker {
+	if len(rules) == 0 {
+		return &router.EmptyChecker{}
+	}
+	rls := make(router.RuleCheckers, 0, len(rules))
+
+	for _, r := range rules {
+		ck, _ := checker.Parse(r.Pattern)
+		if ck == nil {
+			continue
+		}
+		switch strings.ToLower(r.Type) {
+		case Protocol:
+			rls = append(rls, &RequestChecker{
+				read: func(request thrift_context.IRequestReader) string {
+					return request.Protocol()
+				},
+				Checker: ck,
+			})
+		case Transport:
+			rls = append(rls, &RequestChecker{
+				read: func(request thrift_context.IRequestReader) string {
+					return request.Transport()
+				},
+				Checker: ck,
+			})
+		case IP:
+			rls = append(rls, &RequestChecker{
+				read: func(request thrift_context.IRequestReader) string {
+					return request.RemoteIP()
+				},
+				Checker: ck,
+			})
+		}
+	}
+	sort.Sort(rls)
+	return rls
+}
+
+// RequestChecker 检查请求的协议、传输方式等属性
+type RequestChecker struct {
+	read func(request thrift_context.IRequestReader) string
+	checker.Checker
+}
+
+func (r *RequestChecker) Weight() int {
+	return int(checker.CheckTypeAll-r.Checker.CheckType()) * len(r.Checker.Value())
+}
+
+func (r *RequestChecker) MatchCheck(req interface{}) bool {
+	request, ok := req.(thrift_context.IRequestReader)
+	if !ok {
+		return false
+	}
+	v := r.read(request)
+	return r.Checker.Check(v, len(v) > 0)
+}
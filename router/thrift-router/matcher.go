@@ -0,0 +1,187 @@
+package thrift_router
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/eolinker/apinto/checker"
+	thrift_context "github.com/eolinker/apinto/node/thrift-context"
+	"github.com/eolinker/apinto/router"
+	"github.com/eolinker/eosc/log"
+)
+
+type readerHandler func(port int, request thrift_context.IRequestReader) (string, bool)
+
+func newPortMatcher(children map[string]router.IMatcher) router.IMatcher {
+	return &SimpleMatcher{
+		children: children,
+		name:     "port",
+		read: func(port int, request thrift_context.IRequestReader) (string, bool) {
+			return strconv.Itoa(port), true
+		},
+	}
+}
+
+type SimpleMatcher struct {
+	children map[string]router.IMatcher
+	read     readerHandler
+	name     string
+}
+
+func (s *SimpleMatcher) Match(port int, req interface{}) (router.IRouterHandler, bool) {
+	request, ok := req.(thrift_context.IRequestReader)
+	if !ok {
+		return nil, false
+	}
+	if s == nil || len(s.children) == 0 {
+		return nil, false
+	}
+	value, _ := s.read(port, request)
+	log.Debug("SimpleMatcher:", s.name, "-", value)
+
+	next, has := s.children[value]
+	if has {
+		handler, ok := next.Match(port, request)
+		if ok {
+			return handler, true
+		}
+	}
+	next, has = s.children[router.All]
+	if has {
+		return next.Match(port, request)
+	}
+	return nil, false
+}
+
+func NewPathMatcher(equals map[string]router.IMatcher, checkers []*CheckerHandler, all router.IMatcher) *CheckMatcher {
+	read := func(port int, request thrift_context.IRequestReader) (string, bool) {
+		return fmt.Sprintf("%s/%s", request.Service(), request.Method()), true
+	}
+	sort.Sort(CheckerSort(checkers))
+
+	return &CheckMatcher{
+		name:     "path",
+		equals:   equals,
+		checkers: checkers,
+		read:     read,
+		all:      all,
+	}
+}
+
+type CheckMatcher struct {
+	equals   map[string]router.IMatcher //存放使用全等匹配的指标节点
+	read     readerHandler
+	checkers []*CheckerHandler //按优先顺序存放除全等匹配外的checker，顺序与nodes对应
+	all      router.IMatcher
+	name     string
+}
+
+func (c *CheckMatcher) Match(port int, req interface{}) (router.IRouterHandler, bool) {
+	request, ok := req.(thrift_context.IRequestReader)
+	if !ok {
+		return nil, false
+	}
+	value, hasValue := c.read(port, request)
+	log.Debug("CheckMatcher::Match", "(", len(c.checkers), ")", c.name, "=", value)
+
+	next, has := c.equals[value]
+	if has {
+		handler, ok := next.Match(port, request)
+		if ok {
+			return handler, true
+		}
+	}
+
+	for _, ck := range c.checkers {
+		if ck.checker.Check(value, hasValue) {
+			handler, ok := ck.next.Match(port, request)
+			if ok {
+				return handler, true
+			}
+		}
+	}
+	if c.all != nil {
+		return c.all.Match(port, request)
+	}
+	return nil, false
+}
+
+type EmptyMatcher struct {
+	handler router.IRouterHandler
+	has     bool
+}
+
+func (e *EmptyMatcher) Match(port int, request interface{}) (router.IRouterHandler, bool) {
+	return e.handler, e.has
+}
+
+type AppendMatcher struct {
+	handler  router.IRouterHandler
+	checkers router.MatcherChecker
+}
+
+func (a *AppendMatcher) Match(port int, req interface{}) (router.IRouterHandler, bool) {
+	request, ok := req.(thrift_context.IRequestReader)
+	if !ok {
+		return nil, false
+	}
+	if a.checkers.MatchCheck(request) {
+		return a.handler, true
+	}
+	return nil, false
+}
+
+type AppendMatchers []*AppendMatcher
+
+func (as AppendMatchers) Match(port int, request interface{}) (router.IRouterHandler, bool) {
+	for _, m := range as {
+		if h, ok := m.Match(port, request); ok {
+			return h, true
+		}
+	}
+	return nil, false
+}
+
+func (as AppendMatchers) Len() int {
+	return len(as)
+}
+
+// Less 规则权重高的优先匹配
+func (as AppendMatchers) Less(i, j int) bool {
+	return as[i].checkers.Weight() > as[j].checkers.Weight()
+}
+
+func (as AppendMatchers) Swap(i, j int) {
+	as[i], as[j] = as[j], as[i]
+}
+
+type CheckerHandler struct {
+	checker checker.Checker
+	next    router.IMatcher
+}
+
+type CheckerSort []*CheckerHandler
+
+func (cs CheckerSort) Len() int {
+	return len(cs)
+}
+
+func (cs CheckerSort) Less(i, j int) bool {
+	ci, cj := cs[i], cs[j]
+	//按匹配规则优先级排序
+	if ci.checker.CheckType() != cj.checker.CheckType() {
+		return ci.checker.CheckType() < cj.checker.CheckType()
+	}
+
+	//按长度排序, 优先级 长>短
+	vl := len(ci.checker.Value()) - len(cj.checker.Value())
+	if vl != 0 {
+		return vl > 0
+	}
+	return ci.checker.Value() < cj.checker.Value()
+}
+
+func (cs CheckerSort) Swap(i, j int) {
+	cs[i], cs[j] = cs[j], cs[i]
+}
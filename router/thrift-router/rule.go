@@ -0,0 +1,162 @@
+package thrift_router
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/eolinker/apinto/checker"
+	"github.com/eolinker/apinto/router"
+)
+
+var ErrorDuplicate = errors.New("duplicate")
+
+type Root struct {
+	ports map[int]*Ports
+}
+
+func NewRoot() *Root {
+	return &Root{
+		ports: map[int]*Ports{},
+	}
+}
+
+func (r *Root) Build() router.IMatcher {
+	portsHandlers := make(map[string]router.IMatcher)
+	for p, c := range r.ports {
+		name := strconv.Itoa(p)
+		if p == 0 {
+			name = router.All
+		}
+		portsHandlers[name] = c.Build()
+	}
+	return newPortMatcher(portsHandlers)
+}
+
+func (r *Root) Add(id string, handler router.IRouterHandler, port int, service string, method string, append []router.AppendRule) error {
+	pN, has := r.ports[port]
+	if !has {
+		pN = NewPorts()
+		r.ports[port] = pN
+	}
+	err := pN.Add(id, handler, service, method, append)
+	if err != nil {
+		return fmt.Errorf("port=%d %w", port, err)
+	}
+	return nil
+}
+
+type Ports struct {
+	paths map[string]*Paths
+}
+
+func NewPorts() *Ports {
+	return &Ports{
+		paths: map[string]*Paths{},
+	}
+}
+
+func (p *Ports) Build() router.IMatcher {
+	checkers := make([]*CheckerHandler, 0, len(p.paths))
+	equals := make(map[string]router.IMatcher, len(p.paths))
+	var all router.IMatcher
+	for _, next := range p.paths {
+		matcher := next.Build()
+		switch next.checker.CheckType() {
+		case checker.CheckTypeEqual:
+			equals[next.checker.Value()] = matcher
+		case checker.CheckTypeAll:
+			all = matcher
+		default:
+			checkers = append(checkers, &CheckerHandler{
+				checker: next.checker,
+				next:    matcher,
+			})
+		}
+	}
+	return NewPathMatcher(equals, checkers, all)
+}
+
+// Add 服务名为多路复用时消息名中的服务名，为空时匹配全部服务
+func (p *Ports) Add(id string, handler router.IRouterHandler, service string, method string, append []router.AppendRule) error {
+	if service == "" {
+		service = router.All
+	}
+	if method == "" {
+		method = router.All
+	}
+	ck, err := newPathChecker(service, method)
+	if err != nil {
+		return err
+	}
+	path, has := p.paths[ck.Key()]
+	if !has {
+		path = NewPaths(ck)
+		p.paths[ck.Key()] = path
+	}
+	err = path.Add(id, handler, append)
+	if err != nil {
+		return fmt.Errorf("path=%s/%s %w", service, method, err)
+	}
+	return nil
+}
+
+type Paths struct {
+	handlers map[string]*Handler
+	checker  checker.Checker
+}
+
+func NewPaths(checker checker.Checker) *Paths {
+	return &Paths{
+		checker:  checker,
+		handlers: map[string]*Handler{},
+	}
+}
+
+func (p *Paths) Build() router.IMatcher {
+	if len(p.handlers) == 0 {
+		return &EmptyMatcher{handler: nil, has: false}
+	}
+
+	if all, has := p.handlers[router.All]; has {
+		if len(p.handlers) == 1 {
+			return &EmptyMatcher{handler: all.handler, has: true}
+		}
+	}
+
+	nexts := make(AppendMatchers, 0, len(p.handlers))
+	for _, h := range p.handlers {
+		nexts = append(nexts, &AppendMatcher{
+			handler:  h.handler,
+			checkers: Parse(h.rules),
+		})
+	}
+	sort.Sort(nexts)
+	return nexts
+}
+
+func (p *Paths) Add(id string, handler router.IRouterHandler, append []router.AppendRule) error {
+	for _, r := range append {
+		if _, err := checker.Parse(r.Pattern); err != nil {
+			return fmt.Errorf("rule %s[%s]=%s %w", r.Type, r.Name, r.Pattern, err)
+		}
+	}
+	key := router.Key(append)
+	h, has := p.handlers[key]
+	if has && h.id != id {
+		return fmt.Errorf(" append{%s}:%w for (%s %s) ", key, ErrorDuplicate, h.id, id)
+	}
+	p.handlers[key] = NewHandler(id, handler, append)
+	return nil
+}
+
+type Handler struct {
+	id      string
+	handler router.IRouterHandler
+	rules   []router.AppendRule
+}
+
+func NewHandler(id string, handler router.IRouterHandler, appends []router.AppendRule) *Handler {
+	return &Handler{id: id, handler: handler, rules: appends}
+}
@@ -0,0 +1,520 @@
+package thrift_descriptor
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/cloudwego/thriftgo/parser"
+)
+
+const maxTypedefDepth = 32
+
+// Exception 服务端抛出的IDL中声明的异常
+type Exception struct {
+	// Field 异常在throws中的字段名
+	Field string
+	// Type 异常类型名
+	Type  string
+	Value map[string]interface{}
+}
+
+func (e *Exception) Error() string {
+	data, _ := json.Marshal(e.Value)
+	return fmt.Sprintf("thrift exception %s: %s", e.Type, data)
+}
+
+// resolvedType 展开typedef后的类型，file为类型定义所在的文件，用于解析容器元素的类型
+type resolvedType struct {
+	file       *parser.Thrift
+	name       string
+	typ        *parser.Type
+	enum       *parser.Enum
+	structLike *parser.StructLike
+}
+
+func resolveType(file *parser.Thrift, t *parser.Type) (*resolvedType, error) {
+	for i := 0; i < maxTypedefDepth; i++ {
+		switch t.Name {
+		case "bool", "byte", "i8", "i16", "i32", "i64", "double", "string", "binary", "uuid", "list", "set", "map":
+			return &resolvedType{file: file, name: t.Name, typ: t}, nil
+		}
+		f, name := file, t.Name
+		if i := strings.LastIndex(name, "."); i > 0 {
+			inc, has := file.GetReference(name[:i])
+			if !has {
+				return nil, fmt.Errorf("unknown type %s", t.Name)
+			}
+			f, name = inc, name[i+1:]
+		}
+		if td, has := f.GetTypedef(name); has {
+			file, t = f, td.Type
+			continue
+		}
+		if e, has := f.GetEnum(name); has {
+			return &resolvedType{file: f, name: name, typ: t, enum: e}, nil
+		}
+		for _, st := range f.GetStructLikes() {
+			if st.Name == name {
+				return &resolvedType{file: f, name: name, typ: t, structLike: st}, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown type %s", t.Name)
+	}
+	return nil, fmt.Errorf("typedef of %s is too deep", t.Name)
+}
+
+func (r *resolvedType) ttype() thrift.TType {
+	switch {
+	case r.enum != nil:
+		return thrift.I32
+	case r.structLike != nil:
+		return thrift.STRUCT
+	}
+	switch r.name {
+	case "bool":
+		return thrift.BOOL
+	case "byte", "i8":
+		return thrift.BYTE
+	case "i16":
+		return thrift.I16
+	case "i32":
+		return thrift.I32
+	case "i64":
+		return thrift.I64
+	case "double":
+		return thrift.DOUBLE
+	case "uuid":
+		return thrift.UUID
+	case "list":
+		return thrift.LIST
+	case "set":
+		return thrift.SET
+	case "map":
+		return thrift.MAP
+	}
+	return thrift.STRING
+}
+
+// WriteArgs 按方法的参数定义将json解析出的参数写为参数结构体，不写入消息头
+func (f *Function) WriteArgs(ctx context.Context, p thrift.TProtocol, args map[string]interface{}) error {
+	st := &parser.StructLike{Category: "struct", Name: f.Name + "_args", Fields: f.Arguments}
+	return writeStruct(ctx, p, f.file, st, args, "args")
+}
+
+// ReadResult 读取方法的返回结构体，服务端抛出声明的异常时返回*Exception
+func (f *Function) ReadResult(ctx context.Context, p thrift.TProtocol) (interface{}, error) {
+	fields := make([]*parser.Field, 0, len(f.Throws)+1)
+	if !f.Void {
+		fields = append(fields, &parser.Field{ID: 0, Name: "success", Type: f.FunctionType})
+	}
+	fields = append(fields, f.Throws...)
+	st := &parser.StructLike{Category: "struct", Name: f.Name + "_result", Fields: fields}
+	result, err := readStruct(ctx, p, f.file, st)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range f.Throws {
+		if v, has := result[field.Name]; has {
+			value, _ := v.(map[string]interface{})
+			return nil, &Exception{Field: field.Name, Type: field.Type.Name, Value: value}
+		}
+	}
+	if f.Void {
+		return nil, nil
+	}
+	success, has := result["success"]
+	if !has {
+		return nil, thrift.NewTApplicationException(thrift.MISSING_RESULT, f.Name+" failed: unknown result")
+	}
+	return success, nil
+}
+
+func writeStruct(ctx context.Context, p thrift.TProtocol, file *parser.Thrift, st *parser.StructLike, value map[string]interface{}, path string) error {
+	if err := p.WriteStructBegin(ctx, st.Name); err != nil {
+		return err
+	}
+	for _, field := range st.Fields {
+		v, has := value[field.Name]
+		if !has || v == nil {
+			if field.Requiredness.IsRequired() {
+				return fmt.Errorf("%s.%s: required field is missing", path, field.Name)
+			}
+			continue
+		}
+		rt, err := resolveType(file, field.Type)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", path, field.Name, err)
+		}
+		if err = p.WriteFieldBegin(ctx, field.Name, rt.ttype(), int16(field.ID)); err != nil {
+			return err
+		}
+		if err = writeValue(ctx, p, rt, v, path+"."+field.Name); err != nil {
+			return err
+		}
+		if err = p.WriteFieldEnd(ctx); err != nil {
+			return err
+		}
+	}
+	if err := p.WriteFieldStop(ctx); err != nil {
+		return err
+	}
+	return p.WriteStructEnd(ctx)
+}
+
+func writeValue(ctx context.Context, p thrift.TProtocol, rt *resolvedType, value interface{}, path string) error {
+	if rt.enum != nil {
+		v, err := enumValue(rt.enum, value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return p.WriteI32(ctx, v)
+	}
+	if rt.structLike != nil {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: %s requires a json object", path, rt.name)
+		}
+		return writeStruct(ctx, p, rt.file, rt.structLike, fields, path)
+	}
+	switch rt.name {
+	case "bool":
+		switch v := value.(type) {
+		case bool:
+			return p.WriteBool(ctx, v)
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			return p.WriteBool(ctx, b)
+		}
+		return fmt.Errorf("%s: %v is not a boolean", path, value)
+	case "byte", "i8":
+		v, err := toInt(value, 8)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return p.WriteByte(ctx, int8(v))
+	case "i16":
+		v, err := toInt(value, 16)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return p.WriteI16(ctx, int16(v))
+	case "i32":
+		v, err := toInt(value, 32)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return p.WriteI32(ctx, int32(v))
+	case "i64":
+		v, err := toInt(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return p.WriteI64(ctx, v)
+	case "double":
+		v, err := toFloat(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return p.WriteDouble(ctx, v)
+	case "string":
+		return p.WriteString(ctx, toString(value))
+	case "binary":
+		// binary在json中以base64字符串表示
+		v, err := base64.StdEncoding.DecodeString(toString(value))
+		if err != nil {
+			return fmt.Errorf("%s: binary requires a base64 string: %w", path, err)
+		}
+		return p.WriteBinary(ctx, v)
+	case "uuid":
+		v, err := thrift.ParseTuuid(toString(value))
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return p.WriteUUID(ctx, v)
+	case "list", "set":
+		list, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: requires a json array", path)
+		}
+		elem, err := resolveType(rt.file, rt.typ.ValueType)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if rt.name == "list" {
+			err = p.WriteListBegin(ctx, elem.ttype(), len(list))
+		} else {
+			err = p.WriteSetBegin(ctx, elem.ttype(), len(list))
+		}
+		if err != nil {
+			return err
+		}
+		for i, v := range list {
+			if err = writeValue(ctx, p, elem, v, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		if rt.name == "list" {
+			return p.WriteListEnd(ctx)
+		}
+		return p.WriteSetEnd(ctx)
+	case "map":
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: requires a json object", path)
+		}
+		key, err := resolveType(rt.file, rt.typ.KeyType)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		elem, err := resolveType(rt.file, rt.typ.ValueType)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if err = p.WriteMapBegin(ctx, key.ttype(), elem.ttype(), len(fields)); err != nil {
+			return err
+		}
+		for k, v := range fields {
+			// json对象的key均为字符串，按声明的key类型转换
+			if err = writeValue(ctx, p, key, mapKey(key, k), path+"."+k); err != nil {
+				return err
+			}
+			if err = writeValue(ctx, p, elem, v, path+"."+k); err != nil {
+				return err
+			}
+		}
+		return p.WriteMapEnd(ctx)
+	}
+	return fmt.Errorf("%s: unsupported type %s", path, rt.name)
+}
+
+func readStruct(ctx context.Context, p thrift.TProtocol, file *parser.Thrift, st *parser.StructLike) (map[string]interface{}, error) {
+	if _, err := p.ReadStructBegin(ctx); err != nil {
+		return nil, err
+	}
+	fields := make(map[int16]*parser.Field, len(st.Fields))
+	for _, f := range st.Fields {
+		fields[int16(f.ID)] = f
+	}
+	result := make(map[string]interface{}, len(st.Fields))
+	for {
+		_, typeId, id, err := p.ReadFieldBegin(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if typeId == thrift.STOP {
+			break
+		}
+		field, has := fields[id]
+		var rt *resolvedType
+		if has {
+			rt, err = resolveType(file, field.Type)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if rt == nil || rt.ttype() != typeId {
+			// 未定义的字段或类型不一致时跳过
+			if err = p.Skip(ctx, typeId); err != nil {
+				return nil, err
+			}
+		} else {
+			v, err := readValue(ctx, p, rt)
+			if err != nil {
+				return nil, err
+			}
+			result[field.Name] = v
+		}
+		if err = p.ReadFieldEnd(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.ReadStructEnd(ctx); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func readValue(ctx context.Context, p thrift.TProtocol, rt *resolvedType) (interface{}, error) {
+	if rt.enum != nil {
+		v, err := p.ReadI32(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range rt.enum.Values {
+			if e.Value == int64(v) {
+				return e.Name, nil
+			}
+		}
+		return v, nil
+	}
+	if rt.structLike != nil {
+		return readStruct(ctx, p, rt.file, rt.structLike)
+	}
+	switch rt.name {
+	case "bool":
+		return p.ReadBool(ctx)
+	case "byte", "i8":
+		return p.ReadByte(ctx)
+	case "i16":
+		return p.ReadI16(ctx)
+	case "i32":
+		return p.ReadI32(ctx)
+	case "i64":
+		return p.ReadI64(ctx)
+	case "double":
+		return p.ReadDouble(ctx)
+	case "string":
+		return p.ReadString(ctx)
+	case "binary":
+		return p.ReadBinary(ctx)
+	case "uuid":
+		v, err := p.ReadUUID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return v.String(), nil
+	case "list", "set":
+		elem, err := resolveType(rt.file, rt.typ.ValueType)
+		if err != nil {
+			return nil, err
+		}
+		var size int
+		if rt.name == "list" {
+			_, size, err = p.ReadListBegin(ctx)
+		} else {
+			_, size, err = p.ReadSetBegin(ctx)
+		}
+		if err != nil {
+			return nil, err
+		}
+		list := make([]interface{}, 0, size)
+		for i := 0; i < size; i++ {
+			v, err := readValue(ctx, p, elem)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		if rt.name == "list" {
+			err = p.ReadListEnd(ctx)
+		} else {
+			err = p.ReadSetEnd(ctx)
+		}
+		return list, err
+	case "map":
+		key, err := resolveType(rt.file, rt.typ.KeyType)
+		if err != nil {
+			return nil, err
+		}
+		elem, err := resolveType(rt.file, rt.typ.ValueType)
+		if err != nil {
+			return nil, err
+		}
+		_, _, size, err := p.ReadMapBegin(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[string]interface{}, size)
+		for i := 0; i < size; i++ {
+			k, err := readValue(ctx, p, key)
+			if err != nil {
+				return nil, err
+			}
+			v, err := readValue(ctx, p, elem)
+			if err != nil {
+				return nil, err
+			}
+			result[keyString(k)] = v
+		}
+		return result, p.ReadMapEnd(ctx)
+	}
+	return nil, fmt.Errorf("unsupported type %s", rt.name)
+}
+
+func enumValue(enum *parser.Enum, value interface{}) (int32, error) {
+	if s, ok := value.(string); ok {
+		for _, e := range enum.Values {
+			if e.Name == s {
+				return int32(e.Value), nil
+			}
+		}
+	}
+	v, err := toInt(value, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%v is not a value of enum %s", value, enum.Name)
+	}
+	return int32(v), nil
+}
+
+// mapKey 将json对象的key转换为map的key类型可接受的值
+func mapKey(key *resolvedType, k string) interface{} {
+	if key.enum != nil || key.structLike != nil {
+		return k
+	}
+	switch key.ttype() {
+	case thrift.BOOL, thrift.BYTE, thrift.I16, thrift.I32, thrift.I64, thrift.DOUBLE:
+		return json.Number(k)
+	}
+	return k
+}
+
+func keyString(k interface{}) string {
+	switch v := k.(type) {
+	case string:
+		return v
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	}
+	return fmt.Sprint(k)
+}
+
+func toInt(value interface{}, bits int) (int64, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return strconv.ParseInt(v.String(), 10, bits)
+	case float64:
+		if v != float64(int64(v)) {
+			return 0, fmt.Errorf("%v is not an integer", v)
+		}
+		return int64(v), nil
+	case string:
+		return strconv.ParseInt(v, 10, bits)
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("%v is not an integer", value)
+}
+
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return v.Float64()
+	case float64:
+		return v, nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	}
+	return 0, fmt.Errorf("%v is not a number", value)
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
@@ -0,0 +1,164 @@
+package thrift_descriptor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/cloudwego/thriftgo/parser"
+)
+
+var testFiles = map[string]string{
+	"shared.thrift": `
+namespace java com.acme.shared
+enum Status { ACTIVE = 1, BLOCKED = 2 }
+typedef i64 UserId
+exception NotFound { 1: string message }
+service BaseService { string ping() }
+`,
+	"user.thrift": `
+namespace java com.acme.user
+include "shared.thrift"
+struct User {
+  1: required shared.UserId id
+  2: string name
+  3: optional list<string> tags
+  4: optional map<i32, shared.Status> history
+  5: shared.Status status
+  6: optional binary avatar
+}
+service UserService extends shared.BaseService {
+  User getUser(1: shared.UserId id, 2: bool withTags) throws (1: shared.NotFound notFound)
+  void save(1: User user)
+}
+`,
+}
+
+func protocols() map[string]func(thrift.TTransport) thrift.TProtocol {
+	return map[string]func(thrift.TTransport) thrift.TProtocol{
+		"binary": func(t thrift.TTransport) thrift.TProtocol {
+			return thrift.NewTBinaryProtocolConf(t, nil)
+		},
+		"compact": func(t thrift.TTransport) thrift.TProtocol {
+			return thrift.NewTCompactProtocolConf(t, nil)
+		},
+	}
+}
+
+func TestFindFunction(t *testing.T) {
+	d, err := NewDescriptor(testFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, service := range []string{"UserService", "user.UserService", "com.acme.user.UserService"} {
+		fn, err := d.FindFunction(service, "getUser")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fn.Service != "UserService" {
+			t.Errorf("service of %s: %s", service, fn.Service)
+		}
+	}
+	fn, err := d.FindFunction("UserService", "ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fn.Service != "BaseService" {
+		t.Errorf("ping should be defined by BaseService, got %s", fn.Service)
+	}
+	if _, err = d.FindFunction("UserService", "remove"); err == nil {
+		t.Error("remove should not be found")
+	}
+}
+
+func TestDescriptorOrder(t *testing.T) {
+	files := map[string]string{
+		"a/user.thrift":   `service UserService { string fromA() }`,
+		"b/user.thrift":   `service UserService { string fromB() }`,
+		"c/common.thrift": `service CommonService { string fromC() }`,
+		"d/common.thrift": `service CommonService { string fromD() }`,
+		"main.thrift": `include "lib/common.thrift"
+service MainService extends common.CommonService {}`,
+	}
+	// 同名服务及按文件名查找的include均以文件名靠前的为准，不受map遍历顺序影响
+	for i := 0; i < 20; i++ {
+		d, err := NewDescriptor(files)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := d.FindFunction("UserService", "fromA"); err != nil {
+			t.Fatalf("round %d: %v", i, err)
+		}
+		if _, err := d.FindFunction("MainService", "fromC"); err != nil {
+			t.Fatalf("round %d: %v", i, err)
+		}
+	}
+}
+
+func TestCodec(t *testing.T) {
+	d, err := NewDescriptor(testFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for name, newProtocol := range protocols() {
+		t.Run(name, func(t *testing.T) {
+			save, err := d.FindFunction("UserService", "save")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var args map[string]interface{}
+			json.Unmarshal([]byte(`{"user":{"id":10,"name":"apinto","tags":["a","b"],"history":{"1":"ACTIVE","2":2},"status":"BLOCKED","avatar":"aGk=","unknown":1}}`), &args)
+			buffer := thrift.NewTMemoryBuffer()
+			if err = save.WriteArgs(ctx, newProtocol(buffer), args); err != nil {
+				t.Fatal(err)
+			}
+			st := &parser.StructLike{Name: "save_args", Fields: save.Arguments}
+			decoded, err := readStruct(ctx, newProtocol(buffer), save.file, st)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]interface{}{
+				"user": map[string]interface{}{
+					"id":      int64(10),
+					"name":    "apinto",
+					"tags":    []interface{}{"a", "b"},
+					"history": map[string]interface{}{"1": "ACTIVE", "2": "BLOCKED"},
+					"status":  "BLOCKED",
+					"avatar":  []byte("hi"),
+				},
+			}
+			if !reflect.DeepEqual(decoded, want) {
+				t.Errorf("decoded args: %v, want %v", decoded, want)
+			}
+
+			json.Unmarshal([]byte(`{"user":{"name":"apinto"}}`), &args)
+			if err = save.WriteArgs(ctx, newProtocol(thrift.NewTMemoryBuffer()), args); err == nil {
+				t.Error("missing required field should fail")
+			}
+
+			getUser, _ := d.FindFunction("UserService", "getUser")
+			buffer = thrift.NewTMemoryBuffer()
+			p := newProtocol(buffer)
+			p.WriteStructBegin(ctx, "getUser_result")
+			p.WriteFieldBegin(ctx, "notFound", thrift.STRUCT, 1)
+			p.WriteStructBegin(ctx, "NotFound")
+			p.WriteFieldBegin(ctx, "message", thrift.STRING, 1)
+			p.WriteString(ctx, "user 10")
+			p.WriteFieldEnd(ctx)
+			p.WriteFieldStop(ctx)
+			p.WriteStructEnd(ctx)
+			p.WriteFieldEnd(ctx)
+			p.WriteFieldStop(ctx)
+			p.WriteStructEnd(ctx)
+			_, err = getUser.ReadResult(ctx, newProtocol(buffer))
+			var exception *Exception
+			if !errors.As(err, &exception) || exception.Field != "notFound" || exception.Value["message"] != "user 10" {
+				t.Errorf("unexpected result error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package thrift_descriptor
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/thriftgo/parser"
+)
+
+// Descriptor 解析后的Thrift IDL，可按服务名及方法名查找方法定义
+type Descriptor struct {
+	files    []*parser.Thrift
+	services map[string]*serviceRef
+}
+
+type serviceRef struct {
+	file    *parser.Thrift
+	service *parser.Service
+}
+
+// Function Thrift方法定义
+type Function struct {
+	// Service 定义方法的服务名，不包含命名空间
+	Service string
+	*parser.Function
+	file *parser.Thrift
+}
+
+// NewDescriptor 解析IDL文件，files的key为文件名，include的文件需一并提供。
+// 文件按文件名排序后解析，同名服务及按文件名查找的include均以文件名靠前的为准
+func NewDescriptor(files map[string]string) (*Descriptor, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parsed := make(map[string]*parser.Thrift, len(files))
+	list := make([]*parser.Thrift, 0, len(files))
+	for _, name := range names {
+		t, err := parser.ParseString(name, files[name])
+		if err != nil {
+			return nil, fmt.Errorf("parse thrift file(%s) error: %w", name, err)
+		}
+		parsed[name] = t
+		list = append(list, t)
+	}
+	for _, t := range list {
+		for _, inc := range t.Includes {
+			ref, has := lookupInclude(parsed, names, t.Filename, inc.Path)
+			if !has {
+				return nil, fmt.Errorf("thrift file(%s): include file %s not found", t.Filename, inc.Path)
+			}
+			inc.Reference = ref
+		}
+	}
+	d := &Descriptor{
+		files:    list,
+		services: make(map[string]*serviceRef),
+	}
+	for _, t := range list {
+		prefix := refName(t.Filename)
+		for _, s := range t.Services {
+			ref := &serviceRef{file: t, service: s}
+			d.addService(s.Name, ref)
+			d.addService(prefix+"."+s.Name, ref)
+			for _, ns := range t.Namespaces {
+				d.addService(ns.Name+"."+s.Name, ref)
+			}
+		}
+	}
+	return d, nil
+}
+
+func (d *Descriptor) addService(name string, ref *serviceRef) {
+	if _, has := d.services[name]; has {
+		// 同名服务以文件名靠前的为准，可使用文件名或命名空间作为前缀区分
+		return
+	}
+	d.services[name] = ref
+}
+
+// Services 返回IDL中定义的全部服务名
+func (d *Descriptor) Services() []string {
+	names := make([]string, 0)
+	for _, t := range d.files {
+		for _, s := range t.Services {
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
+
+// FindFunction 查找方法，service可以是服务名或以文件名、命名空间为前缀的服务名，会查找继承的服务
+func (d *Descriptor) FindFunction(service string, method string) (*Function, error) {
+	ref, has := d.services[service]
+	if !has {
+		return nil, fmt.Errorf("thrift service %s not found", service)
+	}
+	visited := make(map[*parser.Service]struct{})
+	for ref != nil {
+		if _, has := visited[ref.service]; has {
+			break
+		}
+		visited[ref.service] = struct{}{}
+		for _, fn := range ref.service.Functions {
+			if fn.Name == method {
+				return &Function{Service: ref.service.Name, Function: fn, file: ref.file}, nil
+			}
+		}
+		if ref.service.Extends == "" {
+			break
+		}
+		ref = d.resolveService(ref.file, ref.service.Extends)
+	}
+	return nil, fmt.Errorf("thrift method %s.%s not found", service, method)
+}
+
+func (d *Descriptor) resolveService(file *parser.Thrift, name string) *serviceRef {
+	if i := strings.LastIndex(name, "."); i > 0 {
+		inc, has := file.GetReference(name[:i])
+		if !has {
+			return nil
+		}
+		file, name = inc, name[i+1:]
+	}
+	s, has := file.GetService(name)
+	if !has {
+		return nil
+	}
+	return &serviceRef{file: file, service: s}
+}
+
+// lookupInclude 按include的路径查找文件，依次尝试相对当前文件的路径、原路径及文件名，names为排序后的文件名
+func lookupInclude(files map[string]*parser.Thrift, names []string, current string, include string) (*parser.Thrift, bool) {
+	candidates := []string{path.Join(path.Dir(current), include), include, path.Base(include)}
+	for _, name := range candidates {
+		if t, has := files[name]; has {
+			return t, true
+		}
+	}
+	base := path.Base(include)
+	for _, name := range names {
+		if path.Base(name) == base {
+			return files[name], true
+		}
+	}
+	return nil, false
+}
+
+func refName(filename string) string {
+	return strings.TrimSuffix(path.Base(filename), path.Ext(filename))
+}
@@ -0,0 +1,14 @@
+package thrift_descriptor
+
+const (
+	Skill = "github.com/eolinker/apinto/thrift-transcode.transcode.IDescriptor"
+)
+
+type IDescriptor interface {
+	Descriptor() *Descriptor
+}
+
+// CheckSkill 检查目标能力是否符合
+func CheckSkill(skill string) bool {
+	return skill == Skill
+}